@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// runConfigCommand 执行 config 子命令，输出默认值和环境变量覆盖后的最终配置
+// 返回进程退出码，配置校验失败时为 1
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "配置文件路径")
+	output := fs.String("o", "yaml", "输出格式: yaml 或 json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+
+	data, err := formatConfig(cfg, *output)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to format config: %v\n", err)
+		return 2
+	}
+	stdout.Write(data)
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "Config validation warnings:\n%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// secretMask 输出配置时替换非空密钥的占位符
+const secretMask = "***"
+
+// maskSecrets 返回隐藏密钥字段的配置副本，避免 ${VAR} 展开后的密钥出现在输出中
+func maskSecrets(cfg *config.Config) *config.Config {
+	masked := *cfg
+	if masked.GRPC.Server.ReflectionAuth.Token != "" {
+		masked.GRPC.Server.ReflectionAuth.Token = secretMask
+	}
+	return &masked
+}
+
+// formatConfig 按指定格式序列化配置，密钥字段以 *** 代替
+func formatConfig(cfg *config.Config, format string) ([]byte, error) {
+	data, err := yaml.Marshal(maskSecrets(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case "yaml", "yml":
+		return data, nil
+	case "json":
+		// 通过 YAML 中转以复用 yaml 标签中的字段名
+		var tree map[string]interface{}
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, err
+		}
+		out, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(out, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", format)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfig = `server:
+  grpc_port: 9095
+grpc:
+  server:
+    max_concurrent_streams: 321
+    keepalive_time: 45
+    enable_reflection: true
+discovery:
+  type: ""
+`
+
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "application.yml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestConfigCommandYAML(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	t.Setenv("GRPC_KIT_SERVER_PORT", "8181")
+
+	var stdout, stderr bytes.Buffer
+	if code := runConfigCommand([]string{"-config", path}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	output := stdout.String()
+	for _, expected := range []string{
+		"grpc_port: 9095",
+		"max_concurrent_streams: 321",
+		"keepalive_time: 45",
+		"enable_reflection: true",
+		// 默认值
+		"max_recv_msg_size: 4194304",
+		// 环境变量覆盖
+		"port: 8181",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestConfigCommandJSON(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+
+	var stdout, stderr bytes.Buffer
+	if code := runConfigCommand([]string{"-config", path, "-o", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	var resolved struct {
+		GRPC struct {
+			Server struct {
+				MaxConcurrentStreams int `json:"max_concurrent_streams"`
+				KeepaliveTime        int `json:"keepalive_time"`
+			} `json:"server"`
+		} `json:"grpc"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &resolved); err != nil {
+		t.Fatalf("Expected valid JSON output: %v\n%s", err, stdout.String())
+	}
+	if resolved.GRPC.Server.MaxConcurrentStreams != 321 {
+		t.Errorf("Expected max_concurrent_streams 321, got %d", resolved.GRPC.Server.MaxConcurrentStreams)
+	}
+	if resolved.GRPC.Server.KeepaliveTime != 45 {
+		t.Errorf("Expected keepalive_time 45, got %d", resolved.GRPC.Server.KeepaliveTime)
+	}
+}
+
+func TestConfigCommandValidationWarnings(t *testing.T) {
+	path := writeTestConfig(t, testConfig+"tls:\n  enabled: true\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := runConfigCommand([]string{"-config", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 for invalid config, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "tls.cert_file") {
+		t.Errorf("Expected validation warning about TLS, got %q", stderr.String())
+	}
+	if stdout.Len() == 0 {
+		t.Error("Expected resolved config to be printed despite validation warnings")
+	}
+}
+
+func TestConfigCommandMasksSecrets(t *testing.T) {
+	path := writeTestConfig(t, testConfig)
+	t.Setenv("GRPC_KIT_GRPC_SERVER_REFLECTION_AUTH_TOKEN", "s3cr3t-token")
+
+	for _, format := range []string{"yaml", "json"} {
+		var stdout, stderr bytes.Buffer
+		runConfigCommand([]string{"-config", path, "-o", format}, &stdout, &stderr)
+
+		output := stdout.String()
+		if strings.Contains(output, "s3cr3t-token") {
+			t.Errorf("Expected %s output to hide the reflection token, got:\n%s", format, output)
+		}
+		if !strings.Contains(output, secretMask) {
+			t.Errorf("Expected %s output to contain %q, got:\n%s", format, secretMask, output)
+		}
+	}
+
+	// 未设置的密钥保持为空，便于看出缺少配置
+	var stdout, stderr bytes.Buffer
+	t.Setenv("GRPC_KIT_GRPC_SERVER_REFLECTION_AUTH_TOKEN", "")
+	runConfigCommand([]string{"-config", path}, &stdout, &stderr)
+	if strings.Contains(stdout.String(), secretMask) {
+		t.Errorf("Expected empty token not to be masked, got:\n%s", stdout.String())
+	}
+}
@@ -3,44 +3,84 @@ package main
 import (
 	"flag"
 	"fmt"
-	"log"
 	"os"
+	"sync/atomic"
 
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/app"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/version"
+	"go.uber.org/zap"
 )
 
 var (
-	configFile = flag.String("config", "", "配置文件路径")
-	version    = flag.Bool("version", false, "显示版本信息")
+	configFile  = flag.String("config", "", "配置文件路径")
+	showVersion = flag.Bool("version", false, "显示版本信息")
 )
 
-const (
-	Version = "1.0.0"
-	Name    = "go-grpc-kit"
-)
+const Name = "go-grpc-kit"
 
 func main() {
+	// config 子命令：输出最终配置而不启动服务
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	flag.Parse()
 
-	if *version {
-		fmt.Printf("%s version %s\n", Name, Version)
+	if *showVersion {
+		fmt.Printf("%s version %s\n", Name, version.Get())
 		os.Exit(0)
 	}
 
-	// 加载配置
-	cfg, err := config.Load(*configFile)
+	// 配置加载前使用引导日志器，保证早期错误也是结构化日志
+	logger := app.NewBootstrapLogger()
+	defer logger.Sync()
+
+	// 配置监听回调可能早于应用创建完成触发
+	var current atomic.Pointer[app.Application]
+
+	// 加载配置并监听配置变化
+	cfg, err := loadConfig(*configFile, logger, func(reloaded *config.Config) {
+		if application := current.Load(); application != nil {
+			application.ApplyConfig(reloaded)
+		}
+	})
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		os.Exit(1)
 	}
 
 	// 创建应用程序
+	// 保留配置路径，收到 SIGHUP 时从同一文件重载
 	application := app.New(
 		app.WithConfig(cfg),
+		app.WithConfigPath(*configFile),
 	)
+	current.Store(application)
 
 	// 启动应用程序
 	if err := application.Run(); err != nil {
-		log.Fatalf("Failed to run application: %v", err)
+		logger.Fatal("Failed to run application", zap.Error(err))
+	}
+}
+
+// loadConfig 加载配置并监听变化，失败时记录包含配置路径的结构化日志
+func loadConfig(path string, logger *zap.Logger, onReload func(*config.Config)) (*config.Config, error) {
+	configPath := app.DescribeConfigPath(path)
+
+	cfg, err := config.Watch(path, func(reloaded *config.Config, err error) {
+		if err != nil {
+			logger.Error("Failed to reload config",
+				zap.String("config_path", configPath),
+				zap.Error(err))
+			return
+		}
+		onReload(reloaded)
+	})
+	if err != nil {
+		logger.Error("Failed to load config",
+			zap.String("config_path", configPath),
+			zap.Error(err))
+		return nil, err
 	}
+	return cfg, nil
 }
\ No newline at end of file
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoadConfigInvalidPathLogsStructuredError(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	path := filepath.Join(t.TempDir(), "missing.yml")
+
+	cfg, err := loadConfig(path, zap.New(core), func(*config.Config) {})
+	if err == nil {
+		t.Fatal("Expected error for invalid config path")
+	}
+	if cfg != nil {
+		t.Error("Expected nil config on error")
+	}
+
+	entries := logs.FilterMessage("Failed to load config").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 error log, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != zapcore.ErrorLevel {
+		t.Errorf("Expected error level, got %v", entry.Level)
+	}
+	fields := entry.ContextMap()
+	if fields["config_path"] != path {
+		t.Errorf("Expected config_path %q, got %v", path, fields["config_path"])
+	}
+	if _, ok := fields["error"]; !ok {
+		t.Error("Expected error field")
+	}
+}
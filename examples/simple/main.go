@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log"
 
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/app"
@@ -21,6 +23,26 @@ func (s *GreeterService) SayHello(ctx context.Context, req *proto.HelloRequest)
 	}, nil
 }
 
+// Chat 实现双向流式 Chat 方法，每收到一条消息回复一条，客户端关闭发送端后结束
+func (s *GreeterService) Chat(stream proto.Greeter_ChatServer) error {
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		
+		if err := stream.Send(&proto.ChatMessage{
+			Sender: "greeter",
+			Text:   "Hello " + msg.Sender + ", you said: " + msg.Text,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
 // RegisterService 实现 ServiceRegistrar 接口
 func (s *GreeterService) RegisterService(server grpc.ServiceRegistrar) {
 	proto.RegisterGreeterServer(server, s)
@@ -111,6 +111,59 @@ func (x *HelloResponse) GetMessage() string {
 	return ""
 }
 
+// 对话消息
+type ChatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sender        string                 `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_examples_simple_proto_greeter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_examples_simple_proto_greeter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_examples_simple_proto_greeter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChatMessage) GetSender() string {
+	if x != nil {
+		return x.Sender
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
 var File_examples_simple_proto_greeter_proto protoreflect.FileDescriptor
 
 const file_examples_simple_proto_greeter_proto_rawDesc = "" +
@@ -119,9 +172,13 @@ const file_examples_simple_proto_greeter_proto_rawDesc = "" +
 	"\fHelloRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\")\n" +
 	"\rHelloResponse\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage2D\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\"9\n" +
+	"\vChatMessage\x12\x16\n" +
+	"\x06sender\x18\x01 \x01(\tR\x06sender\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text2|\n" +
 	"\aGreeter\x129\n" +
-	"\bSayHello\x12\x15.greeter.HelloRequest\x1a\x16.greeter.HelloResponseB:Z8github.com/go-grpc-kit/go-grpc-kit/examples/simple/protob\x06proto3"
+	"\bSayHello\x12\x15.greeter.HelloRequest\x1a\x16.greeter.HelloResponse\x126\n" +
+	"\x04Chat\x12\x14.greeter.ChatMessage\x1a\x14.greeter.ChatMessage(\x010\x01B:Z8github.com/go-grpc-kit/go-grpc-kit/examples/simple/protob\x06proto3"
 
 var (
 	file_examples_simple_proto_greeter_proto_rawDescOnce sync.Once
@@ -135,16 +192,19 @@ func file_examples_simple_proto_greeter_proto_rawDescGZIP() []byte {
 	return file_examples_simple_proto_greeter_proto_rawDescData
 }
 
-var file_examples_simple_proto_greeter_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_examples_simple_proto_greeter_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_examples_simple_proto_greeter_proto_goTypes = []any{
 	(*HelloRequest)(nil),  // 0: greeter.HelloRequest
 	(*HelloResponse)(nil), // 1: greeter.HelloResponse
+	(*ChatMessage)(nil),   // 2: greeter.ChatMessage
 }
 var file_examples_simple_proto_greeter_proto_depIdxs = []int32{
 	0, // 0: greeter.Greeter.SayHello:input_type -> greeter.HelloRequest
-	1, // 1: greeter.Greeter.SayHello:output_type -> greeter.HelloResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	2, // 1: greeter.Greeter.Chat:input_type -> greeter.ChatMessage
+	1, // 2: greeter.Greeter.SayHello:output_type -> greeter.HelloResponse
+	2, // 3: greeter.Greeter.Chat:output_type -> greeter.ChatMessage
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -161,7 +221,7 @@ func file_examples_simple_proto_greeter_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_examples_simple_proto_greeter_proto_rawDesc), len(file_examples_simple_proto_greeter_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   3,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
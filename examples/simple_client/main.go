@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"io"
 	"log"
 	"time"
 
@@ -38,4 +40,41 @@ func main() {
 	}
 
 	log.Printf("Response: %s", resp.Message)
+
+	// 调用双向流式方法
+	if err := chat(ctx, client, []string{"Hi", "How are you?", "Bye"}); err != nil {
+		log.Fatalf("Failed to call Chat: %v", err)
+	}
+}
+
+// chat 在流上发送所有消息并打印每条回复，发送和接收在不同协程中进行
+func chat(ctx context.Context, client proto.GreeterClient, texts []string) error {
+	stream, err := client.Chat(ctx)
+	if err != nil {
+		return err
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		for _, text := range texts {
+			if err := stream.Send(&proto.ChatMessage{Sender: "World", Text: text}); err != nil {
+				sendErr <- err
+				return
+			}
+		}
+		// 关闭发送端，服务端收到 EOF 后结束流
+		sendErr <- stream.CloseSend()
+	}()
+
+	for {
+		reply, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Chat reply: %s", reply.Text)
+	}
+	return <-sendErr
 }
\ No newline at end of file
@@ -0,0 +1,97 @@
+// Package grpcserver 提供 pkg/server 与 pkg/starter 共用的 gRPC 服务器构建函数，两者按同一配置监听端口和注册内置服务
+package grpcserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/internal/reuseport"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.uber.org/zap"
+	"golang.org/x/net/netutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// Listen 监听地址，按 server.reuse_port 设置 SO_REUSEPORT
+// 端口被占用且开启 server.auto_port 时改为监听同一主机上的随机空闲端口
+func Listen(cfg *config.ServerConfig, host string, port int, logger *zap.Logger) (net.Listener, error) {
+	addr := fmt.Sprintf("%s:%d", host, port)
+	listener, err := reuseport.Listen(addr, cfg.ReusePort)
+	if err != nil && cfg.AutoPort && errors.Is(err, syscall.EADDRINUSE) {
+		listener, err = reuseport.Listen(fmt.Sprintf("%s:0", host), cfg.ReusePort)
+		if err == nil {
+			logger.Warn("gRPC port already in use, listening on a random free port",
+				zap.String("configured", addr),
+				zap.String("address", listener.Addr().String()))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// LimitListener 限制监听器同时保持的连接数，maxConnections 小于等于 0 时不限制
+// 达到上限后新连接停留在内核队列中，直到已有连接关闭
+func LimitListener(listener net.Listener, maxConnections int) net.Listener {
+	if maxConnections <= 0 {
+		return listener
+	}
+	return netutil.LimitListener(listener, maxConnections)
+}
+
+// KeepaliveParameters 根据配置构建 Keepalive 参数，未配置任何参数时返回 false
+func KeepaliveParameters(cfg *config.GRPCServerConfig) (keepalive.ServerParameters, bool) {
+	params := keepalive.ServerParameters{
+		MaxConnectionIdle:     time.Duration(cfg.MaxConnectionIdle) * time.Second,
+		MaxConnectionAge:      time.Duration(cfg.MaxConnectionAge) * time.Second,
+		MaxConnectionAgeGrace: time.Duration(cfg.MaxConnectionAgeGrace) * time.Second,
+	}
+	if cfg.KeepaliveTime > 0 {
+		params.Time = time.Duration(cfg.KeepaliveTime) * time.Second
+		params.Timeout = time.Duration(cfg.KeepaliveTimeout) * time.Second
+	}
+
+	enabled := cfg.KeepaliveTime > 0 || cfg.MaxConnectionIdle > 0 || cfg.MaxConnectionAge > 0
+	return params, enabled
+}
+
+// HasService 检查服务器上是否已注册指定服务
+func HasService(server *grpc.Server, serviceName string) bool {
+	_, ok := server.GetServiceInfo()[serviceName]
+	return ok
+}
+
+// reflectionServices 反射服务公开的服务列表，过滤掉未公开的服务
+type reflectionServices struct {
+	server *grpc.Server
+	hidden map[string]bool
+}
+
+// GetServiceInfo 返回服务器上注册的服务，不含未公开的服务
+func (r reflectionServices) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := r.server.GetServiceInfo()
+	for name := range r.hidden {
+		delete(info, name)
+	}
+	return info
+}
+
+// RegisterReflection 注册 v1 和 v1alpha 反射服务，存在未公开的服务时使用过滤后的服务列表
+func RegisterReflection(server *grpc.Server, hidden map[string]bool) {
+	if len(hidden) == 0 {
+		reflection.Register(server)
+		return
+	}
+
+	opts := reflection.ServerOptions{Services: reflectionServices{server: server, hidden: hidden}}
+	grpc_reflection_v1.RegisterServerReflectionServer(server, reflection.NewServerV1(opts))
+	grpc_reflection_v1alpha.RegisterServerReflectionServer(server, reflection.NewServer(opts))
+}
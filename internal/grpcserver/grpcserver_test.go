@@ -0,0 +1,47 @@
+package grpcserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+)
+
+func TestKeepaliveParameters(t *testing.T) {
+	cfg := &config.GRPCServerConfig{
+		KeepaliveTime:         30,
+		KeepaliveTimeout:      5,
+		MaxConnectionIdle:     300,
+		MaxConnectionAge:      600,
+		MaxConnectionAgeGrace: 20,
+	}
+
+	params, ok := KeepaliveParameters(cfg)
+	if !ok {
+		t.Fatal("Expected keepalive parameters to be enabled")
+	}
+	if params.Time != 30*time.Second || params.Timeout != 5*time.Second {
+		t.Errorf("Unexpected keepalive time/timeout: %v/%v", params.Time, params.Timeout)
+	}
+	if params.MaxConnectionIdle != 300*time.Second {
+		t.Errorf("Expected MaxConnectionIdle 300s, got %v", params.MaxConnectionIdle)
+	}
+	if params.MaxConnectionAge != 600*time.Second {
+		t.Errorf("Expected MaxConnectionAge 600s, got %v", params.MaxConnectionAge)
+	}
+	if params.MaxConnectionAgeGrace != 20*time.Second {
+		t.Errorf("Expected MaxConnectionAgeGrace 20s, got %v", params.MaxConnectionAgeGrace)
+	}
+
+	// 只配置连接最大存活时间时也需要设置
+	cfg = &config.GRPCServerConfig{MaxConnectionAge: 60}
+	params, ok = KeepaliveParameters(cfg)
+	if !ok || params.MaxConnectionAge != time.Minute || params.Time != 0 {
+		t.Errorf("Unexpected parameters for age-only config: %+v (enabled=%v)", params, ok)
+	}
+
+	// 全部为 0 时不设置
+	if _, ok := KeepaliveParameters(&config.GRPCServerConfig{}); ok {
+		t.Error("Expected keepalive parameters to be disabled")
+	}
+}
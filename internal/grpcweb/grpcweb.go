@@ -0,0 +1,225 @@
+// Package grpcweb 将 gRPC-Web 请求转换为 gRPC 请求交给 grpc.Server 处理，浏览器无需 Envoy 等代理即可调用服务
+package grpcweb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// contentTypeGRPC gRPC 请求的内容类型前缀
+	contentTypeGRPC = "application/grpc"
+	// contentTypeGRPCWeb 二进制格式 gRPC-Web 请求的内容类型前缀
+	contentTypeGRPCWeb = "application/grpc-web"
+	// contentTypeGRPCWebText base64 格式 gRPC-Web 请求的内容类型前缀，暂不支持
+	contentTypeGRPCWebText = "application/grpc-web-text"
+
+	// trailerFrameFlag 响应体中 trailer 帧的标志位
+	trailerFrameFlag = 0x80
+
+	// preflightMaxAge 预检结果的缓存时间（秒）
+	preflightMaxAge = "600"
+)
+
+// Handler gRPC-Web 处理器
+// 只处理二进制格式（application/grpc-web、application/grpc-web+proto），trailer 以帧的形式写在响应体末尾
+type Handler struct {
+	server         http.Handler
+	allowedOrigins map[string]bool
+	allowAll       bool
+}
+
+// NewHandler 创建 gRPC-Web 处理器，server 通常为 *grpc.Server
+// allowedOrigins 为允许跨域调用的来源，"*" 允许所有来源，为空时拒绝所有跨域请求
+func NewHandler(server http.Handler, allowedOrigins []string) *Handler {
+	h := &Handler{
+		server:         server,
+		allowedOrigins: make(map[string]bool, len(allowedOrigins)),
+	}
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			h.allowAll = true
+		}
+		h.allowedOrigins[origin] = true
+	}
+	return h
+}
+
+// IsGrpcWebRequest 检查请求是否为二进制格式的 gRPC-Web 请求
+func IsGrpcWebRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, contentTypeGRPCWebText) {
+		return false
+	}
+	return contentType == contentTypeGRPCWeb || strings.HasPrefix(contentType, contentTypeGRPCWeb+"+") ||
+		strings.HasPrefix(contentType, contentTypeGRPCWeb+";")
+}
+
+// ServeHTTP 处理 CORS 预检和 gRPC-Web 调用
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin != "" {
+		w.Header().Add("Vary", "Origin")
+		if !h.originAllowed(origin) {
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		h.preflight(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "gRPC-Web requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if !IsGrpcWebRequest(r) {
+		http.Error(w, "unsupported content type, use application/grpc-web or application/grpc-web+proto", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// 转换为 gRPC 处理器要求的 HTTP/2 请求
+	req := r.Clone(r.Context())
+	req.ProtoMajor, req.ProtoMinor, req.Proto = 2, 0, "HTTP/2.0"
+	req.Header.Set("Content-Type", contentTypeGRPC+strings.TrimPrefix(r.Header.Get("Content-Type"), contentTypeGRPCWeb))
+
+	// HTTP/1.1 下开始写响应后默认无法再读取请求体
+	http.NewResponseController(w).EnableFullDuplex()
+
+	rw := &responseWriter{w: w, header: make(http.Header), exposeHeaders: origin != ""}
+	h.server.ServeHTTP(rw, req)
+	rw.finish()
+}
+
+// originAllowed 检查来源是否允许跨域调用
+func (h *Handler) originAllowed(origin string) bool {
+	return h.allowAll || h.allowedOrigins[origin]
+}
+
+// preflight 响应 CORS 预检请求
+func (h *Handler) preflight(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+		w.Header().Set("Access-Control-Allow-Headers", headers)
+	}
+	w.Header().Set("Access-Control-Max-Age", preflightMaxAge)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// responseWriter 将 gRPC 处理器的响应转换为 gRPC-Web 响应
+// 写入响应头之前的头部原样发送，之后设置的 trailer 在 finish 时编码为响应体末尾的 trailer 帧
+type responseWriter struct {
+	w             http.ResponseWriter
+	header        http.Header
+	wroteHeader   bool
+	exposeHeaders bool
+}
+
+// Header 返回 gRPC 处理器写入的头部
+func (rw *responseWriter) Header() http.Header {
+	return rw.header
+}
+
+// WriteHeader 发送响应头，内容类型转换为 gRPC-Web
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	rw.wroteHeader = true
+
+	header := rw.w.Header()
+	var exposed []string
+	for key, values := range rw.header {
+		if key == "Trailer" || strings.HasPrefix(key, http.TrailerPrefix) {
+			continue
+		}
+		header[key] = values
+		exposed = append(exposed, key)
+	}
+	if contentType := rw.header.Get("Content-Type"); strings.HasPrefix(contentType, contentTypeGRPC) {
+		header.Set("Content-Type", contentTypeGRPCWeb+strings.TrimPrefix(contentType, contentTypeGRPC))
+	}
+
+	// 跨域调用时浏览器只允许读取声明的响应头
+	if rw.exposeHeaders {
+		exposed = append(exposed, "Grpc-Status", "Grpc-Message")
+		sort.Strings(exposed)
+		header.Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+	}
+	rw.w.WriteHeader(code)
+}
+
+// Write 写入响应体
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.w.Write(b)
+}
+
+// Flush 发送已写入的数据，gRPC 处理器要求 ResponseWriter 实现 http.Flusher
+func (rw *responseWriter) Flush() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := rw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish 将 trailer 编码为 trailer 帧写入响应体
+func (rw *responseWriter) finish() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	trailers := make(http.Header)
+	for _, declared := range rw.header.Values("Trailer") {
+		for _, key := range strings.Split(declared, ",") {
+			key = http.CanonicalHeaderKey(strings.TrimSpace(key))
+			for _, value := range rw.header.Values(key) {
+				trailers.Add(key, value)
+			}
+		}
+	}
+	for key, values := range rw.header {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			for _, value := range values {
+				trailers.Add(strings.TrimPrefix(key, http.TrailerPrefix), value)
+			}
+		}
+	}
+
+	rw.w.Write(encodeTrailers(trailers))
+	rw.Flush()
+}
+
+// encodeTrailers 将 trailer 编码为 gRPC-Web trailer 帧：标志位、4 字节大端长度和 HTTP/1 格式的头部
+func encodeTrailers(trailers http.Header) []byte {
+	keys := make([]string, 0, len(trailers))
+	for key := range trailers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var body bytes.Buffer
+	for _, key := range keys {
+		for _, value := range trailers[key] {
+			body.WriteString(strings.ToLower(key))
+			body.WriteString(": ")
+			body.WriteString(value)
+			body.WriteString("\r\n")
+		}
+	}
+
+	frame := make([]byte, 5, 5+body.Len())
+	frame[0] = trailerFrameFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(body.Len()))
+	return append(frame, body.Bytes()...)
+}
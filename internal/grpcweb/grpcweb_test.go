@@ -0,0 +1,85 @@
+package grpcweb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerRejectsUnsupportedRequests(t *testing.T) {
+	called := false
+	h := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}), nil)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		origin      string
+		want        int
+	}{
+		{name: "text format", method: http.MethodPost, contentType: "application/grpc-web-text", want: http.StatusUnsupportedMediaType},
+		{name: "plain grpc", method: http.MethodPost, contentType: "application/grpc", want: http.StatusUnsupportedMediaType},
+		{name: "get", method: http.MethodGet, contentType: "application/grpc-web", want: http.StatusMethodNotAllowed},
+		{name: "cross origin", method: http.MethodPost, contentType: "application/grpc-web", origin: "https://app.example.com", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/pkg.Service/Method", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			if rr.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rr.Code)
+			}
+		})
+	}
+	if called {
+		t.Error("Expected unsupported requests not to reach the gRPC server")
+	}
+}
+
+func TestHandlerTranslatesResponse(t *testing.T) {
+	// 模拟 gRPC 处理器：写入头部和消息后刷新，再设置 trailer
+	server := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 || r.Header.Get("Content-Type") != "application/grpc+proto" {
+			t.Errorf("Expected HTTP/2 gRPC request, got %s %s", r.Proto, r.Header.Get("Content-Type"))
+		}
+		w.Header().Set("Content-Type", "application/grpc+proto")
+		w.Header().Add("Trailer", "Grpc-Status")
+		w.Header().Add("Trailer", "Grpc-Message")
+		w.Header().Set("X-Request-Id", "abc")
+		w.Write([]byte{0, 0, 0, 0, 1, 'x'})
+		w.(http.Flusher).Flush()
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Add(http.TrailerPrefix+"X-Greeted", "web")
+	})
+	h := NewHandler(server, []string{"*"})
+
+	req := httptest.NewRequest(http.MethodPost, "/pkg.Service/Method", strings.NewReader(""))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("Origin", "https://app.example.com")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if got := rr.Header().Get("Content-Type"); got != "application/grpc-web+proto" {
+		t.Errorf("Expected grpc-web content type, got %s", got)
+	}
+	if got := rr.Header().Get("Access-Control-Expose-Headers"); got != "Content-Type, Grpc-Message, Grpc-Status, X-Request-Id" {
+		t.Errorf("Unexpected exposed headers %q", got)
+	}
+	if rr.Header().Get("Trailer") != "" || rr.Header().Get("Grpc-Status") != "" {
+		t.Errorf("Expected trailers to be sent in body, got headers %v", rr.Header())
+	}
+
+	trailer := "grpc-status: 0\r\nx-greeted: web\r\n"
+	want := "\x00\x00\x00\x00\x01x" + "\x80\x00\x00\x00" + string(rune(len(trailer))) + trailer
+	if got := rr.Body.String(); got != want {
+		t.Errorf("Expected body %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,142 @@
+// Package logging 根据日志配置创建 zap 日志器，app 和 starter 框架共用
+package logging
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// New 创建日志器
+// 日志写入 cfg.OutputPaths，配置了 cfg.Rotation.Filename 时同时写入按大小滚动的文件；
+// 两者都未配置时输出到 stdout。cfg.Sampling.Enabled 为 false 时不采样
+// cfg.Format 不是 json 或 console 时使用 json 并记录警告
+func New(cfg config.LoggingConfig, level zap.AtomicLevel) (*zap.Logger, error) {
+	format, supported := normalizeFormat(cfg.Format)
+
+	outputPaths := cfg.OutputPaths
+	if len(outputPaths) == 0 && cfg.Rotation.Filename == "" {
+		outputPaths = []string{"stdout"}
+	}
+	errorOutputPaths := cfg.ErrorOutputPaths
+	if len(errorOutputPaths) == 0 {
+		errorOutputPaths = []string{"stderr"}
+	}
+
+	var cores []zapcore.Core
+	if len(outputPaths) > 0 {
+		sink, _, err := zap.Open(outputPaths...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log output paths: %w", err)
+		}
+		cores = append(cores, zapcore.NewCore(newEncoder(format, cfg.Development), sink, level))
+	}
+	if rotation := cfg.Rotation; rotation.Filename != "" {
+		writer := &lumberjack.Logger{
+			Filename:   rotation.Filename,
+			MaxSize:    rotation.MaxSize,
+			MaxAge:     rotation.MaxAge,
+			MaxBackups: rotation.MaxBackups,
+			Compress:   rotation.Compress,
+		}
+		// 文件中不写入颜色控制字符
+		cores = append(cores, zapcore.NewCore(newEncoder(format, false), zapcore.AddSync(writer), level))
+	}
+	errorSink, _, err := zap.Open(errorOutputPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log error output paths: %w", err)
+	}
+
+	core := zapcore.NewTee(cores...)
+	if cfg.Sampling.Enabled {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+	}
+
+	// 与 zap 的生产和开发配置一致：记录调用位置，生产模式 error 级别、开发模式 warn 级别附带堆栈
+	opts := []zap.Option{zap.ErrorOutput(errorSink), zap.AddCaller()}
+	if cfg.Development {
+		opts = append(opts, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+	} else {
+		opts = append(opts, zap.AddStacktrace(zapcore.ErrorLevel))
+	}
+	logger := zap.New(core, opts...)
+
+	if !supported {
+		logger.Warn("Unsupported logging format, falling back to json", zap.String("format", cfg.Format))
+	}
+	return logger, nil
+}
+
+// normalizeFormat 检查日志格式，空值使用 json，不支持的格式返回 json 和 false
+func normalizeFormat(format string) (string, bool) {
+	switch format {
+	case "json", "console":
+		return format, true
+	case "":
+		return "json", true
+	default:
+		return "json", false
+	}
+}
+
+// newEncoder 创建编码器，开发模式使用控制台格式并为级别着色
+func newEncoder(format string, development bool) zapcore.Encoder {
+	if development {
+		encoderConfig := zap.NewDevelopmentEncoderConfig()
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	if format == "console" {
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	}
+	return zapcore.NewJSONEncoder(encoderConfig)
+}
+
+// NewAccessLogger 创建访问日志器
+// 固定使用 JSON 格式和 info 级别，不受主日志器级别影响；不输出 level、msg 和调用位置，
+// 每条记录只包含 time 和拦截器写入的字段
+func NewAccessLogger(cfg config.AccessLogConfig) (*zap.Logger, error) {
+	outputPaths := cfg.OutputPaths
+	if len(outputPaths) == 0 {
+		outputPaths = []string{"stdout"}
+	}
+	sink, _, err := zap.Open(outputPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open access log output paths: %w", err)
+	}
+
+	encoderConfig := zapcore.EncoderConfig{
+		TimeKey:    "time",
+		LineEnding: zapcore.DefaultLineEnding,
+		EncodeTime: zapcore.RFC3339NanoTimeEncoder,
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), sink, zapcore.InfoLevel)
+	return zap.New(core), nil
+}
+
+// NewWithFallback 创建日志器，配置无法使用时（如输出位置无法打开）退回输出到 stderr 的生产日志器，
+// 并在其中记录原因，保证不返回 nil
+func NewWithFallback(cfg config.LoggingConfig, level zap.AtomicLevel) *zap.Logger {
+	logger, err := New(cfg, level)
+	if err == nil {
+		return logger
+	}
+
+	fallbackConfig := zap.NewProductionConfig()
+	fallbackConfig.Level = level
+	fallbackConfig.Sampling = nil
+	fallbackConfig.OutputPaths = []string{"stderr"}
+	fallbackConfig.ErrorOutputPaths = []string{"stderr"}
+	fallback, buildErr := fallbackConfig.Build()
+	if buildErr != nil {
+		return zap.NewNop()
+	}
+	fallback.Error("Failed to create configured logger, falling back to stderr", zap.Error(err))
+	return fallback
+}
@@ -0,0 +1,226 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.uber.org/zap"
+)
+
+func TestNewWritesToOutputPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := New(config.LoggingConfig{
+		Format:      "json",
+		OutputPaths: []string{path},
+	}, zap.NewAtomicLevelAt(zap.InfoLevel))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Debug("hidden")
+	logger.Info("hello", zap.String("key", "value"))
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"hello"`) || !strings.Contains(string(data), `"key":"value"`) {
+		t.Errorf("Expected log entry in file, got %q", data)
+	}
+	if strings.Contains(string(data), "hidden") {
+		t.Error("Expected debug entry to be filtered by level")
+	}
+}
+
+func TestNewRotatesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	logger, err := New(config.LoggingConfig{
+		Format:      "json",
+		OutputPaths: []string{},
+		Rotation: config.LogRotationConfig{
+			Filename:   path,
+			MaxSize:    1,
+			MaxBackups: 2,
+		},
+	}, zap.NewAtomicLevelAt(zap.InfoLevel))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// 写入超过 1MB 的日志触发滚动
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 1200; i++ {
+		logger.Info("rotate", zap.String("payload", payload))
+	}
+	logger.Sync()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read log dir: %v", err)
+	}
+	if len(entries) < 2 {
+		t.Fatalf("Expected rotated backup file, got %d files", len(entries))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat log file: %v", err)
+	}
+	if info.Size() > 1024*1024 {
+		t.Errorf("Expected current log file under 1MB, got %d bytes", info.Size())
+	}
+}
+
+func TestNewInvalidOutputPath(t *testing.T) {
+	_, err := New(config.LoggingConfig{
+		OutputPaths: []string{filepath.Join(t.TempDir(), "missing", "app.log")},
+	}, zap.NewAtomicLevelAt(zap.InfoLevel))
+	if err == nil {
+		t.Error("Expected error for unwritable output path")
+	}
+}
+
+func TestNewSampling(t *testing.T) {
+	countEntries := func(t *testing.T, sampling config.LogSamplingConfig) int {
+		path := filepath.Join(t.TempDir(), "app.log")
+		logger, err := New(config.LoggingConfig{
+			Format:      "json",
+			OutputPaths: []string{path},
+			Sampling:    sampling,
+		}, zap.NewAtomicLevelAt(zap.InfoLevel))
+		if err != nil {
+			t.Fatalf("Failed to create logger: %v", err)
+		}
+		for i := 0; i < 200; i++ {
+			logger.Info("repeated")
+		}
+		logger.Sync()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read log file: %v", err)
+		}
+		return strings.Count(string(data), `"msg":"repeated"`)
+	}
+
+	// 默认不采样，重复日志全部记录
+	if got := countEntries(t, config.LogSamplingConfig{Initial: 10, Thereafter: 100}); got != 200 {
+		t.Errorf("Expected 200 entries without sampling, got %d", got)
+	}
+
+	// 开启后先记录 initial 条，之后每 thereafter 条记录一条
+	if got := countEntries(t, config.LogSamplingConfig{Enabled: true, Initial: 10, Thereafter: 100}); got != 11 {
+		t.Errorf("Expected 11 entries with sampling, got %d", got)
+	}
+}
+
+func TestNewInvalidFormatFallsBackToJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger, err := New(config.LoggingConfig{
+		Format:      "text",
+		OutputPaths: []string{path},
+	}, zap.NewAtomicLevelAt(zap.InfoLevel))
+	if err != nil {
+		t.Fatalf("Expected invalid format to fall back, got %v", err)
+	}
+	logger.Info("hello")
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"Unsupported logging format, falling back to json"`) {
+		t.Errorf("Expected fallback warning, got %q", data)
+	}
+	if !strings.Contains(string(data), `"msg":"hello"`) {
+		t.Errorf("Expected JSON entry, got %q", data)
+	}
+}
+
+func TestNewDevelopment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotated := filepath.Join(dir, "rotated.log")
+	logger, err := New(config.LoggingConfig{
+		Format:      "json",
+		Development: true,
+		OutputPaths: []string{path},
+		Rotation:    config.LogRotationConfig{Filename: rotated},
+	}, zap.NewAtomicLevelAt(zap.DebugLevel))
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	if logger == nil {
+		t.Fatal("Expected logger to be created")
+	}
+	logger.Info("hello")
+	logger.Sync()
+
+	// 输出带颜色的级别
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "\x1b[34mINFO\x1b[0m") {
+		t.Errorf("Expected colored level, got %q", data)
+	}
+
+	// 滚动文件不带颜色
+	data, err = os.ReadFile(rotated)
+	if err != nil {
+		t.Fatalf("Failed to read rotated file: %v", err)
+	}
+	if strings.Contains(string(data), "\x1b[") {
+		t.Errorf("Expected no color codes in rotated file, got %q", data)
+	}
+}
+
+func TestNewWithFallback(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zap.WarnLevel)
+	logger := NewWithFallback(config.LoggingConfig{
+		OutputPaths: []string{filepath.Join(t.TempDir(), "missing", "app.log")},
+	}, level)
+	if logger == nil {
+		t.Fatal("Expected fallback logger")
+	}
+	logger.Warn("still usable")
+
+	// 退回的日志器沿用调用方的级别，配置重载仍然生效
+	if logger.Core().Enabled(zap.InfoLevel) {
+		t.Error("Expected info level to be disabled")
+	}
+	level.SetLevel(zap.InfoLevel)
+	if !logger.Core().Enabled(zap.InfoLevel) {
+		t.Error("Expected fallback logger to follow level changes")
+	}
+}
+
+func TestNewAccessLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	logger, err := NewAccessLogger(config.AccessLogConfig{Enabled: true, OutputPaths: []string{path}})
+	if err != nil {
+		t.Fatalf("Failed to create access logger: %v", err)
+	}
+
+	logger.Info("", zap.String("method", "/test.Service/Echo"), zap.String("code", "OK"))
+	logger.Sync()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read access log: %v", err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected one JSON line, got %q: %v", data, err)
+	}
+	// 只包含时间和调用字段，不含 level、msg 和调用位置
+	if len(entry) != 3 || entry["method"] != "/test.Service/Echo" || entry["code"] != "OK" || entry["time"] == nil {
+		t.Errorf("Unexpected access log entry %v", entry)
+	}
+}
@@ -0,0 +1,158 @@
+// Package portmux 在同一个 TCP 端口上区分 gRPC 和 HTTP/1.x 连接
+// 以 HTTP/2 连接前言开头的连接交给 gRPC，其余连接交给 HTTP 服务器，适用于只能暴露一个端口的环境
+package portmux
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// prefaceTimeout 等待客户端发送前几个字节的最长时间，超时的连接被关闭
+const prefaceTimeout = 10 * time.Second
+
+// Mux 拆分监听器的连接
+type Mux struct {
+	root net.Listener
+	grpc *childListener
+	http *childListener
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// New 创建连接拆分器，调用 Serve 后开始接受连接
+func New(root net.Listener) *Mux {
+	m := &Mux{
+		root: root,
+		done: make(chan struct{}),
+	}
+	m.grpc = newChildListener(m)
+	m.http = newChildListener(m)
+	return m
+}
+
+// GRPCListener 返回以 HTTP/2 连接前言开头的连接
+func (m *Mux) GRPCListener() net.Listener {
+	return m.grpc
+}
+
+// HTTPListener 返回其余连接
+func (m *Mux) HTTPListener() net.Listener {
+	return m.http
+}
+
+// Serve 接受连接并分发给子监听器，直到 Close 或底层监听器出错
+func (m *Mux) Serve() error {
+	for {
+		conn, err := m.root.Accept()
+		if err != nil {
+			m.Close()
+			return err
+		}
+		go m.dispatch(conn)
+	}
+}
+
+// Close 关闭底层监听器和所有子监听器
+func (m *Mux) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		err = m.root.Close()
+	})
+	return err
+}
+
+// dispatch 读取连接开头的字节，按是否为 HTTP/2 连接前言分发
+func (m *Mux) dispatch(conn net.Conn) {
+	reader := bufio.NewReaderSize(conn, len(http2.ClientPreface))
+	conn.SetReadDeadline(time.Now().Add(prefaceTimeout))
+	isHTTP2, err := matchPreface(reader)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	target := m.http
+	if isHTTP2 {
+		target = m.grpc
+	}
+	select {
+	case target.conns <- &bufferedConn{Conn: conn, reader: reader}:
+	case <-target.done:
+		conn.Close()
+	case <-m.done:
+		conn.Close()
+	}
+}
+
+// matchPreface 逐字节比较连接前言，出现不同字节时立即判定为 HTTP/1.x，不等待更多数据
+func matchPreface(reader *bufio.Reader) (bool, error) {
+	preface := []byte(http2.ClientPreface)
+	for n := 1; n <= len(preface); n++ {
+		peeked, err := reader.Peek(n)
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(peeked, preface[:n]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// childListener 接收 Mux 分发的连接
+// 关闭子监听器只停止接收对应类型的连接，底层监听器由 Mux.Close 关闭
+type childListener struct {
+	mux   *Mux
+	conns chan net.Conn
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newChildListener(m *Mux) *childListener {
+	return &childListener{
+		mux:   m,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (l *childListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	case <-l.mux.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *childListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *childListener) Addr() net.Addr {
+	return l.mux.root.Addr()
+}
+
+// bufferedConn 先返回判定时读取的字节的连接
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
@@ -0,0 +1,63 @@
+package portmux
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+func TestMuxDispatch(t *testing.T) {
+	root, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	m := New(root)
+	defer m.Close()
+	go m.Serve()
+
+	tests := []struct {
+		name     string
+		payload  string
+		listener net.Listener
+	}{
+		{name: "grpc", payload: http2.ClientPreface + "frames", listener: m.GRPCListener()},
+		{name: "http", payload: "GET / HTTP/1.1\r\n\r\n", listener: m.HTTPListener()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := net.Dial("tcp", root.Addr().String())
+			if err != nil {
+				t.Fatalf("Failed to dial: %v", err)
+			}
+			defer client.Close()
+			if _, err := client.Write([]byte(tt.payload)); err != nil {
+				t.Fatalf("Failed to write: %v", err)
+			}
+
+			conn, err := tt.listener.Accept()
+			if err != nil {
+				t.Fatalf("Failed to accept: %v", err)
+			}
+			defer conn.Close()
+
+			// 判定时读取的字节需要原样交给服务器
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			got := make([]byte, len(tt.payload))
+			if _, err := io.ReadFull(conn, got); err != nil {
+				t.Fatalf("Failed to read: %v", err)
+			}
+			if string(got) != tt.payload {
+				t.Errorf("Expected %q, got %q", tt.payload, got)
+			}
+		})
+	}
+
+	// 关闭后子监听器不再接受连接
+	m.Close()
+	if _, err := m.GRPCListener().Accept(); err == nil {
+		t.Error("Expected accept to fail after close")
+	}
+}
@@ -0,0 +1,38 @@
+// Package reuseport 提供可选启用 SO_REUSEPORT 的 TCP 监听
+// 启用后新进程可以在旧进程仍在监听时绑定同一端口，旧进程优雅关闭期间由内核在两者之间分发新连接，实现无停机的二进制升级
+package reuseport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// Listen 监听 TCP 地址，reusePort 为 true 时设置 SO_REUSEPORT，当前平台不支持时返回错误
+// 端口已被占用时返回说明原因的错误，可用 errors.Is(err, syscall.EADDRINUSE) 判断
+func Listen(addr string, reusePort bool) (net.Listener, error) {
+	var listener net.Listener
+	var err error
+	if reusePort {
+		lc := net.ListenConfig{Control: control}
+		listener, err = lc.Listen(context.Background(), "tcp", addr)
+	} else {
+		listener, err = net.Listen("tcp", addr)
+	}
+	if err != nil && errors.Is(err, syscall.EADDRINUSE) {
+		return nil, addrInUseError(addr, err)
+	}
+	return listener, err
+}
+
+// addrInUseError 说明端口被占用的常见原因和处理方式
+func addrInUseError(addr string, err error) error {
+	port := addr
+	if _, p, splitErr := net.SplitHostPort(addr); splitErr == nil {
+		port = p
+	}
+	return fmt.Errorf("port %s is already in use, probably by another instance of this service or another process; "+
+		"stop that process, change the port or enable server.auto_port: %w", port, err)
+}
@@ -0,0 +1,28 @@
+//go:build linux
+
+package reuseport
+
+import (
+	"testing"
+)
+
+func TestListenReusePort(t *testing.T) {
+	first, err := Listen("127.0.0.1:0", true)
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer first.Close()
+
+	// 启用后第二个监听器可以绑定同一端口
+	second, err := Listen(first.Addr().String(), true)
+	if err != nil {
+		t.Fatalf("Expected second listener to bind %s, got %v", first.Addr(), err)
+	}
+	second.Close()
+
+	// 未启用时绑定失败
+	if third, err := Listen(first.Addr().String(), false); err == nil {
+		third.Close()
+		t.Error("Expected bind to fail without SO_REUSEPORT")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package reuseport
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+// control 当前平台不支持 SO_REUSEPORT
+func control(network, address string, c syscall.RawConn) error {
+	return fmt.Errorf("SO_REUSEPORT is not supported on %s", runtime.GOOS)
+}
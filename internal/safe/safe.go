@@ -0,0 +1,20 @@
+// Package safe 为后台协程提供 panic 恢复，避免单个协程的 panic 使整个进程退出或让协程悄无声息地停止
+package safe
+
+import (
+	"go.uber.org/zap"
+)
+
+// Run 执行 fn，fn panic 时恢复并以 error 级别记录 msg、panic 值和堆栈，返回是否发生了 panic
+// 需要在 panic 后重启的循环可以根据返回值决定是否重新执行
+func Run(logger *zap.Logger, msg string, fn func(), fields ...zap.Field) (panicked bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			panicked = true
+			logger.Error(msg, append(fields, zap.Any("panic", p), zap.Stack("stack"))...)
+		}
+	}()
+
+	fn()
+	return false
+}
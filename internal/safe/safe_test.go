@@ -0,0 +1,33 @@
+package safe
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRun(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	logger := zap.New(core)
+
+	if Run(logger, "Task panicked", func() {}) {
+		t.Error("Expected no panic for a normal return")
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no log entries, got %v", logs.All())
+	}
+
+	if !Run(logger, "Task panicked", func() { panic("boom") }, zap.String("task", "watch")) {
+		t.Fatal("Expected panic to be reported")
+	}
+	entries := logs.All()
+	if len(entries) != 1 || entries[0].Message != "Task panicked" {
+		t.Fatalf("Expected one panic log entry, got %v", entries)
+	}
+	fields := entries[0].ContextMap()
+	if fields["panic"] != "boom" || fields["task"] != "watch" || fields["stack"] == nil {
+		t.Errorf("Expected panic value, task and stack fields, got %v", fields)
+	}
+}
@@ -2,18 +2,26 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/logging"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/client"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/server"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -23,6 +31,7 @@ import (
 // Application 应用程序
 type Application struct {
 	config          *config.Config
+	configPath      string
 	logger          *zap.Logger
 	grpcServer      *server.Server
 	httpServer      *http.Server
@@ -31,8 +40,44 @@ type Application struct {
 	services        []server.ServiceRegistrar
 	mu              sync.RWMutex
 	shutdownTimeout time.Duration
+	
+	// 调用方提供的 gRPC 服务器，由 WithExistingGrpcServer 设置
+	existingGrpcServer *grpc.Server
+	
+	// 服务发现注册器创建函数（为空时使用 discovery.NewRegistry）
+	// 及 fail_fast 关闭时创建注册器、注册失败时重新注册的后台重试状态
+	newRegistry           func(*config.DiscoveryConfig, *zap.Logger) (discovery.Registry, error)
+	registryPending       bool
+	registryRetryInterval time.Duration
+	registrationBackoff   discovery.RegistrationBackoff
+	registryRetryCancel   context.CancelFunc
+	registryRetryDone     chan struct{}
+	// 首次注册是否已成功，用于启动汇总日志
+	discoveryRegistered atomic.Bool
+	
+	// 指标注册表，由 WithMetricsRegistry 设置，为空时使用 prometheus 默认注册表
+	metricsRegistry *prometheus.Registry
+	
+	// 就绪检查，由 WithReadinessCheck 添加
+	readinessChecks        []ReadinessCheck
+	readinessPassed        atomic.Bool
+	readinessRetryInterval time.Duration
+	readinessCancel        context.CancelFunc
+	readinessDone          chan struct{}
+	
+	// 启动时预热的客户端连接，由 WithPrewarm 设置
+	prewarmServices []string
+	prewarmTimeout  time.Duration
+	
+	// 应用创建的日志器的级别，使用 WithLogger 传入的日志器时为空
+	logLevel *zap.AtomicLevel
+	// 最近一次应用的配置，用于重载时比较变化
+	appliedConfig *config.Config
 }
 
+// defaultRegistryRetryInterval 服务发现不可用时后台重试注册的间隔
+const defaultRegistryRetryInterval = 5 * time.Second
+
 // New 创建新的应用程序
 func New(opts ...Option) *Application {
 	app := &Application{
@@ -47,8 +92,17 @@ func New(opts ...Option) *Application {
 	
 	// 如果没有配置，加载默认配置
 	if app.config == nil {
-		cfg, err := config.Load("")
+		cfg, err := config.Load(app.configPath)
 		if err != nil {
+			// 此时还没有按配置创建的日志器，使用引导日志器记录
+			logger := app.logger
+			if logger == nil {
+				logger = NewBootstrapLogger()
+			}
+			logger.Error("Failed to load config, falling back to defaults",
+				zap.String("config_path", DescribeConfigPath(app.configPath)),
+				zap.Error(err))
+			
 			// 使用默认配置
 			cfg = config.Get()
 		}
@@ -73,6 +127,13 @@ func WithConfig(cfg *config.Config) Option {
 	}
 }
 
+// WithConfigPath 设置配置文件路径，未通过 WithConfig 指定配置时使用
+func WithConfigPath(path string) Option {
+	return func(app *Application) {
+		app.configPath = path
+	}
+}
+
 // WithLogger 设置日志器
 func WithLogger(logger *zap.Logger) Option {
 	return func(app *Application) {
@@ -87,6 +148,22 @@ func WithShutdownTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithExistingGrpcServer 使用调用方已配置的 gRPC 服务器
+// 应用仍负责监听、启动、健康检查和服务发现，但不再根据配置构建服务器选项和内置拦截器
+func WithExistingGrpcServer(grpcServer *grpc.Server) Option {
+	return func(app *Application) {
+		app.existingGrpcServer = grpcServer
+	}
+}
+
+// WithMetricsRegistry 使用独立的 prometheus 注册表记录 gRPC 请求指标，指标端点只暴露该注册表中的指标
+// 同一进程中运行多个应用时可避免指标冲突
+func WithMetricsRegistry(registry *prometheus.Registry) Option {
+	return func(app *Application) {
+		app.metricsRegistry = registry
+	}
+}
+
 // RegisterService 注册服务
 func (app *Application) RegisterService(service server.ServiceRegistrar) {
 	app.mu.Lock()
@@ -104,8 +181,55 @@ func (app *Application) GetClient(serviceName string) (*grpc.ClientConn, error)
 	return app.clientFactory.GetClient(serviceName)
 }
 
+
 // Run 运行应用程序
 func (app *Application) Run() error {
+	if err := app.launch(); err != nil {
+		return err
+	}
+	
+	// 等待信号
+	app.waitForShutdown()
+	
+	// 优雅关闭
+	return app.shutdown()
+}
+
+// Start 初始化并启动应用，等待就绪后返回 gRPC 监听地址和停止函数，不等待信号，适用于测试或嵌入其他程序
+// 配置了就绪检查时等待检查通过，ctx 结束前仍未就绪则关闭应用并返回错误；停止函数可多次调用
+func (app *Application) Start(ctx context.Context) (string, func(), error) {
+	if err := app.launch(); err != nil {
+		return "", nil, err
+	}
+	
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			if err := app.shutdown(); err != nil {
+				app.logger.Error("Failed to shutdown application", zap.Error(err))
+			}
+		})
+	}
+	
+	if app.readinessDone != nil {
+		select {
+		case <-app.readinessDone:
+		case <-ctx.Done():
+		}
+		if !app.readinessPassed.Load() {
+			stop()
+			if err := ctx.Err(); err != nil {
+				return "", nil, fmt.Errorf("application did not become ready: %w", err)
+			}
+			return "", nil, errors.New("application did not become ready")
+		}
+	}
+	
+	return app.grpcServer.GetAddress(), stop, nil
+}
+
+// launch 初始化组件并启动服务
+func (app *Application) launch() error {
 	app.logger.Info("Starting application...")
 	
 	// 初始化组件
@@ -117,12 +241,7 @@ func (app *Application) Run() error {
 	if err := app.start(); err != nil {
 		return fmt.Errorf("failed to start application: %w", err)
 	}
-	
-	// 等待信号
-	app.waitForShutdown()
-	
-	// 优雅关闭
-	return app.shutdown()
+	return nil
 }
 
 // initialize 初始化组件
@@ -132,13 +251,20 @@ func (app *Application) initialize() error {
 	// 创建服务发现注册器（如果配置了的话）
 	if app.config.Discovery.Type != "" {
 		var err error
-		registry, err = discovery.NewRegistry(&app.config.Discovery, app.logger)
+		registry, err = app.createRegistry()
 		if err != nil {
-			return fmt.Errorf("failed to create registry: %w", err)
+			if app.config.Discovery.FailFast {
+				return fmt.Errorf("failed to create registry: %w", err)
+			}
+			// 注册中心暂不可用时先启动服务，启动后在后台重试注册
+			app.logger.Warn("Discovery registry unavailable, service registration will be retried in background",
+				zap.String("type", app.config.Discovery.Type),
+				zap.Error(err))
+			app.registryPending = true
+		} else {
+			// 创建服务管理器
+			app.serviceManager = discovery.NewServiceManager(registry, app.logger)
 		}
-		
-		// 创建服务管理器
-		app.serviceManager = discovery.NewServiceManager(registry, app.logger)
 	}
 	
 	// 创建客户端工厂（支持DNS解析器）
@@ -146,6 +272,12 @@ func (app *Application) initialize() error {
 	
 	// 创建 gRPC 服务器
 	app.grpcServer = server.New(app.config, app.logger)
+	if app.existingGrpcServer != nil {
+		app.grpcServer.SetGrpcServer(app.existingGrpcServer)
+	}
+	if app.metricsRegistry != nil {
+		app.grpcServer.SetMetricsRegistry(app.metricsRegistry)
+	}
 	
 	// 注册业务服务
 	for _, service := range app.services {
@@ -153,6 +285,10 @@ func (app *Application) initialize() error {
 	}
 	
 	// 创建 HTTP 服务器（用于指标和健康检查）
+	// 开启 server.multiplex_http 时 gRPC 端口只提供指标、健康检查和版本端点，管理端点仍只在指标端口上提供
+	if app.config.Server.MultiplexHTTP {
+		app.grpcServer.SetHTTPHandler(app.publicHTTPHandler())
+	}
 	if app.config.Metrics.Enabled {
 		app.httpServer = app.createHTTPServer()
 	}
@@ -161,36 +297,29 @@ func (app *Application) initialize() error {
 }
 
 // start 启动服务
+// 配置了就绪检查时，检查在后台运行，通过后才将 gRPC 服务设为 SERVING 并注册到服务发现
 func (app *Application) start() error {
-	// 启动 gRPC 服务器
-	if err := app.grpcServer.Start(); err != nil {
+	// 启动 gRPC 服务器，就绪前健康状态为 NOT_SERVING
+	if err := app.grpcServer.Serve(); err != nil {
 		return fmt.Errorf("failed to start gRPC server: %w", err)
 	}
 	
-	// 注册服务到服务发现（如果启用了服务发现）
-	if app.serviceManager != nil {
-		serviceInfo := &discovery.ServiceInfo{
-			Name:    "grpc-service", // TODO: 从配置获取服务名
-			Address: app.config.Server.Host,
-			Port:    app.config.Server.GRPCPort,
-			Metadata: map[string]string{
-				"version": "1.0.0",
-			},
-		}
-		
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		
-		if err := app.serviceManager.RegisterService(ctx, serviceInfo); err != nil {
-			app.logger.Warn("Failed to register service to discovery", zap.Error(err))
+	// 预热依赖服务的连接，设置了等待时间时在设为 SERVING 之前完成
+	app.prewarmClients()
+	
+	if len(app.readinessChecks) > 0 {
+		app.startReadinessGate(app.registerToDiscovery)
+	} else {
+		if err := app.grpcServer.MarkServing(context.Background()); err != nil {
+			return fmt.Errorf("failed to start gRPC server: %w", err)
 		}
+		app.registerToDiscovery()
 	}
 	
 	// 启动 HTTP 服务器
 	if app.httpServer != nil {
 		go func() {
-			addr := fmt.Sprintf(":%d", app.config.Metrics.Port)
-			app.logger.Info("Starting HTTP server", zap.String("address", addr))
+			app.logger.Info("Starting HTTP server", zap.String("address", app.httpServer.Addr))
 			if err := app.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 				app.logger.Error("HTTP server failed", zap.Error(err))
 			}
@@ -198,16 +327,152 @@ func (app *Application) start() error {
 	}
 	
 	app.logger.Info("Application started successfully")
+	app.logStartupSummary()
 	return nil
 }
 
-// waitForShutdown 等待关闭信号
+// registerToDiscovery 注册服务到服务发现（如果启用了服务发现）
+// 首次注册失败时在后台按指数退避加抖动重试，租约丢失后重新注册，直到应用关闭
+func (app *Application) registerToDiscovery() {
+	if app.serviceManager != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		
+		if err := app.serviceManager.RegisterService(ctx, app.serviceInfo()); err != nil {
+			app.logger.Warn("Failed to register service to discovery, retrying in background", zap.Error(err))
+		} else {
+			app.discoveryRegistered.Store(true)
+		}
+		app.startRegistryRetry()
+	} else if app.registryPending {
+		app.startRegistryRetry()
+	}
+}
+
+// serviceInfo 返回注册到服务发现的服务信息
+func (app *Application) serviceInfo() *discovery.ServiceInfo {
+	address, port, err := discovery.AdvertiseAddress(app.config)
+	if err != nil {
+		app.logger.Warn("Failed to resolve advertise address, registering listen host", zap.Error(err))
+		address, port = app.config.Server.Host, app.config.Server.GRPCPort
+	}
+	
+	// 未配置 advertise_port 时注册实际监听的端口，grpc_port 为 0 或 auto_port 改用空闲端口时也能被正确发现
+	if app.config.Discovery.AdvertisePort == 0 && app.grpcServer != nil {
+		if _, boundPort, err := net.SplitHostPort(app.grpcServer.GetAddress()); err == nil {
+			if p, err := strconv.Atoi(boundPort); err == nil {
+				port = p
+			}
+		}
+	}
+	
+	// 已摘除的实例以 NOT_SERVING 注册，避免注册重试或租约恢复后重新接收流量
+	metadata := discovery.InstanceMetadata(&app.config.Discovery)
+	if app.grpcServer != nil && app.grpcServer.IsDraining() {
+		metadata[discovery.MetadataStatus] = discovery.StatusNotServing
+	}
+	
+	return &discovery.ServiceInfo{
+		Name:     "grpc-service", // TODO: 从配置获取服务名
+		Address:  address,
+		Port:     port,
+		Metadata: metadata,
+	}
+}
+
+// createRegistry 创建服务发现注册器
+func (app *Application) createRegistry() (discovery.Registry, error) {
+	if app.newRegistry != nil {
+		return app.newRegistry(&app.config.Discovery, app.logger)
+	}
+	return discovery.NewRegistry(&app.config.Discovery, app.logger)
+}
+
+// startRegistryRetry 启动后台循环，注册中心不可用时先等待其可用并创建服务管理器，之后保持服务注册
+func (app *Application) startRegistryRetry() {
+	ctx, cancel := context.WithCancel(context.Background())
+	app.registryRetryCancel = cancel
+	app.registryRetryDone = make(chan struct{})
+	
+	app.mu.RLock()
+	manager := app.serviceManager
+	app.mu.RUnlock()
+	
+	go func() {
+		defer close(app.registryRetryDone)
+		
+		if manager == nil {
+			if manager = app.waitForRegistry(ctx); manager == nil {
+				return
+			}
+		}
+		manager.KeepRegistered(ctx, app.serviceInfo(), app.registrationBackoff)
+	}()
+}
+
+// waitForRegistry 按固定间隔重试创建注册器并注册服务，成功后设置服务管理器；ctx 取消时返回 nil
+func (app *Application) waitForRegistry(ctx context.Context) *discovery.ServiceManager {
+	interval := app.registryRetryInterval
+	if interval <= 0 {
+		interval = defaultRegistryRetryInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		
+		registry, err := app.createRegistry()
+		if err != nil {
+			app.logger.Debug("Discovery registry still unavailable", zap.Error(err))
+			continue
+		}
+		
+		manager := discovery.NewServiceManager(registry, app.logger)
+		regCtx, regCancel := context.WithTimeout(ctx, 10*time.Second)
+		err = manager.RegisterService(regCtx, app.serviceInfo())
+		regCancel()
+		if err != nil {
+			app.logger.Warn("Failed to register service to discovery, retrying", zap.Error(err))
+			registry.Close()
+			continue
+		}
+		
+		app.mu.Lock()
+		app.serviceManager = manager
+		app.registryPending = false
+		app.mu.Unlock()
+		app.discoveryRegistered.Store(true)
+		
+		app.logger.Info("Service registered to discovery after registry became available")
+		return manager
+	}
+}
+
+// waitForShutdown 等待关闭信号，收到 SIGHUP 时重载配置并继续等待
 func (app *Application) waitForShutdown() {
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	
-	sig := <-sigChan
-	app.logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigChan)
+	
+	for sig := range sigChan {
+		if sig == syscall.SIGHUP {
+			app.logger.Info("Received SIGHUP, reloading config")
+			if err := app.Reload(); err != nil {
+				app.logger.Error("Failed to reload config",
+					zap.String("config_path", DescribeConfigPath(app.configPath)),
+					zap.Error(err))
+			}
+			continue
+		}
+		
+		app.logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
+		return
+	}
 }
 
 // shutdown 优雅关闭
@@ -219,6 +484,23 @@ func (app *Application) shutdown() error {
 	
 	var wg sync.WaitGroup
 	
+	// 停止就绪检查和后台注册重试，之后的服务管理器不再变化
+	app.stopReadinessGate()
+	if app.registryRetryCancel != nil {
+		app.registryRetryCancel()
+		<-app.registryRetryDone
+	}
+	app.mu.RLock()
+	serviceManager := app.serviceManager
+	app.mu.RUnlock()
+	
+	// 先注销服务，客户端在 gRPC 服务器停止前就不再把新请求发往本实例
+	if serviceManager != nil {
+		if err := serviceManager.DeregisterAll(ctx); err != nil {
+			app.logger.Error("Failed to deregister services", zap.Error(err))
+		}
+	}
+	
 	// 关闭 HTTP 服务器
 	if app.httpServer != nil {
 		wg.Add(1)
@@ -230,17 +512,6 @@ func (app *Application) shutdown() error {
 		}()
 	}
 	
-	// 注销服务
-	if app.serviceManager != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if err := app.serviceManager.DeregisterAll(ctx); err != nil {
-				app.logger.Error("Failed to deregister services", zap.Error(err))
-			}
-		}()
-	}
-	
 	// 关闭客户端工厂
 	if app.clientFactory != nil {
 		wg.Add(1)
@@ -282,46 +553,94 @@ func (app *Application) shutdown() error {
 
 // createLogger 创建日志器
 func (app *Application) createLogger() *zap.Logger {
-	var level zapcore.Level
-	switch app.config.Logging.Level {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	default:
-		level = zapcore.InfoLevel
-	}
+	// 保存日志级别，配置重载时可以直接调整
+	level := zap.NewAtomicLevelAt(parseLogLevel(app.config.Logging.Level))
+	app.logLevel = &level
 	
+	return logging.NewWithFallback(app.config.Logging, level)
+}
+
+// NewBootstrapLogger 创建引导日志器
+// 用于配置加载完成前记录早期错误，固定为 info 级别的 JSON 格式并输出到 stderr
+func NewBootstrapLogger() *zap.Logger {
 	config := zap.Config{
-		Level:       zap.NewAtomicLevelAt(level),
-		Development: false,
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		Encoding:         app.config.Logging.Format,
+		Level:            zap.NewAtomicLevelAt(zapcore.InfoLevel),
+		Development:      false,
+		Encoding:         "json",
 		EncoderConfig:    zap.NewProductionEncoderConfig(),
-		OutputPaths:      []string{"stdout"},
+		OutputPaths:      []string{"stderr"},
 		ErrorOutputPaths: []string{"stderr"},
 	}
 	
-	logger, _ := config.Build()
-	return logger
+	logger, err := config.Build()
+	if err != nil {
+		return zap.NewNop()
+	}
+	return logger.With(zap.String("phase", "bootstrap"))
+}
+
+// DescribeConfigPath 返回用于日志的配置文件路径描述
+// 未指定路径时返回默认搜索位置
+func DescribeConfigPath(path string) string {
+	if path == "" {
+		return "./config/application.*, ./application.*"
+	}
+	return path
+}
+
+// publicHTTPHandler 返回 multiplex_http 时在 gRPC 端口上提供的端点，不包含摘除、重新解析等管理端点
+func (app *Application) publicHTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	app.registerPublicRoutes(mux)
+	return mux
 }
 
 // createHTTPServer 创建 HTTP 服务器
 func (app *Application) createHTTPServer() *http.Server {
 	mux := http.NewServeMux()
+	app.registerPublicRoutes(mux)
+	
+	// 触发服务立即重新解析
+	mux.HandleFunc("POST /debug/resolve/{service}", app.handleResolveNow)
 	
-	// 指标端点
-	mux.Handle(app.config.Metrics.Path, promhttp.Handler())
+	// 查看本进程从注册中心看到的服务实例，仅在配置了服务发现时提供
+	if app.config.Discovery.Type != "" {
+		mux.HandleFunc("GET /discovery/services", app.handleDiscoverServices)
+	}
+	
+	// 手动摘除和恢复实例，仅在指标端口上提供
+	mux.HandleFunc("POST /drain", app.handleDrain)
+	mux.HandleFunc("POST /undrain", app.handleUndrain)
+	
+	// channelz 统计摘要
+	if app.config.GRPC.Server.EnableChannelz {
+		mux.Handle("/debug/channelz", server.ChannelzHandler())
+	}
+	
+	// 设置连接超时，避免慢速连接占用资源
+	timeouts := app.config.Metrics.HTTPTimeouts
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", app.config.Metrics.Host, app.config.Metrics.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: time.Duration(timeouts.ReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(timeouts.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(timeouts.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(timeouts.IdleTimeout) * time.Second,
+	}
+}
+
+// registerPublicRoutes 注册指标、健康检查、就绪检查和版本端点
+func (app *Application) registerPublicRoutes(mux *http.ServeMux) {
+	// 指标端点，抓取方请求 OpenMetrics 格式时同时返回请求持续时间的追踪样本
+	if app.metricsRegistry != nil {
+		mux.Handle(app.config.Metrics.Path, promhttp.HandlerFor(app.metricsRegistry, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	} else {
+		mux.Handle(app.config.Metrics.Path, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
+	}
 	
 	// 健康检查端点
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
 		if app.grpcServer.IsHealthy() {
 			w.WriteHeader(http.StatusOK)
 			w.Write([]byte("OK"))
@@ -332,13 +651,117 @@ func (app *Application) createHTTPServer() *http.Server {
 	})
 	
 	// 就绪检查端点
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Ready"))
+	mux.HandleFunc(readyPath, func(w http.ResponseWriter, r *http.Request) {
+		if app.IsReady() {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("Ready"))
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("Not Ready"))
+		}
 	})
 	
-	return &http.Server{
-		Addr:    fmt.Sprintf(":%d", app.config.Metrics.Port),
-		Handler: mux,
+	// 版本信息端点
+	mux.Handle("/version", version.Handler())
+}
+
+// handleResolveNow 处理立即重新解析请求
+func (app *Application) handleResolveNow(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.PathValue("service")
+	
+	if app.clientFactory == nil {
+		http.Error(w, "client factory not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	
+	if err := app.clientFactory.ResolveNow(serviceName); err != nil {
+		app.logger.Warn("Failed to trigger re-resolution",
+			zap.String("service", serviceName),
+			zap.Error(err))
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte("Re-resolution triggered"))
+}
+
+// handleDiscoverServices 以 JSON 返回注册中心中指定服务的实例列表，服务名由 name 查询参数指定
+func (app *Application) handleDiscoverServices(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.URL.Query().Get("name")
+	if serviceName == "" {
+		http.Error(w, "name query parameter is required", http.StatusBadRequest)
+		return
+	}
+	
+	app.mu.RLock()
+	manager := app.serviceManager
+	app.mu.RUnlock()
+	if manager == nil {
+		http.Error(w, "discovery registry not available", http.StatusServiceUnavailable)
+		return
+	}
+	
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	services, err := manager.DiscoverServices(ctx, serviceName)
+	if err != nil {
+		app.logger.Warn("Failed to discover services",
+			zap.String("service", serviceName),
+			zap.Error(err))
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if services == nil {
+		services = []*discovery.ServiceInfo{}
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services)
+}
+
+// handleDrain 将实例从负载均衡中摘除，gRPC 健康状态和服务发现中的实例状态设为 NOT_SERVING，/ready 返回 503，进程继续运行
+func (app *Application) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if app.grpcServer == nil {
+		http.Error(w, "gRPC server not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	
+	app.grpcServer.Drain()
+	app.setDiscoveryStatus(r.Context(), discovery.StatusNotServing)
+	app.logger.Info("Instance drained, health status set to NOT_SERVING")
+	
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Drained"))
+}
+
+// setDiscoveryStatus 更新注册到服务发现的实例状态，尚未注册时由后续注册使用 serviceInfo 中的状态
+func (app *Application) setDiscoveryStatus(ctx context.Context, status string) {
+	app.mu.RLock()
+	serviceManager := app.serviceManager
+	app.mu.RUnlock()
+	if serviceManager == nil {
+		return
 	}
-}
\ No newline at end of file
+	
+	if err := serviceManager.SetStatus(ctx, status); err != nil {
+		app.logger.Error("Failed to update discovery status",
+			zap.String("status", status),
+			zap.Error(err))
+	}
+}
+
+// handleUndrain 取消摘除，服务就绪时恢复 SERVING
+func (app *Application) handleUndrain(w http.ResponseWriter, r *http.Request) {
+	if app.grpcServer == nil {
+		http.Error(w, "gRPC server not initialized", http.StatusServiceUnavailable)
+		return
+	}
+	
+	app.grpcServer.Undrain()
+	app.setDiscoveryStatus(r.Context(), discovery.StatusServing)
+	app.logger.Info("Instance undrained")
+	
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Undrained"))
+}
@@ -2,14 +2,34 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/client"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/server"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // MockServiceRegistrar 模拟服务注册器
@@ -111,6 +131,28 @@ func TestWithLogger(t *testing.T) {
 	}
 }
 
+func TestNewLogsConfigLoadFailure(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	path := filepath.Join(t.TempDir(), "missing.yml")
+
+	app := New(WithConfigPath(path), WithLogger(zap.New(core)))
+
+	if app.config == nil {
+		t.Fatal("Expected fallback config to be set")
+	}
+
+	entries := logs.FilterMessage("Failed to load config, falling back to defaults").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	if entries[0].Level != zapcore.ErrorLevel {
+		t.Errorf("Expected error level, got %v", entries[0].Level)
+	}
+	if got := entries[0].ContextMap()["config_path"]; got != path {
+		t.Errorf("Expected config_path %q, got %v", path, got)
+	}
+}
+
 func TestWithShutdownTimeout(t *testing.T) {
 	timeout := 60 * time.Second
 	option := WithShutdownTimeout(timeout)
@@ -265,6 +307,7 @@ func TestCreateLogger(t *testing.T) {
 		{"warn level", "warn", "json", true},
 		{"error level", "error", "console", true},
 		{"invalid level", "invalid", "json", true}, // 应该使用默认的 info 级别
+		{"invalid format", "info", "text", true},   // 应该使用 json 格式
 	}
 
 	for _, tt := range tests {
@@ -286,6 +329,73 @@ func TestCreateLogger(t *testing.T) {
 	}
 }
 
+func TestCreateLoggerFallback(t *testing.T) {
+	cfg := &config.Config{
+		Logging: config.LoggingConfig{
+			Level:       "info",
+			Format:      "json",
+			OutputPaths: []string{filepath.Join(t.TempDir(), "missing", "app.log")},
+		},
+	}
+
+	app := &Application{config: cfg}
+	logger := app.createLogger()
+	if logger == nil {
+		t.Fatal("Expected fallback logger when output path cannot be opened")
+	}
+	logger.Info("still usable")
+}
+
+func TestServiceInfoAdvertiseAddress(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Host = "0.0.0.0"
+	cfg.Server.GRPCPort = 9090
+
+	app := &Application{config: cfg, logger: zap.NewNop()}
+
+	// 通配地址替换为本机的具体 IP
+	info := app.serviceInfo()
+	if ip := net.ParseIP(info.Address); ip == nil || ip.IsUnspecified() {
+		t.Errorf("Expected concrete IP address, got %q", info.Address)
+	}
+	if info.Port != 9090 {
+		t.Errorf("Expected port 9090, got %d", info.Port)
+	}
+
+	cfg.Discovery.AdvertiseAddress = "grpc.example.com"
+	cfg.Discovery.AdvertisePort = 443
+	info = app.serviceInfo()
+	if info.Address != "grpc.example.com" || info.Port != 443 {
+		t.Errorf("Expected grpc.example.com:443, got %s:%d", info.Address, info.Port)
+	}
+}
+
+func TestServiceInfoBoundPort(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.GRPCPort = 0
+
+	grpcServer := server.New(cfg, zap.NewNop())
+	if err := grpcServer.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer grpcServer.Stop(context.Background())
+
+	// 注册实际监听的端口而不是配置的端口
+	app := &Application{config: cfg, logger: zap.NewNop(), grpcServer: grpcServer}
+	info := app.serviceInfo()
+	_, boundPort, _ := net.SplitHostPort(grpcServer.GetAddress())
+	if strconv.Itoa(info.Port) != boundPort {
+		t.Errorf("Expected bound port %s, got %d", boundPort, info.Port)
+	}
+
+	// advertise_port 优先
+	cfg.Discovery.AdvertisePort = 443
+	if info := app.serviceInfo(); info.Port != 443 {
+		t.Errorf("Expected advertise port 443, got %d", info.Port)
+	}
+}
+
 func TestCreateHTTPServer(t *testing.T) {
 	cfg := &config.Config{
 		Metrics: config.MetricsConfig{
@@ -312,6 +422,12 @@ func TestCreateHTTPServer(t *testing.T) {
 	if httpServer.Handler == nil {
 		t.Error("Expected HTTP handler to be set")
 	}
+
+	// 配置监听地址后只绑定到该地址
+	cfg.Metrics.Host = "127.0.0.1"
+	if addr := app.createHTTPServer().Addr; addr != "127.0.0.1:8081" {
+		t.Errorf("Expected server address '127.0.0.1:8081', got '%s'", addr)
+	}
 }
 
 func TestHTTPServerEndpoints(t *testing.T) {
@@ -530,4 +646,1016 @@ func BenchmarkCreateLogger(b *testing.B) {
 			b.Fatal("Expected logger to be created")
 		}
 	}
-}
\ No newline at end of file
+}
+// countingRegistry 记录 Register 和 Discover 调用次数的模拟注册器
+type countingRegistry struct {
+	registerCalls atomic.Int32
+	discoverCalls atomic.Int32
+	// failFirst 前若干次注册返回错误
+	failFirst int32
+}
+
+func (r *countingRegistry) Register(ctx context.Context, service *discovery.ServiceInfo) error {
+	if r.registerCalls.Add(1) <= r.failFirst {
+		return errors.New("registry unavailable")
+	}
+	return nil
+}
+
+func (r *countingRegistry) Deregister(ctx context.Context, service *discovery.ServiceInfo) error {
+	return nil
+}
+
+func (r *countingRegistry) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	r.discoverCalls.Add(1)
+	return []*discovery.ServiceInfo{{Name: serviceName, Address: "127.0.0.1", Port: 1}}, nil
+}
+
+func (r *countingRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*discovery.ServiceInfo, error) {
+	return make(chan []*discovery.ServiceInfo), nil
+}
+
+func (r *countingRegistry) Close() error {
+	return nil
+}
+
+// staticRegistry 返回固定实例的内存注册器
+type staticRegistry struct {
+	countingRegistry
+	services map[string][]*discovery.ServiceInfo
+}
+
+func (r *staticRegistry) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	return r.services[serviceName], nil
+}
+
+func TestHTTPServerDiscoverServices(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Discovery.Type = "etcd"
+	registry := &staticRegistry{services: map[string][]*discovery.ServiceInfo{
+		"orders": {
+			{Name: "orders", Address: "10.0.0.1", Port: 9090, Metadata: map[string]string{"zone": "zone-a"}},
+			{Name: "orders", Address: "10.0.0.2", Port: 9090},
+		},
+	}}
+	app := &Application{
+		config:         &cfg,
+		logger:         zap.NewNop(),
+		serviceManager: discovery.NewServiceManager(registry, zap.NewNop()),
+	}
+	handler := app.createHTTPServer().Handler
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/discovery/services?name=orders")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+	var services []discovery.ServiceInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &services); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(services) != 2 || services[0].Address != "10.0.0.1" || services[0].Metadata["zone"] != "zone-a" || services[1].Address != "10.0.0.2" {
+		t.Errorf("Unexpected services %+v", services)
+	}
+
+	// 没有实例时返回空列表
+	if rec := get("/discovery/services?name=unknown"); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("Expected empty list, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := get("/discovery/services"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without name, got %d", rec.Code)
+	}
+
+	// 未配置服务发现时不提供该端点
+	cfg.Discovery.Type = ""
+	handler = app.createHTTPServer().Handler
+	if rec := get("/discovery/services?name=orders"); rec.Code == http.StatusOK {
+		t.Errorf("Expected endpoint to be registered only when discovery is configured, got %d", rec.Code)
+	}
+}
+
+func TestHTTPServerResolveNow(t *testing.T) {
+	cfg := config.Get()
+	logger := zap.NewNop()
+	registry := &countingRegistry{}
+
+	app := &Application{
+		config:        cfg,
+		logger:        logger,
+		clientFactory: client.NewClientFactory(cfg, registry, logger),
+	}
+	defer app.clientFactory.Close()
+
+	if _, err := app.GetClient("user-service"); err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	before := registry.discoverCalls.Load()
+
+	httpServer := app.createHTTPServer()
+
+	req, _ := http.NewRequest("POST", "/debug/resolve/user-service", nil)
+	rr := &MockResponseWriter{}
+	httpServer.Handler.ServeHTTP(rr, req)
+
+	if rr.statusCode != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rr.statusCode, string(rr.body))
+	}
+
+	// ResolveNow 异步执行，等待新的 Discover 调用
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.discoverCalls.Load() <= before {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected re-resolution to trigger a fresh Discover")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 未知服务返回 404
+	req, _ = http.NewRequest("POST", "/debug/resolve/unknown-service", nil)
+	rr = &MockResponseWriter{}
+	httpServer.Handler.ServeHTTP(rr, req)
+
+	if rr.statusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown service, got %d", rr.statusCode)
+	}
+
+	// 只接受 POST
+	req, _ = http.NewRequest("GET", "/debug/resolve/user-service", nil)
+	rr = &MockResponseWriter{}
+	httpServer.Handler.ServeHTTP(rr, req)
+
+	if rr.statusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", rr.statusCode)
+	}
+}
+
+func TestCreateHTTPServerTimeouts(t *testing.T) {
+	cfg := &config.Config{
+		Metrics: config.MetricsConfig{
+			Port: 8081,
+			Path: "/metrics",
+			HTTPTimeouts: config.HTTPTimeoutsConfig{
+				ReadHeaderTimeout: 2,
+				ReadTimeout:       3,
+				WriteTimeout:      4,
+				IdleTimeout:       5,
+			},
+		},
+	}
+
+	app := &Application{
+		config:     cfg,
+		grpcServer: &server.Server{},
+	}
+
+	httpServer := app.createHTTPServer()
+
+	if httpServer.ReadHeaderTimeout != 2*time.Second {
+		t.Errorf("Expected ReadHeaderTimeout 2s, got %s", httpServer.ReadHeaderTimeout)
+	}
+	if httpServer.ReadTimeout != 3*time.Second {
+		t.Errorf("Expected ReadTimeout 3s, got %s", httpServer.ReadTimeout)
+	}
+	if httpServer.WriteTimeout != 4*time.Second {
+		t.Errorf("Expected WriteTimeout 4s, got %s", httpServer.WriteTimeout)
+	}
+	if httpServer.IdleTimeout != 5*time.Second {
+		t.Errorf("Expected IdleTimeout 5s, got %s", httpServer.IdleTimeout)
+	}
+}
+
+func TestHTTPServerVersion(t *testing.T) {
+	oldVersion, oldCommit := version.Version, version.Commit
+	defer func() { version.Version, version.Commit = oldVersion, oldCommit }()
+	version.Version, version.Commit = "v9.9.9", "deadbeef"
+
+	cfg := &config.Config{
+		Metrics: config.MetricsConfig{
+			Port: 8081,
+			Path: "/metrics",
+		},
+	}
+	app := New(WithConfig(cfg), WithLogger(zap.NewNop()))
+	httpServer := app.createHTTPServer()
+
+	rec := httptest.NewRecorder()
+	httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var info version.Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if info.Version != "v9.9.9" || info.Commit != "deadbeef" {
+		t.Errorf("Expected injected version info, got %+v", info)
+	}
+}
+
+// newUnreachableDiscoveryConfig 返回指向不可达 etcd 的应用配置
+func newUnreachableDiscoveryConfig(failFast bool) *config.Config {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0 // 使用随机端口
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{
+		Type:        "etcd",
+		Endpoints:   []string{"127.0.0.1:1"},
+		DialTimeout: 1,
+		FailFast:    failFast,
+	}
+	return &cfg
+}
+
+func TestInitializeDiscoveryFailFast(t *testing.T) {
+	app := New(WithConfig(newUnreachableDiscoveryConfig(true)))
+
+	if err := app.initialize(); err == nil {
+		t.Fatal("Expected initialize to fail when registry is unreachable and fail_fast is enabled")
+	}
+}
+
+func TestStartWithUnreachableDiscovery(t *testing.T) {
+	app := New(WithConfig(newUnreachableDiscoveryConfig(false)))
+	app.registryRetryInterval = time.Hour
+
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Expected initialize to succeed without fail_fast, got %v", err)
+	}
+	if app.serviceManager != nil {
+		t.Error("Expected no service manager while registry is unreachable")
+	}
+
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	conn, err := grpc.NewClient(app.grpcServer.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %v", resp.Status)
+	}
+}
+
+func TestRegistryRetryAfterStartup(t *testing.T) {
+	registry := &countingRegistry{}
+	var attempts atomic.Int32
+
+	app := New(WithConfig(newUnreachableDiscoveryConfig(false)))
+	app.registryRetryInterval = 10 * time.Millisecond
+	// 前两次创建失败，之后注册中心恢复
+	app.newRegistry = func(cfg *config.DiscoveryConfig, logger *zap.Logger) (discovery.Registry, error) {
+		if attempts.Add(1) <= 2 {
+			return nil, errors.New("registry unavailable")
+		}
+		return registry, nil
+	}
+
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.registerCalls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected service to be registered once the registry became reachable")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	app.mu.RLock()
+	manager := app.serviceManager
+	app.mu.RUnlock()
+	if manager == nil {
+		t.Error("Expected service manager to be set after retry succeeded")
+	}
+}
+
+func TestRegistrationRetryWithBackoff(t *testing.T) {
+	registry := &countingRegistry{failFirst: 3}
+
+	app := New(WithConfig(newUnreachableDiscoveryConfig(false)))
+	app.registrationBackoff = discovery.RegistrationBackoff{InitialInterval: 10 * time.Millisecond, MaxInterval: 40 * time.Millisecond}
+	app.newRegistry = func(cfg *config.DiscoveryConfig, logger *zap.Logger) (discovery.Registry, error) {
+		return registry, nil
+	}
+
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	// 前三次注册失败后在后台重试，第四次成功
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.registerCalls.Load() < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected service to eventually register, got %d attempts", registry.registerCalls.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 注册成功后不再重试
+	time.Sleep(100 * time.Millisecond)
+	if got := registry.registerCalls.Load(); got != 4 {
+		t.Errorf("Expected 4 registration attempts, got %d", got)
+	}
+}
+
+func TestReadinessCheckGatesServing(t *testing.T) {
+	registry := &countingRegistry{}
+	var attempts atomic.Int32
+	var passedAt atomic.Int64
+
+	cfg := newUnreachableDiscoveryConfig(true)
+	app := New(WithConfig(cfg), WithReadinessCheck(func(ctx context.Context) error {
+		// 前两次检查失败，模拟依赖尚未就绪
+		if attempts.Add(1) <= 2 {
+			return errors.New("cache not warmed")
+		}
+		passedAt.Store(time.Now().UnixNano())
+		return nil
+	}))
+	app.readinessRetryInterval = 50 * time.Millisecond
+	app.newRegistry = func(cfg *config.DiscoveryConfig, logger *zap.Logger) (discovery.Registry, error) {
+		return registry, nil
+	}
+
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	startedAt := time.Now()
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	conn, err := grpc.NewClient(app.grpcServer.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+
+	checkStatus := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		return resp.Status
+	}
+
+	// 检查通过前保持 NOT_SERVING，且未注册到服务发现
+	if status := checkStatus(); status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected NOT_SERVING before readiness checks pass, got %v", status)
+	}
+	if registry.registerCalls.Load() != 0 {
+		t.Error("Expected no discovery registration before readiness checks pass")
+	}
+	httpServer := app.createHTTPServer()
+	req, _ := http.NewRequest("GET", "/ready", nil)
+	rr := &MockResponseWriter{}
+	httpServer.Handler.ServeHTTP(rr, req)
+	if rr.statusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected /ready to return 503 before readiness checks pass, got %d", rr.statusCode)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for checkStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected SERVING after readiness checks pass")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	servingAt := time.Now()
+
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 readiness attempts, got %d", got)
+	}
+	if passed := time.Unix(0, passedAt.Load()); servingAt.Before(passed) {
+		t.Errorf("Expected SERVING only after the readiness check passed")
+	}
+	// 两次失败后按指数退避重试：50ms + 100ms
+	if elapsed := time.Unix(0, passedAt.Load()).Sub(startedAt); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected readiness retries to back off, passed after %v", elapsed)
+	}
+
+	// 注册在设为 SERVING 之后进行
+	for registry.registerCalls.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected discovery registration after readiness checks pass")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !app.IsReady() {
+		t.Error("Expected application to be ready")
+	}
+	rr = &MockResponseWriter{}
+	httpServer.Handler.ServeHTTP(rr, req)
+	if rr.statusCode != http.StatusOK {
+		t.Errorf("Expected /ready to return 200 after readiness checks pass, got %d", rr.statusCode)
+	}
+	if registry.registerCalls.Load() != 1 {
+		t.Errorf("Expected one discovery registration after readiness, got %d", registry.registerCalls.Load())
+	}
+}
+
+func TestHTTPServerDrain(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0 // 使用随机端口
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	app := New(WithConfig(&cfg))
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	conn, err := grpc.NewClient(app.grpcServer.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+	healthClient := grpc_health_v1.NewHealthClient(conn)
+
+	checkStatus := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		resp, err := healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		return resp.Status
+	}
+	serve := func(method, path string) *MockResponseWriter {
+		req, _ := http.NewRequest(method, path, nil)
+		rr := &MockResponseWriter{}
+		app.createHTTPServer().Handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if status := checkStatus(); status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Fatalf("Expected SERVING before drain, got %v", status)
+	}
+
+	if rr := serve("POST", "/drain"); rr.statusCode != http.StatusOK {
+		t.Fatalf("Expected /drain to return 200, got %d", rr.statusCode)
+	}
+	if rr := serve("GET", "/ready"); rr.statusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected /ready to return 503 after drain, got %d", rr.statusCode)
+	}
+	if status := checkStatus(); status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected NOT_SERVING after drain, got %v", status)
+	}
+	// 摘除后进程仍在运行
+	if rr := serve("GET", "/health"); rr.statusCode != http.StatusOK {
+		t.Errorf("Expected /health to return 200 while drained, got %d", rr.statusCode)
+	}
+
+	if rr := serve("POST", "/undrain"); rr.statusCode != http.StatusOK {
+		t.Fatalf("Expected /undrain to return 200, got %d", rr.statusCode)
+	}
+	if rr := serve("GET", "/ready"); rr.statusCode != http.StatusOK {
+		t.Errorf("Expected /ready to return 200 after undrain, got %d", rr.statusCode)
+	}
+	if status := checkStatus(); status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING after undrain, got %v", status)
+	}
+
+	// 只接受 POST
+	if rr := serve("GET", "/drain"); rr.statusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET /drain, got %d", rr.statusCode)
+	}
+}
+
+// instanceRegistry 按地址保存实例的内存注册器，重复注册时替换已有实例
+type instanceRegistry struct {
+	countingRegistry
+	mu        sync.Mutex
+	instances map[string]*discovery.ServiceInfo
+}
+
+func (r *instanceRegistry) Register(ctx context.Context, service *discovery.ServiceInfo) error {
+	r.registerCalls.Add(1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances[net.JoinHostPort(service.Address, strconv.Itoa(service.Port))] = service
+	return nil
+}
+
+func (r *instanceRegistry) Deregister(ctx context.Context, service *discovery.ServiceInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instances, net.JoinHostPort(service.Address, strconv.Itoa(service.Port)))
+	return nil
+}
+
+func (r *instanceRegistry) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var services []*discovery.ServiceInfo
+	for _, service := range r.instances {
+		if service.Name == serviceName {
+			services = append(services, service)
+		}
+	}
+	return services, nil
+}
+
+func TestHTTPServerDrainUpdatesDiscovery(t *testing.T) {
+	registry := &instanceRegistry{instances: make(map[string]*discovery.ServiceInfo)}
+	app := New(WithConfig(newUnreachableDiscoveryConfig(false)))
+	app.newRegistry = func(cfg *config.DiscoveryConfig, logger *zap.Logger) (discovery.Registry, error) {
+		return registry, nil
+	}
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+
+	serve := func(path string) {
+		req, _ := http.NewRequest("POST", path, nil)
+		rr := &MockResponseWriter{}
+		app.createHTTPServer().Handler.ServeHTTP(rr, req)
+		if rr.statusCode != http.StatusOK {
+			t.Fatalf("Expected %s to return 200, got %d", path, rr.statusCode)
+		}
+	}
+	// servingCount 返回解析器会保留的实例数
+	servingCount := func() int {
+		services, _ := registry.Discover(context.Background(), "grpc-service")
+		count := 0
+		for _, service := range services {
+			if service.IsServing() {
+				count++
+			}
+		}
+		return count
+	}
+
+	if got := servingCount(); got != 1 {
+		t.Fatalf("Expected 1 serving instance after start, got %d", got)
+	}
+
+	// 摘除后实例以 NOT_SERVING 重新注册，被解析器过滤
+	serve("/drain")
+	services, _ := registry.Discover(context.Background(), "grpc-service")
+	if len(services) != 1 || services[0].Metadata[discovery.MetadataStatus] != discovery.StatusNotServing {
+		t.Fatalf("Expected instance to be registered as NOT_SERVING after drain, got %+v", services)
+	}
+	if got := servingCount(); got != 0 {
+		t.Errorf("Expected drained instance to be filtered out, got %d serving instances", got)
+	}
+
+	serve("/undrain")
+	if got := servingCount(); got != 1 {
+		t.Errorf("Expected instance to be SERVING after undrain, got %d serving instances", got)
+	}
+
+	// 关闭时先注销实例
+	app.shutdown()
+	if services, _ := registry.Discover(context.Background(), "grpc-service"); len(services) != 0 {
+		t.Errorf("Expected instance to be deregistered on shutdown, got %+v", services)
+	}
+}
+
+func TestMultiplexHTTPExcludesAdminEndpoints(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0 // 使用随机端口
+	cfg.Server.MultiplexHTTP = true
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Host = "127.0.0.1"
+	cfg.Metrics.Port = 0
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	app := New(WithConfig(&cfg), WithMetricsRegistry(prometheus.NewRegistry()))
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	base := "http://" + app.grpcServer.GetAddress()
+	request := func(method, path string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, base+path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// 公开端点在 gRPC 端口上提供
+	for _, path := range []string{"/health", "/version", cfg.Metrics.Path} {
+		if code := request(http.MethodGet, path); code != http.StatusOK {
+			t.Errorf("Expected GET %s on the gRPC port to return 200, got %d", path, code)
+		}
+	}
+
+	// 管理端点只在指标端口上提供
+	for _, path := range []string{"/drain", "/undrain", "/debug/resolve/orders"} {
+		if code := request(http.MethodPost, path); code != http.StatusNotFound {
+			t.Errorf("Expected POST %s on the gRPC port to return 404, got %d", path, code)
+		}
+	}
+	if app.grpcServer.IsDraining() {
+		t.Error("Expected instance not to be drained through the gRPC port")
+	}
+	if app.httpServer == nil {
+		t.Fatal("Expected admin endpoints to keep the dedicated metrics listener")
+	}
+	rec := httptest.NewRecorder()
+	app.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/drain", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected POST /drain on the metrics listener to return 200, got %d", rec.Code)
+	}
+}
+
+func TestWithMetricsRegistry(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0 // 使用随机端口
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	registry := prometheus.NewRegistry()
+	app := New(WithConfig(&cfg), WithMetricsRegistry(registry))
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	conn, err := grpc.NewClient(app.grpcServer.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", cfg.Metrics.Path, nil)
+	rr := httptest.NewRecorder()
+	app.createHTTPServer().Handler.ServeHTTP(rr, req)
+
+	body := rr.Body.String()
+	if !strings.Contains(body, `grpc_requests_total{code="0",method="/grpc.health.v1.Health/Check"} 1`) {
+		t.Errorf("Expected request metric from the injected registry, got:\n%s", body)
+	}
+	// 只暴露注入注册表中的指标
+	if strings.Contains(body, "go_goroutines") {
+		t.Error("Expected default registry metrics not to be exposed")
+	}
+}
+
+func TestReloadUpdatesLogLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "application.yml")
+	writeConfig := func(level string) {
+		t.Helper()
+		content := "logging:\n  level: " + level + "\n  format: json\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	writeConfig("info")
+	app := New(WithConfigPath(path))
+	if !app.logger.Core().Enabled(zapcore.InfoLevel) {
+		t.Fatal("Expected info level to be enabled initially")
+	}
+
+	// 与收到 SIGHUP 时的处理相同
+	writeConfig("error")
+	if err := app.Reload(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if app.logger.Core().Enabled(zapcore.WarnLevel) {
+		t.Error("Expected warn level to be disabled after reload")
+	}
+	if !app.logger.Core().Enabled(zapcore.ErrorLevel) {
+		t.Error("Expected error level to be enabled after reload")
+	}
+
+	// 无效配置不会改变当前设置
+	writeConfig("verbose")
+	if err := app.Reload(); err == nil {
+		t.Error("Expected invalid config to be rejected")
+	}
+	if got := app.logLevel.Level(); got != zapcore.ErrorLevel {
+		t.Errorf("Expected log level to stay error, got %v", got)
+	}
+}
+
+func TestReloadAppliesSlowThreshold(t *testing.T) {
+	// 重载时校验配置，需要使用有效的端口
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port: %v", err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	path := filepath.Join(t.TempDir(), "application.yml")
+	writeConfig := func(slowThreshold, maxConcurrentStreams int) {
+		t.Helper()
+		content := "server:\n  host: localhost\n  grpc_port: " + strconv.Itoa(port) + "\n" +
+			"grpc:\n  server:\n    enable_logging: true\n" +
+			"    slow_threshold: " + strconv.Itoa(slowThreshold) + "\n" +
+			"    max_concurrent_streams: " + strconv.Itoa(maxConcurrentStreams) + "\n" +
+			"metrics:\n  enabled: false\n" +
+			"discovery:\n  type: \"\"\n"
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	writeConfig(0, 100)
+	core, logs := observer.New(zap.InfoLevel)
+	app := New(WithConfigPath(path), WithLogger(zap.New(core)))
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	conn, err := grpc.NewClient(app.grpcServer.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	check := func() {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+	}
+
+	// 未设置慢请求阈值时调用以 info 级别记录
+	check()
+	if got := logs.FilterMessage("gRPC unary call completed").Len(); got != 1 {
+		t.Fatalf("Expected 1 info completion log, got %d", got)
+	}
+
+	// 重载后低于阈值的调用改为 debug 级别，无需重启
+	writeConfig(60000, 200)
+	if err := app.Reload(); err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	check()
+	if got := logs.FilterMessage("gRPC unary call completed").Len(); got != 1 {
+		t.Errorf("Expected completion log to move below info after reload, got %d info logs", got)
+	}
+
+	reloaded := logs.FilterMessage("Config reloaded").All()
+	if len(reloaded) != 1 {
+		t.Fatalf("Expected 1 reload log, got %d", len(reloaded))
+	}
+	if changed := reloaded[0].ContextMap()["changed"]; !reflect.DeepEqual(changed, []interface{}{"grpc.server.slow_threshold: 0 -> 60000"}) {
+		t.Errorf("Expected slow threshold change to be logged, got %v", changed)
+	}
+
+	// 不支持热更新的设置记录需要重启
+	restart := logs.FilterMessage("Config changes require a restart to take effect").All()
+	if len(restart) != 1 {
+		t.Fatalf("Expected 1 restart warning, got %d", len(restart))
+	}
+	if keys := restart[0].ContextMap()["keys"]; !reflect.DeepEqual(keys, []interface{}{"grpc.server.max_concurrent_streams"}) {
+		t.Errorf("Expected max_concurrent_streams to require a restart, got %v", keys)
+	}
+}
+
+func TestHotReloadChanges(t *testing.T) {
+	previous := &config.Config{Logging: config.LoggingConfig{Level: "info"}}
+	current := &config.Config{Logging: config.LoggingConfig{Level: "debug"}}
+	current.GRPC.Server.EnableLogging = true
+
+	changes := hotReloadChanges(previous, current)
+	expected := []string{"logging.level: info -> debug", "grpc.server.enable_logging: false -> true"}
+	if strings.Join(changes, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected changes %v, got %v", expected, changes)
+	}
+	if changes := hotReloadChanges(current, current); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %v", changes)
+	}
+}
+
+func TestWithPrewarm(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	dependency := grpc.NewServer()
+	go dependency.Serve(lis)
+	defer dependency.Stop()
+
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	target := lis.Addr().String()
+	app := New(WithConfig(&cfg), WithLogger(zap.NewNop()), WithPrewarm(5*time.Second, target))
+	if err := app.initialize(); err != nil {
+		t.Fatalf("Failed to initialize application: %v", err)
+	}
+	if err := app.start(); err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer app.shutdown()
+
+	// 启动返回前连接已就绪
+	conn, err := app.GetClient(target)
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Errorf("Expected prewarmed connection to be READY, got %s", state)
+	}
+}
+
+func TestStart(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0 // 使用随机端口
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	app := New(WithConfig(&cfg))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addr, stop, err := app.Start(ctx)
+	if err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING after Start returns, got %v", resp.Status)
+	}
+
+	// 停止后不再接受连接，重复调用无副作用
+	stop()
+	stop()
+	if dialed, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		dialed.Close()
+		t.Error("Expected listener to be closed after stop")
+	}
+}
+
+// ordersService 注册一个空的业务服务
+type ordersService struct{}
+
+func (ordersService) RegisterService(s grpc.ServiceRegistrar) {
+	s.RegisterService(&grpc.ServiceDesc{ServiceName: "test.Orders", HandlerType: (*any)(nil)}, struct{}{})
+}
+
+func TestStartupSummary(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = true
+	cfg.Metrics.Host = "127.0.0.1"
+	cfg.Metrics.Port = 0
+	cfg.Discovery = config.DiscoveryConfig{}
+	cfg.GRPC.Server.EnableReflection = true
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	app := New(WithConfig(&cfg), WithLogger(zap.New(core)), WithMetricsRegistry(prometheus.NewRegistry()))
+	app.RegisterService(ordersService{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addr, stop, err := app.Start(ctx)
+	if err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer stop()
+
+	entries := logs.FilterMessage("Startup summary").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 startup summary, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["grpc_address"] != addr {
+		t.Errorf("Expected grpc_address %q, got %v", addr, fields["grpc_address"])
+	}
+	if fields["http_address"] != "127.0.0.1:0" || fields["health_path"] != "/health" || fields["ready_path"] != "/ready" {
+		t.Errorf("Expected metrics endpoints on 127.0.0.1:0, got %v", fields)
+	}
+	if fields["service_count"] != int64(1) {
+		t.Errorf("Expected service_count 1, got %v", fields["service_count"])
+	}
+	if fields["discovery"] != "none" || fields["discovery_status"] != "disabled" {
+		t.Errorf("Expected discovery disabled, got %v/%v", fields["discovery"], fields["discovery_status"])
+	}
+	if fields["tls"] != false || fields["reflection"] != true {
+		t.Errorf("Expected tls off and reflection on, got %v/%v", fields["tls"], fields["reflection"])
+	}
+}
+
+func TestStartWaitsForReadiness(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	var attempts atomic.Int32
+	app := New(WithConfig(&cfg), WithReadinessCheck(func(ctx context.Context) error {
+		if attempts.Add(1) <= 2 {
+			return errors.New("cache not warmed")
+		}
+		return nil
+	}))
+	app.readinessRetryInterval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, stop, err := app.Start(ctx)
+	if err != nil {
+		t.Fatalf("Failed to start application: %v", err)
+	}
+	defer stop()
+	if !app.IsReady() || attempts.Load() != 3 {
+		t.Errorf("Expected Start to return after readiness checks pass, ready %v after %d attempts", app.IsReady(), attempts.Load())
+	}
+}
+
+func TestStartReadinessTimeout(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery = config.DiscoveryConfig{}
+
+	app := New(WithConfig(&cfg), WithReadinessCheck(func(ctx context.Context) error {
+		return errors.New("never ready")
+	}))
+	app.readinessRetryInterval = 20 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, _, err := app.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
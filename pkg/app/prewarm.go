@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultPrewarmTimeout 后台预热时等待连接就绪的最长时间
+const defaultPrewarmTimeout = 30 * time.Second
+
+// WithPrewarm 启动时预先建立到依赖服务的连接，使第一次真实请求不必承担拨号和服务发现的延迟，可多次调用
+// waitTimeout 大于 0 时启动最多等待该时间直到连接就绪，之后才将服务设为 SERVING；
+// 为 0 时在后台建立连接，不阻塞启动。预热失败只记录日志，不影响启动
+func WithPrewarm(waitTimeout time.Duration, serviceNames ...string) Option {
+	return func(app *Application) {
+		app.prewarmServices = append(app.prewarmServices, serviceNames...)
+		app.prewarmTimeout = waitTimeout
+	}
+}
+
+// prewarmClients 预热 WithPrewarm 指定的客户端连接
+func (app *Application) prewarmClients() {
+	if len(app.prewarmServices) == 0 || app.clientFactory == nil {
+		return
+	}
+
+	prewarm := func(timeout time.Duration) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err := app.clientFactory.Prewarm(ctx, app.prewarmServices...); err != nil {
+			app.logger.Warn("Failed to prewarm some client connections", zap.Error(err))
+			return
+		}
+		app.logger.Info("Client connections prewarmed", zap.Strings("services", app.prewarmServices))
+	}
+
+	if app.prewarmTimeout > 0 {
+		prewarm(app.prewarmTimeout)
+		return
+	}
+	go prewarm(defaultPrewarmTimeout)
+}
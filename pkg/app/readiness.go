@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReadinessCheck 就绪检查，返回 nil 表示依赖（数据库迁移、缓存预热等）已就绪
+type ReadinessCheck func(ctx context.Context) error
+
+const (
+	// defaultReadinessRetryInterval 就绪检查失败后首次重试的间隔
+	defaultReadinessRetryInterval = time.Second
+	// maxReadinessRetryInterval 就绪检查重试间隔上限
+	maxReadinessRetryInterval = 30 * time.Second
+)
+
+// WithReadinessCheck 添加就绪检查，可多次调用
+// 配置了就绪检查时，gRPC 服务器启动后保持 NOT_SERVING，所有检查通过后才设为 SERVING 并注册到服务发现，
+// 检查失败时按指数退避重试
+func WithReadinessCheck(check ReadinessCheck) Option {
+	return func(app *Application) {
+		app.readinessChecks = append(app.readinessChecks, check)
+	}
+}
+
+// IsReady 检查应用是否就绪，未配置就绪检查时始终就绪，通过 /drain 摘除后不再就绪
+func (app *Application) IsReady() bool {
+	if app.grpcServer != nil && app.grpcServer.IsDraining() {
+		return false
+	}
+	return len(app.readinessChecks) == 0 || app.readinessPassed.Load()
+}
+
+// startReadinessGate 在后台运行就绪检查，全部通过后调用 onReady
+func (app *Application) startReadinessGate(onReady func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	app.readinessCancel = cancel
+	app.readinessDone = make(chan struct{})
+
+	go func() {
+		defer close(app.readinessDone)
+
+		if err := app.waitReadinessChecks(ctx); err != nil {
+			return
+		}
+		if err := app.grpcServer.MarkServing(ctx); err != nil {
+			if ctx.Err() == nil {
+				app.logger.Warn("gRPC services did not become ready", zap.Error(err))
+			}
+			return
+		}
+
+		app.readinessPassed.Store(true)
+		app.logger.Info("Readiness checks passed, service is SERVING")
+		onReady()
+	}()
+}
+
+// stopReadinessGate 停止尚未完成的就绪检查
+func (app *Application) stopReadinessGate() {
+	if app.readinessCancel != nil {
+		app.readinessCancel()
+		<-app.readinessDone
+	}
+}
+
+// waitReadinessChecks 依次运行就绪检查，失败时按指数退避重试，直到全部通过或 ctx 取消
+func (app *Application) waitReadinessChecks(ctx context.Context) error {
+	interval := app.readinessRetryInterval
+	if interval <= 0 {
+		interval = defaultReadinessRetryInterval
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := app.runReadinessChecks(ctx)
+		if err == nil {
+			return nil
+		}
+		app.logger.Warn("Readiness check failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", interval),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > maxReadinessRetryInterval {
+			interval = maxReadinessRetryInterval
+		}
+	}
+}
+
+// runReadinessChecks 运行所有就绪检查，返回第一个失败的检查错误
+func (app *Application) runReadinessChecks(ctx context.Context) error {
+	for i, check := range app.readinessChecks {
+		if err := check(ctx); err != nil {
+			return fmt.Errorf("readiness check %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Reload 从配置文件重新加载配置并应用可热更新的设置，配置无效时保持当前设置
+// 收到 SIGHUP 时自动调用
+func (app *Application) Reload() error {
+	cfg, err := config.Load(app.configPath)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	
+	app.ApplyConfig(cfg)
+	return nil
+}
+
+// ApplyConfig 应用重载后的配置并记录发生变化的设置
+// 目前支持调整日志级别（仅限应用创建的日志器）、动态启用或禁用内置拦截器和调整慢请求阈值，
+// 其余配置需要重启后生效，发生变化时以 warn 级别记录对应的配置键
+func (app *Application) ApplyConfig(cfg *config.Config) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	
+	previous := app.appliedConfig
+	if previous == nil {
+		previous = app.config
+	}
+	
+	changes := hotReloadChanges(previous, cfg)
+	if app.logLevel != nil {
+		app.logLevel.SetLevel(parseLogLevel(cfg.Logging.Level))
+	} else if previous.Logging.Level != cfg.Logging.Level {
+		app.logger.Warn("Log level change ignored, the logger was provided by the caller",
+			zap.String("level", cfg.Logging.Level))
+	}
+	if app.grpcServer != nil {
+		app.grpcServer.ApplyInterceptorConfig(&cfg.GRPC.Server)
+	}
+	app.appliedConfig = cfg
+	
+	if restart := restartRequiredChanges(previous, cfg); len(restart) > 0 {
+		app.logger.Warn("Config changes require a restart to take effect", zap.Strings("keys", restart))
+	}
+	if len(changes) == 0 {
+		app.logger.Info("Config reloaded, no hot-reloadable settings changed")
+		return
+	}
+	app.logger.Info("Config reloaded", zap.Strings("changed", changes))
+}
+
+// hotReloadChanges 比较可热更新的设置，返回 "键: 旧值 -> 新值" 形式的变化列表
+func hotReloadChanges(previous, current *config.Config) []string {
+	var changes []string
+	add := func(key string, was, now interface{}) {
+		if was != now {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", key, was, now))
+		}
+	}
+	
+	add("logging.level", previous.Logging.Level, current.Logging.Level)
+	add("grpc.server.enable_logging", previous.GRPC.Server.EnableLogging, current.GRPC.Server.EnableLogging)
+	add("grpc.server.enable_recovery", previous.GRPC.Server.EnableRecovery, current.GRPC.Server.EnableRecovery)
+	add("grpc.server.enable_metrics", previous.GRPC.Server.EnableMetrics, current.GRPC.Server.EnableMetrics)
+	add("grpc.server.slow_threshold", previous.GRPC.Server.SlowThreshold, current.GRPC.Server.SlowThreshold)
+	return changes
+}
+
+// hotReloadKeys 可热更新的配置键，与 hotReloadChanges 比较的设置一致
+var hotReloadKeys = map[string]bool{
+	"logging.level":               true,
+	"grpc.server.enable_logging":  true,
+	"grpc.server.enable_recovery": true,
+	"grpc.server.enable_metrics":  true,
+	"grpc.server.slow_threshold":  true,
+}
+
+// restartRequiredChanges 返回发生变化但需要重启才能生效的配置键，只记录键名，避免在日志中输出令牌等敏感值
+func restartRequiredChanges(previous, current *config.Config) []string {
+	var keys []string
+	var walk func(prefix string, was, now reflect.Value)
+	walk = func(prefix string, was, now reflect.Value) {
+		for i := 0; i < was.NumField(); i++ {
+			field := was.Type().Field(i)
+			key := field.Tag.Get("mapstructure")
+			if key == "" {
+				continue
+			}
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if field.Type.Kind() == reflect.Struct {
+				walk(key, was.Field(i), now.Field(i))
+				continue
+			}
+			if !hotReloadKeys[key] && !reflect.DeepEqual(was.Field(i).Interface(), now.Field(i).Interface()) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	walk("", reflect.ValueOf(previous).Elem(), reflect.ValueOf(current).Elem())
+	return keys
+}
+
+// parseLogLevel 解析配置中的日志级别，无法识别时使用 info
+func parseLogLevel(level string) zapcore.Level {
+	switch level {
+	case "debug":
+		return zapcore.DebugLevel
+	case "warn":
+		return zapcore.WarnLevel
+	case "error":
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
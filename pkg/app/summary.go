@@ -0,0 +1,77 @@
+package app
+
+import (
+	"go.uber.org/zap"
+)
+
+const (
+	// healthPath 和 readyPath 指标端口上的健康检查和就绪检查路径
+	healthPath = "/health"
+	readyPath  = "/ready"
+)
+
+// logStartupSummary 启动完成后输出一条汇总日志，列出实际生效的监听地址和主要功能开关
+func (app *Application) logStartupSummary() {
+	services := app.grpcServer.ServiceNames()
+	httpAddress := app.httpAddress()
+	fields := []zap.Field{
+		zap.String("grpc_address", app.grpcServer.GetAddress()),
+		zap.String("http_address", httpAddress),
+		zap.String("discovery", app.discoveryBackend()),
+		zap.String("discovery_status", app.discoveryStatus()),
+		zap.Bool("tls", app.grpcServer.TLSEnabled()),
+		zap.Bool("reflection", app.config.GRPC.Server.EnableReflection),
+		zap.Strings("interceptors", app.grpcServer.EnabledInterceptors()),
+		zap.Int("service_count", len(services)),
+		zap.Strings("services", services),
+	}
+	if httpAddress != "" {
+		fields = append(fields,
+			zap.String("metrics_path", app.config.Metrics.Path),
+			zap.String("health_path", healthPath),
+			zap.String("ready_path", readyPath))
+	}
+	if web := app.grpcServer.GetGrpcWebAddress(); web != "" {
+		fields = append(fields, zap.String("grpc_web_address", web))
+	}
+
+	app.logger.Info("Startup summary", fields...)
+}
+
+// httpAddress 返回指标和健康检查端点的地址，开启 multiplex_http 时与 gRPC 共用地址，未启用时返回空字符串
+func (app *Application) httpAddress() string {
+	if app.config.Server.MultiplexHTTP {
+		return app.grpcServer.GetAddress()
+	}
+	if app.httpServer != nil {
+		return app.httpServer.Addr
+	}
+	return ""
+}
+
+// discoveryBackend 返回服务发现后端类型，未配置时返回 none
+func (app *Application) discoveryBackend() string {
+	if app.config.Discovery.Type == "" {
+		return "none"
+	}
+	return app.config.Discovery.Type
+}
+
+// discoveryStatus 返回服务注册状态
+func (app *Application) discoveryStatus() string {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	switch {
+	case app.config.Discovery.Type == "":
+		return "disabled"
+	case app.registryPending:
+		return "registry_unavailable"
+	case app.discoveryRegistered.Load():
+		return "registered"
+	case len(app.readinessChecks) > 0 && !app.readinessPassed.Load():
+		return "waiting_for_readiness"
+	default:
+		return "retrying"
+	}
+}
@@ -3,7 +3,9 @@ package autoregister
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -93,8 +95,6 @@ func (g *Generator) extractImports(services []*ServiceInfo) []string {
 	imports := make(map[string]bool)
 	
 	for _, service := range services {
-		// 从文件路径推断包路径
-		// 这里需要根据实际的项目结构来调整
 		packagePath := g.inferPackagePath(service.FilePath)
 		if packagePath != "" {
 			imports[packagePath] = true
@@ -105,20 +105,30 @@ func (g *Generator) extractImports(services []*ServiceInfo) []string {
 	for imp := range imports {
 		result = append(result, imp)
 	}
+	// 保证生成代码稳定
+	sort.Strings(result)
 	
 	return result
 }
 
 // inferPackagePath 推断包路径
+// 优先根据最近的 go.mod 计算模块限定的导入路径，找不到 go.mod 时退回到按 "/pkg/" 推断
 func (g *Generator) inferPackagePath(filePath string) string {
-	// 这里需要根据项目的模块路径来推断
-	// 简化实现，实际使用时需要更复杂的逻辑
-	
-	// 假设项目结构为 github.com/user/project/...
-	// 需要从 go.mod 文件中读取模块路径
-	
 	dir := filepath.Dir(filePath)
 	
+	if module, err := findModule(dir); err == nil {
+		absDir, err := filepath.Abs(dir)
+		if err == nil {
+			if rel, err := filepath.Rel(module.Root, absDir); err == nil && !strings.HasPrefix(rel, "..") {
+				return path.Join(module.Path, filepath.ToSlash(rel))
+			}
+		}
+	} else {
+		g.logger.Debug("Failed to detect module path, falling back to path heuristics",
+			zap.String("file", filePath),
+			zap.Error(err))
+	}
+	
 	// 移除常见的前缀路径
 	if strings.Contains(dir, "/pkg/") {
 		parts := strings.Split(dir, "/pkg/")
@@ -127,5 +137,7 @@ func (g *Generator) inferPackagePath(filePath string) string {
 		}
 	}
 	
+	g.logger.Warn("Unable to infer import path for service file",
+		zap.String("file", filePath))
 	return ""
-}
\ No newline at end of file
+}
@@ -1,11 +1,13 @@
 package autoregister
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"go.uber.org/zap"
 )
 
@@ -300,4 +302,108 @@ func TestGenerateRegistrationCodeDirectoryCreation(t *testing.T) {
 	if _, err := os.Stat(nestedDir); os.IsNotExist(err) {
 		t.Fatal("Expected nested directories to be created")
 	}
-}
\ No newline at end of file
+}
+func TestGenerateRegistrationCodeModuleImports(t *testing.T) {
+	// 创建临时模块
+	moduleDir := t.TempDir()
+	goMod := "// 测试模块\nmodule example.com/demo // 模块路径\n\ngo 1.22\n"
+	if err := os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+
+	serviceContent := `package %s
+
+import "google.golang.org/grpc"
+
+type %s struct{}
+
+func (s *%s) RegisterService(server grpc.ServiceRegistrar) {}
+`
+	files := map[string][]string{
+		filepath.Join("internal", "services", "user"): {"user", "UserService"},
+		filepath.Join("api", "order"):                 {"order", "OrderService"},
+	}
+	for dir, info := range files {
+		fullDir := filepath.Join(moduleDir, dir)
+		if err := os.MkdirAll(fullDir, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		content := fmt.Sprintf(serviceContent, info[0], info[1], info[1])
+		if err := os.WriteFile(filepath.Join(fullDir, "service.go"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create service file: %v", err)
+		}
+	}
+
+	cfg := &config.AutoRegisterConfig{
+		ScanDirs: []string{moduleDir},
+		Patterns: []string{"*.go"},
+	}
+	services, err := NewScanner(cfg, zap.NewNop()).ScanServices()
+	if err != nil {
+		t.Fatalf("Failed to scan services: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(services))
+	}
+
+	outputPath := filepath.Join(moduleDir, "cmd", "server", "auto_register_generated.go")
+	if err := NewGenerator(zap.NewNop()).GenerateRegistrationCode(services, outputPath); err != nil {
+		t.Fatalf("Failed to generate registration code: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+
+	for _, expected := range []string{
+		`"example.com/demo/api/order"`,
+		`"example.com/demo/internal/services/user"`,
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected generated code to import %s, got:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(string(content), "github.com/go-grpc-kit/go-grpc-kit") {
+		t.Error("Expected generated imports not to use the hardcoded module path")
+	}
+}
+
+func TestReadModulePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{name: "plain", content: "module example.com/demo\n\ngo 1.22\n", expected: "example.com/demo"},
+		{name: "quoted", content: "module \"example.com/quoted\"\n", expected: "example.com/quoted"},
+		{name: "comment", content: "// header\nmodule example.com/commented // trailing\n", expected: "example.com/commented"},
+		{name: "keyword prefix", content: "modulefoo example.com/wrong\nmodule\texample.com/tabbed\n", expected: "example.com/tabbed"},
+		{name: "prefix only", content: "modulefoo\n", wantErr: true},
+		{name: "missing", content: "go 1.22\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goModPath := filepath.Join(t.TempDir(), "go.mod")
+			if err := os.WriteFile(goModPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create go.mod: %v", err)
+			}
+
+			modulePath, err := readModulePath(goModPath)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error for go.mod without module declaration")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read module path: %v", err)
+			}
+			if modulePath != tt.expected {
+				t.Errorf("Expected module path %s, got %s", tt.expected, modulePath)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package autoregister
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// matchPath 检查相对路径是否匹配模式
+// 不含 "/" 和 "**" 的模式只匹配文件名（兼容原有的 "*.go" 写法），
+// 其余模式按 doublestar 语义匹配相对于扫描根目录的完整路径
+func matchPath(pattern, relPath string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	relPath = filepath.ToSlash(relPath)
+
+	if !strings.Contains(pattern, "/") && !strings.Contains(pattern, "**") {
+		matched, _ := path.Match(pattern, path.Base(relPath))
+		return matched
+	}
+
+	return matchGlob(pattern, relPath)
+}
+
+// matchGlob 按 doublestar 语义匹配路径
+// "**" 匹配零个或多个路径段，其他段使用 path.Match 规则
+func matchGlob(pattern, name string) bool {
+	return matchSegments(splitSegments(pattern), splitSegments(name))
+}
+
+// matchSegments 逐段匹配
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// 合并连续的 "**"
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], name[0]); !matched {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// splitSegments 拆分路径段，忽略空段
+func splitSegments(p string) []string {
+	var segments []string
+	for _, seg := range strings.Split(p, "/") {
+		if seg != "" && seg != "." {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
@@ -0,0 +1,42 @@
+package autoregister
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"**", "a/b/c.go", true},
+		{"**/*.go", "c.go", true},
+		{"**/*.go", "a/b/c.go", true},
+		{"a/**", "a", true},
+		{"a/**", "a/b/c.go", true},
+		{"a/**/c.go", "a/c.go", true},
+		{"a/**/c.go", "a/b/d/c.go", true},
+		{"a/*/c.go", "a/b/d/c.go", false},
+		{"a/**", "b/a/c.go", false},
+		{"**/b/*.go", "a/b/c.go", true},
+		{"**/b/*.go", "a/b/d/c.go", false},
+	}
+
+	for _, test := range tests {
+		result := matchGlob(test.pattern, test.name)
+		if result != test.expected {
+			t.Errorf("matchGlob(%s, %s) = %v, expected %v", test.pattern, test.name, result, test.expected)
+		}
+	}
+}
+
+func TestMatchPathBasename(t *testing.T) {
+	if !matchPath("*_test.go", "a/b/c_test.go") {
+		t.Error("Expected basename pattern to match nested file")
+	}
+	if matchPath("*_test.go", "a/b/c.go") {
+		t.Error("Expected basename pattern not to match")
+	}
+	if !matchPath("./services/*.go", "services/user.go") {
+		t.Error("Expected leading ./ to be ignored")
+	}
+}
@@ -0,0 +1,77 @@
+package autoregister
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// moduleInfo go.mod 中的模块信息
+type moduleInfo struct {
+	// Path 模块路径
+	Path string
+	// Root go.mod 所在目录
+	Root string
+}
+
+// findModule 从指定目录向上查找最近的 go.mod
+func findModule(dir string) (*moduleInfo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory %s: %w", dir, err)
+	}
+
+	for {
+		goModPath := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(goModPath); err == nil {
+			modulePath, err := readModulePath(goModPath)
+			if err != nil {
+				return nil, err
+			}
+			return &moduleInfo{Path: modulePath, Root: dir}, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
+
+// readModulePath 读取 go.mod 中的 module 声明
+func readModulePath(goModPath string) (string, error) {
+	file, err := os.Open(goModPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", goModPath, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		// module 关键字后必须是空白，避免把 modulefoo 之类的行当作模块声明
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+
+		modulePath := fields[1]
+		// 模块路径允许使用引号
+		if unquoted, err := strconv.Unquote(modulePath); err == nil {
+			modulePath = unquoted
+		}
+		return modulePath, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+
+	return "", fmt.Errorf("module declaration not found in %s", goModPath)
+}
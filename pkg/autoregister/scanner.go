@@ -64,18 +64,33 @@ func (s *Scanner) scanDirectory(dir string) ([]*ServiceInfo, error) {
 			return err
 		}
 
+		// 模式匹配基于相对于扫描根目录的路径
+		relPath, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			relPath = path
+		}
+
+		// 被排除的目录整体跳过
+		if info.IsDir() {
+			if relPath != "." && s.isExcluded(relPath) {
+				s.logger.Debug("Skipping excluded directory", zap.String("dir", path))
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// 跳过非 Go 文件
 		if !strings.HasSuffix(path, ".go") {
 			return nil
 		}
 
 		// 检查是否匹配模式
-		if !s.matchesPattern(path) {
+		if !s.matchesPattern(relPath) {
 			return nil
 		}
 
 		// 检查是否被排除
-		if s.isExcluded(path) {
+		if s.isExcluded(relPath) {
 			return nil
 		}
 
@@ -106,15 +121,23 @@ func (s *Scanner) scanFile(filePath string) ([]*ServiceInfo, error) {
 	ast.Inspect(src, func(n ast.Node) bool {
 		switch node := n.(type) {
 		case *ast.TypeSpec:
-			if s.isServiceType(node, src) {
-				service := &ServiceInfo{
-					PackageName: src.Name.Name,
-					TypeName:    node.Name.Name,
-					FilePath:    filePath,
-					ServiceName: s.extractServiceName(node.Name.Name),
-				}
-				services = append(services, service)
+			if !s.isServiceType(node, src) {
+				return true
+			}
+			// 泛型类型无法直接实例化，跳过以免生成无效代码
+			if node.TypeParams != nil && len(node.TypeParams.List) > 0 {
+				s.logger.Warn("Skipping generic service type, register it manually with a concrete instantiation",
+					zap.String("type", node.Name.Name),
+					zap.String("file", filePath))
+				return true
+			}
+			service := &ServiceInfo{
+				PackageName: src.Name.Name,
+				TypeName:    node.Name.Name,
+				FilePath:    filePath,
+				ServiceName: s.extractServiceName(node.Name.Name),
 			}
+			services = append(services, service)
 		}
 		return true
 	})
@@ -135,7 +158,7 @@ func (s *Scanner) isServiceType(typeSpec *ast.TypeSpec, file *ast.File) bool {
 		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
 			if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
 				if recv, ok := funcDecl.Recv.List[0].Type.(*ast.StarExpr); ok {
-					if ident, ok := recv.X.(*ast.Ident); ok {
+					if ident := receiverTypeIdent(recv.X); ident != nil {
 						if ident.Name == typeSpec.Name.Name && 
 						   funcDecl.Name.Name == "RegisterService" {
 							return true
@@ -163,28 +186,43 @@ func (s *Scanner) isServiceType(typeSpec *ast.TypeSpec, file *ast.File) bool {
 	return false
 }
 
+// receiverTypeIdent 获取接收者的类型名，兼容泛型接收者 T[P] 和 T[P, Q]
+func receiverTypeIdent(expr ast.Expr) *ast.Ident {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		return x
+	case *ast.IndexExpr:
+		return receiverTypeIdent(x.X)
+	case *ast.IndexListExpr:
+		return receiverTypeIdent(x.X)
+	}
+	return nil
+}
+
 // matchesPattern 检查文件是否匹配模式
-func (s *Scanner) matchesPattern(filePath string) bool {
+// relPath 为相对于扫描根目录的路径，支持 "**" 递归匹配
+func (s *Scanner) matchesPattern(relPath string) bool {
 	if len(s.config.Patterns) == 0 {
 		return true // 如果没有指定模式，匹配所有文件
 	}
 
 	for _, pattern := range s.config.Patterns {
-		if matched, _ := filepath.Match(pattern, filepath.Base(filePath)); matched {
+		if matchPath(pattern, relPath) {
 			return true
 		}
 	}
 	return false
 }
 
-// isExcluded 检查文件是否被排除
-func (s *Scanner) isExcluded(filePath string) bool {
+// isExcluded 检查文件或目录是否被排除
+// relPath 为相对于扫描根目录的路径，支持 "vendor/**" 这类目录排除
+// 不含通配符的排除项（如 "mock"、"testdata/"）同时按子串匹配路径，兼容原有写法
+func (s *Scanner) isExcluded(relPath string) bool {
 	for _, exclude := range s.config.Excludes {
-		if matched, _ := filepath.Match(exclude, filepath.Base(filePath)); matched {
+		if matchPath(exclude, relPath) {
 			return true
 		}
-		// 也检查完整路径
-		if strings.Contains(filePath, exclude) {
+		if !strings.ContainsAny(exclude, "*?[") && strings.Contains(filepath.ToSlash(relPath), filepath.ToSlash(exclude)) {
 			return true
 		}
 	}
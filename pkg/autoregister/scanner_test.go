@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewScanner(t *testing.T) {
@@ -346,4 +347,175 @@ func TestExtractServiceNameWithPattern(t *testing.T) {
 	if result != expected {
 		t.Errorf("extractServiceName with pattern = %s, expected %s", result, expected)
 	}
-}
\ No newline at end of file
+}
+func TestMatchesPatternRecursive(t *testing.T) {
+	cfg := &config.AutoRegisterConfig{
+		Patterns: []string{"services/**/*.go"},
+	}
+	scanner := NewScanner(cfg, zap.NewNop())
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"services/user.go", true},
+		{"services/user/v1/user.go", true},
+		{"handlers/user.go", false},
+		{"user.go", false},
+	}
+
+	for _, test := range tests {
+		result := scanner.matchesPattern(test.path)
+		if result != test.expected {
+			t.Errorf("matchesPattern(%s) = %v, expected %v", test.path, result, test.expected)
+		}
+	}
+}
+
+func TestIsExcludedDirectory(t *testing.T) {
+	cfg := &config.AutoRegisterConfig{
+		Excludes: []string{"vendor/**", "**/internal/*.go", "*_test.go"},
+	}
+	scanner := NewScanner(cfg, zap.NewNop())
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"vendor", true},
+		{"vendor/lib/service.go", true},
+		{"pkg/internal/service.go", true},
+		{"internal/service.go", true},
+		{"pkg/user/user_test.go", true},
+		{"myvendor/service.go", false},
+		{"pkg/internal/sub/service.go", false},
+		{"pkg/user/service.go", false},
+	}
+
+	for _, test := range tests {
+		result := scanner.isExcluded(test.path)
+		if result != test.expected {
+			t.Errorf("isExcluded(%s) = %v, expected %v", test.path, result, test.expected)
+		}
+	}
+}
+
+func TestIsExcludedPlainSubstring(t *testing.T) {
+	cfg := &config.AutoRegisterConfig{
+		Excludes: []string{"mock", "testdata/"},
+	}
+	scanner := NewScanner(cfg, zap.NewNop())
+
+	// 不含通配符的排除项按子串匹配嵌套路径
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"pkg/mocks/user.go", true},
+		{"pkg/user/user_mock.go", true},
+		{"pkg/user/testdata/service.go", true},
+		{"testdata/service.go", true},
+		{"pkg/user/service.go", false},
+		{"pkg/testdatax/service.go", false},
+	}
+
+	for _, test := range tests {
+		result := scanner.isExcluded(test.path)
+		if result != test.expected {
+			t.Errorf("isExcluded(%s) = %v, expected %v", test.path, result, test.expected)
+		}
+	}
+}
+
+func TestScanServicesNestedDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"user/user_service.go":         "package user\n\ntype UserService struct{}\n",
+		"order/v1/order_service.go":    "package v1\n\ntype OrderService struct{}\n",
+		"vendor/lib/vendor_service.go": "package lib\n\ntype VendorService struct{}\n",
+		"legacy/legacy_service.go":     "package legacy\n\ntype LegacyService struct{}\n",
+	}
+	for name, content := range files {
+		fullPath := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test service file: %v", err)
+		}
+	}
+
+	cfg := &config.AutoRegisterConfig{
+		ScanDirs: []string{tempDir},
+		Patterns: []string{"**/*_service.go"},
+		Excludes: []string{"vendor/**", "legacy/*.go"},
+	}
+	scanner := NewScanner(cfg, zap.NewNop())
+
+	services, err := scanner.ScanServices()
+	if err != nil {
+		t.Fatalf("Failed to scan services: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, service := range services {
+		found[service.TypeName] = true
+	}
+
+	if len(services) != 2 {
+		t.Errorf("Expected 2 services, got %d", len(services))
+	}
+	if !found["UserService"] || !found["OrderService"] {
+		t.Errorf("Expected UserService and OrderService to be found, got %v", found)
+	}
+	if found["VendorService"] || found["LegacyService"] {
+		t.Errorf("Expected excluded services to be skipped, got %v", found)
+	}
+}
+
+func TestScanServicesSkipsGenericTypes(t *testing.T) {
+	tempDir := t.TempDir()
+
+	serviceContent := `package services
+
+import "google.golang.org/grpc"
+
+type CacheService[K comparable, V any] struct {
+	items map[K]V
+}
+
+func (s *CacheService[K, V]) RegisterService(server grpc.ServiceRegistrar) {}
+
+type UserService struct{}
+`
+
+	serviceFile := filepath.Join(tempDir, "services.go")
+	if err := os.WriteFile(serviceFile, []byte(serviceContent), 0644); err != nil {
+		t.Fatalf("Failed to create test service file: %v", err)
+	}
+
+	core, logs := observer.New(zap.WarnLevel)
+	cfg := &config.AutoRegisterConfig{
+		ScanDirs: []string{tempDir},
+		Patterns: []string{"*.go"},
+	}
+	scanner := NewScanner(cfg, zap.New(core))
+
+	services, err := scanner.ScanServices()
+	if err != nil {
+		t.Fatalf("Failed to scan services: %v", err)
+	}
+
+	if len(services) != 1 || services[0].TypeName != "UserService" {
+		t.Fatalf("Expected only UserService to be found, got %v", services)
+	}
+
+	warnings := logs.FilterMessage("Skipping generic service type, register it manually with a concrete instantiation").All()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning for generic type, got %d", len(warnings))
+	}
+	if warnings[0].ContextMap()["type"] != "CacheService" {
+		t.Errorf("Expected warning for CacheService, got %v", warnings[0].ContextMap()["type"])
+	}
+}
@@ -0,0 +1,274 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// WeightedRoundRobinName 基于服务发现元数据的加权轮询负载均衡策略名称
+// 与 gRPC 内置的基于 ORCA 负载上报的 weighted_round_robin 区分开
+const WeightedRoundRobinName = "metadata_weighted_round_robin"
+
+const (
+	// MetadataWeight 服务元数据中的权重键
+	MetadataWeight = discovery.MetadataWeight
+	// MetadataZone 服务元数据中的可用区键
+	MetadataZone = discovery.MetadataZone
+
+	// defaultWeight 未设置或设置无效时的默认权重
+	defaultWeight = discovery.DefaultWeight
+
+	// slowStartScale 开启慢启动时权重的放大倍数，使爬升过程中的权重可以按比例细分
+	slowStartScale = 100
+	// slowStartMinFactor 慢启动开始时新地址获得的权重比例
+	slowStartMinFactor = 0.1
+)
+
+// weightAttributeKey 地址属性中的权重键
+type weightAttributeKey struct{}
+
+// zoneAttributeKey 地址属性中的可用区键
+type zoneAttributeKey struct{}
+
+// addedAtAttributeKey 地址属性中地址被发现的时间键
+type addedAtAttributeKey struct{}
+
+func init() {
+	balancer.Register(&weightedBalancerBuilder{})
+}
+
+// withEndpointMetadata 将服务元数据中的权重和可用区附加到地址属性
+func withEndpointMetadata(addr resolver.Address, metadata map[string]string) resolver.Address {
+	info := &discovery.ServiceInfo{Metadata: metadata}
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(weightAttributeKey{}, info.Weight())
+
+	if zone := info.Zone(); zone != "" {
+		addr.BalancerAttributes = addr.BalancerAttributes.WithValue(zoneAttributeKey{}, zone)
+	}
+
+	return addr
+}
+
+// withAddedAt 将地址被发现的时间附加到地址属性，供慢启动使用
+func withAddedAt(addr resolver.Address, addedAt time.Time) resolver.Address {
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(addedAtAttributeKey{}, addedAt)
+	return addr
+}
+
+// addedAtFromAddress 获取地址被发现的时间，未设置时返回零值
+func addedAtFromAddress(addr resolver.Address) time.Time {
+	addedAt, _ := addr.BalancerAttributes.Value(addedAtAttributeKey{}).(time.Time)
+	return addedAt
+}
+
+// WeightFromAddress 获取地址上的权重，未设置时返回默认权重 1
+func WeightFromAddress(addr resolver.Address) int {
+	if weight, ok := addr.BalancerAttributes.Value(weightAttributeKey{}).(int); ok && weight > 0 {
+		return weight
+	}
+	return defaultWeight
+}
+
+// ZoneFromAddress 获取地址上的可用区
+func ZoneFromAddress(addr resolver.Address) string {
+	zone, _ := addr.BalancerAttributes.Value(zoneAttributeKey{}).(string)
+	return zone
+}
+
+// weightedBalancerConfig 加权轮询负载均衡配置
+type weightedBalancerConfig struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	// LocalZone 客户端所在可用区，非空时优先选择同可用区的后端
+	LocalZone string `json:"localZone,omitempty"`
+
+	// SlowStartWindow 慢启动窗口（秒），大于 0 时新发现的后端在窗口内从 10% 权重线性爬升到完整权重
+	SlowStartWindow int `json:"slowStartWindow,omitempty"`
+}
+
+// weightedBalancerBuilder 加权轮询负载均衡构建器
+type weightedBalancerBuilder struct{}
+
+// Build 构建负载均衡器
+func (b *weightedBalancerBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pickerBuilder := &weightedPickerBuilder{}
+	return &weightedBalancer{
+		Balancer:      base.NewBalancerBuilder(WeightedRoundRobinName, pickerBuilder, base.Config{HealthCheck: true}).Build(cc, opts),
+		pickerBuilder: pickerBuilder,
+	}
+}
+
+// Name 返回负载均衡策略名称
+func (b *weightedBalancerBuilder) Name() string {
+	return WeightedRoundRobinName
+}
+
+// ParseConfig 解析负载均衡配置
+func (b *weightedBalancerBuilder) ParseConfig(js json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	cfg := &weightedBalancerConfig{}
+	if len(js) > 0 {
+		if err := json.Unmarshal(js, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s config: %w", WeightedRoundRobinName, err)
+		}
+	}
+	return cfg, nil
+}
+
+// weightedBalancer 在基础负载均衡器上记录最新的地址权重和本地可用区
+type weightedBalancer struct {
+	balancer.Balancer
+	pickerBuilder *weightedPickerBuilder
+}
+
+// UpdateClientConnState 更新解析结果
+func (b *weightedBalancer) UpdateClientConnState(state balancer.ClientConnState) error {
+	localZone := ""
+	var slowStartWindow time.Duration
+	if cfg, ok := state.BalancerConfig.(*weightedBalancerConfig); ok {
+		localZone = cfg.LocalZone
+		slowStartWindow = time.Duration(cfg.SlowStartWindow) * time.Second
+	}
+	b.pickerBuilder.update(localZone, slowStartWindow, state.ResolverState.Addresses)
+
+	return b.Balancer.UpdateClientConnState(state)
+}
+
+// endpointInfo 后端的权重、可用区和被发现的时间
+type endpointInfo struct {
+	weight  int
+	zone    string
+	addedAt time.Time
+}
+
+// newEndpointInfo 从地址属性读取后端信息
+func newEndpointInfo(addr resolver.Address) endpointInfo {
+	return endpointInfo{
+		weight:  WeightFromAddress(addr),
+		zone:    ZoneFromAddress(addr),
+		addedAt: addedAtFromAddress(addr),
+	}
+}
+
+// weightedPickerBuilder 加权轮询选择器构建器
+type weightedPickerBuilder struct {
+	mu              sync.RWMutex
+	localZone       string
+	slowStartWindow time.Duration
+	endpoints       map[string]endpointInfo
+	// now 返回当前时间，测试中可替换
+	now func() time.Time
+}
+
+// update 记录本地可用区、慢启动窗口和各地址的权重信息
+// 基础负载均衡器会复用已创建的 SubConn 地址，因此以最新解析结果为准
+func (pb *weightedPickerBuilder) update(localZone string, slowStartWindow time.Duration, addrs []resolver.Address) {
+	endpoints := make(map[string]endpointInfo, len(addrs))
+	for _, addr := range addrs {
+		endpoints[addr.Addr] = newEndpointInfo(addr)
+	}
+
+	pb.mu.Lock()
+	pb.localZone = localZone
+	pb.slowStartWindow = slowStartWindow
+	pb.endpoints = endpoints
+	pb.mu.Unlock()
+}
+
+// Build 构建选择器
+func (pb *weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+
+	var all, local []*weightedSubConn
+	for sc, scInfo := range info.ReadySCs {
+		endpoint, ok := pb.endpoints[scInfo.Address.Addr]
+		if !ok {
+			endpoint = newEndpointInfo(scInfo.Address)
+		}
+
+		wsc := &weightedSubConn{subConn: sc, weight: endpoint.weight, addedAt: endpoint.addedAt}
+		all = append(all, wsc)
+		if pb.localZone != "" && endpoint.zone == pb.localZone {
+			local = append(local, wsc)
+		}
+	}
+
+	picker := &weightedPicker{subConns: all, slowStartWindow: pb.slowStartWindow, now: pb.now}
+	if picker.now == nil {
+		picker.now = time.Now
+	}
+
+	// 本地可用区有可用后端时只在本地可用区内均衡
+	if len(local) > 0 {
+		picker.subConns = local
+	}
+	return picker
+}
+
+// weightedSubConn 带权重的子连接
+type weightedSubConn struct {
+	subConn       balancer.SubConn
+	weight        int
+	addedAt       time.Time
+	currentWeight int
+}
+
+// effectiveWeight 返回子连接当前的权重
+// 开启慢启动时权重放大 slowStartScale 倍，窗口内新发现的后端从 slowStartMinFactor 线性爬升到完整权重
+func (sc *weightedSubConn) effectiveWeight(now time.Time, window time.Duration) int {
+	if window <= 0 {
+		return sc.weight
+	}
+	weight := sc.weight * slowStartScale
+	elapsed := now.Sub(sc.addedAt)
+	if sc.addedAt.IsZero() || elapsed >= window {
+		return weight
+	}
+	factor := float64(elapsed) / float64(window)
+	if factor < slowStartMinFactor {
+		factor = slowStartMinFactor
+	}
+	return int(float64(weight) * factor)
+}
+
+// weightedPicker 平滑加权轮询选择器
+// 选择时计算各子连接的权重，慢启动中的后端无需重建选择器即可逐步获得更多流量
+type weightedPicker struct {
+	mu              sync.Mutex
+	subConns        []*weightedSubConn
+	slowStartWindow time.Duration
+	now             func() time.Time
+}
+
+// Pick 选择子连接
+func (p *weightedPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var selected *weightedSubConn
+	total := 0
+	now := p.now()
+	for _, sc := range p.subConns {
+		weight := sc.effectiveWeight(now, p.slowStartWindow)
+		sc.currentWeight += weight
+		total += weight
+		if selected == nil || sc.currentWeight > selected.currentWeight {
+			selected = sc
+		}
+	}
+	selected.currentWeight -= total
+
+	return balancer.PickResult{SubConn: selected.subConn}, nil
+}
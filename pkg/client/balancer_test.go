@@ -0,0 +1,147 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+// mockSubConn 模拟子连接
+type mockSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+func buildTestPicker(localZone string, addrs []resolver.Address) (balancer.Picker, map[balancer.SubConn]string) {
+	pb := &weightedPickerBuilder{}
+	pb.update(localZone, 0, addrs)
+	return buildPickerWithReadySCs(pb, addrs)
+}
+
+// buildPickerWithReadySCs 以所有地址都已就绪构建选择器
+func buildPickerWithReadySCs(pb *weightedPickerBuilder, addrs []resolver.Address) (balancer.Picker, map[balancer.SubConn]string) {
+	readySCs := make(map[balancer.SubConn]base.SubConnInfo)
+	names := make(map[balancer.SubConn]string)
+	for _, addr := range addrs {
+		sc := &mockSubConn{addr: addr.Addr}
+		readySCs[sc] = base.SubConnInfo{Address: addr}
+		names[sc] = addr.Addr
+	}
+
+	return pb.Build(base.PickerBuildInfo{ReadySCs: readySCs}), names
+}
+
+func TestWeightedPickerDistribution(t *testing.T) {
+	addrs := []resolver.Address{
+		withEndpointMetadata(resolver.Address{Addr: "10.0.0.1:9090"}, map[string]string{"weight": "3"}),
+		withEndpointMetadata(resolver.Address{Addr: "10.0.0.2:9090"}, map[string]string{"weight": "1"}),
+	}
+	picker, names := buildTestPicker("", addrs)
+
+	counts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		result, err := picker.Pick(balancer.PickInfo{})
+		if err != nil {
+			t.Fatalf("Unexpected pick error: %v", err)
+		}
+		counts[names[result.SubConn]]++
+	}
+
+	if counts["10.0.0.1:9090"] != 300 || counts["10.0.0.2:9090"] != 100 {
+		t.Errorf("Expected 300/100 distribution, got %v", counts)
+	}
+}
+
+func TestWeightedPickerPrefersLocalZone(t *testing.T) {
+	addrs := []resolver.Address{
+		withEndpointMetadata(resolver.Address{Addr: "10.0.0.1:9090"}, map[string]string{"weight": "10", "zone": "zone-a"}),
+		withEndpointMetadata(resolver.Address{Addr: "10.0.0.2:9090"}, map[string]string{"weight": "1", "zone": "zone-b"}),
+	}
+	picker, names := buildTestPicker("zone-b", addrs)
+
+	for i := 0; i < 10; i++ {
+		result, err := picker.Pick(balancer.PickInfo{})
+		if err != nil {
+			t.Fatalf("Unexpected pick error: %v", err)
+		}
+		if names[result.SubConn] != "10.0.0.2:9090" {
+			t.Errorf("Expected local zone backend, got %s", names[result.SubConn])
+		}
+	}
+}
+
+func TestWeightedPickerNoReadySubConns(t *testing.T) {
+	pb := &weightedPickerBuilder{}
+	picker := pb.Build(base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{}})
+
+	if _, err := picker.Pick(balancer.PickInfo{}); err != balancer.ErrNoSubConnAvailable {
+		t.Errorf("Expected ErrNoSubConnAvailable, got %v", err)
+	}
+}
+
+func TestWeightedPickerSlowStart(t *testing.T) {
+	added := time.Now()
+	now := added
+	addrs := []resolver.Address{
+		withEndpointMetadata(resolver.Address{Addr: "10.0.0.1:9090"}, nil),
+		withAddedAt(withEndpointMetadata(resolver.Address{Addr: "10.0.0.2:9090"}, nil), added),
+	}
+	pb := &weightedPickerBuilder{now: func() time.Time { return now }}
+	pb.update("", 10*time.Second, addrs)
+	picker, names := buildPickerWithReadySCs(pb, addrs)
+
+	share := func() float64 {
+		t.Helper()
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			result, err := picker.Pick(balancer.PickInfo{})
+			if err != nil {
+				t.Fatalf("Unexpected pick error: %v", err)
+			}
+			counts[names[result.SubConn]]++
+		}
+		return float64(counts["10.0.0.2:9090"]) / 1000
+	}
+
+	// 新地址刚被发现时只获得最小比例的流量，随时间推移逐步增加，窗口结束后与其他地址相同
+	var shares []float64
+	for _, elapsed := range []time.Duration{0, 5 * time.Second, 10 * time.Second} {
+		now = added.Add(elapsed)
+		shares = append(shares, share())
+	}
+	if shares[0] > 0.1 {
+		t.Errorf("Expected new address to receive at most 10%% of picks initially, got %.2f", shares[0])
+	}
+	if shares[1] <= shares[0] || shares[2] <= shares[1] {
+		t.Errorf("Expected pick share to increase over time, got %v", shares)
+	}
+	if shares[2] != 0.5 {
+		t.Errorf("Expected even share after slow start window, got %.2f", shares[2])
+	}
+}
+
+func TestWeightedBalancerParseConfig(t *testing.T) {
+	builder := balancer.Get(WeightedRoundRobinName)
+	if builder == nil {
+		t.Fatal("Expected weighted balancer to be registered")
+	}
+
+	parser, ok := builder.(balancer.ConfigParser)
+	if !ok {
+		t.Fatal("Expected weighted balancer to implement ConfigParser")
+	}
+
+	cfg, err := parser.ParseConfig([]byte(`{"localZone": "zone-a", "slowStartWindow": 30}`))
+	if err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if cfg.(*weightedBalancerConfig).LocalZone != "zone-a" {
+		t.Errorf("Expected local zone 'zone-a', got '%s'", cfg.(*weightedBalancerConfig).LocalZone)
+	}
+	if cfg.(*weightedBalancerConfig).SlowStartWindow != 30 {
+		t.Errorf("Expected slow start window 30, got %d", cfg.(*weightedBalancerConfig).SlowStartWindow)
+	}
+}
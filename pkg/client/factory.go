@@ -2,17 +2,26 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	_ "github.com/go-grpc-kit/go-grpc-kit/pkg/compression" // 注册 gzip 和 deflate 压缩器
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/interceptor"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // 注册客户端健康检查实现，healthCheckConfig 依赖它生效
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/stats"
 )
 
 // ClientFactory gRPC 客户端工厂
@@ -21,66 +30,421 @@ type ClientFactory struct {
 	logger    *zap.Logger
 	registry  discovery.Registry
 	clients   map[string]*grpc.ClientConn
+	resolvers map[string]*discoveryResolverBuilder
+	pending   map[string]*pendingConnection
 	mu        sync.RWMutex
+	closed    bool
+	
+	// 连接状态监听
+	watchState bool
+	
+	// 客户端健康检查使用的健康服务名，按目标服务设置的优先于默认值
+	healthCheck         *string
+	serviceHealthChecks map[string]string
+	
+	// 按目标服务设置的调用默认超时，优先于 grpc.client.timeout
+	serviceTimeouts map[string]time.Duration
+	
+	// 调用方提供的 stats.Handler，如 otelgrpc.NewClientHandler()
+	statsHandlers []stats.Handler
+	
+	// 不带 scheme 的服务名使用的解析器 scheme，如 xds，为空时使用服务发现或直连
+	resolverScheme string
+	
+	// 后台协程共享的上下文，Close 时取消并等待所有后台协程退出
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	
+	// 开启空闲回收时各缓存连接的使用情况
+	usage map[string]*connUsage
+	// now 返回当前时间，测试中可替换
+	now func() time.Time
+}
+
+const (
+	// defaultWarmUpConcurrency 未配置时的预热并发数
+	defaultWarmUpConcurrency = 4
+	// defaultConnectTimeout 未配置超时时等待连接就绪的时间
+	defaultConnectTimeout = 30 * time.Second
+)
+
+// pendingConnection 正在创建的连接，同一服务的并发请求共享创建结果
+type pendingConnection struct {
+	done chan struct{}
+	conn *grpc.ClientConn
+	err  error
+}
+
+// FactoryOption 客户端工厂选项
+type FactoryOption func(*ClientFactory)
+
+// WithConnectionStateWatch 启用连接状态监听，记录状态变化日志和指标
+func WithConnectionStateWatch(enabled bool) FactoryOption {
+	return func(f *ClientFactory) {
+		f.watchState = enabled
+	}
+}
+
+// WithHealthCheck 为所有连接启用客户端健康检查，serviceName 为后端健康服务中检查的服务名，空字符串表示整体状态
+// gRPC 定期通过 grpc.health.v1.Health/Watch 检查每个后端，从负载均衡池中移除非 SERVING 的后端；
+// 后端需要注册标准健康服务，负载均衡策略需为 round_robin 等支持健康检查的策略，且需启用 use_service_config
+func WithHealthCheck(serviceName string) FactoryOption {
+	return func(f *ClientFactory) {
+		f.healthCheck = &serviceName
+	}
+}
+
+// WithServiceHealthCheck 为指定目标服务启用客户端健康检查，优先于 WithHealthCheck
+func WithServiceHealthCheck(target, serviceName string) FactoryOption {
+	return func(f *ClientFactory) {
+		if f.serviceHealthChecks == nil {
+			f.serviceHealthChecks = make(map[string]string)
+		}
+		f.serviceHealthChecks[target] = serviceName
+	}
+}
+
+// WithServiceTimeout 为指定目标服务设置一元调用的默认超时，优先于 grpc.client.timeout，0 表示不设置默认超时
+func WithServiceTimeout(target string, timeout time.Duration) FactoryOption {
+	return func(f *ClientFactory) {
+		if f.serviceTimeouts == nil {
+			f.serviceTimeouts = make(map[string]time.Duration)
+		}
+		f.serviceTimeouts[target] = timeout
+	}
+}
+
+// WithStatsHandler 为所有连接添加 gRPC stats.Handler，可对接 otelgrpc 等基于 stats 的追踪和指标，可多次调用
+func WithStatsHandler(handler stats.Handler) FactoryOption {
+	return func(f *ClientFactory) {
+		f.statsHandlers = append(f.statsHandlers, handler)
+	}
+}
+
+// WithResolverScheme 设置不带 scheme 的服务名使用的 gRPC 解析器，如 WithResolverScheme("xds") 时
+// GetClient("user-service") 连接 xds:///user-service，不经过服务发现；对应的解析器需已注册
+// 带 scheme 的完整目标（如 dns:///host:port）始终原样交给 gRPC，不受此选项影响
+func WithResolverScheme(scheme string) FactoryOption {
+	return func(f *ClientFactory) {
+		f.resolverScheme = scheme
+	}
 }
 
 // NewClientFactory 创建客户端工厂
-func NewClientFactory(cfg *config.Config, registry discovery.Registry, logger *zap.Logger) *ClientFactory {
-	return &ClientFactory{
+func NewClientFactory(cfg *config.Config, registry discovery.Registry, logger *zap.Logger, opts ...FactoryOption) *ClientFactory {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &ClientFactory{
 		config:   cfg,
 		logger:   logger,
 		registry: registry,
-		clients:  make(map[string]*grpc.ClientConn),
+		clients:   make(map[string]*grpc.ClientConn),
+		resolvers: make(map[string]*discoveryResolverBuilder),
+		pending:   make(map[string]*pendingConnection),
+		ctx:       ctx,
+		cancel:    cancel,
+		usage:     make(map[string]*connUsage),
+		now:       time.Now,
+	}
+	
+	// 应用选项
+	for _, opt := range opts {
+		opt(f)
+	}
+	
+	// 定期回收空闲连接
+	if f.idleTimeout() > 0 {
+		f.goBackground(f.sweepIdle)
 	}
+	
+	return f
 }
 
 // GetClient 获取客户端连接
 func (f *ClientFactory) GetClient(serviceName string) (*grpc.ClientConn, error) {
 	f.mu.RLock()
 	if conn, exists := f.clients[serviceName]; exists {
+		if usage := f.usage[serviceName]; usage != nil {
+			usage.touch(f.now())
+		}
 		f.mu.RUnlock()
 		return conn, nil
 	}
 	f.mu.RUnlock()
 	
+	return f.connect(serviceName)
+}
+
+// WarmUp 预先建立到指定服务的连接
+// 同时建立的连接数受 warm_up_concurrency 限制，单个服务失败不影响其他服务，失败信息汇总后返回
+func (f *ClientFactory) WarmUp(ctx context.Context, serviceNames []string) error {
+	concurrency := f.config.GRPC.Client.WarmUpConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultWarmUpConcurrency
+	}
+	
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	sem := make(chan struct{}, concurrency)
+	
+	for _, serviceName := range serviceNames {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("warm up %s: %w", serviceName, ctx.Err()))
+			mu.Unlock()
+			continue
+		}
+		
+		wg.Add(1)
+		go func(serviceName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			
+			if _, err := f.GetClient(serviceName); err != nil {
+				f.logger.Warn("Failed to warm up client connection",
+					zap.String("service", serviceName),
+					zap.Error(err))
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("warm up %s: %w", serviceName, err))
+				mu.Unlock()
+			}
+		}(serviceName)
+	}
+	
+	wg.Wait()
+	
+	f.logger.Info("Client connections warmed up",
+		zap.Int("services", len(serviceNames)),
+		zap.Int("failed", len(errs)),
+		zap.Int("concurrency", concurrency))
+	
+	return errors.Join(errs...)
+}
+
+// Prewarm 预先建立到指定服务的连接并主动拨号，等待连接就绪，使第一次真实请求不必承担拨号和服务发现的延迟
+// 等待时间由 ctx 控制，未就绪或失败的服务只记录日志并汇总返回，连接仍保留在工厂中，之后会继续尝试连接
+func (f *ClientFactory) Prewarm(ctx context.Context, serviceNames ...string) error {
+	warmUpErr := f.WarmUp(ctx, serviceNames)
+	
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	f.mu.RLock()
+	for _, serviceName := range serviceNames {
+		conn, exists := f.clients[serviceName]
+		if !exists {
+			continue
+		}
+		
+		wg.Add(1)
+		go func(serviceName string, conn *grpc.ClientConn) {
+			defer wg.Done()
+			
+			if err := waitForReady(ctx, conn); err != nil {
+				f.logger.Warn("Client connection not ready after prewarm",
+					zap.String("service", serviceName),
+					zap.String("state", conn.GetState().String()),
+					zap.Error(err))
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("prewarm %s: %w", serviceName, err))
+				mu.Unlock()
+			}
+		}(serviceName, conn)
+	}
+	f.mu.RUnlock()
+	
+	wg.Wait()
+	return errors.Join(warmUpErr, errors.Join(errs...))
+}
+
+// waitForReady 主动拨号并等待连接进入 READY 状态，直到 ctx 结束
+// BlockOnConnect 和 Prewarm 共用，连接已关闭时立即返回错误
+func waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("connection closed")
+		case connectivity.Idle:
+			// 空闲的连接不会自动拨号
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection not ready, last state %s: %w", state, ctx.Err())
+		}
+	}
+}
+
+// CloseClient 关闭并移除指定服务的缓存连接
+func (f *ClientFactory) CloseClient(serviceName string) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	
+	return f.closeClientLocked(serviceName)
+}
+
+// Reconnect 关闭指定服务的缓存连接并重新创建
+func (f *ClientFactory) Reconnect(serviceName string) (*grpc.ClientConn, error) {
+	f.mu.Lock()
+	if _, exists := f.clients[serviceName]; exists {
+		if err := f.closeClientLocked(serviceName); err != nil {
+			f.logger.Warn("Failed to close client connection before reconnect",
+				zap.String("service", serviceName),
+				zap.Error(err))
+		}
+	}
+	f.mu.Unlock()
+	
+	return f.connect(serviceName)
+}
+
+// connect 创建并缓存连接
+// 连接在锁外创建，避免慢速的服务发现阻塞其他服务；同一服务的并发请求只创建一次
+func (f *ClientFactory) connect(serviceName string) (*grpc.ClientConn, error) {
+	f.mu.Lock()
+	
+	if f.closed {
+		f.mu.Unlock()
+		return nil, fmt.Errorf("client factory is closed")
+	}
+	
 	// 双重检查
 	if conn, exists := f.clients[serviceName]; exists {
+		f.mu.Unlock()
 		return conn, nil
 	}
 	
-	// 创建新连接
-	conn, err := f.createConnection(serviceName)
-	if err != nil {
-		return nil, err
+	// 等待正在进行的创建
+	if pending, exists := f.pending[serviceName]; exists {
+		f.mu.Unlock()
+		<-pending.done
+		return pending.conn, pending.err
+	}
+	
+	pending := &pendingConnection{done: make(chan struct{})}
+	f.pending[serviceName] = pending
+	f.mu.Unlock()
+	
+	var usage *connUsage
+	if f.idleTimeout() > 0 {
+		usage = &connUsage{}
 	}
+	conn, builder, err := f.createConnection(serviceName, usage)
 	
-	f.clients[serviceName] = conn
-	return conn, nil
+	f.mu.Lock()
+	delete(f.pending, serviceName)
+	if err == nil && f.closed {
+		// 创建期间工厂已关闭，丢弃新连接
+		conn.Close()
+		conn, err = nil, fmt.Errorf("client factory is closed")
+	}
+	if err == nil {
+		f.clients[serviceName] = conn
+		if builder != nil {
+			f.resolvers[serviceName] = builder
+		}
+		if usage != nil {
+			usage.touch(f.now())
+			f.usage[serviceName] = usage
+		}
+		if f.watchState {
+			f.goBackground(func(ctx context.Context) {
+				f.watchConnectionState(ctx, serviceName, conn)
+			})
+		}
+	}
+	f.mu.Unlock()
+	
+	pending.conn, pending.err = conn, err
+	close(pending.done)
+	
+	return conn, err
 }
 
-// createConnection 创建连接
-func (f *ClientFactory) createConnection(serviceName string) (*grpc.ClientConn, error) {
-	// 首先检查服务是否存在
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// closeClientLocked 关闭并移除缓存连接，调用方需持有写锁
+func (f *ClientFactory) closeClientLocked(serviceName string) error {
+	conn, exists := f.clients[serviceName]
+	if !exists {
+		return fmt.Errorf("no client connection for service %s", serviceName)
+	}
 	
-	services, err := f.registry.Discover(ctx, serviceName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to discover service %s: %w", serviceName, err)
+	builder := f.resolvers[serviceName]
+	delete(f.clients, serviceName)
+	delete(f.resolvers, serviceName)
+	delete(f.usage, serviceName)
+	
+	if err := conn.Close(); err != nil {
+		return fmt.Errorf("failed to close client connection for %s: %w", serviceName, err)
+	}
+	if builder != nil {
+		builder.wait()
 	}
 	
-	if len(services) == 0 {
-		return nil, fmt.Errorf("service %s not found", serviceName)
+	f.logger.Info("Closed gRPC client connection", zap.String("service", serviceName))
+	return nil
+}
+
+// goBackground 启动由工厂管理的后台协程，调用方需持有写锁
+// 协程应在 ctx 取消后尽快退出，Close 会等待其结束
+func (f *ClientFactory) goBackground(fn func(ctx context.Context)) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		fn(f.ctx)
+	}()
+}
+
+// watchConnectionState 监听连接状态变化，连接关闭或工厂关闭后退出
+func (f *ClientFactory) watchConnectionState(ctx context.Context, serviceName string, conn *grpc.ClientConn) {
+	state := conn.GetState()
+	for {
+		changed := conn.WaitForStateChange(ctx, state)
+		
+		// 工厂关闭时连接先于上下文关闭，仍记录最后一次状态变化
+		newState := conn.GetState()
+		if newState != state {
+			clientConnectionStateChanges.WithLabelValues(serviceName, newState.String()).Inc()
+			f.logger.Info("gRPC client connection state changed",
+				zap.String("service", serviceName),
+				zap.String("from", state.String()),
+				zap.String("to", newState.String()))
+		}
+		
+		if !changed || newState == connectivity.Shutdown {
+			return
+		}
+		state = newState
+	}
+}
+
+// createConnection 创建连接，usage 不为 nil 时记录连接的使用时间
+func (f *ClientFactory) createConnection(serviceName string, usage *connUsage) (*grpc.ClientConn, *discoveryResolverBuilder, error) {
+	// 带 scheme 的目标由对应的 gRPC 解析器负责服务发现，跳过注册中心
+	target, direct := f.dialTarget(serviceName)
+	if direct {
+		if err := checkResolverSupport(target); err != nil {
+			return nil, nil, fmt.Errorf("failed to create client for %s: %w", serviceName, err)
+		}
+	} else if f.registry != nil {
+		if err := f.checkServiceExists(serviceName); err != nil {
+			return nil, nil, err
+		}
 	}
 	
 	// 构建连接选项
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultServiceConfig(f.buildServiceConfig()),
 	}
+	opts = append(opts, f.serviceConfigOptions(serviceName)...)
 	
 	// 设置消息大小限制
 	if f.config.GRPC.Client.MaxRecvMsgSize > 0 {
@@ -90,6 +454,11 @@ func (f *ClientFactory) createConnection(serviceName string) (*grpc.ClientConn,
 		opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(f.config.GRPC.Client.MaxSendMsgSize)))
 	}
 	
+	// 设置请求压缩算法
+	if clientCfg := f.config.GRPC.Client; clientCfg.EnableCompression && clientCfg.CompressionLevel != "" {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(clientCfg.CompressionLevel)))
+	}
+	
 	// 设置 Keepalive 配置
 	if f.config.GRPC.Client.KeepaliveTime > 0 {
 		keepaliveParams := keepalive.ClientParameters{
@@ -100,48 +469,178 @@ func (f *ClientFactory) createConnection(serviceName string) (*grpc.ClientConn,
 		opts = append(opts, grpc.WithKeepaliveParams(keepaliveParams))
 	}
 	
+	// 设置流控窗口和头部列表大小
+	opts = append(opts, f.flowControlOptions()...)
+	
 	// 添加拦截器
-	opts = append(opts, f.buildInterceptors()...)
+	opts = append(opts, f.buildInterceptors(serviceName)...)
+	if usage != nil {
+		opts = append(opts, f.usageInterceptors(usage)...)
+	}
+	for _, handler := range f.statsHandlers {
+		opts = append(opts, grpc.WithStatsHandler(handler))
+	}
 	
 	// 确定目标地址
-	var target string
-	if f.registry != nil {
+	var builder *discoveryResolverBuilder
+	if isXDSTarget(target) {
+		// 使用 xDS 解析器，目标如 xds:///user-service
+		f.logger.Info("Using xDS resolver for gRPC client",
+			zap.String("service", serviceName),
+			zap.String("target", target),
+			zap.String("bootstrap", os.Getenv(XDSBootstrapEnv)))
+	} else if direct {
+		// 使用目标 scheme 对应的解析器，目标原样交给 gRPC
+		f.logger.Info("Using scheme resolver for gRPC client",
+			zap.String("service", serviceName),
+			zap.String("target", target))
+	} else if f.registry != nil {
 		// 使用服务发现解析器
 		target = fmt.Sprintf("discovery:///%s", serviceName)
-		// 注册自定义解析器
-		f.registerResolver(serviceName)
+		// 为每个连接单独创建解析器，避免全局注册的解析器互相覆盖
+		builder = f.newResolverBuilder(serviceName)
+		opts = append(opts, grpc.WithResolvers(builder))
 	} else {
 		// 直接使用DNS解析，serviceName应该是host:port格式
-		target = serviceName
 		f.logger.Info("Using DNS resolver for gRPC client",
 			zap.String("service", serviceName),
 			zap.String("target", target))
 	}
 	
 	// 创建连接
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 
-		time.Duration(f.config.GRPC.Client.Timeout)*time.Second)
-	defer cancel2()
-	
-	conn, err := grpc.DialContext(ctx2, target, opts...)
+	conn, err := grpc.NewClient(target, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial %s: %w", serviceName, err)
+		return nil, nil, fmt.Errorf("failed to create client for %s: %w", serviceName, err)
+	}
+	
+	// NewClient 创建的连接处于 IDLE 状态，主动触发连接
+	conn.Connect()
+	
+	if f.config.GRPC.Client.BlockOnConnect {
+		timeout := f.connectTimeout()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := waitForReady(ctx, conn)
+		cancel()
+		if err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("failed to connect to %s within %s: %w", serviceName, timeout, err)
+		}
 	}
 	
 	f.logger.Info("Created gRPC client connection",
 		zap.String("service", serviceName),
 		zap.String("target", target))
 	
-	return conn, nil
+	return conn, builder, nil
 }
 
-// buildServiceConfig 构建服务配置
-func (f *ClientFactory) buildServiceConfig() string {
+// flowControlOptions 返回流控窗口和头部列表大小选项，值为 0 时使用 gRPC 默认值
+func (f *ClientFactory) flowControlOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	clientCfg := f.config.GRPC.Client
+	if clientCfg.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(clientCfg.InitialWindowSize))
+	}
+	if clientCfg.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(clientCfg.InitialConnWindowSize))
+	}
+	if clientCfg.MaxHeaderListSize > 0 {
+		opts = append(opts, grpc.WithMaxHeaderListSize(clientCfg.MaxHeaderListSize))
+	}
+	return opts
+}
+
+// checkServiceExists 检查服务是否已在注册中心注册
+func (f *ClientFactory) checkServiceExists(serviceName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	
+	services, err := f.registry.Discover(ctx, serviceName)
+	if err != nil {
+		return fmt.Errorf("failed to discover service %s: %w", serviceName, err)
+	}
+	
+	if len(services) == 0 {
+		return fmt.Errorf("service %s not found", serviceName)
+	}
+	return nil
+}
+
+// connectTimeout 返回 block_on_connect 等待连接就绪的时间，为客户端 timeout 配置
+func (f *ClientFactory) connectTimeout() time.Duration {
+	timeout := time.Duration(f.config.GRPC.Client.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	return timeout
+}
+
+// serviceConfigOptions 构建连接到 target 的默认服务配置选项
+// 关闭 use_service_config 时不设置，避免覆盖解析器提供的服务配置
+func (f *ClientFactory) serviceConfigOptions(target string) []grpc.DialOption {
+	if !f.config.GRPC.Client.UseServiceConfig {
+		if _, ok := f.healthCheckServiceName(target); ok {
+			f.logger.Warn("Client health check requires use_service_config, skipping",
+				zap.String("service", target))
+		}
+		return nil
+	}
+	return []grpc.DialOption{grpc.WithDefaultServiceConfig(f.buildServiceConfig(target))}
+}
+
+// buildServiceConfig 构建连接到 target 的服务配置
+func (f *ClientFactory) buildServiceConfig(target string) string {
+	return fmt.Sprintf(`{
+		%s%s%s%s
+	}`, f.buildLoadBalancingConfig(),
+		f.buildHealthCheckConfig(target),
+		f.buildMethodConfig(),
+		f.buildRetryThrottlingConfig())
+}
+
+// healthCheckServiceName 返回 target 的健康检查服务名，未启用健康检查时返回 false
+func (f *ClientFactory) healthCheckServiceName(target string) (string, bool) {
+	if serviceName, ok := f.serviceHealthChecks[target]; ok {
+		return serviceName, true
+	}
+	if f.healthCheck != nil {
+		return *f.healthCheck, true
+	}
+	return "", false
+}
+
+// buildHealthCheckConfig 构建健康检查配置片段，未启用时返回空字符串
+func (f *ClientFactory) buildHealthCheckConfig(target string) string {
+	serviceName, ok := f.healthCheckServiceName(target)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(`,
+		"healthCheckConfig": {"serviceName": %q}`, serviceName)
+}
+
+// buildMethodConfig 构建方法配置片段，重试策略通过通配名称作用于所有方法
+// max_attempts 不大于 1 时不重试，返回空字符串；其余字段未设置或无效时使用默认值，避免 gRPC 拒绝整个服务配置
+func (f *ClientFactory) buildMethodConfig() string {
 	retryPolicy := f.config.GRPC.Client.RetryPolicy
+	if retryPolicy.MaxAttempts <= 1 {
+		return ""
+	}
+	
+	initialBackoff := parseBackoff(retryPolicy.InitialBackoff, defaultInitialBackoff)
+	maxBackoff := parseBackoff(retryPolicy.MaxBackoff, defaultMaxBackoff)
+	multiplier := retryPolicy.BackoffMultiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+	retryableCodes := retryPolicy.RetryableStatusCodes
+	if len(retryableCodes) == 0 {
+		retryableCodes = defaultRetryableStatusCodes
+	}
 	
 	// 构建重试状态码数组
 	statusCodes := "["
-	for i, code := range retryPolicy.RetryableStatusCodes {
+	for i, code := range retryableCodes {
 		if i > 0 {
 			statusCodes += ", "
 		}
@@ -149,30 +648,96 @@ func (f *ClientFactory) buildServiceConfig() string {
 	}
 	statusCodes += "]"
 	
-	return fmt.Sprintf(`{
-		"loadBalancingPolicy": "%s",
-		"retryPolicy": {
-			"maxAttempts": %d,
-			"initialBackoff": "%s",
-			"maxBackoff": "%s",
-			"backoffMultiplier": %f,
-			"retryableStatusCodes": %s
-		}
-	}`, f.config.GRPC.Client.LoadBalancing, 
-		retryPolicy.MaxAttempts,
-		retryPolicy.InitialBackoff,
-		retryPolicy.MaxBackoff,
-		retryPolicy.BackoffMultiplier,
+	return fmt.Sprintf(`,
+		"methodConfig": [{
+			"name": [{"service": ""}],
+			"retryPolicy": {
+				"maxAttempts": %d,
+				"initialBackoff": "%s",
+				"maxBackoff": "%s",
+				"backoffMultiplier": %f,
+				"retryableStatusCodes": %s
+			}
+		}]`, retryPolicy.MaxAttempts,
+		serviceConfigDuration(initialBackoff),
+		serviceConfigDuration(maxBackoff),
+		multiplier,
 		statusCodes)
 }
 
+// 重试策略字段的默认值，与配置默认值一致
+const (
+	defaultInitialBackoff    = time.Second
+	defaultMaxBackoff        = 30 * time.Second
+	defaultBackoffMultiplier = 2.0
+)
+
+var defaultRetryableStatusCodes = []string{"UNAVAILABLE", "DEADLINE_EXCEEDED"}
+
+// parseBackoff 解析退避时间，未设置或无效时返回默认值
+func parseBackoff(value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// serviceConfigDuration 将时长格式化为服务配置要求的秒数形式，如 "1.5s"
+func serviceConfigDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// buildRetryThrottlingConfig 构建重试限流配置片段，未启用时返回空字符串
+func (f *ClientFactory) buildRetryThrottlingConfig() string {
+	retryPolicy := f.config.GRPC.Client.RetryPolicy
+	if retryPolicy.ThrottleMaxTokens <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(`,
+		"retryThrottling": {
+			"maxTokens": %d,
+			"tokenRatio": %f
+		}`, retryPolicy.ThrottleMaxTokens, retryPolicy.ThrottleTokenRatio)
+}
+
+// buildLoadBalancingConfig 构建负载均衡配置片段
+func (f *ClientFactory) buildLoadBalancingConfig() string {
+	if f.config.GRPC.Client.LoadBalancing == WeightedRoundRobinName {
+		slowStart := ""
+		if window := f.config.GRPC.Client.SlowStartWindow; window > 0 {
+			slowStart = fmt.Sprintf(`, "slowStartWindow": %d`, window)
+		}
+		// 配置值需转义后再写入 JSON，避免引号等字符破坏服务配置
+		return fmt.Sprintf(`"loadBalancingConfig": [{"%s": {"localZone": %q%s}}]`,
+			WeightedRoundRobinName, f.config.GRPC.Client.LocalZone, slowStart)
+	}
+	return fmt.Sprintf(`"loadBalancingPolicy": %q`, f.config.GRPC.Client.LoadBalancing)
+}
+
 // buildInterceptors 构建拦截器
-func (f *ClientFactory) buildInterceptors() []grpc.DialOption {
+func (f *ClientFactory) buildInterceptors(serviceName string) []grpc.DialOption {
 	var opts []grpc.DialOption
 	var unaryInterceptors []grpc.UnaryClientInterceptor
 	var streamInterceptors []grpc.StreamClientInterceptor
 	
 	// 根据配置添加拦截器
+	if f.config.GRPC.Client.EnableRequestID {
+		unaryInterceptors = append(unaryInterceptors, interceptor.RequestIDUnaryClientInterceptor())
+		streamInterceptors = append(streamInterceptors, interceptor.RequestIDStreamClientInterceptor())
+	}
+	
+	// 流式调用通常长期存在，只为一元调用设置默认超时
+	if timeout := f.callTimeout(serviceName); timeout > 0 {
+		unaryInterceptors = append(unaryInterceptors, interceptor.TimeoutUnaryClientInterceptor(timeout))
+	}
+	
+	if f.config.GRPC.Client.EnableDeadlinePropagation {
+		hopBudget := time.Duration(f.config.GRPC.Client.DeadlineHopBudget) * time.Millisecond
+		unaryInterceptors = append(unaryInterceptors, interceptor.DeadlineUnaryClientInterceptor(hopBudget))
+		streamInterceptors = append(streamInterceptors, interceptor.DeadlineStreamClientInterceptor(hopBudget))
+	}
+	
 	if f.config.GRPC.Client.EnableLogging {
 		unaryInterceptors = append(unaryInterceptors, f.loggingUnaryInterceptor())
 		streamInterceptors = append(streamInterceptors, f.loggingStreamInterceptor())
@@ -199,20 +764,59 @@ func (f *ClientFactory) buildInterceptors() []grpc.DialOption {
 	return opts
 }
 
-// registerResolver 注册自定义解析器
-func (f *ClientFactory) registerResolver(serviceName string) {
+// callTimeout 返回服务一元调用的默认超时，为 0 时不设置
+func (f *ClientFactory) callTimeout(serviceName string) time.Duration {
+	if timeout, ok := f.serviceTimeouts[serviceName]; ok {
+		return timeout
+	}
+	return time.Duration(f.config.GRPC.Client.Timeout) * time.Second
+}
+
+// dialTarget 返回服务名对应的 gRPC 目标，direct 表示目标带有 scheme，由对应的 gRPC 解析器解析而不经过服务发现
+func (f *ClientFactory) dialTarget(serviceName string) (target string, direct bool) {
+	if hasResolverScheme(serviceName) {
+		return serviceName, true
+	}
+	if f.resolverScheme != "" {
+		return fmt.Sprintf("%s:///%s", f.resolverScheme, serviceName), true
+	}
+	return serviceName, false
+}
+
+// newResolverBuilder 创建服务发现解析器构建器
+func (f *ClientFactory) newResolverBuilder(serviceName string) *discoveryResolverBuilder {
 	builder := &discoveryResolverBuilder{
 		serviceName: serviceName,
 		registry:    f.registry,
 		logger:      f.logger,
+		ctx:         f.ctx,
 	}
-	resolver.Register(builder)
+	return builder
 }
 
-// Close 关闭所有客户端连接
+// ResolveNow 触发指定服务连接的立即重新解析
+// 用于拓扑变化后 watch 未能及时感知的场景
+func (f *ClientFactory) ResolveNow(serviceName string) error {
+	f.mu.RLock()
+	builder, exists := f.resolvers[serviceName]
+	f.mu.RUnlock()
+	
+	if !exists {
+		return fmt.Errorf("no discovery client connection for service %s", serviceName)
+	}
+	
+	f.logger.Info("Triggering re-resolution", zap.String("service", serviceName))
+	return builder.resolveNow()
+}
+
+// Close 关闭所有客户端连接并等待后台协程退出，可重复调用
 func (f *ClientFactory) Close() error {
 	f.mu.Lock()
-	defer f.mu.Unlock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
 	
 	for serviceName, conn := range f.clients {
 		if err := conn.Close(); err != nil {
@@ -222,7 +826,18 @@ func (f *ClientFactory) Close() error {
 		}
 	}
 	
+	builders := f.resolvers
 	f.clients = make(map[string]*grpc.ClientConn)
+	f.resolvers = make(map[string]*discoveryResolverBuilder)
+	f.usage = make(map[string]*connUsage)
+	f.mu.Unlock()
+	
+	// 通知并等待后台协程和解析器协程退出，等待时不持有锁
+	f.cancel()
+	f.wg.Wait()
+	for _, builder := range builders {
+		builder.wait()
+	}
 	return nil
 }
 
@@ -270,18 +885,59 @@ func (f *ClientFactory) loggingStreamInterceptor() grpc.StreamClientInterceptor
 	}
 }
 
-// metricsUnaryInterceptor 客户端一元调用指标拦截器
+// metricsUnaryInterceptor 客户端一元调用指标拦截器，调用方在调用完成前取消时记录取消次数
 func (f *ClientFactory) metricsUnaryInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		// TODO: 实现客户端指标收集
-		return invoker(ctx, method, req, reply, cc, opts...)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if callerCancelled(ctx, err) {
+			clientCancellations.WithLabelValues(method).Inc()
+		}
+		return err
 	}
 }
 
-// metricsStreamInterceptor 客户端流式调用指标拦截器
+// metricsStreamInterceptor 客户端流式调用指标拦截器，流在结束前被调用方取消时记录一次取消
 func (f *ClientFactory) metricsStreamInterceptor() grpc.StreamClientInterceptor {
 	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		// TODO: 实现客户端指标收集
-		return streamer(ctx, desc, cc, method, opts...)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			if callerCancelled(ctx, err) {
+				clientCancellations.WithLabelValues(method).Inc()
+			}
+			return nil, err
+		}
+		return &cancelCountingStream{ClientStream: stream, ctx: ctx, method: method}, nil
+	}
+}
+
+// callerCancelled 判断调用是否因调用方取消上下文而失败，超时不计入
+func callerCancelled(ctx context.Context, err error) bool {
+	return err != nil && errors.Is(ctx.Err(), context.Canceled)
+}
+
+// cancelCountingStream 在流因调用方取消而失败时记录一次取消的客户端流
+type cancelCountingStream struct {
+	grpc.ClientStream
+	ctx     context.Context
+	method  string
+	counted atomic.Bool
+}
+
+func (s *cancelCountingStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	s.observe(err)
+	return err
+}
+
+func (s *cancelCountingStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	s.observe(err)
+	return err
+}
+
+// observe 流因调用方取消而失败时记录取消，同一个流只记录一次
+func (s *cancelCountingStream) observe(err error) {
+	if err != io.EOF && callerCancelled(s.ctx, err) && s.counted.CompareAndSwap(false, true) {
+		clientCancellations.WithLabelValues(s.method).Inc()
 	}
 }
\ No newline at end of file
@@ -2,13 +2,29 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/goleak"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 // MockRegistry 模拟服务发现注册器
@@ -184,7 +200,7 @@ func TestBuildServiceConfig(t *testing.T) {
 	logger := zap.NewNop()
 
 	factory := NewClientFactory(cfg, registry, logger)
-	serviceConfig := factory.buildServiceConfig()
+	serviceConfig := factory.buildServiceConfig("test-service")
 
 	if serviceConfig == "" {
 		t.Error("Expected non-empty service config")
@@ -212,7 +228,7 @@ func TestBuildInterceptors(t *testing.T) {
 	logger := zap.NewNop()
 
 	factory := NewClientFactory(cfg, registry, logger)
-	opts := factory.buildInterceptors()
+	opts := factory.buildInterceptors("test-service")
 
 	if len(opts) == 0 {
 		t.Error("Expected non-empty interceptor options")
@@ -385,4 +401,1117 @@ func containsSubstring(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestBuildServiceConfigWeighted(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing: WeightedRoundRobinName,
+				LocalZone:     "zone-a",
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop())
+	serviceConfig := factory.buildServiceConfig("test-service")
+
+	if !contains(serviceConfig, `"loadBalancingConfig": [{"metadata_weighted_round_robin": {"localZone": "zone-a"}}]`) {
+		t.Errorf("Expected weighted load balancing config, got %s", serviceConfig)
+	}
+
+	// 慢启动窗口传给负载均衡器
+	cfg.GRPC.Client.SlowStartWindow = 30
+	serviceConfig = factory.buildServiceConfig("test-service")
+	if !contains(serviceConfig, `{"localZone": "zone-a", "slowStartWindow": 30}`) {
+		t.Errorf("Expected slow start window in load balancing config, got %s", serviceConfig)
+	}
+
+	// 可用区中的引号和反斜杠被转义，服务配置仍是合法的 JSON
+	cfg.GRPC.Client.LocalZone = `zone "a"\b`
+	serviceConfig = factory.buildServiceConfig("test-service")
+	var parsed struct {
+		LoadBalancingConfig []map[string]struct {
+			LocalZone string `json:"localZone"`
+		} `json:"loadBalancingConfig"`
+	}
+	if err := json.Unmarshal([]byte(serviceConfig), &parsed); err != nil {
+		t.Fatalf("Expected valid service config JSON, got %v: %s", err, serviceConfig)
+	}
+	if len(parsed.LoadBalancingConfig) != 1 || parsed.LoadBalancingConfig[0][WeightedRoundRobinName].LocalZone != cfg.GRPC.Client.LocalZone {
+		t.Errorf("Expected local zone %q to round-trip, got %s", cfg.GRPC.Client.LocalZone, serviceConfig)
+	}
+}
+
+func TestBuildServiceConfigMethodConfig(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing: "round_robin",
+				RetryPolicy: config.RetryPolicyConfig{
+					MaxAttempts:          3,
+					InitialBackoff:       "10ms",
+					MaxBackoff:           "1m",
+					BackoffMultiplier:    2.0,
+					RetryableStatusCodes: []string{"UNAVAILABLE"},
+				},
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop())
+	serviceConfig := factory.buildServiceConfig("test-service")
+
+	// 与 gRPC 服务配置结构一致
+	var parsed struct {
+		LoadBalancingPolicy string          `json:"loadBalancingPolicy"`
+		RetryPolicy         json.RawMessage `json:"retryPolicy"`
+		MethodConfig        []struct {
+			Name []struct {
+				Service string `json:"service"`
+				Method  string `json:"method"`
+			} `json:"name"`
+			RetryPolicy *struct {
+				MaxAttempts          int      `json:"maxAttempts"`
+				InitialBackoff       string   `json:"initialBackoff"`
+				MaxBackoff           string   `json:"maxBackoff"`
+				BackoffMultiplier    float64  `json:"backoffMultiplier"`
+				RetryableStatusCodes []string `json:"retryableStatusCodes"`
+			} `json:"retryPolicy"`
+		} `json:"methodConfig"`
+	}
+	if err := json.Unmarshal([]byte(serviceConfig), &parsed); err != nil {
+		t.Fatalf("Expected well-formed service config JSON, got %v: %s", err, serviceConfig)
+	}
+	if parsed.RetryPolicy != nil {
+		t.Errorf("Expected no top-level retryPolicy, got %s", parsed.RetryPolicy)
+	}
+	if len(parsed.MethodConfig) != 1 || parsed.MethodConfig[0].RetryPolicy == nil {
+		t.Fatalf("Expected retry policy under methodConfig, got %s", serviceConfig)
+	}
+	methodConfig := parsed.MethodConfig[0]
+	if len(methodConfig.Name) != 1 || methodConfig.Name[0].Service != "" || methodConfig.Name[0].Method != "" {
+		t.Errorf("Expected wildcard method name, got %+v", methodConfig.Name)
+	}
+	retryPolicy := methodConfig.RetryPolicy
+	if retryPolicy.MaxAttempts != 3 || retryPolicy.InitialBackoff != "0.01s" || retryPolicy.MaxBackoff != "60s" {
+		t.Errorf("Unexpected retry policy %+v", *retryPolicy)
+	}
+
+	// gRPC 按服务配置重试：第一次返回 UNAVAILABLE，重试后成功
+	var attempts atomic.Int32
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if attempts.Add(1) == 1 {
+			return nil, status.Error(codes.Unavailable, "try again")
+		}
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig))
+	if err != nil {
+		t.Fatalf("Expected gRPC to accept service config, got %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Expected call to succeed after retry, got %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+
+	// max_attempts 不大于 1 时不生成 methodConfig
+	cfg.GRPC.Client.RetryPolicy.MaxAttempts = 1
+	if serviceConfig := factory.buildServiceConfig("test-service"); contains(serviceConfig, "methodConfig") {
+		t.Errorf("Expected no methodConfig without retries, got %s", serviceConfig)
+	}
+}
+
+func TestBuildServiceConfigRetryThrottling(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing: "round_robin",
+				RetryPolicy: config.RetryPolicyConfig{
+					MaxAttempts:          3,
+					InitialBackoff:       "1s",
+					MaxBackoff:           "30s",
+					BackoffMultiplier:    2.0,
+					RetryableStatusCodes: []string{"UNAVAILABLE"},
+					ThrottleMaxTokens:    100,
+					ThrottleTokenRatio:   0.2,
+				},
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop())
+	serviceConfig := factory.buildServiceConfig("test-service")
+
+	var parsed struct {
+		RetryThrottling *struct {
+			MaxTokens  int     `json:"maxTokens"`
+			TokenRatio float64 `json:"tokenRatio"`
+		} `json:"retryThrottling"`
+	}
+	if err := json.Unmarshal([]byte(serviceConfig), &parsed); err != nil {
+		t.Fatalf("Expected well-formed service config JSON, got %v: %s", err, serviceConfig)
+	}
+	if parsed.RetryThrottling == nil {
+		t.Fatalf("Expected retryThrottling in service config, got %s", serviceConfig)
+	}
+	if parsed.RetryThrottling.MaxTokens != 100 || parsed.RetryThrottling.TokenRatio != 0.2 {
+		t.Errorf("Expected maxTokens 100 and tokenRatio 0.2, got %+v", *parsed.RetryThrottling)
+	}
+
+	// gRPC 能够解析生成的服务配置
+	conn, err := grpc.NewClient("passthrough:///localhost:0",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(serviceConfig))
+	if err != nil {
+		t.Fatalf("Expected gRPC to accept service config, got %v", err)
+	}
+	conn.Close()
+
+	// 未启用时不生成 retryThrottling
+	cfg.GRPC.Client.RetryPolicy.ThrottleMaxTokens = 0
+	serviceConfig = factory.buildServiceConfig("test-service")
+	if !json.Valid([]byte(serviceConfig)) {
+		t.Fatalf("Expected well-formed service config JSON, got %s", serviceConfig)
+	}
+	if contains(serviceConfig, "retryThrottling") {
+		t.Errorf("Expected no retryThrottling when disabled, got %s", serviceConfig)
+	}
+}
+
+func TestBuildServiceConfigHealthCheck(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing:    "round_robin",
+				UseServiceConfig: true,
+			},
+		},
+	}
+
+	healthServiceName := func(serviceConfig string) *string {
+		t.Helper()
+		var parsed struct {
+			HealthCheckConfig *struct {
+				ServiceName string `json:"serviceName"`
+			} `json:"healthCheckConfig"`
+		}
+		if err := json.Unmarshal([]byte(serviceConfig), &parsed); err != nil {
+			t.Fatalf("Expected well-formed service config JSON, got %v: %s", err, serviceConfig)
+		}
+		if parsed.HealthCheckConfig == nil {
+			return nil
+		}
+		return &parsed.HealthCheckConfig.ServiceName
+	}
+
+	// 未启用时不生成 healthCheckConfig
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop())
+	if name := healthServiceName(factory.buildServiceConfig("test-service")); name != nil {
+		t.Errorf("Expected no healthCheckConfig by default, got %q", *name)
+	}
+
+	factory = NewClientFactory(cfg, NewMockRegistry(), zap.NewNop(),
+		WithHealthCheck(""),
+		WithServiceHealthCheck("order-service", "order.OrderService"))
+	if name := healthServiceName(factory.buildServiceConfig("test-service")); name == nil || *name != "" {
+		t.Errorf("Expected default healthCheckConfig for overall status, got %v", name)
+	}
+	if name := healthServiceName(factory.buildServiceConfig("order-service")); name == nil || *name != "order.OrderService" {
+		t.Errorf("Expected per-service healthCheckConfig, got %v", name)
+	}
+}
+
+func TestHealthCheckRemovesUnhealthyBackend(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing:    "round_robin",
+				UseServiceConfig: true,
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop(), WithHealthCheck("test.Backend"))
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("test.Backend", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, healthSrv)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(factory.buildServiceConfig("test-service")))
+	if err != nil {
+		t.Fatalf("Expected gRPC to accept service config, got %v", err)
+	}
+	defer conn.Close()
+
+	check := func(timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+		return err
+	}
+
+	// 后端不健康时不会被选中
+	if err := check(200 * time.Millisecond); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected unhealthy backend to be skipped, got %v", err)
+	}
+
+	healthSrv.SetServingStatus("test.Backend", grpc_health_v1.HealthCheckResponse_SERVING)
+	if err := check(5 * time.Second); err != nil {
+		t.Fatalf("Expected call to succeed once backend is healthy, got %v", err)
+	}
+}
+
+func TestServiceConfigOptions(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing:    "round_robin",
+				UseServiceConfig: true,
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop())
+
+	if opts := factory.serviceConfigOptions("test-service"); len(opts) != 1 {
+		t.Errorf("Expected default service config option, got %d options", len(opts))
+	}
+
+	// 关闭后不设置默认服务配置
+	cfg.GRPC.Client.UseServiceConfig = false
+	if opts := factory.serviceConfigOptions("test-service"); len(opts) != 0 {
+		t.Errorf("Expected no service config option when disabled, got %d options", len(opts))
+	}
+}
+
+func TestFlowControlOptions(t *testing.T) {
+	cfg := &config.Config{}
+	factory := NewClientFactory(cfg, NewMockRegistry(), zap.NewNop())
+
+	// 默认使用 gRPC 默认值
+	if opts := factory.flowControlOptions(); len(opts) != 0 {
+		t.Errorf("Expected no flow control options by default, got %d", len(opts))
+	}
+
+	cfg.GRPC.Client.InitialWindowSize = 1 << 20
+	cfg.GRPC.Client.InitialConnWindowSize = 4 << 20
+	cfg.GRPC.Client.MaxHeaderListSize = 16 << 10
+	if opts := factory.flowControlOptions(); len(opts) != 3 {
+		t.Errorf("Expected 3 flow control options, got %d", len(opts))
+	}
+}
+
+func TestConnectionStateWatch(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       30,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	// 使用未监听的端口，连接会经历 CONNECTING -> TRANSIENT_FAILURE
+	registry.Register(context.Background(), &discovery.ServiceInfo{
+		Name:    "state-service",
+		Address: "127.0.0.1",
+		Port:    1,
+	})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop(), WithConnectionStateWatch(true))
+
+	failures := clientConnectionStateChanges.WithLabelValues("state-service", connectivity.TransientFailure.String())
+	before := testutil.ToFloat64(failures)
+
+	if _, err := factory.GetClient("state-service"); err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.ToFloat64(failures) <= before {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected TRANSIENT_FAILURE state change to be recorded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Close 应等待监听协程退出
+	done := make(chan struct{})
+	go func() {
+		factory.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Close to return after state watchers exit")
+	}
+
+	shutdowns := clientConnectionStateChanges.WithLabelValues("state-service", connectivity.Shutdown.String())
+	if testutil.ToFloat64(shutdowns) < 1 {
+		t.Error("Expected SHUTDOWN state change to be recorded")
+	}
+}
+
+func TestCloseClient(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       30,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	registry.Register(context.Background(), &discovery.ServiceInfo{Name: "service-a", Address: "localhost", Port: 9090})
+	registry.Register(context.Background(), &discovery.ServiceInfo{Name: "service-b", Address: "localhost", Port: 9091})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	conn1, err := factory.GetClient("service-a")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if _, err := factory.GetClient("service-b"); err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+
+	if err := factory.CloseClient("service-a"); err != nil {
+		t.Fatalf("Failed to close client: %v", err)
+	}
+
+	if len(factory.clients) != 1 {
+		t.Errorf("Expected 1 cached client after CloseClient, got %d", len(factory.clients))
+	}
+	if _, exists := factory.clients["service-b"]; !exists {
+		t.Error("Expected service-b connection to remain cached")
+	}
+	if conn1.GetState() != connectivity.Shutdown {
+		t.Errorf("Expected closed connection to be SHUTDOWN, got %s", conn1.GetState())
+	}
+
+	// 再次获取应创建新连接
+	conn2, err := factory.GetClient("service-a")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if conn1 == conn2 {
+		t.Error("Expected a fresh connection after CloseClient")
+	}
+
+	if err := factory.CloseClient("unknown-service"); err == nil {
+		t.Error("Expected error when closing unknown service")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       30,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	registry.Register(context.Background(), &discovery.ServiceInfo{Name: "test-service", Address: "localhost", Port: 9090})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	conn1, err := factory.GetClient("test-service")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+
+	conn2, err := factory.Reconnect("test-service")
+	if err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+
+	if conn1 == conn2 {
+		t.Error("Expected a fresh connection after Reconnect")
+	}
+	if conn1.GetState() != connectivity.Shutdown {
+		t.Errorf("Expected old connection to be SHUTDOWN, got %s", conn1.GetState())
+	}
+
+	conn3, err := factory.GetClient("test-service")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if conn3 != conn2 {
+		t.Error("Expected GetClient to return the reconnected connection")
+	}
+}
+
+// concurrencyRegistry 记录同时进行的服务发现调用数量
+type concurrencyRegistry struct {
+	*MockRegistry
+	seen    sync.Map
+	current atomic.Int32
+	max     atomic.Int32
+}
+
+func (r *concurrencyRegistry) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	// 只统计建立连接时的首次发现，解析器后续的重新解析直接返回
+	if _, loaded := r.seen.LoadOrStore(serviceName, struct{}{}); loaded {
+		return r.MockRegistry.Discover(ctx, serviceName)
+	}
+
+	current := r.current.Add(1)
+	defer r.current.Add(-1)
+	for {
+		max := r.max.Load()
+		if current <= max || r.max.CompareAndSwap(max, current) {
+			break
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	return r.MockRegistry.Discover(ctx, serviceName)
+}
+
+func TestWarmUp(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:           30,
+				LoadBalancing:     "round_robin",
+				WarmUpConcurrency: 3,
+			},
+		},
+	}
+	registry := &concurrencyRegistry{MockRegistry: NewMockRegistry()}
+
+	var services []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("service-%d", i)
+		services = append(services, name)
+		// service-0 未注册，预热时应失败
+		if i > 0 {
+			registry.Register(context.Background(), &discovery.ServiceInfo{Name: name, Address: "localhost", Port: 9090 + i})
+		}
+	}
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	err := factory.WarmUp(context.Background(), services)
+	if err == nil {
+		t.Fatal("Expected error for unregistered service")
+	}
+	if !strings.Contains(err.Error(), "service-0") {
+		t.Errorf("Expected error to mention service-0, got %v", err)
+	}
+
+	if max := registry.max.Load(); max > 3 {
+		t.Errorf("Expected at most 3 concurrent discovery calls, got %d", max)
+	}
+
+	if len(factory.clients) != 9 {
+		t.Errorf("Expected 9 warmed up clients, got %d", len(factory.clients))
+	}
+	if _, exists := factory.clients["service-0"]; exists {
+		t.Error("Expected failed service not to be cached")
+	}
+}
+
+func TestPrewarm(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       5,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	registry.Register(context.Background(), &discovery.ServiceInfo{
+		Name:    "ready-service",
+		Address: "127.0.0.1",
+		Port:    lis.Addr().(*net.TCPAddr).Port,
+	})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// 未注册的服务失败不影响其他服务
+	err = factory.Prewarm(ctx, "ready-service", "missing-service")
+	if err == nil || !strings.Contains(err.Error(), "missing-service") {
+		t.Errorf("Expected error mentioning missing-service, got %v", err)
+	}
+
+	factory.mu.RLock()
+	prewarmed, exists := factory.clients["ready-service"]
+	factory.mu.RUnlock()
+	if !exists {
+		t.Fatal("Expected prewarmed connection to be cached")
+	}
+	if state := prewarmed.GetState(); state != connectivity.Ready {
+		t.Errorf("Expected prewarmed connection to be READY, got %s", state)
+	}
+
+	conn, err := factory.GetClient("ready-service")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if conn != prewarmed {
+		t.Error("Expected GetClient to return the prewarmed connection")
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	dial := func() *grpc.ClientConn {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		return conn
+	}
+
+	// 空闲连接由 waitForReady 主动拨号
+	conn := dial()
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := waitForReady(ctx, conn); err != nil {
+		t.Fatalf("Expected idle connection to become ready, got %v", err)
+	}
+
+	// 已关闭的连接立即返回错误，不等待 ctx 结束
+	closed := dial()
+	closed.Close()
+	start := time.Now()
+	if err := waitForReady(ctx, closed); err == nil {
+		t.Error("Expected error for closed connection")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected closed connection to fail fast, took %s", elapsed)
+	}
+}
+
+func TestGetClientBlockOnConnect(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:        5,
+				LoadBalancing:  "round_robin",
+				BlockOnConnect: true,
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	registry.Register(context.Background(), &discovery.ServiceInfo{
+		Name:    "ready-service",
+		Address: "127.0.0.1",
+		Port:    lis.Addr().(*net.TCPAddr).Port,
+	})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	conn, err := factory.GetClient("ready-service")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Errorf("Expected connection to be READY, got %s", state)
+	}
+}
+
+func TestGetClientBlockOnConnectTimeout(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:        1,
+				LoadBalancing:  "round_robin",
+				BlockOnConnect: true,
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	// 使用未监听的端口，连接无法就绪
+	registry.Register(context.Background(), &discovery.ServiceInfo{
+		Name:    "down-service",
+		Address: "127.0.0.1",
+		Port:    1,
+	})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	start := time.Now()
+	if _, err := factory.GetClient("down-service"); err == nil {
+		t.Fatal("Expected error when backend is down")
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("Expected GetClient to block until timeout, returned after %s", elapsed)
+	}
+
+	if _, exists := factory.clients["down-service"]; exists {
+		t.Error("Expected failed connection not to be cached")
+	}
+}
+
+func TestGetClientNonBlocking(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       30,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	registry.Register(context.Background(), &discovery.ServiceInfo{
+		Name:    "down-service",
+		Address: "127.0.0.1",
+		Port:    1,
+	})
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	// 非阻塞模式下即使后端不可用也立即返回连接
+	start := time.Now()
+	conn, err := factory.GetClient("down-service")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected GetClient to return immediately, took %s", elapsed)
+	}
+	if conn.GetState() == connectivity.Ready {
+		t.Error("Expected connection to a down backend not to be READY")
+	}
+}
+
+func TestCloseStopsBackgroundGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       30,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := NewMockRegistry()
+	for i := 0; i < 3; i++ {
+		registry.Register(context.Background(), &discovery.ServiceInfo{
+			Name:    fmt.Sprintf("service-%d", i),
+			Address: "127.0.0.1",
+			Port:    1,
+		})
+	}
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop(), WithConnectionStateWatch(true))
+	for i := 0; i < 3; i++ {
+		if _, err := factory.GetClient(fmt.Sprintf("service-%d", i)); err != nil {
+			t.Fatalf("Failed to get client: %v", err)
+		}
+	}
+
+	if err := factory.Close(); err != nil {
+		t.Fatalf("Failed to close factory: %v", err)
+	}
+	// 重复关闭应直接返回
+	if err := factory.Close(); err != nil {
+		t.Errorf("Expected second Close to succeed, got %v", err)
+	}
+
+	if _, err := factory.GetClient("service-0"); err == nil {
+		t.Error("Expected GetClient to fail after Close")
+	}
+}
+
+// recordingStatsHandler 记录 HandleRPC 收到的事件
+type recordingStatsHandler struct {
+	mu     sync.Mutex
+	events []stats.RPCStats
+}
+
+func (h *recordingStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *recordingStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, s)
+}
+
+func (h *recordingStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *recordingStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+// ended 检查是否收到了调用结束事件
+func (h *recordingStatsHandler) ended() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, event := range h.events {
+		if _, ok := event.(*stats.End); ok && event.IsClient() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithStatsHandler(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       5,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	handler := &recordingStatsHandler{}
+	factory := NewClientFactory(cfg, nil, zap.NewNop(), WithStatsHandler(handler))
+	defer factory.Close()
+
+	conn, err := factory.GetClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	if !handler.ended() {
+		t.Error("Expected stats handler to receive the RPC end event")
+	}
+}
+
+func TestIdleEviction(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:             5,
+				LoadBalancing:       "round_robin",
+				IdleEvictionTimeout: 60,
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, nil, zap.NewNop())
+	defer factory.Close()
+	now := time.Now()
+	factory.now = func() time.Time { return now }
+
+	port := lis.Addr().(*net.TCPAddr).Port
+	called := fmt.Sprintf("127.0.0.1:%d", port)
+	unused := fmt.Sprintf("localhost:%d", port)
+	conn, err := factory.GetClient(called)
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	idleConn, err := factory.GetClient(unused)
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+
+	check := func(conn *grpc.ClientConn) {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+	}
+
+	// 调用方持有连接发起的调用同样计为使用
+	now = now.Add(30 * time.Second)
+	check(conn)
+	now = now.Add(40 * time.Second)
+	factory.evictIdle()
+	if idleConn.GetState() != connectivity.Shutdown {
+		t.Errorf("Expected idle connection to be closed, got %s", idleConn.GetState())
+	}
+	if conn.GetState() == connectivity.Shutdown {
+		t.Error("Expected recently used connection to stay open")
+	}
+
+	now = now.Add(60 * time.Second)
+	factory.evictIdle()
+	if conn.GetState() != connectivity.Shutdown {
+		t.Errorf("Expected connection to be closed after idle timeout, got %s", conn.GetState())
+	}
+	if len(factory.clients) != 0 {
+		t.Errorf("Expected no cached clients after eviction, got %d", len(factory.clients))
+	}
+
+	// 回收后再次获取时重新创建连接
+	newConn, err := factory.GetClient(called)
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if newConn == conn {
+		t.Error("Expected a fresh connection after eviction")
+	}
+	check(newConn)
+}
+
+func TestIdleEvictionKeepsOpenStreams(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:             5,
+				LoadBalancing:       "round_robin",
+				IdleEvictionTimeout: 60,
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, nil, zap.NewNop())
+	defer factory.Close()
+	var now atomic.Int64
+	now.Store(time.Now().UnixNano())
+	factory.now = func() time.Time { return time.Unix(0, now.Load()) }
+	advance := func(d time.Duration) { now.Add(int64(d)) }
+
+	target := lis.Addr().String()
+	conn, err := factory.GetClient(target)
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+
+	// 服务端流式调用在空闲时间之后仍未结束
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Failed to receive from stream: %v", err)
+	}
+
+	advance(2 * time.Minute)
+	factory.evictIdle()
+	if conn.GetState() == connectivity.Shutdown {
+		t.Fatal("Expected connection with an open stream not to be evicted")
+	}
+	if _, exists := factory.clients[target]; !exists {
+		t.Error("Expected connection with an open stream to stay cached")
+	}
+
+	// 流随 ctx 结束后连接重新计时，超过空闲时间后被回收
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for factory.usage[target].active.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected stream to release the connection after ctx is cancelled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	advance(2 * time.Minute)
+	factory.evictIdle()
+	if conn.GetState() != connectivity.Shutdown {
+		t.Errorf("Expected connection to be evicted after the stream ended, got %s", conn.GetState())
+	}
+}
+
+func TestDefaultCallTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	// 处理器一直阻塞，直到调用方放弃
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	port := lis.Addr().(*net.TCPAddr).Port
+	defaultTarget := fmt.Sprintf("127.0.0.1:%d", port)
+	overrideTarget := fmt.Sprintf("localhost:%d", port)
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       1,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, nil, zap.NewNop(), WithServiceTimeout(overrideTarget, 200*time.Millisecond))
+	defer factory.Close()
+
+	call := func(target string) time.Duration {
+		t.Helper()
+		conn, err := factory.GetClient(target)
+		if err != nil {
+			t.Fatalf("Failed to get client: %v", err)
+		}
+		start := time.Now()
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if status.Code(err) != codes.DeadlineExceeded {
+			t.Fatalf("Expected DeadlineExceeded for %s, got %v", target, err)
+		}
+		return time.Since(start)
+	}
+
+	if elapsed := call(defaultTarget); elapsed < time.Second || elapsed > 3*time.Second {
+		t.Errorf("Expected call to time out after about 1s, took %v", elapsed)
+	}
+	if elapsed := call(overrideTarget); elapsed < 200*time.Millisecond || elapsed > 900*time.Millisecond {
+		t.Errorf("Expected call to time out after the 200ms service override, took %v", elapsed)
+	}
+}
+
+func TestClientCancellationMetric(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	// 一元调用一直阻塞，直到调用方放弃
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				EnableMetrics: true,
+			},
+		},
+	}
+	factory := NewClientFactory(cfg, nil, zap.NewNop())
+	defer factory.Close()
+	conn, err := factory.GetClient(lis.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	const checkMethod = "/grpc.health.v1.Health/Check"
+	const watchMethod = "/grpc.health.v1.Health/Watch"
+	checkBefore := testutil.ToFloat64(clientCancellations.WithLabelValues(checkMethod))
+	watchBefore := testutil.ToFloat64(clientCancellations.WithLabelValues(watchMethod))
+
+	// 调用进行中取消
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.Canceled {
+		t.Fatalf("Expected Canceled, got %v", err)
+	}
+	if got := testutil.ToFloat64(clientCancellations.WithLabelValues(checkMethod)) - checkBefore; got != 1 {
+		t.Errorf("Expected 1 cancelled call, got %v", got)
+	}
+
+	// 超时不计为取消
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected DeadlineExceeded, got %v", err)
+	}
+	if got := testutil.ToFloat64(clientCancellations.WithLabelValues(checkMethod)) - checkBefore; got != 1 {
+		t.Errorf("Expected timeout not to be counted as cancellation, got %v", got)
+	}
+
+	// 流在接收过程中取消，只记录一次
+	ctx, cancel = context.WithCancel(context.Background())
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Failed to receive initial status: %v", err)
+	}
+	cancel()
+	for i := 0; i < 2; i++ {
+		if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+			t.Fatalf("Expected Canceled, got %v", err)
+		}
+	}
+	if got := testutil.ToFloat64(clientCancellations.WithLabelValues(watchMethod)) - watchBefore; got != 1 {
+		t.Errorf("Expected 1 cancelled stream, got %v", got)
+	}
+}
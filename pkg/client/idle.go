@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// minIdleSweepInterval 空闲连接检查的最小间隔
+const minIdleSweepInterval = time.Second
+
+// connUsage 缓存连接的使用情况
+type connUsage struct {
+	// lastUsed 最近一次 GetClient 或调用开始、结束的时间（Unix 纳秒）
+	lastUsed atomic.Int64
+	// active 正在进行的调用数，包括尚未结束的流
+	active atomic.Int64
+}
+
+// touch 记录连接在 now 被使用
+func (u *connUsage) touch(now time.Time) {
+	u.lastUsed.Store(now.UnixNano())
+}
+
+// idleSince 返回连接最近一次被使用的时间
+func (u *connUsage) idleSince() time.Time {
+	return time.Unix(0, u.lastUsed.Load())
+}
+
+// idleTimeout 返回空闲连接的回收时间，为 0 时不回收
+func (f *ClientFactory) idleTimeout() time.Duration {
+	return time.Duration(f.config.GRPC.Client.IdleEvictionTimeout) * time.Second
+}
+
+// usageInterceptors 返回记录连接使用时间的拦截器，调用方持有连接时仍按调用判断是否空闲
+func (f *ClientFactory) usageInterceptors(usage *connUsage) []grpc.DialOption {
+	unary := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		usage.active.Add(1)
+		usage.touch(f.now())
+		defer func() {
+			usage.touch(f.now())
+			usage.active.Add(-1)
+		}()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		usage.active.Add(1)
+		usage.touch(f.now())
+		s, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			usage.touch(f.now())
+			usage.active.Add(-1)
+			return nil, err
+		}
+
+		// 流结束前连接保持使用中，避免长期存在的流被当作空闲连接关闭
+		us := &usageStream{ClientStream: s, desc: desc}
+		us.release = func() {
+			usage.touch(f.now())
+			usage.active.Add(-1)
+		}
+		us.stop = context.AfterFunc(ctx, func() { us.once.Do(us.release) })
+		return us, nil
+	}
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(unary),
+		grpc.WithChainStreamInterceptor(stream),
+	}
+}
+
+// usageStream 在流结束时释放连接的使用计数
+// RecvMsg 返回错误（包括 io.EOF）、仅客户端流式的调用收到响应或关闭发送、或 ctx 结束时视为流结束，只释放一次
+type usageStream struct {
+	grpc.ClientStream
+	desc    *grpc.StreamDesc
+	release func()
+	stop    func() bool
+	once    sync.Once
+}
+
+func (s *usageStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.desc.ServerStreams {
+		s.finish()
+	}
+	return err
+}
+
+func (s *usageStream) CloseSend() error {
+	err := s.ClientStream.CloseSend()
+	if !s.desc.ServerStreams {
+		s.finish()
+	}
+	return err
+}
+
+// finish 释放流占用的使用计数并停止监听 ctx
+func (s *usageStream) finish() {
+	s.once.Do(s.release)
+	s.stop()
+}
+
+// sweepIdle 定期关闭空闲连接，工厂关闭后退出
+func (f *ClientFactory) sweepIdle(ctx context.Context) {
+	interval := f.idleTimeout() / 2
+	if interval < minIdleSweepInterval {
+		interval = minIdleSweepInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.evictIdle()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// evictIdle 关闭并移除超过空闲时间未被使用且没有进行中调用的连接，下次 GetClient 时重新创建
+func (f *ClientFactory) evictIdle() {
+	timeout := f.idleTimeout()
+	if timeout <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	for serviceName, usage := range f.usage {
+		idle := now.Sub(usage.idleSince())
+		if idle < timeout || usage.active.Load() > 0 {
+			continue
+		}
+		if err := f.closeClientLocked(serviceName); err != nil {
+			f.logger.Warn("Failed to close idle client connection",
+				zap.String("service", serviceName),
+				zap.Error(err))
+			continue
+		}
+		clientIdleEvictions.WithLabelValues(serviceName).Inc()
+		f.logger.Info("Evicted idle gRPC client connection",
+			zap.String("service", serviceName),
+			zap.Duration("idle", idle))
+	}
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// 客户端连接状态变化次数
+	clientConnectionStateChanges = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_client_connection_state_changes_total",
+			Help: "Total number of gRPC client connection state changes",
+		},
+		[]string{"service", "state"},
+	)
+
+	// 因空闲被回收的客户端连接数
+	clientIdleEvictions = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_client_idle_evictions_total",
+			Help: "Total number of gRPC client connections closed after being idle",
+		},
+		[]string{"service"},
+	)
+
+	// 调用完成前被调用方取消的客户端调用次数，不含超时
+	clientCancellations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_client_cancellations_total",
+			Help: "Total number of gRPC client calls cancelled by the caller before completion",
+		},
+		[]string{"method"},
+	)
+)
@@ -3,7 +3,11 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"sync"
+	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/resolver"
@@ -14,24 +18,64 @@ type discoveryResolverBuilder struct {
 	serviceName string
 	registry    discovery.Registry
 	logger      *zap.Logger
+	// ctx 工厂的上下文，工厂关闭时解析器随之停止
+	ctx context.Context
+	
+	mu       sync.Mutex
+	resolver *discoveryResolver
 }
 
 // Build 构建解析器
 func (b *discoveryResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	parent := b.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
 	r := &discoveryResolver{
 		serviceName: b.serviceName,
 		registry:    b.registry,
 		logger:      b.logger,
 		cc:          cc,
-		ctx:         context.Background(),
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 	
+	b.mu.Lock()
+	b.resolver = r
+	b.mu.Unlock()
+	
 	// 启动解析器
-	go r.start()
+	r.goTracked(r.start)
 	
 	return r, nil
 }
 
+// wait 停止已构建的解析器并等待其协程退出
+func (b *discoveryResolverBuilder) wait() {
+	b.mu.Lock()
+	r := b.resolver
+	b.mu.Unlock()
+	
+	if r != nil {
+		r.wait()
+	}
+}
+
+// resolveNow 触发已构建解析器的立即解析
+func (b *discoveryResolverBuilder) resolveNow() error {
+	b.mu.Lock()
+	r := b.resolver
+	b.mu.Unlock()
+	
+	if r == nil {
+		return fmt.Errorf("resolver for service %s not built yet", b.serviceName)
+	}
+	
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	return nil
+}
+
 // Scheme 返回解析器方案
 func (b *discoveryResolverBuilder) Scheme() string {
 	return "discovery"
@@ -45,19 +89,90 @@ type discoveryResolver struct {
 	cc          resolver.ClientConn
 	ctx         context.Context
 	cancel      context.CancelFunc
+	
+	// mu 保证解析器关闭后不再启动新的协程
+	mu sync.Mutex
+	wg sync.WaitGroup
+	
+	// addedAt 记录上次解析的地址及其被发现的时间，首次解析的地址时间为零值
+	addedMu  sync.Mutex
+	addedAt  map[string]time.Time
+	resolved bool
+	
+	// 监听失败、意外结束或 panic 后重新建立监听的退避策略，零值使用默认值
+	watchBackoff discovery.RegistrationBackoff
 }
 
-// start 启动解析器
+// goTracked 启动受 wait 等待的协程，解析器关闭后不再启动
+func (r *discoveryResolver) goTracked(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	if r.ctx.Err() != nil {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		safe.Run(r.logger, "Resolver goroutine panicked", fn, zap.String("service", r.serviceName))
+	}()
+}
+
+// wait 关闭解析器并等待所有协程退出
+// Close 在 gRPC 内部的串行队列中调用，不宜阻塞，因此由工厂在连接关闭后调用 wait
+func (r *discoveryResolver) wait() {
+	r.Close()
+	
+	r.mu.Lock()
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// start 启动解析器，监听失败、意外结束或处理更新时 panic 后按退避重新建立监听，直到解析器关闭
 func (r *discoveryResolver) start() {
-	r.ctx, r.cancel = context.WithCancel(context.Background())
+	attempt := 0
+	for {
+		var received bool
+		panicked := safe.Run(r.logger, "Service watch panicked", func() {
+			received = r.watch()
+		}, zap.String("service", r.serviceName))
+		if r.ctx.Err() != nil {
+			return
+		}
+		
+		// 正常收到过更新的监听结束后从最短间隔开始重试
+		if received && !panicked {
+			attempt = 0
+		}
+		attempt++
+		delay := r.watchBackoff.Next(attempt)
+		r.logger.Warn("Re-establishing service watch",
+			zap.String("service", r.serviceName),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay))
+		
+		timer := time.NewTimer(delay)
+		select {
+		case <-r.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// watch 监听服务变化并更新地址，直到监听通道关闭或解析器关闭，返回是否收到过服务列表
+func (r *discoveryResolver) watch() (received bool) {
+	// 每次监听使用独立的上下文，重新建立前释放上一次的监听
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
 	
-	// 监听服务变化
-	ch, err := r.registry.Watch(r.ctx, r.serviceName)
+	ch, err := r.registry.Watch(ctx, r.serviceName)
 	if err != nil {
 		r.logger.Error("Failed to watch services", 
 			zap.String("service", r.serviceName),
 			zap.Error(err))
-		return
+		return false
 	}
 	
 	for {
@@ -66,29 +181,51 @@ func (r *discoveryResolver) start() {
 			if !ok {
 				r.logger.Info("Service watch channel closed",
 					zap.String("service", r.serviceName))
-				return
+				return received
 			}
 			
+			received = true
 			r.updateAddresses(services)
 			
 		case <-r.ctx.Done():
-			return
+			return received
 		}
 	}
 }
 
 // updateAddresses 更新地址列表
 func (r *discoveryResolver) updateAddresses(services []*discovery.ServiceInfo) {
+	// 解析器关闭后不再推送地址
+	if r.ctx != nil && r.ctx.Err() != nil {
+		return
+	}
+	
 	var addrs []resolver.Address
 	
 	for _, service := range services {
+		// 跳过上报为不可用的实例
+		if !service.IsServing() {
+			r.logger.Debug("Skipping non-serving instance",
+				zap.String("service", r.serviceName),
+				zap.String("address", service.Address),
+				zap.Int("port", service.Port),
+				zap.String("status", service.Metadata[discovery.MetadataStatus]))
+			continue
+		}
+		
 		addr := resolver.Address{
 			Addr: fmt.Sprintf("%s:%d", service.Address, service.Port),
 		}
 		
+		// 附加权重和可用区信息，供加权负载均衡使用
+		addr = withEndpointMetadata(addr, service.Metadata)
+		
 		addrs = append(addrs, addr)
 	}
 	
+	// 附加被发现的时间，供负载均衡慢启动使用
+	addrs = r.markAdded(addrs)
+	
 	state := resolver.State{
 		Addresses: addrs,
 	}
@@ -104,10 +241,33 @@ func (r *discoveryResolver) updateAddresses(services []*discovery.ServiceInfo) {
 	}
 }
 
+// markAdded 为首次解析之后新出现的地址附加被发现的时间
+// 已知地址保留原来的时间，消失后再次出现的地址重新计时
+func (r *discoveryResolver) markAdded(addrs []resolver.Address) []resolver.Address {
+	r.addedMu.Lock()
+	defer r.addedMu.Unlock()
+	
+	now := time.Now()
+	current := make(map[string]time.Time, len(addrs))
+	for i, addr := range addrs {
+		addedAt, known := r.addedAt[addr.Addr]
+		if !known && r.resolved {
+			addedAt = now
+		}
+		current[addr.Addr] = addedAt
+		if !addedAt.IsZero() {
+			addrs[i] = withAddedAt(addr, addedAt)
+		}
+	}
+	r.addedAt = current
+	r.resolved = true
+	return addrs
+}
+
 // ResolveNow 立即解析
 func (r *discoveryResolver) ResolveNow(opts resolver.ResolveNowOptions) {
 	// 触发立即解析
-	go func() {
+	r.goTracked(func() {
 		services, err := r.registry.Discover(r.ctx, r.serviceName)
 		if err != nil {
 			r.logger.Error("Failed to discover services",
@@ -117,7 +277,7 @@ func (r *discoveryResolver) ResolveNow(opts resolver.ResolveNowOptions) {
 		}
 		
 		r.updateAddresses(services)
-	}()
+	})
 }
 
 // Close 关闭解析器
@@ -125,4 +285,28 @@ func (r *discoveryResolver) Close() {
 	if r.cancel != nil {
 		r.cancel()
 	}
-}
\ No newline at end of file
+}
+// hasResolverScheme 判断目标是否带有已注册解析器的 scheme，如 dns:///host:port、unix:///path
+// 与 gRPC 的规则一致，scheme 未注册的目标（如 host:port）视为不带 scheme；xds 目标即使未注册解析器也视为带 scheme，以便提示导入
+func hasResolverScheme(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	return u.Scheme == xdsScheme || resolver.Get(u.Scheme) != nil
+}
+
+// checkResolverSupport 检查目标 scheme 对应的解析器是否已注册，xds 目标同时检查 bootstrap 配置
+func checkResolverSupport(target string) error {
+	if isXDSTarget(target) {
+		return checkXDSSupport()
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid target %q: %w", target, err)
+	}
+	if resolver.Get(u.Scheme) == nil {
+		return fmt.Errorf("resolver for scheme %q is not registered", u.Scheme)
+	}
+	return nil
+}
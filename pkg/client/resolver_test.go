@@ -0,0 +1,255 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/resolver"
+)
+
+// mockClientConn 记录解析结果的模拟 resolver.ClientConn
+type mockClientConn struct {
+	resolver.ClientConn
+	states []resolver.State
+}
+
+func (cc *mockClientConn) UpdateState(state resolver.State) error {
+	cc.states = append(cc.states, state)
+	return nil
+}
+
+func (cc *mockClientConn) ReportError(err error) {}
+
+func TestUpdateAddressesWithWeight(t *testing.T) {
+	cc := &mockClientConn{}
+	r := &discoveryResolver{
+		serviceName: "test-service",
+		logger:      zap.NewNop(),
+		cc:          cc,
+	}
+
+	r.updateAddresses([]*discovery.ServiceInfo{
+		{Name: "test-service", Address: "10.0.0.1", Port: 9090, Metadata: map[string]string{"weight": "5", "zone": "zone-a"}},
+		{Name: "test-service", Address: "10.0.0.2", Port: 9090, Metadata: map[string]string{"weight": "invalid"}},
+		{Name: "test-service", Address: "10.0.0.3", Port: 9090},
+	})
+
+	if len(cc.states) != 1 {
+		t.Fatalf("Expected 1 state update, got %d", len(cc.states))
+	}
+
+	addrs := cc.states[0].Addresses
+	if len(addrs) != 3 {
+		t.Fatalf("Expected 3 addresses, got %d", len(addrs))
+	}
+
+	if weight := WeightFromAddress(addrs[0]); weight != 5 {
+		t.Errorf("Expected weight 5, got %d", weight)
+	}
+	if zone := ZoneFromAddress(addrs[0]); zone != "zone-a" {
+		t.Errorf("Expected zone 'zone-a', got '%s'", zone)
+	}
+	if weight := WeightFromAddress(addrs[1]); weight != 1 {
+		t.Errorf("Expected default weight 1 for invalid value, got %d", weight)
+	}
+	if weight := WeightFromAddress(addrs[2]); weight != 1 {
+		t.Errorf("Expected default weight 1 without metadata, got %d", weight)
+	}
+	if zone := ZoneFromAddress(addrs[2]); zone != "" {
+		t.Errorf("Expected empty zone, got '%s'", zone)
+	}
+}
+
+func TestUpdateAddressesFiltersNonServing(t *testing.T) {
+	cc := &mockClientConn{}
+	r := &discoveryResolver{
+		serviceName: "test-service",
+		logger:      zap.NewNop(),
+		cc:          cc,
+	}
+
+	r.updateAddresses([]*discovery.ServiceInfo{
+		{Name: "test-service", Address: "10.0.0.1", Port: 9090, Metadata: map[string]string{discovery.MetadataStatus: discovery.StatusServing}},
+		{Name: "test-service", Address: "10.0.0.2", Port: 9090, Metadata: map[string]string{discovery.MetadataStatus: discovery.StatusNotServing}},
+	})
+
+	if len(cc.states) != 1 {
+		t.Fatalf("Expected 1 state update, got %d", len(cc.states))
+	}
+
+	addrs := cc.states[0].Addresses
+	if len(addrs) != 1 {
+		t.Fatalf("Expected 1 address after filtering, got %d", len(addrs))
+	}
+	if addrs[0].Addr != "10.0.0.1:9090" {
+		t.Errorf("Expected healthy instance 10.0.0.1:9090, got %s", addrs[0].Addr)
+	}
+}
+
+func TestUpdateAddressesMarksAdded(t *testing.T) {
+	cc := &mockClientConn{}
+	r := &discoveryResolver{
+		serviceName: "test-service",
+		logger:      zap.NewNop(),
+		cc:          cc,
+	}
+	first := &discovery.ServiceInfo{Name: "test-service", Address: "10.0.0.1", Port: 9090}
+	second := &discovery.ServiceInfo{Name: "test-service", Address: "10.0.0.2", Port: 9090}
+
+	// 首次解析的地址不参与慢启动
+	r.updateAddresses([]*discovery.ServiceInfo{first})
+	if addedAt := addedAtFromAddress(cc.states[0].Addresses[0]); !addedAt.IsZero() {
+		t.Errorf("Expected no added time for initial address, got %v", addedAt)
+	}
+
+	// 之后新出现的地址记录被发现的时间，再次解析时保持不变
+	r.updateAddresses([]*discovery.ServiceInfo{first, second})
+	addedAt := addedAtFromAddress(cc.states[1].Addresses[1])
+	if addedAt.IsZero() || !addedAtFromAddress(cc.states[1].Addresses[0]).IsZero() {
+		t.Fatalf("Expected added time only for new address, got %v", cc.states[1].Addresses)
+	}
+	r.updateAddresses([]*discovery.ServiceInfo{first, second})
+	if got := addedAtFromAddress(cc.states[2].Addresses[1]); !got.Equal(addedAt) {
+		t.Errorf("Expected added time %v to be kept, got %v", addedAt, got)
+	}
+
+	// 消失后再次出现的地址重新计时
+	r.updateAddresses([]*discovery.ServiceInfo{second})
+	r.updateAddresses([]*discovery.ServiceInfo{first, second})
+	if addedAtFromAddress(cc.states[4].Addresses[0]).IsZero() {
+		t.Error("Expected re-added address to start slow start again")
+	}
+}
+
+// blockingWatchRegistry Watch 通道保持打开直到 ctx 取消的注册器
+type blockingWatchRegistry struct {
+	*MockRegistry
+}
+
+func (r *blockingWatchRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*discovery.ServiceInfo, error) {
+	ch := make(chan []*discovery.ServiceInfo, 1)
+	ch <- r.services[serviceName]
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func TestResolverGoroutinesStopOnClose(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				Timeout:       30,
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := &blockingWatchRegistry{MockRegistry: NewMockRegistry()}
+	for i := 0; i < 5; i++ {
+		registry.Register(context.Background(), &discovery.ServiceInfo{
+			Name:    fmt.Sprintf("service-%d", i),
+			Address: "127.0.0.1",
+			Port:    1,
+		})
+	}
+
+	baseline := runtime.NumGoroutine()
+
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	for i := 0; i < 5; i++ {
+		serviceName := fmt.Sprintf("service-%d", i)
+		if _, err := factory.GetClient(serviceName); err != nil {
+			t.Fatalf("Failed to get client: %v", err)
+		}
+		// 等待解析器构建后触发一次立即解析
+		deadline := time.Now().Add(time.Second)
+		for factory.ResolveNow(serviceName) != nil && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// 单独关闭的连接也应停止其解析器协程
+	if err := factory.CloseClient("service-0"); err != nil {
+		t.Fatalf("Failed to close client: %v", err)
+	}
+	if err := factory.Close(); err != nil {
+		t.Fatalf("Failed to close factory: %v", err)
+	}
+
+	// gRPC 内部协程异步退出，轮询等待协程数回落
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := runtime.NumGoroutine(); got > baseline {
+		t.Errorf("Expected goroutines to return to %d after Close, got %d", baseline, got)
+	}
+}
+
+// panickingClientConn 第一次更新时 panic 的 resolver.ClientConn，之后的更新写入 updates
+type panickingClientConn struct {
+	resolver.ClientConn
+	calls   atomic.Int32
+	updates chan resolver.State
+}
+
+func (cc *panickingClientConn) UpdateState(state resolver.State) error {
+	if cc.calls.Add(1) == 1 {
+		panic("update failed")
+	}
+	cc.updates <- state
+	return nil
+}
+
+func (cc *panickingClientConn) ReportError(err error) {}
+
+// countingWatchRegistry 记录 Watch 次数的注册器
+type countingWatchRegistry struct {
+	blockingWatchRegistry
+	watches atomic.Int32
+}
+
+func (r *countingWatchRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*discovery.ServiceInfo, error) {
+	r.watches.Add(1)
+	return r.blockingWatchRegistry.Watch(ctx, serviceName)
+}
+
+func TestResolverRecoversWatchPanic(t *testing.T) {
+	registry := &countingWatchRegistry{blockingWatchRegistry: blockingWatchRegistry{MockRegistry: NewMockRegistry()}}
+	registry.Register(context.Background(), &discovery.ServiceInfo{Name: "test-service", Address: "10.0.0.1", Port: 9090})
+	cc := &panickingClientConn{updates: make(chan resolver.State, 1)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &discoveryResolver{
+		serviceName:  "test-service",
+		registry:     registry,
+		logger:       zap.NewNop(),
+		cc:           cc,
+		ctx:          ctx,
+		cancel:       cancel,
+		watchBackoff: discovery.RegistrationBackoff{InitialInterval: 10 * time.Millisecond, MaxInterval: 10 * time.Millisecond},
+	}
+	r.goTracked(r.start)
+	defer r.wait()
+
+	// 处理更新时 panic 后重新建立监听，进程不退出
+	select {
+	case state := <-cc.updates:
+		if len(state.Addresses) != 1 || state.Addresses[0].Addr != "10.0.0.1:9090" {
+			t.Errorf("Expected address 10.0.0.1:9090 after recovery, got %v", state.Addresses)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected resolver to recover and update addresses")
+	}
+	if got := registry.watches.Load(); got != 2 {
+		t.Errorf("Expected watch to be re-established once, got %d watches", got)
+	}
+}
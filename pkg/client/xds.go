@@ -0,0 +1,36 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// xdsScheme xDS 目标的 scheme，如 xds:///user-service
+	xdsScheme = "xds"
+	
+	// XDSBootstrapEnv xDS bootstrap 文件路径的环境变量
+	XDSBootstrapEnv = "GRPC_XDS_BOOTSTRAP"
+	// XDSBootstrapConfigEnv xDS bootstrap 内容的环境变量
+	XDSBootstrapConfigEnv = "GRPC_XDS_BOOTSTRAP_CONFIG"
+)
+
+// isXDSTarget 判断目标是否为 xDS 目标
+func isXDSTarget(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme == xdsScheme
+}
+
+// checkXDSSupport 检查 xDS 解析器是否已注册以及 bootstrap 是否已配置
+func checkXDSSupport() error {
+	if resolver.Get(xdsScheme) == nil {
+		return fmt.Errorf("xds resolver is not registered, import _ \"github.com/go-grpc-kit/go-grpc-kit/pkg/client/xds\"")
+	}
+	if os.Getenv(XDSBootstrapEnv) == "" && os.Getenv(XDSBootstrapConfigEnv) == "" {
+		return fmt.Errorf("xds bootstrap is not configured, set %s or %s", XDSBootstrapEnv, XDSBootstrapConfigEnv)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+// Package xds 注册 gRPC 的 xDS 解析器和负载均衡策略，使客户端工厂支持 xds:/// 目标
+//
+// xDS 依赖较多，因此不在 client 包中默认引入，需要时匿名导入：
+//
+//	import _ "github.com/go-grpc-kit/go-grpc-kit/pkg/client/xds"
+//
+// 启动前需要通过 GRPC_XDS_BOOTSTRAP 环境变量指定 bootstrap 文件路径，
+// 或通过 GRPC_XDS_BOOTSTRAP_CONFIG 直接提供 bootstrap 内容。
+package xds
+
+import (
+	_ "google.golang.org/grpc/xds" // 注册 xds 解析器
+)
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// countingRegistry 记录 Discover 调用次数的注册器
+type countingRegistry struct {
+	*MockRegistry
+	discovers atomic.Int32
+}
+
+func (r *countingRegistry) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	r.discovers.Add(1)
+	return r.MockRegistry.Discover(ctx, serviceName)
+}
+
+func TestIsXDSTarget(t *testing.T) {
+	tests := map[string]bool{
+		"xds:///user-service":          true,
+		"xds://control-plane/user-svc": true,
+		"user-service":                 false,
+		"dns:///example.com:9090":      false,
+		"example.com:9090":             false,
+	}
+	for target, expected := range tests {
+		if got := isXDSTarget(target); got != expected {
+			t.Errorf("isXDSTarget(%q) = %v, expected %v", target, got, expected)
+		}
+	}
+}
+
+func TestGetClientXDSTargetBypassesDiscovery(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Client: config.GRPCClientConfig{
+				LoadBalancing: "round_robin",
+			},
+		},
+	}
+	registry := &countingRegistry{MockRegistry: NewMockRegistry()}
+	factory := NewClientFactory(cfg, registry, zap.NewNop())
+	defer factory.Close()
+
+	const target = "xds:///user-service"
+
+	// 未注册 xds 解析器
+	if resolver.Get(xdsScheme) == nil {
+		if _, err := factory.GetClient(target); err == nil || !strings.Contains(err.Error(), "not registered") {
+			t.Errorf("Expected resolver not registered error, got %v", err)
+		}
+		// 使用模拟解析器代替真实的 xDS 解析器
+		resolver.Register(manual.NewBuilderWithScheme(xdsScheme))
+	}
+
+	// 未配置 bootstrap
+	t.Setenv(XDSBootstrapEnv, "")
+	t.Setenv(XDSBootstrapConfigEnv, "")
+	if _, err := factory.GetClient(target); err == nil || !strings.Contains(err.Error(), XDSBootstrapEnv) {
+		t.Errorf("Expected bootstrap error, got %v", err)
+	}
+
+	t.Setenv(XDSBootstrapEnv, "/etc/xds/bootstrap.json")
+	conn, err := factory.GetClient(target)
+	if err != nil {
+		t.Fatalf("Failed to get xDS client: %v", err)
+	}
+	if conn.Target() != target {
+		t.Errorf("Expected target %s, got %s", target, conn.Target())
+	}
+
+	if n := registry.discovers.Load(); n != 0 {
+		t.Errorf("Expected discovery registry to be bypassed, got %d Discover calls", n)
+	}
+	factory.mu.RLock()
+	_, hasResolver := factory.resolvers[target]
+	factory.mu.RUnlock()
+	if hasResolver {
+		t.Error("Expected no discovery resolver for xDS target")
+	}
+}
+
+func TestHasResolverScheme(t *testing.T) {
+	tests := map[string]bool{
+		"xds:///user-service":       true,
+		"dns:///example.com:9090":   true,
+		"passthrough:///10.0.0.1:1": true,
+		"unix:///tmp/grpc.sock":     true,
+		"user-service":              false,
+		"example.com:9090":          false,
+		"localhost:9090":            false,
+	}
+	for target, expected := range tests {
+		if got := hasResolverScheme(target); got != expected {
+			t.Errorf("hasResolverScheme(%q) = %v, expected %v", target, got, expected)
+		}
+	}
+}
+
+func TestGetClientSchemeTargetBypassesDiscovery(t *testing.T) {
+	registry := &countingRegistry{MockRegistry: NewMockRegistry()}
+	factory := NewClientFactory(&config.Config{}, registry, zap.NewNop())
+	defer factory.Close()
+
+	const target = "dns:///localhost:9090"
+	conn, err := factory.GetClient(target)
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if conn.Target() != target {
+		t.Errorf("Expected target %s, got %s", target, conn.Target())
+	}
+	if n := registry.discovers.Load(); n != 0 {
+		t.Errorf("Expected discovery registry to be bypassed, got %d Discover calls", n)
+	}
+}
+
+func TestWithResolverScheme(t *testing.T) {
+	if resolver.Get(xdsScheme) == nil {
+		resolver.Register(manual.NewBuilderWithScheme(xdsScheme))
+	}
+	t.Setenv(XDSBootstrapEnv, "/etc/xds/bootstrap.json")
+
+	registry := &countingRegistry{MockRegistry: NewMockRegistry()}
+	factory := NewClientFactory(&config.Config{}, registry, zap.NewNop(), WithResolverScheme(xdsScheme))
+	defer factory.Close()
+
+	// 不带 scheme 的服务名使用 xds 解析器，带 scheme 的目标保持不变
+	conn, err := factory.GetClient("user-service")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if conn.Target() != "xds:///user-service" {
+		t.Errorf("Expected target xds:///user-service, got %s", conn.Target())
+	}
+	conn, err = factory.GetClient("dns:///localhost:9090")
+	if err != nil {
+		t.Fatalf("Failed to get client: %v", err)
+	}
+	if conn.Target() != "dns:///localhost:9090" {
+		t.Errorf("Expected target dns:///localhost:9090, got %s", conn.Target())
+	}
+	if n := registry.discovers.Load(); n != 0 {
+		t.Errorf("Expected discovery registry to be bypassed, got %d Discover calls", n)
+	}
+
+	// 未注册的 scheme 返回错误，而不是被 gRPC 当作 DNS 名称解析
+	unknown := NewClientFactory(&config.Config{}, nil, zap.NewNop(), WithResolverScheme("unknown"))
+	defer unknown.Close()
+	if _, err := unknown.GetClient("user-service"); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("Expected resolver not registered error, got %v", err)
+	}
+}
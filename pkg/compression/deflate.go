@@ -0,0 +1,91 @@
+// Package compression 注册 gRPC 内置之外的压缩算法
+// 导入该包即可在客户端和服务端使用 deflate，同时确保 gzip 已注册
+package compression
+
+import (
+	"compress/flate"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // 注册 gzip 压缩器
+)
+
+const (
+	// Gzip gzip 压缩算法名称
+	Gzip = "gzip"
+	// Deflate deflate 压缩算法名称
+	Deflate = "deflate"
+)
+
+func init() {
+	c := &deflateCompressor{}
+	c.poolCompressor.New = func() interface{} {
+		w, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return &deflateWriter{Writer: w, pool: &c.poolCompressor}
+	}
+	encoding.RegisterCompressor(c)
+}
+
+// IsRegistered 检查压缩算法是否已注册到 gRPC
+func IsRegistered(name string) bool {
+	return encoding.GetCompressor(name) != nil
+}
+
+// deflateCompressor 实现 encoding.Compressor，复用压缩器和解压器以减少分配
+type deflateCompressor struct {
+	poolCompressor   sync.Pool
+	poolDecompressor sync.Pool
+}
+
+// Name 返回压缩算法名称
+func (c *deflateCompressor) Name() string {
+	return Deflate
+}
+
+// Compress 返回写入 w 的压缩写入器
+func (c *deflateCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	z := c.poolCompressor.Get().(*deflateWriter)
+	z.Writer.Reset(w)
+	return z, nil
+}
+
+// Decompress 返回读取 r 的解压读取器
+func (c *deflateCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	z, inPool := c.poolDecompressor.Get().(*deflateReader)
+	if !inPool {
+		return &deflateReader{ReadCloser: flate.NewReader(r), pool: &c.poolDecompressor}, nil
+	}
+	if err := z.ReadCloser.(flate.Resetter).Reset(r, nil); err != nil {
+		c.poolDecompressor.Put(z)
+		return nil, err
+	}
+	return z, nil
+}
+
+// deflateWriter 关闭后归还到池中的压缩写入器
+type deflateWriter struct {
+	*flate.Writer
+	pool *sync.Pool
+}
+
+// Close 刷新剩余数据并归还写入器
+func (z *deflateWriter) Close() error {
+	defer z.pool.Put(z)
+	return z.Writer.Close()
+}
+
+// deflateReader 读取完毕后归还到池中的解压读取器
+type deflateReader struct {
+	io.ReadCloser
+	pool *sync.Pool
+}
+
+// Read 读取解压数据，读到 EOF 时归还读取器
+func (z *deflateReader) Read(p []byte) (int, error) {
+	n, err := z.ReadCloser.Read(p)
+	if err == io.EOF {
+		z.pool.Put(z)
+	}
+	return n, err
+}
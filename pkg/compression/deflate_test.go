@@ -0,0 +1,71 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// roundTrip 使用指定压缩器压缩并解压数据，返回压缩后的数据和解压结果
+func roundTrip(t *testing.T, c encoding.Compressor, data []byte) ([]byte, []byte) {
+	t.Helper()
+
+	var compressed bytes.Buffer
+	w, err := c.Compress(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to create compressor: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("Failed to compress: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close compressor: %v", err)
+	}
+
+	r, err := c.Decompress(bytes.NewReader(compressed.Bytes()))
+	if err != nil {
+		t.Fatalf("Failed to create decompressor: %v", err)
+	}
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	return compressed.Bytes(), decompressed
+}
+
+func TestDeflateRegistered(t *testing.T) {
+	for _, name := range []string{Gzip, Deflate} {
+		if !IsRegistered(name) {
+			t.Errorf("Expected %s compressor to be registered", name)
+		}
+	}
+	if IsRegistered("snappy") {
+		t.Error("Expected snappy compressor not to be registered")
+	}
+}
+
+func TestDeflateRoundTrip(t *testing.T) {
+	c := encoding.GetCompressor(Deflate)
+	data := []byte(strings.Repeat("go-grpc-kit deflate payload ", 100))
+
+	// 多次往返以覆盖池中复用的压缩器和解压器
+	for i := 0; i < 3; i++ {
+		compressed, decompressed := roundTrip(t, c, data)
+		if !bytes.Equal(decompressed, data) {
+			t.Fatalf("Round trip %d: decompressed data does not match input", i)
+		}
+		if len(compressed) >= len(data) {
+			t.Errorf("Round trip %d: expected compressed size %d to be smaller than %d", i, len(compressed), len(data))
+		}
+	}
+}
+
+func TestDeflateEmptyMessage(t *testing.T) {
+	_, decompressed := roundTrip(t, encoding.GetCompressor(Deflate), nil)
+	if len(decompressed) != 0 {
+		t.Errorf("Expected empty result, got %d bytes", len(decompressed))
+	}
+}
@@ -3,8 +3,13 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
@@ -24,6 +29,36 @@ type ServerConfig struct {
 	Port     int    `mapstructure:"port" yaml:"port"`
 	GRPCPort int    `mapstructure:"grpc_port" yaml:"grpc_port"`
 	Host     string `mapstructure:"host" yaml:"host"`
+	
+	// 额外的 gRPC 监听器，与默认监听器共享已注册的服务
+	Listeners []ListenerConfig `mapstructure:"listeners" yaml:"listeners"`
+	
+	// gRPC 监听器启用 SO_REUSEPORT，新进程可以在旧进程优雅关闭前绑定同一端口，实现无停机升级
+	ReusePort bool `mapstructure:"reuse_port" yaml:"reuse_port"`
+	
+	// 在默认 gRPC 端口上同时提供指标和健康检查等 HTTP 端点，适用于只能暴露一个端口的环境，不支持 TLS
+	MultiplexHTTP bool `mapstructure:"multiplex_http" yaml:"multiplex_http"`
+	
+	// gRPC 端口被占用时改为监听随机空闲端口并记录日志，适用于本地开发
+	AutoPort bool `mapstructure:"auto_port" yaml:"auto_port"`
+	
+	// gRPC-Web 配置，浏览器通过 HTTP/1.1 调用默认监听器上的服务
+	GrpcWeb GrpcWebConfig `mapstructure:"grpc_web" yaml:"grpc_web"`
+}
+
+// GrpcWebConfig gRPC-Web 配置
+type GrpcWebConfig struct {
+	Enabled        bool     `mapstructure:"enabled" yaml:"enabled"`
+	Port           int      `mapstructure:"port" yaml:"port"`                       // 监听 server.host 上的该端口
+	AllowedOrigins []string `mapstructure:"allowed_origins" yaml:"allowed_origins"` // 允许跨域调用的来源，"*" 允许所有来源，为空时拒绝跨域请求
+}
+
+// ListenerConfig gRPC 监听器配置
+type ListenerConfig struct {
+	Name             string `mapstructure:"name" yaml:"name"`
+	Host             string `mapstructure:"host" yaml:"host"`
+	Port             int    `mapstructure:"port" yaml:"port"`
+	EnableReflection bool   `mapstructure:"enable_reflection" yaml:"enable_reflection"`
 }
 
 // GRPCConfig gRPC 配置
@@ -38,6 +73,9 @@ type GRPCServerConfig struct {
 	MaxRecvMsgSize int `mapstructure:"max_recv_msg_size" yaml:"max_recv_msg_size"`
 	MaxSendMsgSize int `mapstructure:"max_send_msg_size" yaml:"max_send_msg_size"`
 	
+	// 开启后由拦截器校验请求大小，超限时返回包含方法名和上限的错误并记录指标
+	EnableMessageSizeCheck bool `mapstructure:"enable_message_size_check" yaml:"enable_message_size_check"`
+	
 	// 连接配置
 	MaxConcurrentStreams uint32 `mapstructure:"max_concurrent_streams" yaml:"max_concurrent_streams"`
 	ConnectionTimeout    int    `mapstructure:"connection_timeout" yaml:"connection_timeout"`     // 秒
@@ -45,26 +83,101 @@ type GRPCServerConfig struct {
 	KeepaliveTimeout     int    `mapstructure:"keepalive_timeout" yaml:"keepalive_timeout"`       // 秒
 	KeepaliveMinTime     int    `mapstructure:"keepalive_min_time" yaml:"keepalive_min_time"`     // 秒
 	
+	// 连接生命周期配置，到期后发送 GOAWAY 使客户端重连，便于负载均衡重新分配，0 表示不限制
+	MaxConnectionIdle     int `mapstructure:"max_connection_idle" yaml:"max_connection_idle"`           // 秒
+	MaxConnectionAge      int `mapstructure:"max_connection_age" yaml:"max_connection_age"`             // 秒
+	MaxConnectionAgeGrace int `mapstructure:"max_connection_age_grace" yaml:"max_connection_age_grace"` // 秒
+	
+	// 每个监听器同时保持的最大 TCP 连接数，超出的新连接在已有连接关闭前不会被接受，0 表示不限制
+	MaxConnections int `mapstructure:"max_connections" yaml:"max_connections"`
+	
+	// HTTP/2 流控窗口和头部列表大小，0 表示使用 gRPC 默认值；设置窗口大小后不再按带宽延迟积动态调整
+	InitialWindowSize     int32  `mapstructure:"initial_window_size" yaml:"initial_window_size"`           // 字节，每个流的窗口，至少 65535
+	InitialConnWindowSize int32  `mapstructure:"initial_conn_window_size" yaml:"initial_conn_window_size"` // 字节，每个连接的窗口，至少 65535
+	MaxHeaderListSize     uint32 `mapstructure:"max_header_list_size" yaml:"max_header_list_size"`         // 字节，接收的头部列表最大大小
+	
+	// 不注册标准健康检查服务，自行注册 grpc.health.v1.Health 实现时需开启，开启后框架不再维护健康状态
+	DisableDefaultHealth bool `mapstructure:"disable_default_health" yaml:"disable_default_health"`
+	
 	// 安全配置
 	EnableReflection bool `mapstructure:"enable_reflection" yaml:"enable_reflection"`
 	
+	// 反射服务访问控制，开启后反射请求必须携带指定的元数据令牌，否则返回 Unauthenticated
+	ReflectionAuth ReflectionAuthConfig `mapstructure:"reflection_auth" yaml:"reflection_auth"`
+	
+	// 调试配置，注册 channelz 服务用于排查连接和子通道问题，并在指标端口提供 /debug/channelz 摘要
+	EnableChannelz bool `mapstructure:"enable_channelz" yaml:"enable_channelz"`
+	
 	// 压缩配置
 	EnableCompression bool   `mapstructure:"enable_compression" yaml:"enable_compression"`
 	CompressionLevel  string `mapstructure:"compression_level" yaml:"compression_level"` // gzip, deflate
 	
 	// 拦截器配置
-	EnableLogging  bool `mapstructure:"enable_logging" yaml:"enable_logging"`
-	EnableMetrics  bool `mapstructure:"enable_metrics" yaml:"enable_metrics"`
-	EnableRecovery bool `mapstructure:"enable_recovery" yaml:"enable_recovery"`
-	EnableTracing  bool `mapstructure:"enable_tracing" yaml:"enable_tracing"`
+	EnableLogging   bool `mapstructure:"enable_logging" yaml:"enable_logging"`
+	EnableMetrics   bool `mapstructure:"enable_metrics" yaml:"enable_metrics"`
+	EnableRecovery  bool `mapstructure:"enable_recovery" yaml:"enable_recovery"`
+	EnableTracing   bool `mapstructure:"enable_tracing" yaml:"enable_tracing"`
+	EnableRequestID bool `mapstructure:"enable_request_id" yaml:"enable_request_id"` // 读取或生成 x-request-id 并在响应 trailer 中返回
+	
+	// 内置拦截器 recovery、logging、metrics 由外到内的顺序，未列出的按默认顺序追加在内层
+	// 默认恢复拦截器位于最外层，日志和指标拦截器中的 panic 也能被恢复，panic 的调用仍被记录
+	InterceptorOrder []string `mapstructure:"interceptor_order" yaml:"interceptor_order"`
+	
+	// 调用请求消息的 ValidateAll() 或 Validate()（如 protoc-gen-validate 生成的方法），校验失败时返回 InvalidArgument
+	EnableValidation bool `mapstructure:"enable_validation" yaml:"enable_validation"`
+	
+	// 废弃方法配置，调用时记录指标和告警日志
+	DeprecatedMethods []string `mapstructure:"deprecated_methods" yaml:"deprecated_methods"` // 完整方法名，如 /pkg.Service/Method
+	DeprecationHeader string   `mapstructure:"deprecation_header" yaml:"deprecation_header"` // 非空时在响应头中标记废弃
+	
+	// 必需的请求元数据键（如 x-tenant-id），缺少时返回 InvalidArgument，健康检查和反射请求不校验
+	RequiredMetadata []string `mapstructure:"required_metadata" yaml:"required_metadata"`
+	
+	// 方法访问控制，用于在同一进程中只对外暴露部分服务
+	MethodACL MethodACLConfig `mapstructure:"method_acl" yaml:"method_acl"`
+	
+	// 慢请求阈值，大于 0 时低于阈值的调用以 debug 级别记录，达到阈值的以 warn 级别记录
+	SlowThreshold int `mapstructure:"slow_threshold" yaml:"slow_threshold"` // 毫秒
+	
+	// 载荷日志配置，仅在日志级别为 debug 时生效，生产环境不应开启
+	LogPayloads       bool `mapstructure:"log_payloads" yaml:"log_payloads"`
+	LogPayloadMaxSize int  `mapstructure:"log_payload_max_size" yaml:"log_payload_max_size"` // 字节
+	
+	// 服务器标识配置，用于排查负载均衡后由哪个副本处理了请求
+	EnableServerIdentity bool   `mapstructure:"enable_server_identity" yaml:"enable_server_identity"`
+	ServerIdentityHeader string `mapstructure:"server_identity_header" yaml:"server_identity_header"`
+	ServerIdentity       string `mapstructure:"server_identity" yaml:"server_identity"` // 为空时使用 HOSTNAME 环境变量
+}
+
+// MethodACLConfig 方法访问控制配置
+// 规则为完整方法名模式，支持 path.Match 通配符，如 /pkg.Service/* 匹配整个服务；
+// deny 优先于 allow，allow 非空时只放行匹配的方法，健康检查、反射和 channelz 请求不受限制
+type MethodACLConfig struct {
+	Allow []string `mapstructure:"allow" yaml:"allow"`
+	Deny  []string `mapstructure:"deny" yaml:"deny"`
+	Code  string   `mapstructure:"code" yaml:"code"` // 被拦截时返回的状态码，UNIMPLEMENTED 或 PERMISSION_DENIED
+}
+
+// ReflectionAuthConfig 反射服务访问控制配置
+type ReflectionAuthConfig struct {
+	Enabled     bool   `mapstructure:"enabled" yaml:"enabled"`
+	MetadataKey string `mapstructure:"metadata_key" yaml:"metadata_key"` // 携带令牌的元数据键
+	Token       string `mapstructure:"token" yaml:"token"`               // 建议使用 ${VAR} 从环境变量读取
 }
 
 // GRPCClientConfig gRPC 客户端配置
 type GRPCClientConfig struct {
 	// 基础配置
-	Timeout        int    `mapstructure:"timeout" yaml:"timeout"`
+	Timeout        int    `mapstructure:"timeout" yaml:"timeout"` // 秒，等待连接就绪的超时，也是未设置截止时间的一元调用的默认超时
 	MaxRetries     int    `mapstructure:"max_retries" yaml:"max_retries"`
 	LoadBalancing  string `mapstructure:"load_balancing" yaml:"load_balancing"`
+	LocalZone      string `mapstructure:"local_zone" yaml:"local_zone"` // 客户端所在可用区，用于加权负载均衡的就近选择
+	
+	// 慢启动窗口（秒），大于 0 时新发现的后端在窗口内逐步获得完整流量，需使用 metadata_weighted_round_robin 负载均衡
+	SlowStartWindow int `mapstructure:"slow_start_window" yaml:"slow_start_window"`
+	
+	// 空闲连接回收时间（秒），大于 0 时工厂关闭超过该时间未被获取或调用的缓存连接，下次获取时重新创建
+	IdleEvictionTimeout int `mapstructure:"idle_eviction_timeout" yaml:"idle_eviction_timeout"`
 	
 	// 连接配置
 	MaxRecvMsgSize       int  `mapstructure:"max_recv_msg_size" yaml:"max_recv_msg_size"`
@@ -72,6 +185,16 @@ type GRPCClientConfig struct {
 	KeepaliveTime        int  `mapstructure:"keepalive_time" yaml:"keepalive_time"`         // 秒
 	KeepaliveTimeout     int  `mapstructure:"keepalive_timeout" yaml:"keepalive_timeout"`   // 秒
 	PermitWithoutStream  bool `mapstructure:"permit_without_stream" yaml:"permit_without_stream"`
+	BlockOnConnect       bool `mapstructure:"block_on_connect" yaml:"block_on_connect"`     // 创建连接时等待连接就绪，超时时间为 timeout
+	UseServiceConfig     bool `mapstructure:"use_service_config" yaml:"use_service_config"` // 是否设置客户端默认服务配置，关闭后完全使用解析器提供的服务配置
+	
+	// HTTP/2 流控窗口和头部列表大小，0 表示使用 gRPC 默认值；设置窗口大小后不再按带宽延迟积动态调整
+	InitialWindowSize     int32  `mapstructure:"initial_window_size" yaml:"initial_window_size"`           // 字节，每个流的窗口，至少 65535
+	InitialConnWindowSize int32  `mapstructure:"initial_conn_window_size" yaml:"initial_conn_window_size"` // 字节，每个连接的窗口，至少 65535
+	MaxHeaderListSize     uint32 `mapstructure:"max_header_list_size" yaml:"max_header_list_size"`         // 字节，接收的头部列表最大大小
+	
+	// 预热配置
+	WarmUpConcurrency int `mapstructure:"warm_up_concurrency" yaml:"warm_up_concurrency"` // 预热时同时建立连接的最大数量
 	
 	// 重试配置
 	RetryPolicy      RetryPolicyConfig `mapstructure:"retry_policy" yaml:"retry_policy"`
@@ -81,9 +204,14 @@ type GRPCClientConfig struct {
 	CompressionLevel  string `mapstructure:"compression_level" yaml:"compression_level"`
 	
 	// 拦截器配置
-	EnableLogging bool `mapstructure:"enable_logging" yaml:"enable_logging"`
-	EnableMetrics bool `mapstructure:"enable_metrics" yaml:"enable_metrics"`
-	EnableTracing bool `mapstructure:"enable_tracing" yaml:"enable_tracing"`
+	EnableLogging   bool `mapstructure:"enable_logging" yaml:"enable_logging"`
+	EnableMetrics   bool `mapstructure:"enable_metrics" yaml:"enable_metrics"`
+	EnableTracing   bool `mapstructure:"enable_tracing" yaml:"enable_tracing"`
+	EnableRequestID bool `mapstructure:"enable_request_id" yaml:"enable_request_id"` // 将上下文中的请求 ID 写入 x-request-id
+	
+	// 截止时间传递，服务端处理请求时发起的下游调用在入站截止时间基础上预留 deadline_hop_budget 毫秒
+	EnableDeadlinePropagation bool `mapstructure:"enable_deadline_propagation" yaml:"enable_deadline_propagation"`
+	DeadlineHopBudget         int  `mapstructure:"deadline_hop_budget" yaml:"deadline_hop_budget"` // 毫秒
 }
 
 // RetryPolicyConfig 重试策略配置
@@ -93,6 +221,11 @@ type RetryPolicyConfig struct {
 	MaxBackoff           string   `mapstructure:"max_backoff" yaml:"max_backoff"`               // 如 "30s"
 	BackoffMultiplier    float64  `mapstructure:"backoff_multiplier" yaml:"backoff_multiplier"`
 	RetryableStatusCodes []string `mapstructure:"retryable_status_codes" yaml:"retryable_status_codes"`
+	
+	// 重试限流（令牌桶），失败请求消耗 1 个令牌，成功请求归还 throttle_token_ratio 个令牌
+	// 令牌数不超过一半时停止重试，避免大范围故障时重试风暴放大负载；throttle_max_tokens 为 0 表示不限流
+	ThrottleMaxTokens  int     `mapstructure:"throttle_max_tokens" yaml:"throttle_max_tokens"`
+	ThrottleTokenRatio float64 `mapstructure:"throttle_token_ratio" yaml:"throttle_token_ratio"`
 }
 
 // DiscoveryConfig 服务发现配置
@@ -100,12 +233,108 @@ type DiscoveryConfig struct {
 	Type      string   `mapstructure:"type" yaml:"type"`
 	Endpoints []string `mapstructure:"endpoints" yaml:"endpoints"`
 	Namespace string   `mapstructure:"namespace" yaml:"namespace"`
+	// etcd 连接设置（秒），0 表示使用客户端默认值
+	DialTimeout       int `mapstructure:"dial_timeout" yaml:"dial_timeout"`
+	DialKeepAliveTime int `mapstructure:"dial_keepalive_time" yaml:"dial_keepalive_time"`
+	
+	// 服务发现结果缓存时间（秒），0 表示不缓存
+	CacheTTL int `mapstructure:"cache_ttl" yaml:"cache_ttl"`
+	
+	// 启动时注册中心不可用是否直接失败，false 时先启动服务并在后台重试注册
+	FailFast bool `mapstructure:"fail_fast" yaml:"fail_fast"`
+	
+	// 注册到服务发现的地址和端口，客户端通过它们连接服务
+	// 地址为空时使用 server.host，server.host 为 0.0.0.0 等通配地址时使用本机出站 IP；端口为 0 时使用 server.grpc_port
+	AdvertiseAddress string `mapstructure:"advertise_address" yaml:"advertise_address"`
+	AdvertisePort    int    `mapstructure:"advertise_port" yaml:"advertise_port"`
+	
+	// 注册时写入的实例元数据
+	Metadata DiscoveryMetadataConfig `mapstructure:"metadata" yaml:"metadata"`
+	
+	// consul 健康检查配置
+	HealthCheck HealthCheckConfig `mapstructure:"health_check" yaml:"health_check"`
+	
+	// 额外的注册中心，与 type 指定的注册中心同时注册，发现结果合并，用于注册中心迁移
+	Backends []DiscoveryBackendConfig `mapstructure:"backends" yaml:"backends"`
+}
+
+// DiscoveryMetadataConfig 注册到服务发现的实例元数据，客户端的解析器和负载均衡器据此做路由决策
+type DiscoveryMetadataConfig struct {
+	Weight        int    `mapstructure:"weight" yaml:"weight"`                 // 实例权重，0 表示不设置，客户端按 1 处理
+	Zone          string `mapstructure:"zone" yaml:"zone"`                     // 实例所在可用区
+	Version       string `mapstructure:"version" yaml:"version"`               // 服务版本
+	Protocol      string `mapstructure:"protocol" yaml:"protocol"`             // 服务协议
+	HealthService string `mapstructure:"health_service" yaml:"health_service"` // 客户端健康检查使用的健康服务名
+}
+
+// DiscoveryBackendConfig 额外的注册中心配置，连接超时和健康检查沿用 discovery 中的配置
+type DiscoveryBackendConfig struct {
+	Type      string   `mapstructure:"type" yaml:"type"`
+	Endpoints []string `mapstructure:"endpoints" yaml:"endpoints"`
+	Namespace string   `mapstructure:"namespace" yaml:"namespace"` // 为空时使用 discovery.namespace
+}
+
+// HealthCheckConfig consul 健康检查配置，时间单位为秒，0 表示使用默认值
+type HealthCheckConfig struct {
+	Type            string `mapstructure:"type" yaml:"type"`                         // grpc、http 或 ttl
+	Interval        int    `mapstructure:"interval" yaml:"interval"`                 // 检查间隔，ttl 类型为 TTL 时长
+	Timeout         int    `mapstructure:"timeout" yaml:"timeout"`
+	DeregisterAfter int    `mapstructure:"deregister_after" yaml:"deregister_after"` // 持续不健康多久后自动注销
+	
+	// http 类型的检查路径和端口，端口为 0 时使用服务端口
+	HTTPPath string `mapstructure:"http_path" yaml:"http_path"`
+	HTTPPort int    `mapstructure:"http_port" yaml:"http_port"`
+	
+	// grpc 类型是否使用 TLS 以及是否跳过证书校验
+	GRPCUseTLS    bool `mapstructure:"grpc_use_tls" yaml:"grpc_use_tls"`
+	TLSSkipVerify bool `mapstructure:"tls_skip_verify" yaml:"tls_skip_verify"`
 }
 
 // LoggingConfig 日志配置
 type LoggingConfig struct {
 	Level  string `mapstructure:"level" yaml:"level"`
-	Format string `mapstructure:"format" yaml:"format"`
+	Format string `mapstructure:"format" yaml:"format"` // json 或 console，其他值使用 json
+	
+	// 开发模式，使用带颜色级别的控制台格式，warn 级别附带堆栈
+	Development bool `mapstructure:"development" yaml:"development"`
+	
+	// 日志输出位置，支持 stdout、stderr 和文件路径
+	OutputPaths []string `mapstructure:"output_paths" yaml:"output_paths"`
+	// 日志器内部错误的输出位置
+	ErrorOutputPaths []string `mapstructure:"error_output_paths" yaml:"error_output_paths"`
+	
+	// 按大小滚动的日志文件，设置 filename 后与 output_paths 同时写入
+	Rotation LogRotationConfig `mapstructure:"rotation" yaml:"rotation"`
+	
+	// 日志采样，默认关闭
+	Sampling LogSamplingConfig `mapstructure:"sampling" yaml:"sampling"`
+	
+	// 访问日志，开启后日志拦截器的每次调用记录写入独立的输出
+	AccessLog AccessLogConfig `mapstructure:"access_log" yaml:"access_log"`
+}
+
+// AccessLogConfig 访问日志配置
+// 访问日志固定为 JSON 格式，不受 logging.level 影响，每次调用一行
+type AccessLogConfig struct {
+	Enabled     bool     `mapstructure:"enabled" yaml:"enabled"`
+	OutputPaths []string `mapstructure:"output_paths" yaml:"output_paths"` // 支持 stdout、stderr 和文件路径
+}
+
+// LogSamplingConfig 日志采样配置
+// 开启后每秒内相同级别和消息的日志先记录 initial 条，之后每 thereafter 条记录一条
+type LogSamplingConfig struct {
+	Enabled    bool `mapstructure:"enabled" yaml:"enabled"`
+	Initial    int  `mapstructure:"initial" yaml:"initial"`
+	Thereafter int  `mapstructure:"thereafter" yaml:"thereafter"`
+}
+
+// LogRotationConfig 日志文件滚动配置
+type LogRotationConfig struct {
+	Filename   string `mapstructure:"filename" yaml:"filename"`
+	MaxSize    int    `mapstructure:"max_size" yaml:"max_size"`       // 单个文件的最大大小 (MB)，0 表示 100
+	MaxAge     int    `mapstructure:"max_age" yaml:"max_age"`         // 旧文件保留天数，0 表示不按时间清理
+	MaxBackups int    `mapstructure:"max_backups" yaml:"max_backups"` // 旧文件保留个数，0 表示全部保留
+	Compress   bool   `mapstructure:"compress" yaml:"compress"`       // 是否 gzip 压缩旧文件
 }
 
 // TLSConfig TLS 配置
@@ -118,15 +347,87 @@ type TLSConfig struct {
 
 // MetricsConfig 指标配置
 type MetricsConfig struct {
-	Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-	Port    int    `mapstructure:"port" yaml:"port"`
-	Path    string `mapstructure:"path" yaml:"path"`
+	Enabled      bool               `mapstructure:"enabled" yaml:"enabled"`
+	Host         string             `mapstructure:"host" yaml:"host"` // 监听地址，为空时监听所有网卡
+	Port         int                `mapstructure:"port" yaml:"port"`
+	Path         string             `mapstructure:"path" yaml:"path"`
+	HTTPTimeouts HTTPTimeoutsConfig `mapstructure:"http_timeouts" yaml:"http_timeouts"`
+	
+	// 请求持续时间直方图的桶边界（秒），为空时使用 prometheus 默认桶
+	LatencyBuckets []float64 `mapstructure:"latency_buckets" yaml:"latency_buckets"`
 }
 
-var globalConfig *Config
+// HTTPTimeoutsConfig HTTP 服务器连接超时配置，0 表示不限制
+type HTTPTimeoutsConfig struct {
+	ReadHeaderTimeout int `mapstructure:"read_header_timeout" yaml:"read_header_timeout"` // 秒
+	ReadTimeout       int `mapstructure:"read_timeout" yaml:"read_timeout"`               // 秒
+	WriteTimeout      int `mapstructure:"write_timeout" yaml:"write_timeout"`             // 秒
+	IdleTimeout       int `mapstructure:"idle_timeout" yaml:"idle_timeout"`               // 秒
+}
+
+// EnvProfile 选择环境配置的环境变量
+const EnvProfile = "GRPC_KIT_ENV"
+
+var (
+	// globalConfig 全局配置，配置文件变化时由监听协程替换，读写需持有 globalMu
+	globalConfig *Config
+	globalMu     sync.RWMutex
+)
+
+// setGlobalConfig 替换全局配置
+func setGlobalConfig(config *Config) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	globalConfig = config
+}
 
 // Load 加载配置
 func Load(configPath string) (*Config, error) {
+	_, config, err := load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	
+	setGlobalConfig(config)
+	return config, nil
+}
+
+// Watch 加载配置并监听配置文件变化
+// 配置文件修改后重新解析并更新全局配置，然后回调 onChange；解析失败时回调错误且保留旧配置
+func Watch(configPath string, onChange func(*Config, error)) (*Config, error) {
+	v, config, err := load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	setGlobalConfig(config)
+	
+	// 未找到配置文件时没有可监听的对象
+	if v.ConfigFileUsed() == "" {
+		return config, nil
+	}
+	
+	v.OnConfigChange(func(e fsnotify.Event) {
+		// 重新读取只包含基础配置，需要再次合并环境配置
+		if err := mergeOverlay(v, configPath); err != nil {
+			onChange(nil, err)
+			return
+		}
+		
+		var reloaded Config
+		if err := v.Unmarshal(&reloaded, expandEnvOption); err != nil {
+			onChange(nil, fmt.Errorf("failed to unmarshal reloaded config: %w", err))
+			return
+		}
+		setGlobalConfig(&reloaded)
+		onChange(&reloaded, nil)
+	})
+	v.WatchConfig()
+	
+	return config, nil
+}
+
+// load 读取并解析配置
+func load(configPath string) (*viper.Viper, *Config, error) {
 	v := viper.New()
 	
 	// 设置配置文件路径
@@ -150,22 +451,113 @@ func Load(configPath string) (*Config, error) {
 	// 读取配置文件
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
 		}
 	}
 	
+	// 合并环境配置
+	if err := mergeOverlay(v, configPath); err != nil {
+		return nil, nil, err
+	}
+	
 	// 解析配置
 	var config Config
-	if err := v.Unmarshal(&config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := v.Unmarshal(&config, expandEnvOption); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 	
-	globalConfig = &config
-	return &config, nil
+	return v, &config, nil
+}
+
+// expandEnvOption 解析配置时展开字符串值中的 ${VAR} 和 $VAR 环境变量引用，未设置的变量展开为空字符串
+// 展开在类型转换之前进行，数值和布尔字段同样可以引用环境变量，如 grpc_port: ${PORT}
+func expandEnvOption(c *mapstructure.DecoderConfig) {
+	c.DecodeHook = mapstructure.ComposeDecodeHookFunc(expandEnvHook, c.DecodeHook)
+}
+
+// expandEnvHook 展开字符串中的环境变量引用
+func expandEnvHook(from reflect.Kind, to reflect.Kind, data interface{}) (interface{}, error) {
+	if from != reflect.String {
+		return data, nil
+	}
+	value := data.(string)
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+	return os.ExpandEnv(value), nil
+}
+
+// mergeOverlay 根据 GRPC_KIT_ENV 合并环境配置，如 application-prod.yml
+// 环境配置覆盖基础配置中的同名键，环境变量的优先级仍然最高；环境配置文件不存在时忽略
+func mergeOverlay(v *viper.Viper, configPath string) error {
+	env := os.Getenv(EnvProfile)
+	if env == "" {
+		return nil
+	}
+	
+	overlayPath := findOverlay(v.ConfigFileUsed(), configPath, env)
+	if overlayPath == "" {
+		return nil
+	}
+	
+	file, err := os.Open(overlayPath)
+	if err != nil {
+		return fmt.Errorf("failed to open config overlay %s: %w", overlayPath, err)
+	}
+	defer file.Close()
+	
+	// 使用 MergeConfig 而非 MergeInConfig，保持监听的仍是基础配置文件
+	v.SetConfigType(strings.TrimPrefix(filepath.Ext(overlayPath), "."))
+	if err := v.MergeConfig(file); err != nil {
+		return fmt.Errorf("failed to merge config overlay %s: %w", overlayPath, err)
+	}
+	
+	return nil
+}
+
+// findOverlay 查找环境配置文件，与基础配置文件位于同一目录，文件名为 <name>-<env><ext>
+func findOverlay(baseFile, configPath, env string) string {
+	var candidates []string
+	switch {
+	case baseFile != "":
+		candidates = append(candidates, overlayName(baseFile, env))
+	case configPath != "":
+		candidates = append(candidates, overlayName(configPath, env))
+	default:
+		// 未找到基础配置文件时按默认搜索路径查找
+		for _, dir := range []string{"./config", "."} {
+			for _, ext := range []string{".yml", ".yaml"} {
+				candidates = append(candidates, filepath.Join(dir, "application-"+env+ext))
+			}
+		}
+	}
+	
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// overlayName 根据基础配置文件名生成环境配置文件名
+func overlayName(baseFile, env string) string {
+	ext := filepath.Ext(baseFile)
+	name := strings.TrimSuffix(filepath.Base(baseFile), ext)
+	return filepath.Join(filepath.Dir(baseFile), name+"-"+env+ext)
 }
 
 // Get 获取全局配置
 func Get() *Config {
+	globalMu.RLock()
+	config := globalConfig
+	globalMu.RUnlock()
+	if config != nil {
+		return config
+	}
+	
+	globalMu.Lock()
+	defer globalMu.Unlock()
 	if globalConfig == nil {
 		// 如果没有加载配置，使用默认配置
 		config := &Config{}
@@ -180,37 +572,83 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.grpc_port", 9090)
 	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.reuse_port", false)
+	v.SetDefault("server.multiplex_http", false)
+	v.SetDefault("server.auto_port", false)
+	v.SetDefault("server.grpc_web.enabled", false)
+	v.SetDefault("server.grpc_web.port", 8082)
+	v.SetDefault("server.grpc_web.allowed_origins", []string{})
 	
 	// gRPC 服务端默认值
 	v.SetDefault("grpc.server.max_recv_msg_size", 4*1024*1024) // 4MB
 	v.SetDefault("grpc.server.max_send_msg_size", 4*1024*1024) // 4MB
 	v.SetDefault("grpc.server.max_concurrent_streams", 100)
 	v.SetDefault("grpc.server.connection_timeout", 120)
+	v.SetDefault("grpc.server.enable_message_size_check", false)
+	v.SetDefault("grpc.server.slow_threshold", 0)
 	v.SetDefault("grpc.server.keepalive_time", 30)
 	v.SetDefault("grpc.server.keepalive_timeout", 5)
 	v.SetDefault("grpc.server.keepalive_min_time", 5)
+	v.SetDefault("grpc.server.max_connection_idle", 0)
+	v.SetDefault("grpc.server.max_connection_age", 0)
+	v.SetDefault("grpc.server.max_connection_age_grace", 0)
+	v.SetDefault("grpc.server.max_connections", 0)
+	v.SetDefault("grpc.server.initial_window_size", 0)
+	v.SetDefault("grpc.server.initial_conn_window_size", 0)
+	v.SetDefault("grpc.server.max_header_list_size", 0)
+	v.SetDefault("grpc.server.disable_default_health", false)
 	v.SetDefault("grpc.server.enable_reflection", false)
+	v.SetDefault("grpc.server.enable_channelz", false)
 	v.SetDefault("grpc.server.enable_compression", false)
 	v.SetDefault("grpc.server.compression_level", "gzip")
 	v.SetDefault("grpc.server.enable_logging", true)
 	v.SetDefault("grpc.server.enable_metrics", true)
 	v.SetDefault("grpc.server.enable_recovery", true)
 	v.SetDefault("grpc.server.enable_tracing", false)
+	v.SetDefault("grpc.server.enable_request_id", false)
+	v.SetDefault("grpc.server.interceptor_order", []string{"recovery", "logging", "metrics"})
+	v.SetDefault("grpc.server.enable_validation", false)
+	v.SetDefault("grpc.server.deprecated_methods", []string{})
+	v.SetDefault("grpc.server.deprecation_header", "")
+	v.SetDefault("grpc.server.required_metadata", []string{})
+	v.SetDefault("grpc.server.method_acl.allow", []string{})
+	v.SetDefault("grpc.server.method_acl.deny", []string{})
+	v.SetDefault("grpc.server.method_acl.code", "UNIMPLEMENTED")
+	v.SetDefault("grpc.server.reflection_auth.enabled", false)
+	v.SetDefault("grpc.server.reflection_auth.metadata_key", "x-reflection-token")
+	v.SetDefault("grpc.server.reflection_auth.token", "")
+	v.SetDefault("grpc.server.log_payloads", false)
+	v.SetDefault("grpc.server.log_payload_max_size", 1024)
+	v.SetDefault("grpc.server.enable_server_identity", false)
+	v.SetDefault("grpc.server.server_identity_header", "server-id")
+	v.SetDefault("grpc.server.server_identity", "")
 	
 	// gRPC 客户端默认值
 	v.SetDefault("grpc.client.timeout", 30)
 	v.SetDefault("grpc.client.max_retries", 3)
 	v.SetDefault("grpc.client.load_balancing", "round_robin")
+	v.SetDefault("grpc.client.local_zone", "")
+	v.SetDefault("grpc.client.slow_start_window", 0)
+	v.SetDefault("grpc.client.idle_eviction_timeout", 0)
 	v.SetDefault("grpc.client.max_recv_msg_size", 4*1024*1024) // 4MB
 	v.SetDefault("grpc.client.max_send_msg_size", 4*1024*1024) // 4MB
 	v.SetDefault("grpc.client.keepalive_time", 30)
 	v.SetDefault("grpc.client.keepalive_timeout", 5)
+	v.SetDefault("grpc.client.initial_window_size", 0)
+	v.SetDefault("grpc.client.initial_conn_window_size", 0)
+	v.SetDefault("grpc.client.max_header_list_size", 0)
 	v.SetDefault("grpc.client.permit_without_stream", false)
+	v.SetDefault("grpc.client.block_on_connect", false)
+	v.SetDefault("grpc.client.use_service_config", true)
+	v.SetDefault("grpc.client.warm_up_concurrency", 4)
 	v.SetDefault("grpc.client.enable_compression", false)
 	v.SetDefault("grpc.client.compression_level", "gzip")
 	v.SetDefault("grpc.client.enable_logging", true)
 	v.SetDefault("grpc.client.enable_metrics", true)
 	v.SetDefault("grpc.client.enable_tracing", false)
+	v.SetDefault("grpc.client.enable_request_id", false)
+	v.SetDefault("grpc.client.enable_deadline_propagation", false)
+	v.SetDefault("grpc.client.deadline_hop_budget", 50)
 	
 	// 重试策略默认值
 	v.SetDefault("grpc.client.retry_policy.max_attempts", 3)
@@ -218,19 +656,49 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("grpc.client.retry_policy.max_backoff", "30s")
 	v.SetDefault("grpc.client.retry_policy.backoff_multiplier", 2.0)
 	v.SetDefault("grpc.client.retry_policy.retryable_status_codes", []string{"UNAVAILABLE", "DEADLINE_EXCEEDED"})
+	v.SetDefault("grpc.client.retry_policy.throttle_max_tokens", 0)
+	v.SetDefault("grpc.client.retry_policy.throttle_token_ratio", 0.1)
 	
 	v.SetDefault("discovery.type", "etcd")
 	v.SetDefault("discovery.endpoints", []string{"localhost:2379"})
 	v.SetDefault("discovery.namespace", "/grpc-kit")
+	v.SetDefault("discovery.dial_timeout", 5)
+	v.SetDefault("discovery.dial_keepalive_time", 0)
+	v.SetDefault("discovery.cache_ttl", 0)
+	v.SetDefault("discovery.fail_fast", true)
+	v.SetDefault("discovery.advertise_address", "")
+	v.SetDefault("discovery.advertise_port", 0)
+	v.SetDefault("discovery.metadata.weight", 0)
+	v.SetDefault("discovery.metadata.zone", "")
+	v.SetDefault("discovery.metadata.version", "1.0.0")
+	v.SetDefault("discovery.metadata.protocol", "grpc")
+	v.SetDefault("discovery.metadata.health_service", "")
+	v.SetDefault("discovery.health_check.type", "grpc")
+	v.SetDefault("discovery.health_check.interval", 10)
+	v.SetDefault("discovery.health_check.timeout", 3)
+	v.SetDefault("discovery.health_check.deregister_after", 30)
+	v.SetDefault("discovery.health_check.http_path", "/health")
 	
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
+	v.SetDefault("logging.development", false)
+	v.SetDefault("logging.output_paths", []string{"stdout"})
+	v.SetDefault("logging.error_output_paths", []string{"stderr"})
+	v.SetDefault("logging.sampling.enabled", false)
+	v.SetDefault("logging.sampling.initial", 100)
+	v.SetDefault("logging.sampling.thereafter", 100)
+	v.SetDefault("logging.access_log.enabled", false)
+	v.SetDefault("logging.access_log.output_paths", []string{"stdout"})
 	
 	v.SetDefault("tls.enabled", false)
 	
 	v.SetDefault("metrics.enabled", true)
 	v.SetDefault("metrics.port", 8081)
 	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.http_timeouts.read_header_timeout", 5)
+	v.SetDefault("metrics.http_timeouts.read_timeout", 10)
+	v.SetDefault("metrics.http_timeouts.write_timeout", 30)
+	v.SetDefault("metrics.http_timeouts.idle_timeout", 60)
 	
 	v.SetDefault("auto_register.enabled", false)
 	v.SetDefault("auto_register.scan_dirs", []string{"./pkg/services", "./internal/services"})
@@ -244,37 +712,76 @@ func setDefaultValues(config *Config) {
 	config.Server.Port = 8080
 	config.Server.GRPCPort = 9090
 	config.Server.Host = "0.0.0.0"
+	config.Server.ReusePort = false
+	config.Server.MultiplexHTTP = false
+	config.Server.AutoPort = false
+	config.Server.GrpcWeb.Enabled = false
+	config.Server.GrpcWeb.Port = 8082
 	
 	// gRPC 服务端默认值
 	config.GRPC.Server.MaxRecvMsgSize = 4 * 1024 * 1024
 	config.GRPC.Server.MaxSendMsgSize = 4 * 1024 * 1024
 	config.GRPC.Server.MaxConcurrentStreams = 100
 	config.GRPC.Server.ConnectionTimeout = 120
+	config.GRPC.Server.EnableMessageSizeCheck = false
+	config.GRPC.Server.SlowThreshold = 0
 	config.GRPC.Server.KeepaliveTime = 30
 	config.GRPC.Server.KeepaliveTimeout = 5
 	config.GRPC.Server.KeepaliveMinTime = 5
+	config.GRPC.Server.MaxConnectionIdle = 0
+	config.GRPC.Server.MaxConnectionAge = 0
+	config.GRPC.Server.MaxConnectionAgeGrace = 0
+	config.GRPC.Server.MaxConnections = 0
+	config.GRPC.Server.InitialWindowSize = 0
+	config.GRPC.Server.InitialConnWindowSize = 0
+	config.GRPC.Server.MaxHeaderListSize = 0
+	config.GRPC.Server.DisableDefaultHealth = false
 	config.GRPC.Server.EnableReflection = false
+	config.GRPC.Server.EnableChannelz = false
 	config.GRPC.Server.EnableCompression = false
 	config.GRPC.Server.CompressionLevel = "gzip"
 	config.GRPC.Server.EnableLogging = true
 	config.GRPC.Server.EnableMetrics = true
 	config.GRPC.Server.EnableRecovery = true
 	config.GRPC.Server.EnableTracing = false
+	config.GRPC.Server.EnableRequestID = false
+	config.GRPC.Server.InterceptorOrder = []string{"recovery", "logging", "metrics"}
+	config.GRPC.Server.EnableValidation = false
+	config.GRPC.Server.LogPayloads = false
+	config.GRPC.Server.LogPayloadMaxSize = 1024
+	config.GRPC.Server.EnableServerIdentity = false
+	config.GRPC.Server.ServerIdentityHeader = "server-id"
+	config.GRPC.Server.MethodACL.Code = "UNIMPLEMENTED"
+	config.GRPC.Server.ReflectionAuth.Enabled = false
+	config.GRPC.Server.ReflectionAuth.MetadataKey = "x-reflection-token"
+	config.GRPC.Server.ReflectionAuth.Token = ""
 	
 	// gRPC 客户端默认值
 	config.GRPC.Client.Timeout = 30
 	config.GRPC.Client.MaxRetries = 3
 	config.GRPC.Client.LoadBalancing = "round_robin"
+	config.GRPC.Client.LocalZone = ""
+	config.GRPC.Client.SlowStartWindow = 0
+	config.GRPC.Client.IdleEvictionTimeout = 0
 	config.GRPC.Client.MaxRecvMsgSize = 4 * 1024 * 1024
 	config.GRPC.Client.MaxSendMsgSize = 4 * 1024 * 1024
 	config.GRPC.Client.KeepaliveTime = 30
 	config.GRPC.Client.KeepaliveTimeout = 5
+	config.GRPC.Client.InitialWindowSize = 0
+	config.GRPC.Client.InitialConnWindowSize = 0
+	config.GRPC.Client.MaxHeaderListSize = 0
 	config.GRPC.Client.PermitWithoutStream = false
+	config.GRPC.Client.BlockOnConnect = false
+	config.GRPC.Client.UseServiceConfig = true
+	config.GRPC.Client.WarmUpConcurrency = 4
 	config.GRPC.Client.EnableCompression = false
 	config.GRPC.Client.CompressionLevel = "gzip"
 	config.GRPC.Client.EnableLogging = true
 	config.GRPC.Client.EnableMetrics = true
 	config.GRPC.Client.EnableTracing = false
+	config.GRPC.Client.EnableRequestID = false
+	config.GRPC.Client.EnableDeadlinePropagation = false
+	config.GRPC.Client.DeadlineHopBudget = 50
 	
 	// 重试策略默认值
 	config.GRPC.Client.RetryPolicy.MaxAttempts = 3
@@ -282,19 +789,46 @@ func setDefaultValues(config *Config) {
 	config.GRPC.Client.RetryPolicy.MaxBackoff = "30s"
 	config.GRPC.Client.RetryPolicy.BackoffMultiplier = 2.0
 	config.GRPC.Client.RetryPolicy.RetryableStatusCodes = []string{"UNAVAILABLE", "DEADLINE_EXCEEDED"}
+	config.GRPC.Client.RetryPolicy.ThrottleMaxTokens = 0
+	config.GRPC.Client.RetryPolicy.ThrottleTokenRatio = 0.1
 	
 	config.Discovery.Type = "etcd"
 	config.Discovery.Endpoints = []string{"localhost:2379"}
 	config.Discovery.Namespace = "/grpc-kit"
+	config.Discovery.DialTimeout = 5
+	config.Discovery.DialKeepAliveTime = 0
+	config.Discovery.CacheTTL = 0
+	config.Discovery.FailFast = true
+	config.Discovery.AdvertiseAddress = ""
+	config.Discovery.AdvertisePort = 0
+	config.Discovery.Metadata.Version = "1.0.0"
+	config.Discovery.Metadata.Protocol = "grpc"
+	config.Discovery.HealthCheck.Type = "grpc"
+	config.Discovery.HealthCheck.Interval = 10
+	config.Discovery.HealthCheck.Timeout = 3
+	config.Discovery.HealthCheck.DeregisterAfter = 30
+	config.Discovery.HealthCheck.HTTPPath = "/health"
 	
 	config.Logging.Level = "info"
 	config.Logging.Format = "json"
+	config.Logging.Development = false
+	config.Logging.OutputPaths = []string{"stdout"}
+	config.Logging.ErrorOutputPaths = []string{"stderr"}
+	config.Logging.Sampling.Enabled = false
+	config.Logging.Sampling.Initial = 100
+	config.Logging.Sampling.Thereafter = 100
+	config.Logging.AccessLog.Enabled = false
+	config.Logging.AccessLog.OutputPaths = []string{"stdout"}
 	
 	config.TLS.Enabled = false
 	
 	config.Metrics.Enabled = true
 	config.Metrics.Port = 8081
 	config.Metrics.Path = "/metrics"
+	config.Metrics.HTTPTimeouts.ReadHeaderTimeout = 5
+	config.Metrics.HTTPTimeouts.ReadTimeout = 10
+	config.Metrics.HTTPTimeouts.WriteTimeout = 30
+	config.Metrics.HTTPTimeouts.IdleTimeout = 60
 	
 	config.AutoRegister.Enabled = false
 	config.AutoRegister.ScanDirs = []string{"./pkg/services", "./internal/services"}
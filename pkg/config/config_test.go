@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -27,6 +28,10 @@ func TestLoad(t *testing.T) {
 	if cfg.Discovery.Type != "etcd" {
 		t.Errorf("Expected discovery type to be etcd, got %s", cfg.Discovery.Type)
 	}
+
+	if !cfg.Discovery.FailFast {
+		t.Error("Expected discovery fail_fast to default to true")
+	}
 }
 
 func TestEnvironmentVariables(t *testing.T) {
@@ -83,12 +88,14 @@ func TestDefaultGRPCServerConfig(t *testing.T) {
 	assert.Equal(t, 30, config.GRPC.Server.KeepaliveTime)
 	assert.Equal(t, 5, config.GRPC.Server.KeepaliveTimeout)
 	assert.Equal(t, 5, config.GRPC.Server.KeepaliveMinTime)
+	assert.False(t, config.GRPC.Server.DisableDefaultHealth)
 	assert.False(t, config.GRPC.Server.EnableReflection)
 	assert.False(t, config.GRPC.Server.EnableCompression)
 	assert.Equal(t, "gzip", config.GRPC.Server.CompressionLevel)
 	assert.True(t, config.GRPC.Server.EnableLogging)
 	assert.True(t, config.GRPC.Server.EnableMetrics)
 	assert.True(t, config.GRPC.Server.EnableRecovery)
+	assert.Equal(t, []string{"recovery", "logging", "metrics"}, config.GRPC.Server.InterceptorOrder)
 	assert.False(t, config.GRPC.Server.EnableTracing)
 }
 
@@ -103,12 +110,15 @@ func TestDefaultGRPCClientConfig(t *testing.T) {
 	assert.Equal(t, 30, config.GRPC.Client.KeepaliveTime)
 	assert.Equal(t, 5, config.GRPC.Client.KeepaliveTimeout)
 	assert.False(t, config.GRPC.Client.PermitWithoutStream)
+	assert.True(t, config.GRPC.Client.UseServiceConfig)
 	assert.Equal(t, "round_robin", config.GRPC.Client.LoadBalancing)
 	assert.False(t, config.GRPC.Client.EnableCompression)
 	assert.Equal(t, "gzip", config.GRPC.Client.CompressionLevel)
 	assert.True(t, config.GRPC.Client.EnableLogging)
 	assert.True(t, config.GRPC.Client.EnableMetrics)
 	assert.False(t, config.GRPC.Client.EnableTracing)
+	assert.False(t, config.GRPC.Client.EnableDeadlinePropagation)
+	assert.Equal(t, 50, config.GRPC.Client.DeadlineHopBudget)
 }
 
 func TestDefaultRetryPolicyConfig(t *testing.T) {
@@ -123,6 +133,8 @@ func TestDefaultRetryPolicyConfig(t *testing.T) {
 	assert.Equal(t, 2.0, retryPolicy.BackoffMultiplier)
 	assert.Contains(t, retryPolicy.RetryableStatusCodes, "UNAVAILABLE")
 	assert.Contains(t, retryPolicy.RetryableStatusCodes, "DEADLINE_EXCEEDED")
+	assert.Equal(t, 0, retryPolicy.ThrottleMaxTokens)
+	assert.Equal(t, 0.1, retryPolicy.ThrottleTokenRatio)
 }
 
 func TestRetryPolicyDurations(t *testing.T) {
@@ -141,4 +153,199 @@ func TestRetryPolicyDurations(t *testing.T) {
 	
 	// 确保最大退避时间大于初始退避时间
 	assert.Greater(t, maxBackoff, initialBackoff)
-}
\ No newline at end of file
+}
+
+func TestWatch(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "application.yml")
+	if err := os.WriteFile(configFile, []byte("grpc:\n  server:\n    enable_logging: true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	// 避免影响其他依赖全局配置的测试
+	t.Cleanup(func() { setGlobalConfig(nil) })
+
+	changes := make(chan *Config, 1)
+	cfg, err := Watch(configFile, func(reloaded *Config, err error) {
+		if err == nil {
+			select {
+			case changes <- reloaded:
+			default:
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("Failed to watch config: %v", err)
+	}
+	assert.True(t, cfg.GRPC.Server.EnableLogging)
+
+	// 重载期间并发读取全局配置，配合 -race 检查数据竞争
+	done := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = Get().GRPC.Server.EnableLogging
+			}
+		}
+	}()
+	defer func() {
+		close(done)
+		<-readerDone
+	}()
+
+	if err := os.WriteFile(configFile, []byte("grpc:\n  server:\n    enable_logging: false\n"), 0644); err != nil {
+		t.Fatalf("Failed to update config file: %v", err)
+	}
+
+	select {
+	case reloaded := <-changes:
+		assert.False(t, reloaded.GRPC.Server.EnableLogging)
+		assert.Equal(t, reloaded, Get())
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timeout waiting for config change")
+	}
+}
+
+func TestDefaultLoggingSampling(t *testing.T) {
+	cfg, err := Load("")
+	assert.NoError(t, err)
+
+	// 默认关闭采样，避免调试时丢失重复日志
+	assert.False(t, cfg.Logging.Sampling.Enabled)
+	assert.Equal(t, 100, cfg.Logging.Sampling.Initial)
+	assert.Equal(t, 100, cfg.Logging.Sampling.Thereafter)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "application.yaml")
+	content := "logging:\n  sampling:\n    enabled: true\n    initial: 5\n    thereafter: 50\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	cfg, err = Load(path)
+	assert.NoError(t, err)
+	assert.True(t, cfg.Logging.Sampling.Enabled)
+	assert.Equal(t, 5, cfg.Logging.Sampling.Initial)
+	assert.Equal(t, 50, cfg.Logging.Sampling.Thereafter)
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{}
+	setDefaultValues(cfg)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Server.GRPCPort = 70000
+	cfg.Discovery.Type = "zookeeper"
+	cfg.TLS.Enabled = true
+	cfg.GRPC.Client.RetryPolicy.InitialBackoff = "soon"
+	cfg.GRPC.Server.MaxConnections = -1
+	cfg.Discovery.CacheTTL = -1
+	cfg.Discovery.HealthCheck.Type = "tcp"
+	cfg.GRPC.Server.RequiredMetadata = []string{""}
+	cfg.Metrics.LatencyBuckets = []float64{0.1, 0.05}
+	cfg.Server.MultiplexHTTP = true
+	cfg.Logging.Rotation.MaxSize = -1
+	cfg.Logging.Sampling = LogSamplingConfig{Enabled: true, Initial: -1}
+	cfg.Discovery.AdvertisePort = 70000
+	cfg.Discovery.Backends = []DiscoveryBackendConfig{{Type: "consul"}}
+	cfg.GRPC.Server.MethodACL.Deny = []string{"/pkg.Service/[*"}
+	cfg.Discovery.Metadata.Weight = -1
+	cfg.Logging.AccessLog = AccessLogConfig{Enabled: true}
+	cfg.GRPC.Server.ReflectionAuth = ReflectionAuthConfig{Enabled: true, MetadataKey: "x-reflection-token"}
+	cfg.GRPC.Server.InitialWindowSize = 1024
+	cfg.GRPC.Client.InitialConnWindowSize = -1
+	cfg.GRPC.Server.InterceptorOrder = []string{"recovery", "auth"}
+	cfg.GRPC.Client.SlowStartWindow = 30
+	cfg.GRPC.Client.IdleEvictionTimeout = -1
+	cfg.Server.GrpcWeb = GrpcWebConfig{Enabled: true, Port: cfg.Server.GRPCPort}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	for _, expected := range []string{"grpc_port", "discovery.type", "tls.cert_file", "initial_backoff", "max_connections", "cache_ttl", "health_check.type", "required_metadata", "latency_buckets", "multiplex_http", "logging.rotation", "logging.sampling", "advertise_port", "discovery.backends[0].endpoints", "method_acl.deny[0]", "discovery.metadata.weight", "logging.access_log", "reflection_auth", "grpc.server.initial_window_size", "grpc.client.initial_conn_window_size", "interceptor_order[1]", "slow_start_window", "grpc_web.port", "idle_eviction_timeout"} {
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestLoadWithEnvOverlay(t *testing.T) {
+	dir := t.TempDir()
+	base := `server:
+  grpc_port: 9090
+  host: "0.0.0.0"
+grpc:
+  server:
+    max_concurrent_streams: 100
+    enable_reflection: true
+logging:
+  level: "debug"
+`
+	overlay := `grpc:
+  server:
+    enable_reflection: false
+logging:
+  level: "warn"
+`
+	baseFile := filepath.Join(dir, "application.yml")
+	assert.NoError(t, os.WriteFile(baseFile, []byte(base), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "application-prod.yml"), []byte(overlay), 0644))
+
+	t.Cleanup(func() { setGlobalConfig(nil) })
+
+	// 未设置环境时只加载基础配置
+	cfg, err := Load(baseFile)
+	assert.NoError(t, err)
+	assert.True(t, cfg.GRPC.Server.EnableReflection)
+	assert.Equal(t, "debug", cfg.Logging.Level)
+
+	t.Setenv(EnvProfile, "prod")
+	cfg, err = Load(baseFile)
+	assert.NoError(t, err)
+
+	// 环境配置覆盖基础配置
+	assert.False(t, cfg.GRPC.Server.EnableReflection)
+	assert.Equal(t, "warn", cfg.Logging.Level)
+	// 未覆盖的键保持不变
+	assert.Equal(t, 9090, cfg.Server.GRPCPort)
+	assert.Equal(t, uint32(100), cfg.GRPC.Server.MaxConcurrentStreams)
+
+	// 环境变量优先级最高
+	t.Setenv("GRPC_KIT_LOGGING_LEVEL", "error")
+	cfg, err = Load(baseFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "error", cfg.Logging.Level)
+
+	// 环境配置文件不存在时忽略
+	t.Setenv(EnvProfile, "staging")
+	cfg, err = Load(baseFile)
+	assert.NoError(t, err)
+	assert.True(t, cfg.GRPC.Server.EnableReflection)
+}
+
+func TestLoadExpandsEnvVars(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "application.yaml")
+	content := `server:
+  grpc_port: ${PORT}
+discovery:
+  namespace: "$NAMESPACE"
+  endpoints:
+    - "${ETCD_HOST}:2379"
+logging:
+  level: "${UNSET_LEVEL}"
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	t.Cleanup(func() { setGlobalConfig(nil) })
+
+	t.Setenv("PORT", "9191")
+	t.Setenv("NAMESPACE", "staging")
+	t.Setenv("ETCD_HOST", "etcd.internal")
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 9191, cfg.Server.GRPCPort)
+	assert.Equal(t, "staging", cfg.Discovery.Namespace)
+	assert.Equal(t, []string{"etcd.internal:2379"}, cfg.Discovery.Endpoints)
+	// 未设置的变量展开为空字符串
+	assert.Equal(t, "", cfg.Logging.Level)
+}
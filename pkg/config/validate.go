@@ -0,0 +1,257 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Validate 校验配置，返回所有发现的问题
+func (c *Config) Validate() error {
+	var errs []error
+	
+	// 端口
+	if !validPort(c.Server.GRPCPort) {
+		errs = append(errs, fmt.Errorf("server.grpc_port %d is out of range", c.Server.GRPCPort))
+	}
+	if c.Server.Port != 0 && !validPort(c.Server.Port) {
+		errs = append(errs, fmt.Errorf("server.port %d is out of range", c.Server.Port))
+	}
+	if c.Server.Port != 0 && c.Server.Port == c.Server.GRPCPort {
+		errs = append(errs, fmt.Errorf("server.port and server.grpc_port must differ, both are %d", c.Server.Port))
+	}
+	if web := c.Server.GrpcWeb; web.Enabled {
+		if !validPort(web.Port) {
+			errs = append(errs, fmt.Errorf("server.grpc_web.port %d is out of range", web.Port))
+		} else if web.Port == c.Server.GRPCPort {
+			errs = append(errs, fmt.Errorf("server.grpc_web.port and server.grpc_port must differ, both are %d", web.Port))
+		}
+	}
+	if c.Metrics.Enabled && !validPort(c.Metrics.Port) {
+		errs = append(errs, fmt.Errorf("metrics.port %d is out of range", c.Metrics.Port))
+	}
+	names := map[string]bool{"default": true}
+	for i, listener := range c.Server.Listeners {
+		if listener.Name == "" {
+			errs = append(errs, fmt.Errorf("server.listeners[%d].name must not be empty", i))
+		} else if names[listener.Name] {
+			errs = append(errs, fmt.Errorf("server.listeners[%d].name %q is duplicated", i, listener.Name))
+		}
+		names[listener.Name] = true
+		if !validPort(listener.Port) {
+			errs = append(errs, fmt.Errorf("server.listeners[%d].port %d is out of range", i, listener.Port))
+		}
+	}
+	
+	// gRPC 服务端
+	if c.GRPC.Server.MaxRecvMsgSize < 0 || c.GRPC.Server.MaxSendMsgSize < 0 {
+		errs = append(errs, fmt.Errorf("grpc.server message size limits must not be negative"))
+	}
+	if c.GRPC.Server.MaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("grpc.server.max_connections must not be negative"))
+	}
+	if err := validateWindowSize("grpc.server.initial_window_size", c.GRPC.Server.InitialWindowSize); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateWindowSize("grpc.server.initial_conn_window_size", c.GRPC.Server.InitialConnWindowSize); err != nil {
+		errs = append(errs, err)
+	}
+	if c.GRPC.Server.EnableCompression && !validCompression(c.GRPC.Server.CompressionLevel) {
+		errs = append(errs, fmt.Errorf("grpc.server.compression_level %q is not supported, use gzip or deflate", c.GRPC.Server.CompressionLevel))
+	}
+	for i, key := range c.GRPC.Server.RequiredMetadata {
+		if key == "" {
+			errs = append(errs, fmt.Errorf("grpc.server.required_metadata[%d] must not be empty", i))
+		}
+	}
+	errs = append(errs, validateMethodPatterns("grpc.server.method_acl.allow", c.GRPC.Server.MethodACL.Allow)...)
+	errs = append(errs, validateMethodPatterns("grpc.server.method_acl.deny", c.GRPC.Server.MethodACL.Deny)...)
+	switch c.GRPC.Server.MethodACL.Code {
+	case "", "UNIMPLEMENTED", "PERMISSION_DENIED":
+	default:
+		errs = append(errs, fmt.Errorf("grpc.server.method_acl.code %q is not supported, use UNIMPLEMENTED or PERMISSION_DENIED", c.GRPC.Server.MethodACL.Code))
+	}
+	if auth := c.GRPC.Server.ReflectionAuth; auth.Enabled && (auth.MetadataKey == "" || auth.Token == "") {
+		errs = append(errs, fmt.Errorf("grpc.server.reflection_auth.metadata_key and token are required when reflection auth is enabled"))
+	}
+	seenInterceptors := make(map[string]bool)
+	for i, name := range c.GRPC.Server.InterceptorOrder {
+		switch {
+		case name != "recovery" && name != "logging" && name != "metrics":
+			errs = append(errs, fmt.Errorf("grpc.server.interceptor_order[%d] %q is not supported, use recovery, logging or metrics", i, name))
+		case seenInterceptors[name]:
+			errs = append(errs, fmt.Errorf("grpc.server.interceptor_order[%d] %q is duplicated", i, name))
+		}
+		seenInterceptors[name] = true
+	}
+	
+	// gRPC 客户端
+	if c.GRPC.Client.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.timeout must not be negative"))
+	}
+	if c.GRPC.Client.EnableCompression && !validCompression(c.GRPC.Client.CompressionLevel) {
+		errs = append(errs, fmt.Errorf("grpc.client.compression_level %q is not supported, use gzip or deflate", c.GRPC.Client.CompressionLevel))
+	}
+	if c.GRPC.Client.DeadlineHopBudget < 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.deadline_hop_budget must not be negative"))
+	}
+	if c.GRPC.Client.IdleEvictionTimeout < 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.idle_eviction_timeout must not be negative"))
+	}
+	if c.GRPC.Client.SlowStartWindow < 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.slow_start_window must not be negative"))
+	} else if c.GRPC.Client.SlowStartWindow > 0 && c.GRPC.Client.LoadBalancing != "metadata_weighted_round_robin" {
+		errs = append(errs, fmt.Errorf("grpc.client.slow_start_window requires load_balancing metadata_weighted_round_robin, got %q", c.GRPC.Client.LoadBalancing))
+	}
+	if err := validateWindowSize("grpc.client.initial_window_size", c.GRPC.Client.InitialWindowSize); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateWindowSize("grpc.client.initial_conn_window_size", c.GRPC.Client.InitialConnWindowSize); err != nil {
+		errs = append(errs, err)
+	}
+	errs = append(errs, c.GRPC.Client.RetryPolicy.validate()...)
+	
+	// 服务发现
+	switch c.Discovery.Type {
+	case "":
+	case "etcd", "consul":
+		if len(c.Discovery.Endpoints) == 0 {
+			errs = append(errs, fmt.Errorf("discovery.endpoints must not be empty for %s", c.Discovery.Type))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("discovery.type %q is not supported, use etcd or consul", c.Discovery.Type))
+	}
+	for i, backend := range c.Discovery.Backends {
+		switch {
+		case c.Discovery.Type == "":
+			errs = append(errs, fmt.Errorf("discovery.backends requires discovery.type"))
+		case backend.Type != "etcd" && backend.Type != "consul":
+			errs = append(errs, fmt.Errorf("discovery.backends[%d].type %q is not supported, use etcd or consul", i, backend.Type))
+		case len(backend.Endpoints) == 0:
+			errs = append(errs, fmt.Errorf("discovery.backends[%d].endpoints must not be empty", i))
+		}
+	}
+	if c.Discovery.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("discovery.cache_ttl must not be negative"))
+	}
+	if c.Discovery.AdvertisePort < 0 || c.Discovery.AdvertisePort > 65535 {
+		errs = append(errs, fmt.Errorf("discovery.advertise_port %d is out of range", c.Discovery.AdvertisePort))
+	}
+	if c.Discovery.Metadata.Weight < 0 {
+		errs = append(errs, fmt.Errorf("discovery.metadata.weight must not be negative"))
+	}
+	switch c.Discovery.HealthCheck.Type {
+	case "", "grpc", "http", "ttl":
+	default:
+		errs = append(errs, fmt.Errorf("discovery.health_check.type %q is not supported, use grpc, http or ttl", c.Discovery.HealthCheck.Type))
+	}
+	if c.Discovery.HealthCheck.Interval < 0 || c.Discovery.HealthCheck.Timeout < 0 || c.Discovery.HealthCheck.DeregisterAfter < 0 {
+		errs = append(errs, fmt.Errorf("discovery.health_check durations must not be negative"))
+	}
+	
+	// 日志
+	switch c.Logging.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logging.level %q is not supported", c.Logging.Level))
+	}
+	if r := c.Logging.Rotation; r.MaxSize < 0 || r.MaxAge < 0 || r.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("logging.rotation values must not be negative"))
+	}
+	if s := c.Logging.Sampling; s.Enabled && (s.Initial < 0 || s.Thereafter < 0) {
+		errs = append(errs, fmt.Errorf("logging.sampling values must not be negative"))
+	}
+	if a := c.Logging.AccessLog; a.Enabled && len(a.OutputPaths) == 0 {
+		errs = append(errs, fmt.Errorf("logging.access_log.output_paths is required when access log is enabled"))
+	}
+	
+	// 指标
+	for i := 1; i < len(c.Metrics.LatencyBuckets); i++ {
+		if c.Metrics.LatencyBuckets[i] <= c.Metrics.LatencyBuckets[i-1] {
+			errs = append(errs, fmt.Errorf("metrics.latency_buckets must be in strictly increasing order"))
+			break
+		}
+	}
+	
+	// TLS
+	if c.TLS.Enabled && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("tls.cert_file and tls.key_file are required when tls is enabled"))
+	}
+	if c.TLS.Enabled && c.Server.MultiplexHTTP {
+		errs = append(errs, fmt.Errorf("server.multiplex_http cannot be used with tls"))
+	}
+	if c.TLS.Enabled && c.Server.GrpcWeb.Enabled {
+		errs = append(errs, fmt.Errorf("server.grpc_web cannot be used with tls"))
+	}
+	
+	return errors.Join(errs...)
+}
+
+// validate 校验重试策略
+func (r RetryPolicyConfig) validate() []error {
+	var errs []error
+	
+	if r.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.retry_policy.max_attempts must not be negative"))
+	}
+	for _, backoff := range []struct{ name, value string }{
+		{"initial_backoff", r.InitialBackoff},
+		{"max_backoff", r.MaxBackoff},
+	} {
+		if backoff.value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(backoff.value); err != nil {
+			errs = append(errs, fmt.Errorf("grpc.client.retry_policy.%s %q is not a valid duration", backoff.name, backoff.value))
+		}
+	}
+	if r.BackoffMultiplier < 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.retry_policy.backoff_multiplier must not be negative"))
+	}
+	if r.ThrottleMaxTokens < 0 || r.ThrottleMaxTokens > 1000 {
+		errs = append(errs, fmt.Errorf("grpc.client.retry_policy.throttle_max_tokens %d is out of range, use 0-1000", r.ThrottleMaxTokens))
+	}
+	if r.ThrottleMaxTokens > 0 && r.ThrottleTokenRatio <= 0 {
+		errs = append(errs, fmt.Errorf("grpc.client.retry_policy.throttle_token_ratio must be positive when throttling is enabled"))
+	}
+	
+	return errs
+}
+
+// validPort 检查端口范围
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// validCompression 检查压缩算法
+func validCompression(name string) bool {
+	return name == "" || name == "gzip" || name == "deflate"
+}
+
+// validateMethodPatterns 校验方法名模式，模式需以 / 开头并能被 path.Match 解析
+func validateMethodPatterns(field string, patterns []string) []error {
+	var errs []error
+	for i, pattern := range patterns {
+		if !strings.HasPrefix(pattern, "/") {
+			errs = append(errs, fmt.Errorf("%s[%d] %q must start with /", field, i, pattern))
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d] %q is not a valid pattern", field, i, pattern))
+		}
+	}
+	return errs
+}
+
+// minWindowSize gRPC 接受的最小流控窗口，更小的值会被忽略
+const minWindowSize = 65535
+
+// validateWindowSize 校验流控窗口大小，0 表示使用默认值，其余值不能小于 65535
+func validateWindowSize(field string, size int32) error {
+	if size != 0 && size < minWindowSize {
+		return fmt.Errorf("%s must be 0 or at least %d, got %d", field, minWindowSize, size)
+	}
+	return nil
+}
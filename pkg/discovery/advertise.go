@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+)
+
+// outboundIP 返回本机访问外部网络时使用的 IP，测试中可替换
+var outboundIP = detectOutboundIP
+
+// AdvertiseAddress 返回注册到服务发现的地址和端口
+// 优先使用 discovery.advertise_address 和 discovery.advertise_port；
+// 未配置地址且监听地址为空或 0.0.0.0、:: 等通配地址时，使用本机出站 IP，避免客户端拿到无法连接的地址
+func AdvertiseAddress(cfg *config.Config) (string, int, error) {
+	port := cfg.Server.GRPCPort
+	if cfg.Discovery.AdvertisePort > 0 {
+		port = cfg.Discovery.AdvertisePort
+	}
+
+	if cfg.Discovery.AdvertiseAddress != "" {
+		return cfg.Discovery.AdvertiseAddress, port, nil
+	}
+	if !isUnspecifiedHost(cfg.Server.Host) {
+		return cfg.Server.Host, port, nil
+	}
+
+	ip, err := outboundIP()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to resolve advertise address for host %q: %w", cfg.Server.Host, err)
+	}
+	return ip.String(), port, nil
+}
+
+// isUnspecifiedHost 检查监听地址是否为空或通配地址
+func isUnspecifiedHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip == nil && len(host) > 2 && host[0] == '[' && host[len(host)-1] == ']' {
+		ip = net.ParseIP(host[1 : len(host)-1])
+	}
+	return ip != nil && ip.IsUnspecified()
+}
+
+// detectOutboundIP 通过 UDP "连接"（不发送数据）获取默认路由使用的本机 IP，
+// 没有默认路由时退回第一个非回环网卡地址
+func detectOutboundIP() (net.IP, error) {
+	if conn, err := net.Dial("udp", "8.8.8.8:80"); err == nil {
+		defer conn.Close()
+		if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsUnspecified() {
+			return addr.IP, nil
+		}
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ip := ipNet.IP.To4(); ip != nil {
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("no non-loopback IPv4 address found")
+}
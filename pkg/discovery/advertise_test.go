@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+)
+
+func TestAdvertiseAddress(t *testing.T) {
+	original := outboundIP
+	outboundIP = func() (net.IP, error) { return net.ParseIP("10.1.2.3"), nil }
+	defer func() { outboundIP = original }()
+
+	tests := []struct {
+		name             string
+		host             string
+		advertiseAddress string
+		advertisePort    int
+		wantAddress      string
+		wantPort         int
+	}{
+		{name: "wildcard ipv4", host: "0.0.0.0", wantAddress: "10.1.2.3", wantPort: 9090},
+		{name: "wildcard ipv6", host: "::", wantAddress: "10.1.2.3", wantPort: 9090},
+		{name: "bracketed wildcard", host: "[::]", wantAddress: "10.1.2.3", wantPort: 9090},
+		{name: "empty host", host: "", wantAddress: "10.1.2.3", wantPort: 9090},
+		{name: "concrete host", host: "192.168.1.10", wantAddress: "192.168.1.10", wantPort: 9090},
+		{name: "hostname", host: "svc.internal", wantAddress: "svc.internal", wantPort: 9090},
+		{name: "advertise address", host: "0.0.0.0", advertiseAddress: "public.example.com", wantAddress: "public.example.com", wantPort: 9090},
+		{name: "advertise port", host: "0.0.0.0", advertisePort: 443, wantAddress: "10.1.2.3", wantPort: 443},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Server.Host = tt.host
+			cfg.Server.GRPCPort = 9090
+			cfg.Discovery.AdvertiseAddress = tt.advertiseAddress
+			cfg.Discovery.AdvertisePort = tt.advertisePort
+
+			address, port, err := AdvertiseAddress(cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if address != tt.wantAddress || port != tt.wantPort {
+				t.Errorf("Expected %s:%d, got %s:%d", tt.wantAddress, tt.wantPort, address, port)
+			}
+		})
+	}
+}
+
+func TestAdvertiseAddressResolveError(t *testing.T) {
+	original := outboundIP
+	outboundIP = func() (net.IP, error) { return nil, errors.New("no network") }
+	defer func() { outboundIP = original }()
+
+	cfg := &config.Config{}
+	cfg.Server.Host = "0.0.0.0"
+	if _, _, err := AdvertiseAddress(cfg); err == nil {
+		t.Error("Expected error when outbound IP cannot be resolved")
+	}
+}
+
+func TestDetectOutboundIP(t *testing.T) {
+	ip, err := detectOutboundIP()
+	if err != nil {
+		t.Skipf("No usable network interface: %v", err)
+	}
+	if ip.IsUnspecified() || ip.IsLoopback() {
+		t.Errorf("Expected concrete non-loopback IP, got %s", ip)
+	}
+}
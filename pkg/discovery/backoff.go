@@ -0,0 +1,67 @@
+package discovery
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultRegistrationInitialInterval 注册失败后首次重试的默认间隔
+	DefaultRegistrationInitialInterval = time.Second
+	// DefaultRegistrationMaxInterval 注册重试间隔的默认上限
+	DefaultRegistrationMaxInterval = 30 * time.Second
+	// DefaultRegistrationMultiplier 每次失败后重试间隔的默认增长倍数
+	DefaultRegistrationMultiplier = 2.0
+	// DefaultRegistrationJitter 重试间隔的默认随机抖动比例
+	DefaultRegistrationJitter = 0.2
+)
+
+// RegistrationBackoff 服务注册失败后的重试退避策略，零值字段使用默认值
+// 多个实例同时因注册中心故障而失败时，随机抖动可以避免它们在同一时刻集中重试
+type RegistrationBackoff struct {
+	// InitialInterval 首次重试前的等待时间
+	InitialInterval time.Duration
+	// MaxInterval 重试间隔上限
+	MaxInterval time.Duration
+	// Multiplier 每次失败后重试间隔的增长倍数
+	Multiplier float64
+	// Jitter 随机抖动比例，实际间隔在基础间隔的 [1-Jitter, 1+Jitter] 倍之间
+	Jitter float64
+}
+
+// Next 返回第 attempt 次（从 1 开始）注册失败后的等待时间
+func (b RegistrationBackoff) Next(attempt int) time.Duration {
+	b = b.withDefaults()
+
+	interval := float64(b.InitialInterval)
+	for i := 1; i < attempt && interval < float64(b.MaxInterval); i++ {
+		interval *= b.Multiplier
+	}
+	if interval > float64(b.MaxInterval) {
+		interval = float64(b.MaxInterval)
+	}
+
+	// 在 [1-Jitter, 1+Jitter] 范围内随机缩放
+	interval *= 1 + b.Jitter*(2*rand.Float64()-1)
+	return time.Duration(interval)
+}
+
+// withDefaults 使用默认值填充零值字段
+func (b RegistrationBackoff) withDefaults() RegistrationBackoff {
+	if b.InitialInterval <= 0 {
+		b.InitialInterval = DefaultRegistrationInitialInterval
+	}
+	if b.MaxInterval <= 0 {
+		b.MaxInterval = DefaultRegistrationMaxInterval
+	}
+	if b.MaxInterval < b.InitialInterval {
+		b.MaxInterval = b.InitialInterval
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = DefaultRegistrationMultiplier
+	}
+	if b.Jitter <= 0 || b.Jitter >= 1 {
+		b.Jitter = DefaultRegistrationJitter
+	}
+	return b
+}
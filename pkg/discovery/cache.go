@@ -0,0 +1,151 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CachingRegistry 带 TTL 缓存的注册器装饰器
+// Discover 在 TTL 内直接返回缓存结果，同一服务的并发查询合并为一次请求；
+// Watch 推送的服务列表和本地的注册、注销会刷新缓存，避免返回已知过期的结果
+type CachingRegistry struct {
+	inner Registry
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry 缓存的服务列表
+type cacheEntry struct {
+	services  []*ServiceInfo
+	expiresAt time.Time
+}
+
+// NewCachingRegistry 创建带缓存的注册器，ttl 为缓存有效期
+func NewCachingRegistry(inner Registry, ttl time.Duration) *CachingRegistry {
+	return &CachingRegistry{
+		inner:   inner,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Register 注册服务并使该服务的缓存失效
+func (r *CachingRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	defer r.Invalidate(service.Name)
+	return r.inner.Register(ctx, service)
+}
+
+// Deregister 注销服务并使该服务的缓存失效
+func (r *CachingRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
+	defer r.Invalidate(service.Name)
+	return r.inner.Deregister(ctx, service)
+}
+
+// Discover 发现服务，缓存未过期时直接返回缓存结果
+func (r *CachingRegistry) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	if services, ok := r.lookup(serviceName); ok {
+		return services, nil
+	}
+
+	result, err, _ := r.group.Do(serviceName, func() (interface{}, error) {
+		services, err := r.inner.Discover(ctx, serviceName)
+		if err != nil {
+			return nil, err
+		}
+		r.store(serviceName, services)
+		return services, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyServices(result.([]*ServiceInfo)), nil
+}
+
+// Watch 监听服务变化，推送的服务列表同时写入缓存
+func (r *CachingRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInfo, error) {
+	ch, err := r.inner.Watch(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan []*ServiceInfo, 1)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case services, ok := <-ch:
+				if !ok {
+					return
+				}
+				r.store(serviceName, services)
+				select {
+				case out <- services:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// LeaseLost 转发内部注册器的租约丢失通知，内部注册器未实现 LeaseWatcher 时返回 nil
+func (r *CachingRegistry) LeaseLost(service *ServiceInfo) <-chan struct{} {
+	if watcher, ok := r.inner.(LeaseWatcher); ok {
+		return watcher.LeaseLost(service)
+	}
+	return nil
+}
+
+// Close 关闭内部注册器
+func (r *CachingRegistry) Close() error {
+	return r.inner.Close()
+}
+
+// Invalidate 使指定服务的缓存失效，下次 Discover 会查询内部注册器
+func (r *CachingRegistry) Invalidate(serviceName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, serviceName)
+}
+
+// lookup 读取未过期的缓存
+func (r *CachingRegistry) lookup(serviceName string) ([]*ServiceInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[serviceName]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return copyServices(entry.services), true
+}
+
+// store 写入缓存
+func (r *CachingRegistry) store(serviceName string, services []*ServiceInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[serviceName] = cacheEntry{
+		services:  copyServices(services),
+		expiresAt: time.Now().Add(r.ttl),
+	}
+}
+
+// copyServices 复制服务列表，避免调用方修改缓存内容
+func copyServices(services []*ServiceInfo) []*ServiceInfo {
+	if services == nil {
+		return nil
+	}
+	return append([]*ServiceInfo(nil), services...)
+}
@@ -0,0 +1,125 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingRegistry 记录 Discover 调用次数的注册器
+type countingRegistry struct {
+	discovers atomic.Int32
+	delay     time.Duration
+	watchCh   chan []*ServiceInfo
+}
+
+func (r *countingRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	return nil
+}
+
+func (r *countingRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
+	return nil
+}
+
+func (r *countingRegistry) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	r.discovers.Add(1)
+	time.Sleep(r.delay)
+	return []*ServiceInfo{{Name: serviceName, Address: "127.0.0.1", Port: 8080}}, nil
+}
+
+func (r *countingRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInfo, error) {
+	return r.watchCh, nil
+}
+
+func (r *countingRegistry) Close() error {
+	return nil
+}
+
+func TestCachingRegistryDiscover(t *testing.T) {
+	inner := &countingRegistry{}
+	registry := NewCachingRegistry(inner, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		services, err := registry.Discover(context.Background(), "test-service")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(services) != 1 || services[0].Port != 8080 {
+			t.Fatalf("Unexpected services: %v", services)
+		}
+	}
+
+	if got := inner.discovers.Load(); got != 1 {
+		t.Errorf("Expected inner registry to be queried once, got %d", got)
+	}
+}
+
+func TestCachingRegistryConcurrentDiscover(t *testing.T) {
+	inner := &countingRegistry{delay: 50 * time.Millisecond}
+	registry := NewCachingRegistry(inner, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := registry.Discover(context.Background(), "test-service"); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := inner.discovers.Load(); got != 1 {
+		t.Errorf("Expected concurrent lookups to be coalesced, got %d inner calls", got)
+	}
+}
+
+func TestCachingRegistryExpiry(t *testing.T) {
+	inner := &countingRegistry{}
+	registry := NewCachingRegistry(inner, 20*time.Millisecond)
+
+	registry.Discover(context.Background(), "test-service")
+	time.Sleep(40 * time.Millisecond)
+	registry.Discover(context.Background(), "test-service")
+
+	if got := inner.discovers.Load(); got != 2 {
+		t.Errorf("Expected expired entry to be refreshed, got %d inner calls", got)
+	}
+
+	// 注册会使缓存失效
+	registry.Register(context.Background(), &ServiceInfo{Name: "test-service"})
+	registry.Discover(context.Background(), "test-service")
+	if got := inner.discovers.Load(); got != 3 {
+		t.Errorf("Expected register to invalidate the cache, got %d inner calls", got)
+	}
+}
+
+func TestCachingRegistryWatch(t *testing.T) {
+	inner := &countingRegistry{watchCh: make(chan []*ServiceInfo, 1)}
+	registry := NewCachingRegistry(inner, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := registry.Watch(ctx, "test-service")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	inner.watchCh <- []*ServiceInfo{{Name: "test-service", Address: "10.0.0.1", Port: 9090}}
+	<-ch
+
+	services, err := registry.Discover(context.Background(), "test-service")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(services) != 1 || services[0].Address != "10.0.0.1" {
+		t.Errorf("Expected watch update to be cached, got %v", services)
+	}
+	if got := inner.discovers.Load(); got != 0 {
+		t.Errorf("Expected cached watch result to be served, got %d inner calls", got)
+	}
+}
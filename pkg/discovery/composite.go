@@ -0,0 +1,197 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// CompositeRegistry 同时写入多个注册中心的注册器，用于注册中心迁移（如 etcd 迁移到 consul）期间双写
+// Register、Deregister 发往所有后端；Discover 和 Watch 合并各后端的结果，按地址和端口去重，
+// 先出现的后端优先
+type CompositeRegistry struct {
+	registries []Registry
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewCompositeRegistry 创建组合注册器，registries 按优先级排列
+func NewCompositeRegistry(registries ...Registry) *CompositeRegistry {
+	return &CompositeRegistry{
+		registries: registries,
+		done:       make(chan struct{}),
+	}
+}
+
+// Register 在所有后端注册服务，返回所有失败后端的错误
+// 部分后端失败时已成功的注册不会回滚，调用方重试时重复注册是幂等的
+func (r *CompositeRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	var errs []error
+	for i, registry := range r.registries {
+		if err := registry.Register(ctx, service); err != nil {
+			errs = append(errs, fmt.Errorf("registry %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Deregister 在所有后端注销服务，返回所有失败后端的错误
+func (r *CompositeRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
+	var errs []error
+	for i, registry := range r.registries {
+		if err := registry.Deregister(ctx, service); err != nil {
+			errs = append(errs, fmt.Errorf("registry %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Discover 合并所有后端的发现结果，只要有一个后端成功就返回合并后的结果，全部失败时返回错误
+func (r *CompositeRegistry) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	results := make([][]*ServiceInfo, 0, len(r.registries))
+	var errs []error
+	for i, registry := range r.registries {
+		services, err := registry.Discover(ctx, serviceName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registry %d: %w", i, err))
+			continue
+		}
+		results = append(results, services)
+	}
+	if len(results) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return mergeServices(results), nil
+}
+
+// Watch 监听所有后端的服务变化，任一后端推送时发送合并后的服务列表
+// 部分后端无法监听时只监听其余后端，全部失败时返回错误；所有后端的通道关闭或 ctx 取消后关闭返回的通道
+func (r *CompositeRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInfo, error) {
+	type update struct {
+		index    int
+		services []*ServiceInfo
+	}
+
+	updates := make(chan update)
+	var wg sync.WaitGroup
+	var errs []error
+	for i, registry := range r.registries {
+		ch, err := registry.Watch(ctx, serviceName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registry %d: %w", i, err))
+			continue
+		}
+		wg.Add(1)
+		go func(index int, ch <-chan []*ServiceInfo) {
+			defer wg.Done()
+			for {
+				select {
+				case services, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case updates <- update{index: index, services: services}:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(i, ch)
+	}
+	if len(errs) == len(r.registries) && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	go func() {
+		wg.Wait()
+		close(updates)
+	}()
+
+	out := make(chan []*ServiceInfo, 1)
+	go func() {
+		defer close(out)
+		latest := make([][]*ServiceInfo, len(r.registries))
+		for u := range updates {
+			latest[u.index] = u.services
+			select {
+			case out <- mergeServices(latest):
+			case <-ctx.Done():
+				// 排空 updates，让转发协程退出
+				for range updates {
+				}
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// LeaseLost 任一实现 LeaseWatcher 的后端丢失租约时通知，没有后端支持时返回 nil
+func (r *CompositeRegistry) LeaseLost(service *ServiceInfo) <-chan struct{} {
+	var channels []<-chan struct{}
+	for _, registry := range r.registries {
+		if watcher, ok := registry.(LeaseWatcher); ok {
+			if lost := watcher.LeaseLost(service); lost != nil {
+				channels = append(channels, lost)
+			}
+		}
+	}
+	switch len(channels) {
+	case 0:
+		return nil
+	case 1:
+		return channels[0]
+	}
+
+	merged := make(chan struct{})
+	var once sync.Once
+	for _, lost := range channels {
+		go func(lost <-chan struct{}) {
+			select {
+			case <-lost:
+				once.Do(func() { close(merged) })
+			case <-merged:
+			case <-r.done:
+			}
+		}(lost)
+	}
+	return merged
+}
+
+// Close 关闭所有后端，返回所有失败后端的错误
+func (r *CompositeRegistry) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.done)
+	})
+
+	var errs []error
+	for i, registry := range r.registries {
+		if err := registry.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("registry %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// mergeServices 合并多个服务列表，按地址和端口去重，保留先出现的实例
+func mergeServices(lists [][]*ServiceInfo) []*ServiceInfo {
+	seen := make(map[string]bool)
+	var merged []*ServiceInfo
+	for _, services := range lists {
+		for _, service := range services {
+			key := fmt.Sprintf("%s:%d", service.Address, service.Port)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, service)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,220 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryRegistry 内存中的注册器，Watch 在每次注册和注销后推送服务列表
+type memoryRegistry struct {
+	mu       sync.Mutex
+	services map[string][]*ServiceInfo
+	watchers map[string][]chan []*ServiceInfo
+	closed   bool
+	err      error
+}
+
+func newMemoryRegistry() *memoryRegistry {
+	return &memoryRegistry{
+		services: make(map[string][]*ServiceInfo),
+		watchers: make(map[string][]chan []*ServiceInfo),
+	}
+}
+
+func (r *memoryRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.services[service.Name] = append(r.services[service.Name], service)
+	r.notify(service.Name)
+	return nil
+}
+
+func (r *memoryRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []*ServiceInfo
+	for _, s := range r.services[service.Name] {
+		if s.Address != service.Address || s.Port != service.Port {
+			kept = append(kept, s)
+		}
+	}
+	r.services[service.Name] = kept
+	r.notify(service.Name)
+	return nil
+}
+
+func (r *memoryRegistry) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	return copyServices(r.services[serviceName]), nil
+}
+
+func (r *memoryRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan []*ServiceInfo, 16)
+	r.watchers[serviceName] = append(r.watchers[serviceName], ch)
+	return ch, nil
+}
+
+func (r *memoryRegistry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *memoryRegistry) notify(serviceName string) {
+	for _, ch := range r.watchers[serviceName] {
+		ch <- copyServices(r.services[serviceName])
+	}
+}
+
+func TestCompositeRegistryRegisterAndDiscover(t *testing.T) {
+	etcd := newMemoryRegistry()
+	consul := newMemoryRegistry()
+	registry := NewCompositeRegistry(etcd, consul)
+	ctx := context.Background()
+
+	service := &ServiceInfo{Name: "orders", Address: "10.0.0.1", Port: 9090}
+	if err := registry.Register(ctx, service); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	// 注册写入两个后端
+	for name, backend := range map[string]*memoryRegistry{"etcd": etcd, "consul": consul} {
+		if services, _ := backend.Discover(ctx, "orders"); len(services) != 1 {
+			t.Errorf("Expected service registered in %s, got %d instances", name, len(services))
+		}
+	}
+
+	// 只在一个后端存在的实例也会被发现，重复实例去重
+	consul.Register(ctx, &ServiceInfo{Name: "orders", Address: "10.0.0.2", Port: 9090})
+	services, err := registry.Discover(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Failed to discover: %v", err)
+	}
+	if len(services) != 2 || services[0].Address != "10.0.0.1" || services[1].Address != "10.0.0.2" {
+		t.Errorf("Expected merged instances 10.0.0.1 and 10.0.0.2, got %v", services)
+	}
+
+	if err := registry.Deregister(ctx, service); err != nil {
+		t.Fatalf("Failed to deregister: %v", err)
+	}
+	if services, _ := etcd.Discover(ctx, "orders"); len(services) != 0 {
+		t.Errorf("Expected service deregistered from etcd, got %d instances", len(services))
+	}
+
+	if err := registry.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+	if !etcd.closed || !consul.closed {
+		t.Error("Expected all backends to be closed")
+	}
+}
+
+func TestCompositeRegistryPartialFailure(t *testing.T) {
+	healthy := newMemoryRegistry()
+	broken := newMemoryRegistry()
+	broken.err = errors.New("backend unavailable")
+	registry := NewCompositeRegistry(healthy, broken)
+	ctx := context.Background()
+
+	// 部分后端失败时返回错误，调用方据此重试
+	err := registry.Register(ctx, &ServiceInfo{Name: "orders", Address: "10.0.0.1", Port: 9090})
+	if err == nil {
+		t.Fatal("Expected error when a backend fails")
+	}
+	if services, _ := healthy.Discover(ctx, "orders"); len(services) != 1 {
+		t.Error("Expected healthy backend to be registered")
+	}
+
+	// 发现时忽略失败的后端
+	services, err := registry.Discover(ctx, "orders")
+	if err != nil || len(services) != 1 {
+		t.Errorf("Expected 1 instance from healthy backend, got %v, %v", services, err)
+	}
+
+	healthy.err = errors.New("backend unavailable")
+	if _, err := registry.Discover(ctx, "orders"); err == nil {
+		t.Error("Expected error when all backends fail")
+	}
+}
+
+func TestCompositeRegistryWatch(t *testing.T) {
+	etcd := newMemoryRegistry()
+	consul := newMemoryRegistry()
+	registry := NewCompositeRegistry(etcd, consul)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := registry.Watch(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+
+	receive := func() []*ServiceInfo {
+		t.Helper()
+		select {
+		case services := <-ch:
+			return services
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected watch update")
+			return nil
+		}
+	}
+
+	etcd.Register(ctx, &ServiceInfo{Name: "orders", Address: "10.0.0.1", Port: 9090})
+	if services := receive(); len(services) != 1 {
+		t.Fatalf("Expected 1 instance, got %d", len(services))
+	}
+
+	// 另一个后端的更新与已知实例合并
+	consul.Register(ctx, &ServiceInfo{Name: "orders", Address: "10.0.0.1", Port: 9090})
+	consul.Register(ctx, &ServiceInfo{Name: "orders", Address: "10.0.0.2", Port: 9090})
+	receive()
+	if services := receive(); len(services) != 2 {
+		t.Fatalf("Expected 2 merged instances, got %d", len(services))
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		for ok {
+			_, ok = <-ch
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected watch channel to close after cancel")
+	}
+}
+
+func TestCompositeRegistryLeaseLost(t *testing.T) {
+	first := &flakyRegistry{}
+	second := &flakyRegistry{}
+	registry := NewCompositeRegistry(first, second)
+	defer registry.Close()
+
+	service := &ServiceInfo{Name: "orders", Address: "10.0.0.1", Port: 9090}
+	if err := registry.Register(context.Background(), service); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	lost := registry.LeaseLost(service)
+	if lost == nil {
+		t.Fatal("Expected lease lost channel")
+	}
+	second.loseLease()
+	select {
+	case <-lost:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected lease lost notification from any backend")
+	}
+}
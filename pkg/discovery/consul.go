@@ -2,9 +2,12 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
 	"github.com/hashicorp/consul/api"
 	"go.uber.org/zap"
 )
@@ -14,10 +17,80 @@ type ConsulRegistry struct {
 	client    *api.Client
 	logger    *zap.Logger
 	namespace string
+	
+	check     ConsulCheck
+	
+	// 通过该注册器注册的服务，按服务 ID 索引，Close 时统一注销
+	mu         sync.Mutex
+	registered map[string]*ServiceInfo
+	// ttl 类型检查的心跳协程，按服务 ID 索引
+	heartbeats map[string]context.CancelFunc
+}
+
+const (
+	// ConsulCheckGRPC 使用 gRPC 健康检查协议检查服务
+	ConsulCheckGRPC = "grpc"
+	// ConsulCheckHTTP 通过 HTTP GET 检查服务
+	ConsulCheckHTTP = "http"
+	// ConsulCheckTTL 由注册器定期上报心跳，超过 TTL 未上报视为不健康
+	ConsulCheckTTL = "ttl"
+)
+
+// ConsulCheck consul 健康检查配置，时间为 0 的字段使用 DefaultConsulCheck 中的值
+type ConsulCheck struct {
+	Type            string
+	Interval        time.Duration // ttl 类型为 TTL 时长，心跳间隔为其一半
+	Timeout         time.Duration
+	DeregisterAfter time.Duration
+	
+	// http 类型的检查路径和端口，端口为 0 时使用服务端口
+	HTTPPath string
+	HTTPPort int
+	
+	// grpc 类型是否使用 TLS 以及是否跳过证书校验
+	GRPCUseTLS    bool
+	TLSSkipVerify bool
+}
+
+// DefaultConsulCheck 默认健康检查：每 10s 通过 gRPC 检查一次，超时 3s，持续不健康 30s 后注销
+func DefaultConsulCheck() ConsulCheck {
+	return ConsulCheck{
+		Type:            ConsulCheckGRPC,
+		Interval:        10 * time.Second,
+		Timeout:         3 * time.Second,
+		DeregisterAfter: 30 * time.Second,
+		HTTPPath:        "/health",
+	}
+}
+
+// ConsulOption consul 注册器选项
+type ConsulOption func(*ConsulRegistry)
+
+// WithConsulCheck 设置健康检查配置
+func WithConsulCheck(check ConsulCheck) ConsulOption {
+	return func(r *ConsulRegistry) {
+		defaults := DefaultConsulCheck()
+		if check.Type == "" {
+			check.Type = defaults.Type
+		}
+		if check.Interval <= 0 {
+			check.Interval = defaults.Interval
+		}
+		if check.Timeout <= 0 {
+			check.Timeout = defaults.Timeout
+		}
+		if check.DeregisterAfter <= 0 {
+			check.DeregisterAfter = defaults.DeregisterAfter
+		}
+		if check.HTTPPath == "" {
+			check.HTTPPath = defaults.HTTPPath
+		}
+		r.check = check
+	}
 }
 
 // NewConsulRegistry 创建 consul 注册器
-func NewConsulRegistry(endpoints []string, namespace string, logger *zap.Logger) (*ConsulRegistry, error) {
+func NewConsulRegistry(endpoints []string, namespace string, logger *zap.Logger, opts ...ConsulOption) (*ConsulRegistry, error) {
 	config := api.DefaultConfig()
 	if len(endpoints) > 0 {
 		config.Address = endpoints[0]
@@ -28,36 +101,97 @@ func NewConsulRegistry(endpoints []string, namespace string, logger *zap.Logger)
 		return nil, fmt.Errorf("failed to create consul client: %w", err)
 	}
 	
-	return &ConsulRegistry{
-		client:    client,
-		logger:    logger,
-		namespace: namespace,
-	}, nil
+	r := &ConsulRegistry{
+		client:     client,
+		logger:     logger,
+		namespace:  namespace,
+		check:      DefaultConsulCheck(),
+		registered: make(map[string]*ServiceInfo),
+		heartbeats: make(map[string]context.CancelFunc),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	
+	switch r.check.Type {
+	case ConsulCheckGRPC, ConsulCheckHTTP, ConsulCheckTTL:
+	default:
+		return nil, fmt.Errorf("unsupported consul check type: %s", r.check.Type)
+	}
+	
+	return r, nil
 }
 
-// Register 注册服务
-func (r *ConsulRegistry) Register(ctx context.Context, service *ServiceInfo) error {
-	serviceID := fmt.Sprintf("%s-%s-%d", service.Name, service.Address, service.Port)
-	
-	registration := &api.AgentServiceRegistration{
+// consulServiceID 构建服务实例 ID，同一地址和端口重复注册时覆盖已有实例
+func consulServiceID(service *ServiceInfo) string {
+	return fmt.Sprintf("%s-%s-%d", service.Name, service.Address, service.Port)
+}
+
+// consulCheckID 服务健康检查 ID
+func consulCheckID(serviceID string) string {
+	return "service:" + serviceID
+}
+
+// registration 构建服务注册请求
+func (r *ConsulRegistry) registration(service *ServiceInfo) *api.AgentServiceRegistration {
+	serviceID := consulServiceID(service)
+	return &api.AgentServiceRegistration{
 		ID:      serviceID,
 		Name:    service.Name,
 		Address: service.Address,
 		Port:    service.Port,
 		Tags:    []string{"grpc"},
 		Meta:    service.Metadata,
-		Check: &api.AgentServiceCheck{
-			GRPC:                           fmt.Sprintf("%s:%d", service.Address, service.Port),
-			Interval:                       "10s",
-			Timeout:                        "3s",
-			DeregisterCriticalServiceAfter: "30s",
-		},
+		Check:   r.buildCheck(serviceID, service),
+	}
+}
+
+// buildCheck 按检查类型构建健康检查
+func (r *ConsulRegistry) buildCheck(serviceID string, service *ServiceInfo) *api.AgentServiceCheck {
+	check := &api.AgentServiceCheck{
+		CheckID:                        consulCheckID(serviceID),
+		DeregisterCriticalServiceAfter: r.check.DeregisterAfter.String(),
+	}
+	
+	switch r.check.Type {
+	case ConsulCheckTTL:
+		check.TTL = r.check.Interval.String()
+	case ConsulCheckHTTP:
+		port := r.check.HTTPPort
+		if port == 0 {
+			port = service.Port
+		}
+		check.HTTP = fmt.Sprintf("http://%s:%d%s", service.Address, port, r.check.HTTPPath)
+		check.Interval = r.check.Interval.String()
+		check.Timeout = r.check.Timeout.String()
+	default:
+		check.GRPC = fmt.Sprintf("%s:%d", service.Address, service.Port)
+		check.GRPCUseTLS = r.check.GRPCUseTLS
+		check.Interval = r.check.Interval.String()
+		check.Timeout = r.check.Timeout.String()
+		check.TLSSkipVerify = r.check.TLSSkipVerify
 	}
+	return check
+}
+
+// Register 注册服务
+// 同一实例重复注册（如进程在相同地址和端口重启）时替换已有的健康检查，避免残留过期检查
+func (r *ConsulRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	registration := r.registration(service)
+	serviceID := registration.ID
 	
-	if err := r.client.Agent().ServiceRegister(registration); err != nil {
+	opts := api.ServiceRegisterOpts{ReplaceExistingChecks: true}.WithContext(ctx)
+	if err := r.client.Agent().ServiceRegisterOpts(registration, opts); err != nil {
 		return fmt.Errorf("failed to register service: %w", err)
 	}
 	
+	r.mu.Lock()
+	r.registered[serviceID] = service
+	if r.check.Type == ConsulCheckTTL {
+		r.startHeartbeat(serviceID)
+	}
+	r.mu.Unlock()
+	
 	r.logger.Info("Service registered to consul",
 		zap.String("service", service.Name),
 		zap.String("address", service.Address),
@@ -69,12 +203,23 @@ func (r *ConsulRegistry) Register(ctx context.Context, service *ServiceInfo) err
 
 // Deregister 注销服务
 func (r *ConsulRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
-	serviceID := fmt.Sprintf("%s-%s-%d", service.Name, service.Address, service.Port)
-	
-	if err := r.client.Agent().ServiceDeregister(serviceID); err != nil {
+	return r.deregister(ctx, consulServiceID(service), service)
+}
+
+// deregister 按服务 ID 注销服务并停止跟踪
+func (r *ConsulRegistry) deregister(ctx context.Context, serviceID string, service *ServiceInfo) error {
+	if err := r.client.Agent().ServiceDeregisterOpts(serviceID, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
 		return fmt.Errorf("failed to deregister service: %w", err)
 	}
 	
+	r.mu.Lock()
+	delete(r.registered, serviceID)
+	if cancel, ok := r.heartbeats[serviceID]; ok {
+		cancel()
+		delete(r.heartbeats, serviceID)
+	}
+	r.mu.Unlock()
+	
 	r.logger.Info("Service deregistered from consul",
 		zap.String("service", service.Name),
 		zap.String("service_id", serviceID))
@@ -82,6 +227,39 @@ func (r *ConsulRegistry) Deregister(ctx context.Context, service *ServiceInfo) e
 	return nil
 }
 
+// startHeartbeat 启动 ttl 检查的心跳协程，立即上报一次并按 TTL 的一半定期上报，调用方需持有 r.mu
+func (r *ConsulRegistry) startHeartbeat(serviceID string) {
+	if cancel, ok := r.heartbeats[serviceID]; ok {
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.heartbeats[serviceID] = cancel
+	
+	checkID := consulCheckID(serviceID)
+	go func() {
+		ticker := time.NewTicker(r.check.Interval / 2)
+		defer ticker.Stop()
+		
+		for {
+			// 单次上报 panic 时继续心跳，避免检查过期后服务被注销
+			safe.Run(r.logger, "Consul TTL heartbeat panicked", func() {
+				opts := (&api.QueryOptions{}).WithContext(ctx)
+				if err := r.client.Agent().UpdateTTLOpts(checkID, "", api.HealthPassing, opts); err != nil && ctx.Err() == nil {
+					r.logger.Warn("Failed to update consul TTL check",
+						zap.String("check_id", checkID),
+						zap.Error(err))
+				}
+			}, zap.String("check_id", checkID))
+			
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
 // Discover 发现服务
 func (r *ConsulRegistry) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
 	services, _, err := r.client.Health().Service(serviceName, "", true, nil)
@@ -114,8 +292,8 @@ func (r *ConsulRegistry) Watch(ctx context.Context, serviceName string) (<-chan
 	}
 	ch <- services
 	
-	// 启动监听协程
-	go func() {
+	// 启动监听协程，panic 时关闭通道，由调用方重新建立监听
+	go safe.Run(r.logger, "Service watch panicked", func() {
 		defer close(ch)
 		
 		var lastIndex uint64
@@ -158,13 +336,41 @@ func (r *ConsulRegistry) Watch(ctx context.Context, serviceName string) (<-chan
 				return
 			}
 		}
-	}()
+	}, zap.String("service", serviceName))
 	
 	return ch, nil
 }
 
-// Close 关闭注册器
+// Close 注销通过该注册器注册且尚未注销的服务
+// Consul client 本身不需要显式关闭；进程异常退出未能注销时由 DeregisterCriticalServiceAfter 兜底清理
 func (r *ConsulRegistry) Close() error {
-	// Consul client 不需要显式关闭
-	return nil
+	r.mu.Lock()
+	registered := make(map[string]*ServiceInfo, len(r.registered))
+	for id, service := range r.registered {
+		registered[id] = service
+	}
+	r.mu.Unlock()
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	
+	var errs []error
+	for id, service := range registered {
+		if err := r.deregister(ctx, id, service); err != nil {
+			errs = append(errs, fmt.Errorf("service %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisteredServices 返回通过该注册器注册且尚未注销的服务
+func (r *ConsulRegistry) RegisteredServices() []*ServiceInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	services := make([]*ServiceInfo, 0, len(r.registered))
+	for _, service := range r.registered {
+		services = append(services, service)
+	}
+	return services
 }
\ No newline at end of file
@@ -0,0 +1,147 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// newTestConsulRegistry 创建连接本地 consul 的注册器，consul 不可用时跳过测试
+func newTestConsulRegistry(t *testing.T) *ConsulRegistry {
+	t.Helper()
+
+	registry, err := NewConsulRegistry([]string{"localhost:8500"}, "/test", zap.NewNop())
+	if err != nil {
+		t.Skipf("Skipping test due to consul client error: %v", err)
+	}
+	if _, err := registry.client.Agent().Self(); err != nil {
+		t.Skipf("Skipping test due to consul connection error: %v", err)
+	}
+	return registry
+}
+
+func TestConsulServiceID(t *testing.T) {
+	service := &ServiceInfo{Name: "user-service", Address: "10.0.0.1", Port: 9090}
+	if id := consulServiceID(service); id != "user-service-10.0.0.1-9090" {
+		t.Errorf("Unexpected service ID %s", id)
+	}
+}
+
+func TestConsulRegistrationCheck(t *testing.T) {
+	service := &ServiceInfo{Name: "user-service", Address: "10.0.0.1", Port: 9090}
+
+	t.Run("default grpc", func(t *testing.T) {
+		registry, err := NewConsulRegistry(nil, "", zap.NewNop())
+		if err != nil {
+			t.Fatalf("Failed to create registry: %v", err)
+		}
+		check := registry.registration(service).Check
+		if check.GRPC != "10.0.0.1:9090" || check.Interval != "10s" || check.Timeout != "3s" || check.DeregisterCriticalServiceAfter != "30s" {
+			t.Errorf("Unexpected default check %+v", check)
+		}
+		if check.HTTP != "" || check.TTL != "" || check.GRPCUseTLS || check.TLSSkipVerify {
+			t.Errorf("Unexpected fields on grpc check %+v", check)
+		}
+	})
+
+	t.Run("grpc with tls", func(t *testing.T) {
+		registry, err := NewConsulRegistry(nil, "", zap.NewNop(), WithConsulCheck(ConsulCheck{
+			Type:          ConsulCheckGRPC,
+			Interval:      5 * time.Second,
+			GRPCUseTLS:    true,
+			TLSSkipVerify: true,
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create registry: %v", err)
+		}
+		check := registry.registration(service).Check
+		if !check.GRPCUseTLS || !check.TLSSkipVerify || check.Interval != "5s" || check.Timeout != "3s" {
+			t.Errorf("Unexpected grpc tls check %+v", check)
+		}
+	})
+
+	t.Run("http", func(t *testing.T) {
+		registry, err := NewConsulRegistry(nil, "", zap.NewNop(), WithConsulCheck(ConsulCheck{
+			Type:            ConsulCheckHTTP,
+			Timeout:         time.Second,
+			DeregisterAfter: time.Minute,
+			HTTPPath:        "/ready",
+			HTTPPort:        8080,
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create registry: %v", err)
+		}
+		check := registry.registration(service).Check
+		if check.HTTP != "http://10.0.0.1:8080/ready" || check.Timeout != "1s" || check.DeregisterCriticalServiceAfter != "1m0s" {
+			t.Errorf("Unexpected http check %+v", check)
+		}
+		if check.GRPC != "" || check.TTL != "" {
+			t.Errorf("Unexpected fields on http check %+v", check)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		registry, err := NewConsulRegistry(nil, "", zap.NewNop(), WithConsulCheck(ConsulCheck{
+			Type:     ConsulCheckTTL,
+			Interval: 15 * time.Second,
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create registry: %v", err)
+		}
+		registration := registry.registration(service)
+		check := registration.Check
+		if check.TTL != "15s" || check.CheckID != "service:"+registration.ID {
+			t.Errorf("Unexpected ttl check %+v", check)
+		}
+		if check.GRPC != "" || check.HTTP != "" || check.Interval != "" {
+			t.Errorf("Unexpected fields on ttl check %+v", check)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if _, err := NewConsulRegistry(nil, "", zap.NewNop(), WithConsulCheck(ConsulCheck{Type: "tcp"})); err == nil {
+			t.Error("Expected error for unsupported check type")
+		}
+	})
+}
+
+func TestConsulRegistryCloseDeregisters(t *testing.T) {
+	registry := newTestConsulRegistry(t)
+	ctx := context.Background()
+
+	services := []*ServiceInfo{
+		{Name: "close-test", Address: "127.0.0.1", Port: 19090},
+		{Name: "close-test", Address: "127.0.0.1", Port: 19091},
+	}
+	for _, service := range services {
+		if err := registry.Register(ctx, service); err != nil {
+			t.Fatalf("Failed to register service: %v", err)
+		}
+	}
+	// 重复注册同一实例不会产生新的跟踪项
+	if err := registry.Register(ctx, services[0]); err != nil {
+		t.Fatalf("Failed to re-register service: %v", err)
+	}
+	if got := len(registry.RegisteredServices()); got != 2 {
+		t.Fatalf("Expected 2 tracked services, got %d", got)
+	}
+
+	if err := registry.Close(); err != nil {
+		t.Fatalf("Failed to close registry: %v", err)
+	}
+	if got := len(registry.RegisteredServices()); got != 0 {
+		t.Errorf("Expected no tracked services after close, got %d", got)
+	}
+
+	agentServices, err := registry.client.Agent().Services()
+	if err != nil {
+		t.Fatalf("Failed to list agent services: %v", err)
+	}
+	for _, service := range services {
+		if _, ok := agentServices[consulServiceID(service)]; ok {
+			t.Errorf("Expected %s to be deregistered from consul", consulServiceID(service))
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"path"
+	"sync"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
 	"go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 )
@@ -17,7 +19,16 @@ type EtcdRegistry struct {
 	logger    *zap.Logger
 	namespace string
 	ttl       int64
-	leaseID   clientv3.LeaseID
+	
+	mu     sync.Mutex
+	leases map[string]*etcdLease
+}
+
+// etcdLease 已注册服务的租约，lost 在租约续期意外停止时关闭
+type etcdLease struct {
+	id     clientv3.LeaseID
+	cancel context.CancelFunc
+	lost   chan struct{}
 }
 
 // ServiceInfo 服务信息
@@ -28,12 +39,65 @@ type ServiceInfo struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
-// NewEtcdRegistry 创建 etcd 注册器
-func NewEtcdRegistry(endpoints []string, namespace string, logger *zap.Logger) (*EtcdRegistry, error) {
-	client, err := clientv3.New(clientv3.Config{
+const (
+	// MetadataStatus 服务元数据中的健康状态键
+	MetadataStatus = "status"
+
+	// StatusServing 服务正常
+	StatusServing = "SERVING"
+	// StatusNotServing 服务不可用
+	StatusNotServing = "NOT_SERVING"
+)
+
+// IsServing 检查服务实例是否可用
+// 未设置健康状态的实例视为可用，以兼容旧的注册数据
+func (s *ServiceInfo) IsServing() bool {
+	status, ok := s.Metadata[MetadataStatus]
+	if !ok || status == "" {
+		return true
+	}
+	return status == StatusServing
+}
+
+// DefaultEtcdDialTimeout etcd 客户端默认连接超时
+const DefaultEtcdDialTimeout = 5 * time.Second
+
+// EtcdOption etcd 注册器选项
+type EtcdOption func(*clientv3.Config)
+
+// WithDialTimeout 设置 etcd 连接超时，小于等于 0 时使用默认值
+func WithDialTimeout(timeout time.Duration) EtcdOption {
+	return func(c *clientv3.Config) {
+		if timeout > 0 {
+			c.DialTimeout = timeout
+		}
+	}
+}
+
+// WithDialKeepAliveTime 设置 etcd 连接的 keepalive 探测间隔，小于等于 0 时不启用
+func WithDialKeepAliveTime(interval time.Duration) EtcdOption {
+	return func(c *clientv3.Config) {
+		if interval > 0 {
+			c.DialKeepAliveTime = interval
+		}
+	}
+}
+
+// newEtcdClientConfig 构建 etcd 客户端配置
+func newEtcdClientConfig(endpoints []string, opts []EtcdOption) clientv3.Config {
+	cfg := clientv3.Config{
 		Endpoints:   endpoints,
-		DialTimeout: 5 * time.Second,
-	})
+		DialTimeout: DefaultEtcdDialTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// NewEtcdRegistry 创建 etcd 注册器
+func NewEtcdRegistry(endpoints []string, namespace string, logger *zap.Logger, opts ...EtcdOption) (*EtcdRegistry, error) {
+	client, err := clientv3.New(newEtcdClientConfig(endpoints, opts))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create etcd client: %w", err)
 	}
@@ -53,6 +117,7 @@ func NewEtcdRegistry(endpoints []string, namespace string, logger *zap.Logger) (
 		logger:    logger,
 		namespace: namespace,
 		ttl:       30, // 30 秒 TTL
+		leases:    make(map[string]*etcdLease),
 	}, nil
 }
 
@@ -63,7 +128,6 @@ func (r *EtcdRegistry) Register(ctx context.Context, service *ServiceInfo) error
 	if err != nil {
 		return fmt.Errorf("failed to grant lease: %w", err)
 	}
-	r.leaseID = lease.ID
 	
 	// 序列化服务信息
 	data, err := json.Marshal(service)
@@ -80,16 +144,34 @@ func (r *EtcdRegistry) Register(ctx context.Context, service *ServiceInfo) error
 		return fmt.Errorf("failed to register service: %w", err)
 	}
 	
-	// 启动租约续期
-	ch, kaerr := r.client.KeepAlive(ctx, lease.ID)
+	// 启动租约续期，续期在注销或关闭前持续进行，不受本次请求 ctx 的影响
+	kaCtx, kaCancel := context.WithCancel(context.Background())
+	ch, kaerr := r.client.KeepAlive(kaCtx, lease.ID)
 	if kaerr != nil {
+		kaCancel()
 		return fmt.Errorf("failed to keep alive lease: %w", kaerr)
 	}
 	
-	// 处理续期响应
+	entry := &etcdLease{id: lease.ID, cancel: kaCancel, lost: make(chan struct{})}
+	r.mu.Lock()
+	if previous, ok := r.leases[key]; ok {
+		previous.cancel()
+	}
+	r.leases[key] = entry
+	r.mu.Unlock()
+	
+	// 处理续期响应，续期意外停止或处理协程 panic 时通知租约丢失，由调用方重新注册
 	go func() {
-		for ka := range ch {
-			r.logger.Debug("Lease renewed", zap.Int64("lease_id", int64(ka.ID)))
+		safe.Run(r.logger, "Lease keep-alive panicked", func() {
+			for ka := range ch {
+				r.logger.Debug("Lease renewed", zap.Int64("lease_id", int64(ka.ID)))
+			}
+		}, zap.String("service", service.Name), zap.String("key", key))
+		if kaCtx.Err() == nil {
+			r.logger.Warn("Lease keep-alive stopped, service registration lost",
+				zap.String("service", service.Name),
+				zap.String("key", key))
+			close(entry.lost)
 		}
 	}()
 	
@@ -104,16 +186,21 @@ func (r *EtcdRegistry) Register(ctx context.Context, service *ServiceInfo) error
 
 // Deregister 注销服务
 func (r *EtcdRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
-	// 撤销租约
-	if r.leaseID != 0 {
-		_, err := r.client.Revoke(ctx, r.leaseID)
-		if err != nil {
+	key := r.buildServiceKey(service.Name, service.Address, service.Port)
+	
+	// 停止续期并撤销租约
+	r.mu.Lock()
+	entry, ok := r.leases[key]
+	delete(r.leases, key)
+	r.mu.Unlock()
+	if ok {
+		entry.cancel()
+		if _, err := r.client.Revoke(ctx, entry.id); err != nil {
 			r.logger.Warn("Failed to revoke lease", zap.Error(err))
 		}
 	}
 	
 	// 删除服务键
-	key := r.buildServiceKey(service.Name, service.Address, service.Port)
 	_, err := r.client.Delete(ctx, key)
 	if err != nil {
 		return fmt.Errorf("failed to deregister service: %w", err)
@@ -166,7 +253,8 @@ func (r *EtcdRegistry) Watch(ctx context.Context, serviceName string) (<-chan []
 	// 监听变化
 	watchCh := r.client.Watch(ctx, prefix, clientv3.WithPrefix())
 	
-	go func() {
+	// panic 时关闭通道，由调用方重新建立监听
+	go safe.Run(r.logger, "Service watch panicked", func() {
 		defer close(ch)
 		for watchResp := range watchCh {
 			if watchResp.Err() != nil {
@@ -187,13 +275,33 @@ func (r *EtcdRegistry) Watch(ctx context.Context, serviceName string) (<-chan []
 				return
 			}
 		}
-	}()
+	}, zap.String("service", serviceName))
 	
 	return ch, nil
 }
 
-// Close 关闭注册器
+// LeaseLost 返回服务当前注册的租约丢失通知，租约续期意外停止时关闭；服务未注册时返回 nil
+func (r *EtcdRegistry) LeaseLost(service *ServiceInfo) <-chan struct{} {
+	key := r.buildServiceKey(service.Name, service.Address, service.Port)
+	
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	if entry, ok := r.leases[key]; ok {
+		return entry.lost
+	}
+	return nil
+}
+
+// Close 关闭注册器，停止所有租约续期
 func (r *EtcdRegistry) Close() error {
+	r.mu.Lock()
+	for key, entry := range r.leases {
+		entry.cancel()
+		delete(r.leases, key)
+	}
+	r.mu.Unlock()
+	
 	return r.client.Close()
 }
 
@@ -270,4 +270,58 @@ func BenchmarkServiceInfoSerialization(b *testing.B) {
 			b.Fatalf("Failed to unmarshal service info: %v", err)
 		}
 	}
-}
\ No newline at end of file
+}
+func TestServiceInfoIsServing(t *testing.T) {
+	tests := []struct {
+		metadata map[string]string
+		expected bool
+	}{
+		{nil, true},
+		{map[string]string{"version": "1.0"}, true},
+		{map[string]string{MetadataStatus: StatusServing}, true},
+		{map[string]string{MetadataStatus: StatusNotServing}, false},
+		{map[string]string{MetadataStatus: "UNKNOWN"}, false},
+	}
+
+	for _, test := range tests {
+		service := &ServiceInfo{Name: "test-service", Metadata: test.metadata}
+		if result := service.IsServing(); result != test.expected {
+			t.Errorf("IsServing() with metadata %v = %v, expected %v", test.metadata, result, test.expected)
+		}
+	}
+}
+
+func TestNewEtcdClientConfig(t *testing.T) {
+	endpoints := []string{"localhost:2379"}
+
+	cfg := newEtcdClientConfig(endpoints, nil)
+	if cfg.DialTimeout != DefaultEtcdDialTimeout {
+		t.Errorf("Expected default dial timeout %v, got %v", DefaultEtcdDialTimeout, cfg.DialTimeout)
+	}
+	if cfg.DialKeepAliveTime != 0 {
+		t.Errorf("Expected keepalive to be disabled by default, got %v", cfg.DialKeepAliveTime)
+	}
+
+	cfg = newEtcdClientConfig(endpoints, []EtcdOption{
+		WithDialTimeout(10 * time.Second),
+		WithDialKeepAliveTime(30 * time.Second),
+	})
+	if cfg.DialTimeout != 10*time.Second {
+		t.Errorf("Expected dial timeout 10s, got %v", cfg.DialTimeout)
+	}
+	if cfg.DialKeepAliveTime != 30*time.Second {
+		t.Errorf("Expected keepalive time 30s, got %v", cfg.DialKeepAliveTime)
+	}
+	if len(cfg.Endpoints) != 1 || cfg.Endpoints[0] != endpoints[0] {
+		t.Errorf("Expected endpoints %v, got %v", endpoints, cfg.Endpoints)
+	}
+
+	// 零值保留默认配置
+	cfg = newEtcdClientConfig(endpoints, []EtcdOption{WithDialTimeout(0), WithDialKeepAliveTime(0)})
+	if cfg.DialTimeout != DefaultEtcdDialTimeout {
+		t.Errorf("Expected zero dial timeout to keep default, got %v", cfg.DialTimeout)
+	}
+	if cfg.DialKeepAliveTime != 0 {
+		t.Errorf("Expected zero keepalive time to keep it disabled, got %v", cfg.DialKeepAliveTime)
+	}
+}
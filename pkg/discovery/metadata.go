@@ -0,0 +1,94 @@
+package discovery
+
+import (
+	"strconv"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+)
+
+// 框架识别的服务元数据键，解析器和负载均衡器据此做路由决策
+const (
+	// MetadataWeight 实例权重，正整数
+	MetadataWeight = "weight"
+	// MetadataZone 实例所在可用区
+	MetadataZone = "zone"
+	// MetadataVersion 服务版本
+	MetadataVersion = "version"
+	// MetadataProtocol 服务协议，如 grpc
+	MetadataProtocol = "protocol"
+	// MetadataHealthService 客户端健康检查使用的健康服务名
+	MetadataHealthService = "grpc.health.service"
+)
+
+const (
+	// DefaultWeight 未设置或设置无效时的实例权重
+	DefaultWeight = 1
+	// DefaultProtocol 未设置时的服务协议
+	DefaultProtocol = "grpc"
+)
+
+// Weight 返回实例权重，未设置或不是正整数时返回 DefaultWeight
+func (s *ServiceInfo) Weight() int {
+	if weight, err := strconv.Atoi(s.Metadata[MetadataWeight]); err == nil && weight > 0 {
+		return weight
+	}
+	return DefaultWeight
+}
+
+// Zone 返回实例所在可用区，未设置时返回空字符串
+func (s *ServiceInfo) Zone() string {
+	return s.Metadata[MetadataZone]
+}
+
+// Version 返回服务版本，未设置时返回空字符串
+func (s *ServiceInfo) Version() string {
+	return s.Metadata[MetadataVersion]
+}
+
+// Protocol 返回服务协议，未设置时返回 DefaultProtocol
+func (s *ServiceInfo) Protocol() string {
+	if protocol := s.Metadata[MetadataProtocol]; protocol != "" {
+		return protocol
+	}
+	return DefaultProtocol
+}
+
+// HealthService 返回客户端健康检查使用的健康服务名，未设置时返回空字符串，表示检查整体状态
+func (s *ServiceInfo) HealthService() string {
+	return s.Metadata[MetadataHealthService]
+}
+
+// InstanceMetadata 根据 discovery.metadata 配置生成注册时的服务元数据，状态为 SERVING，未配置的键不写入
+func InstanceMetadata(cfg *config.DiscoveryConfig) map[string]string {
+	metadata := map[string]string{
+		MetadataStatus: StatusServing,
+	}
+	instance := cfg.Metadata
+	if instance.Weight > 0 {
+		metadata[MetadataWeight] = strconv.Itoa(instance.Weight)
+	}
+	for key, value := range map[string]string{
+		MetadataZone:          instance.Zone,
+		MetadataVersion:       instance.Version,
+		MetadataProtocol:      instance.Protocol,
+		MetadataHealthService: instance.HealthService,
+	} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// withStatus 返回状态替换为 status 的服务副本，不修改原服务的元数据
+func (s *ServiceInfo) withStatus(status string) *ServiceInfo {
+	metadata := make(map[string]string, len(s.Metadata)+1)
+	for key, value := range s.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataStatus] = status
+
+	updated := *s
+	updated.Metadata = metadata
+	return &updated
+}
@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+)
+
+func TestServiceInfoMetadataAccessors(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      map[string]string
+		weight        int
+		zone          string
+		version       string
+		protocol      string
+		healthService string
+	}{
+		{
+			name:     "absent",
+			metadata: nil,
+			weight:   DefaultWeight,
+			protocol: DefaultProtocol,
+		},
+		{
+			name: "all set",
+			metadata: map[string]string{
+				MetadataWeight:        "5",
+				MetadataZone:          "zone-a",
+				MetadataVersion:       "v2.1.0",
+				MetadataProtocol:      "grpcs",
+				MetadataHealthService: "user.UserService",
+			},
+			weight:        5,
+			zone:          "zone-a",
+			version:       "v2.1.0",
+			protocol:      "grpcs",
+			healthService: "user.UserService",
+		},
+		{name: "malformed weight", metadata: map[string]string{MetadataWeight: "heavy"}, weight: DefaultWeight, protocol: DefaultProtocol},
+		{name: "zero weight", metadata: map[string]string{MetadataWeight: "0"}, weight: DefaultWeight, protocol: DefaultProtocol},
+		{name: "negative weight", metadata: map[string]string{MetadataWeight: "-3"}, weight: DefaultWeight, protocol: DefaultProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &ServiceInfo{Name: "orders", Metadata: tt.metadata}
+			if got := service.Weight(); got != tt.weight {
+				t.Errorf("Expected weight %d, got %d", tt.weight, got)
+			}
+			if got := service.Zone(); got != tt.zone {
+				t.Errorf("Expected zone %q, got %q", tt.zone, got)
+			}
+			if got := service.Version(); got != tt.version {
+				t.Errorf("Expected version %q, got %q", tt.version, got)
+			}
+			if got := service.Protocol(); got != tt.protocol {
+				t.Errorf("Expected protocol %q, got %q", tt.protocol, got)
+			}
+			if got := service.HealthService(); got != tt.healthService {
+				t.Errorf("Expected health service %q, got %q", tt.healthService, got)
+			}
+		})
+	}
+}
+
+func TestInstanceMetadata(t *testing.T) {
+	cfg := &config.DiscoveryConfig{
+		Metadata: config.DiscoveryMetadataConfig{
+			Weight:   3,
+			Zone:     "zone-a",
+			Version:  "1.0.0",
+			Protocol: "grpc",
+		},
+	}
+
+	service := &ServiceInfo{Name: "orders", Metadata: InstanceMetadata(cfg)}
+	if !service.IsServing() || service.Metadata[MetadataStatus] != StatusServing {
+		t.Error("Expected instance to be registered as SERVING")
+	}
+	if service.Weight() != 3 || service.Zone() != "zone-a" || service.Version() != "1.0.0" {
+		t.Errorf("Expected metadata from config, got %v", service.Metadata)
+	}
+
+	// 未配置的键不写入
+	if _, ok := service.Metadata[MetadataHealthService]; ok {
+		t.Error("Expected unset health service to be omitted")
+	}
+	if _, ok := InstanceMetadata(&config.DiscoveryConfig{})[MetadataWeight]; ok {
+		t.Error("Expected zero weight to be omitted")
+	}
+}
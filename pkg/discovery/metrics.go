@@ -0,0 +1,145 @@
+package discovery
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// resultSuccess 操作成功时的 result 标签值
+	resultSuccess = "success"
+	// resultError 操作失败时的 result 标签值
+	resultError = "error"
+)
+
+var (
+	// 服务注册次数
+	discoveryRegisterTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "discovery_register_total",
+			Help: "Total number of service registrations to the discovery backend",
+		},
+		[]string{"backend", "result"},
+	)
+
+	// 服务注销次数
+	discoveryDeregisterTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "discovery_deregister_total",
+			Help: "Total number of service deregistrations from the discovery backend",
+		},
+		[]string{"backend", "result"},
+	)
+
+	// 服务发现查询耗时
+	discoveryDiscoverDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "discovery_discover_duration_seconds",
+			Help:    "Duration of service discovery queries in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"backend", "result"},
+	)
+
+	// 服务监听推送的更新次数，监听无法建立时以 error 计数
+	discoveryWatchEvents = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "discovery_watch_events_total",
+			Help: "Total number of service watch updates received from the discovery backend",
+		},
+		[]string{"backend", "result"},
+	)
+)
+
+// MetricsRegistry 记录注册、注销、发现和监听指标的注册器装饰器，backend 作为指标的 backend 标签
+type MetricsRegistry struct {
+	inner   Registry
+	backend string
+}
+
+// NewMetricsRegistry 创建记录指标的注册器，backend 通常为注册中心类型（etcd、consul）
+func NewMetricsRegistry(inner Registry, backend string) *MetricsRegistry {
+	return &MetricsRegistry{
+		inner:   inner,
+		backend: backend,
+	}
+}
+
+// Register 注册服务并记录结果
+func (r *MetricsRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	err := r.inner.Register(ctx, service)
+	discoveryRegisterTotal.WithLabelValues(r.backend, resultLabel(err)).Inc()
+	return err
+}
+
+// Deregister 注销服务并记录结果
+func (r *MetricsRegistry) Deregister(ctx context.Context, service *ServiceInfo) error {
+	err := r.inner.Deregister(ctx, service)
+	discoveryDeregisterTotal.WithLabelValues(r.backend, resultLabel(err)).Inc()
+	return err
+}
+
+// Discover 发现服务并记录耗时和结果
+func (r *MetricsRegistry) Discover(ctx context.Context, serviceName string) ([]*ServiceInfo, error) {
+	start := time.Now()
+	services, err := r.inner.Discover(ctx, serviceName)
+	discoveryDiscoverDuration.WithLabelValues(r.backend, resultLabel(err)).Observe(time.Since(start).Seconds())
+	return services, err
+}
+
+// Watch 监听服务变化并记录推送的更新次数
+func (r *MetricsRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*ServiceInfo, error) {
+	ch, err := r.inner.Watch(ctx, serviceName)
+	if err != nil {
+		discoveryWatchEvents.WithLabelValues(r.backend, resultError).Inc()
+		return nil, err
+	}
+
+	events := discoveryWatchEvents.WithLabelValues(r.backend, resultSuccess)
+	out := make(chan []*ServiceInfo, 1)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case services, ok := <-ch:
+				if !ok {
+					return
+				}
+				events.Inc()
+				select {
+				case out <- services:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// LeaseLost 转发内部注册器的租约丢失通知，内部注册器未实现 LeaseWatcher 时返回 nil
+func (r *MetricsRegistry) LeaseLost(service *ServiceInfo) <-chan struct{} {
+	if watcher, ok := r.inner.(LeaseWatcher); ok {
+		return watcher.LeaseLost(service)
+	}
+	return nil
+}
+
+// Close 关闭内部注册器
+func (r *MetricsRegistry) Close() error {
+	return r.inner.Close()
+}
+
+// resultLabel 返回操作结果的 result 标签值
+func resultLabel(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultSuccess
+}
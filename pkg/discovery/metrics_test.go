@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsRegistry(t *testing.T) {
+	inner := newMemoryRegistry()
+	registry := NewMetricsRegistry(inner, "memory")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 各后端共用全局指标，只统计 memory 后端的数据
+	discoverCount := func(result string) uint64 {
+		t.Helper()
+		families, err := prometheus.DefaultGatherer.Gather()
+		if err != nil {
+			t.Fatalf("Failed to gather metrics: %v", err)
+		}
+		for _, family := range families {
+			if family.GetName() != "discovery_discover_duration_seconds" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+				if labels["backend"] == "memory" && labels["result"] == result {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+		return 0
+	}
+
+	ch, err := registry.Watch(ctx, "orders")
+	if err != nil {
+		t.Fatalf("Failed to watch: %v", err)
+	}
+	receive := func() {
+		t.Helper()
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected watch update")
+		}
+	}
+
+	service := &ServiceInfo{Name: "orders", Address: "10.0.0.1", Port: 9090}
+	if err := registry.Register(ctx, service); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	receive()
+	if _, err := registry.Discover(ctx, "orders"); err != nil {
+		t.Fatalf("Failed to discover: %v", err)
+	}
+	if err := registry.Deregister(ctx, service); err != nil {
+		t.Fatalf("Failed to deregister: %v", err)
+	}
+	receive()
+
+	if got := testutil.ToFloat64(discoveryRegisterTotal.WithLabelValues("memory", resultSuccess)); got != 1 {
+		t.Errorf("Expected 1 successful registration, got %v", got)
+	}
+	if got := testutil.ToFloat64(discoveryDeregisterTotal.WithLabelValues("memory", resultSuccess)); got != 1 {
+		t.Errorf("Expected 1 successful deregistration, got %v", got)
+	}
+	if got := testutil.ToFloat64(discoveryWatchEvents.WithLabelValues("memory", resultSuccess)); got != 2 {
+		t.Errorf("Expected 2 watch events, got %v", got)
+	}
+	if got := discoverCount(resultSuccess); got != 1 {
+		t.Errorf("Expected 1 successful discover observation, got %d", got)
+	}
+
+	// 后端失败时以 error 计数
+	inner.err = errors.New("backend unavailable")
+	registry.Register(ctx, service)
+	registry.Discover(ctx, "orders")
+	if got := testutil.ToFloat64(discoveryRegisterTotal.WithLabelValues("memory", resultError)); got != 1 {
+		t.Errorf("Expected 1 failed registration, got %v", got)
+	}
+	if got := discoverCount(resultError); got != 1 {
+		t.Errorf("Expected 1 failed discover observation, got %d", got)
+	}
+}
@@ -2,7 +2,10 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"go.uber.org/zap"
@@ -17,13 +20,75 @@ type Registry interface {
 	Close() error
 }
 
+// LeaseWatcher 可选的注册器接口，注册依赖租约（如 etcd）的注册器在租约丢失时通知调用方重新注册
+type LeaseWatcher interface {
+	// LeaseLost 返回服务当前注册的租约丢失通知，服务未注册时返回 nil
+	LeaseLost(service *ServiceInfo) <-chan struct{}
+}
+
+// registrationAttemptTimeout 单次注册请求的超时时间
+const registrationAttemptTimeout = 10 * time.Second
+
 // NewRegistry 创建服务注册器
+// 配置了 backends 时使用 CompositeRegistry 同时写入所有注册中心，配置了 cache_ttl 时使用 CachingRegistry 包装
 func NewRegistry(cfg *config.DiscoveryConfig, logger *zap.Logger) (Registry, error) {
+	registry, err := newRegistry(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Backends) > 0 {
+		registries := []Registry{registry}
+		for i, backend := range cfg.Backends {
+			backendCfg := *cfg
+			backendCfg.Type = backend.Type
+			backendCfg.Endpoints = backend.Endpoints
+			if backend.Namespace != "" {
+				backendCfg.Namespace = backend.Namespace
+			}
+			backendRegistry, err := newRegistry(&backendCfg, logger)
+			if err != nil {
+				for _, created := range registries {
+					created.Close()
+				}
+				return nil, fmt.Errorf("failed to create discovery backend %d: %w", i, err)
+			}
+			registries = append(registries, backendRegistry)
+		}
+		registry = NewCompositeRegistry(registries...)
+	}
+	if cfg.CacheTTL > 0 {
+		return NewCachingRegistry(registry, time.Duration(cfg.CacheTTL)*time.Second), nil
+	}
+	return registry, nil
+}
+
+// newRegistry 按类型创建服务注册器，使用 MetricsRegistry 包装以按后端类型记录指标
+func newRegistry(cfg *config.DiscoveryConfig, logger *zap.Logger) (Registry, error) {
+	registry, err := newBackendRegistry(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	return NewMetricsRegistry(registry, cfg.Type), nil
+}
+
+// newBackendRegistry 按类型创建注册中心客户端
+func newBackendRegistry(cfg *config.DiscoveryConfig, logger *zap.Logger) (Registry, error) {
 	switch cfg.Type {
 	case "etcd":
-		return NewEtcdRegistry(cfg.Endpoints, cfg.Namespace, logger)
+		return NewEtcdRegistry(cfg.Endpoints, cfg.Namespace, logger,
+			WithDialTimeout(time.Duration(cfg.DialTimeout)*time.Second),
+			WithDialKeepAliveTime(time.Duration(cfg.DialKeepAliveTime)*time.Second))
 	case "consul":
-		return NewConsulRegistry(cfg.Endpoints, cfg.Namespace, logger)
+		return NewConsulRegistry(cfg.Endpoints, cfg.Namespace, logger, WithConsulCheck(ConsulCheck{
+			Type:            cfg.HealthCheck.Type,
+			Interval:        time.Duration(cfg.HealthCheck.Interval) * time.Second,
+			Timeout:         time.Duration(cfg.HealthCheck.Timeout) * time.Second,
+			DeregisterAfter: time.Duration(cfg.HealthCheck.DeregisterAfter) * time.Second,
+			HTTPPath:        cfg.HealthCheck.HTTPPath,
+			HTTPPort:        cfg.HealthCheck.HTTPPort,
+			GRPCUseTLS:      cfg.HealthCheck.GRPCUseTLS,
+			TLSSkipVerify:   cfg.HealthCheck.TLSSkipVerify,
+		}))
 	default:
 		return nil, fmt.Errorf("unsupported discovery type: %s", cfg.Type)
 	}
@@ -33,7 +98,11 @@ func NewRegistry(cfg *config.DiscoveryConfig, logger *zap.Logger) (Registry, err
 type ServiceManager struct {
 	registry Registry
 	logger   *zap.Logger
+	
+	mu       sync.Mutex
 	services map[string]*ServiceInfo
+	// updated 在 SetStatus 重新注册服务后关闭并替换，通知 KeepRegistered 重新获取租约丢失通知
+	updated chan struct{}
 }
 
 // NewServiceManager 创建服务管理器
@@ -42,6 +111,7 @@ func NewServiceManager(registry Registry, logger *zap.Logger) *ServiceManager {
 		registry: registry,
 		logger:   logger,
 		services: make(map[string]*ServiceInfo),
+		updated:  make(chan struct{}),
 	}
 }
 
@@ -51,36 +121,192 @@ func (sm *ServiceManager) RegisterService(ctx context.Context, service *ServiceI
 		return err
 	}
 	
-	key := fmt.Sprintf("%s:%s:%d", service.Name, service.Address, service.Port)
-	sm.services[key] = service
+	sm.mu.Lock()
+	sm.services[serviceKey(service)] = service
+	sm.mu.Unlock()
 	
 	return nil
 }
 
+// RegisterWithRetry 注册服务，失败时按 backoff 重试，直到注册成功或 ctx 取消
+func (sm *ServiceManager) RegisterWithRetry(ctx context.Context, service *ServiceInfo, backoff RegistrationBackoff) error {
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, registrationAttemptTimeout)
+		err := sm.RegisterService(attemptCtx, service)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				sm.logger.Info("Service registered to discovery after retries",
+					zap.String("service", service.Name),
+					zap.Int("attempts", attempt))
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		
+		delay := backoff.Next(attempt)
+		sm.logger.Warn("Failed to register service to discovery, retrying",
+			zap.String("service", service.Name),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err))
+		
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// KeepRegistered 保持服务注册，直到 ctx 取消
+// 服务尚未通过当前管理器注册时先按 backoff 重试注册；注册器实现 LeaseWatcher 时，租约丢失后重新注册
+func (sm *ServiceManager) KeepRegistered(ctx context.Context, service *ServiceInfo, backoff RegistrationBackoff) {
+	registered := sm.isRegistered(service)
+	for {
+		if !registered {
+			if err := sm.RegisterWithRetry(ctx, service, backoff); err != nil {
+				return
+			}
+		}
+		
+		watcher, ok := sm.registry.(LeaseWatcher)
+		if !ok {
+			return
+		}
+		updated := sm.updatedChan()
+		lost := watcher.LeaseLost(service)
+		if lost == nil {
+			return
+		}
+		
+		select {
+		case <-ctx.Done():
+			return
+		case <-updated:
+			// 状态更新后服务已使用新租约注册，重新获取租约丢失通知
+			registered = true
+		case <-lost:
+			sm.logger.Warn("Service registration lost, re-registering",
+				zap.String("service", service.Name))
+			// 使用管理器保存的最新副本重新注册，保留 SetStatus 设置的状态
+			service = sm.current(service)
+			registered = false
+		}
+	}
+}
+
+// SetStatus 更新所有已注册服务上报的健康状态并重新注册，服务发现据此过滤不可用实例
+func (sm *ServiceManager) SetStatus(ctx context.Context, status string) error {
+	sm.mu.Lock()
+	services := make([]*ServiceInfo, 0, len(sm.services))
+	for _, service := range sm.services {
+		services = append(services, service)
+	}
+	sm.mu.Unlock()
+	
+	var errs []error
+	for _, service := range services {
+		if service.Metadata[MetadataStatus] == status {
+			continue
+		}
+		
+		updated := service.withStatus(status)
+		if err := sm.registry.Register(ctx, updated); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set status of service %s: %w", service.Name, err))
+			continue
+		}
+		
+		// 更新期间服务已被注销时撤回本次注册
+		key := serviceKey(service)
+		sm.mu.Lock()
+		_, ok := sm.services[key]
+		if ok {
+			sm.services[key] = updated
+		}
+		sm.mu.Unlock()
+		if !ok {
+			if err := sm.registry.Deregister(ctx, updated); err != nil {
+				errs = append(errs, fmt.Errorf("failed to deregister service %s: %w", service.Name, err))
+			}
+		}
+	}
+	
+	sm.mu.Lock()
+	close(sm.updated)
+	sm.updated = make(chan struct{})
+	sm.mu.Unlock()
+	
+	return errors.Join(errs...)
+}
+
+// updatedChan 返回当前的状态更新通知
+func (sm *ServiceManager) updatedChan() <-chan struct{} {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updated
+}
+
+// current 返回管理器保存的服务副本，未保存时返回传入的服务
+func (sm *ServiceManager) current(service *ServiceInfo) *ServiceInfo {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	
+	if stored, ok := sm.services[serviceKey(service)]; ok {
+		return stored
+	}
+	return service
+}
+
+// isRegistered 检查服务是否已通过当前管理器注册
+func (sm *ServiceManager) isRegistered(service *ServiceInfo) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	
+	_, ok := sm.services[serviceKey(service)]
+	return ok
+}
+
+// serviceKey 返回服务实例在管理器中的键
+func serviceKey(service *ServiceInfo) string {
+	return fmt.Sprintf("%s:%s:%d", service.Name, service.Address, service.Port)
+}
+
 // DeregisterService 注销服务
 func (sm *ServiceManager) DeregisterService(ctx context.Context, service *ServiceInfo) error {
 	if err := sm.registry.Deregister(ctx, service); err != nil {
 		return err
 	}
 	
-	key := fmt.Sprintf("%s:%s:%d", service.Name, service.Address, service.Port)
-	delete(sm.services, key)
+	sm.mu.Lock()
+	delete(sm.services, serviceKey(service))
+	sm.mu.Unlock()
 	
 	return nil
 }
 
-// DeregisterAll 注销所有服务
+// DeregisterAll 注销所有服务，返回所有注销失败的错误
 func (sm *ServiceManager) DeregisterAll(ctx context.Context) error {
-	for _, service := range sm.services {
+	sm.mu.Lock()
+	services := sm.services
+	sm.services = make(map[string]*ServiceInfo)
+	sm.mu.Unlock()
+	
+	var errs []error
+	for _, service := range services {
 		if err := sm.registry.Deregister(ctx, service); err != nil {
 			sm.logger.Error("Failed to deregister service",
 				zap.String("service", service.Name),
 				zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to deregister service %s: %w", service.Name, err))
 		}
 	}
 	
-	sm.services = make(map[string]*ServiceInfo)
-	return nil
+	return errors.Join(errs...)
 }
 
 // DiscoverServices 发现服务
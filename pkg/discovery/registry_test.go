@@ -0,0 +1,222 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// flakyRegistry 前若干次注册失败的注册器，实现 LeaseWatcher 以模拟租约丢失
+type flakyRegistry struct {
+	countingRegistry
+	failFirst int32
+	registers atomic.Int32
+
+	mu   sync.Mutex
+	lost chan struct{}
+	last *ServiceInfo
+}
+
+func (r *flakyRegistry) Register(ctx context.Context, service *ServiceInfo) error {
+	if r.registers.Add(1) <= r.failFirst {
+		return errors.New("registry unavailable")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lost = make(chan struct{})
+	r.last = service
+	return nil
+}
+
+// lastStatus 返回最近一次注册上报的状态
+func (r *flakyRegistry) lastStatus() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last.Metadata[MetadataStatus]
+}
+
+func (r *flakyRegistry) LeaseLost(service *ServiceInfo) <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lost
+}
+
+// loseLease 模拟当前注册的租约丢失
+func (r *flakyRegistry) loseLease() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	close(r.lost)
+}
+
+// waitRegisters 等待注册次数达到 n
+func waitRegisters(t *testing.T, registry *flakyRegistry, n int32) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.registers.Load() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d registration attempts, got %d", n, registry.registers.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+var testBackoff = RegistrationBackoff{InitialInterval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond}
+
+func TestRegistrationBackoffNext(t *testing.T) {
+	backoff := RegistrationBackoff{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     time.Second,
+		Multiplier:      2,
+		Jitter:          0.1,
+	}
+
+	tests := []struct {
+		attempt int
+		base    time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{50, time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			got := backoff.Next(tt.attempt)
+			low := time.Duration(float64(tt.base) * 0.9)
+			high := time.Duration(float64(tt.base) * 1.1)
+			if got < low || got > high {
+				t.Errorf("attempt %d: expected delay in [%v, %v], got %v", tt.attempt, low, high, got)
+			}
+		}
+	}
+
+	// 零值使用默认策略
+	if got := (RegistrationBackoff{}).Next(1); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+		t.Errorf("Expected default first delay around 1s, got %v", got)
+	}
+}
+
+func TestKeepRegisteredRetries(t *testing.T) {
+	registry := &flakyRegistry{failFirst: 3}
+	manager := NewServiceManager(registry, zap.NewNop())
+	service := &ServiceInfo{Name: "test-service", Address: "127.0.0.1", Port: 8080}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		manager.KeepRegistered(ctx, service, testBackoff)
+	}()
+
+	waitRegisters(t, registry, 4)
+	if !manager.isRegistered(service) {
+		t.Error("Expected service to be tracked after successful registration")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected KeepRegistered to return after ctx is cancelled")
+	}
+}
+
+func TestKeepRegisteredAfterLeaseLoss(t *testing.T) {
+	registry := &flakyRegistry{}
+	manager := NewServiceManager(registry, zap.NewNop())
+	service := &ServiceInfo{Name: "test-service", Address: "127.0.0.1", Port: 8080}
+
+	// 已注册的服务不重复注册，只监听租约
+	if err := manager.RegisterService(context.Background(), service); err != nil {
+		t.Fatalf("Failed to register service: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.KeepRegistered(ctx, service, testBackoff)
+
+	time.Sleep(20 * time.Millisecond)
+	if got := registry.registers.Load(); got != 1 {
+		t.Fatalf("Expected already registered service not to be re-registered, got %d registrations", got)
+	}
+
+	// 租约丢失后重新注册，重新注册失败时继续重试
+	registry.failFirst = 3
+	registry.loseLease()
+	waitRegisters(t, registry, 4)
+
+	registry.loseLease()
+	waitRegisters(t, registry, 5)
+}
+
+func TestSetStatus(t *testing.T) {
+	registry := &flakyRegistry{}
+	manager := NewServiceManager(registry, zap.NewNop())
+	service := &ServiceInfo{
+		Name:     "test-service",
+		Address:  "127.0.0.1",
+		Port:     8080,
+		Metadata: map[string]string{MetadataStatus: StatusServing, MetadataZone: "zone-a"},
+	}
+	if err := manager.RegisterService(context.Background(), service); err != nil {
+		t.Fatalf("Failed to register service: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.KeepRegistered(ctx, service, testBackoff)
+	time.Sleep(20 * time.Millisecond)
+
+	if err := manager.SetStatus(context.Background(), StatusNotServing); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	waitRegisters(t, registry, 2)
+	if got := registry.lastStatus(); got != StatusNotServing {
+		t.Errorf("Expected NOT_SERVING to be registered, got %q", got)
+	}
+	if registry.last.Metadata[MetadataZone] != "zone-a" {
+		t.Error("Expected other metadata to be kept")
+	}
+	if service.Metadata[MetadataStatus] != StatusServing {
+		t.Error("Expected caller's service metadata not to be modified")
+	}
+
+	// 状态未变化时不重复注册
+	if err := manager.SetStatus(context.Background(), StatusNotServing); err != nil {
+		t.Fatalf("Failed to set status: %v", err)
+	}
+	if got := registry.registers.Load(); got != 2 {
+		t.Errorf("Expected no re-registration for unchanged status, got %d registrations", got)
+	}
+
+	// 新租约丢失后重新注册，保留 NOT_SERVING
+	time.Sleep(20 * time.Millisecond)
+	registry.loseLease()
+	waitRegisters(t, registry, 3)
+	if got := registry.lastStatus(); got != StatusNotServing {
+		t.Errorf("Expected re-registration to keep NOT_SERVING, got %q", got)
+	}
+}
+
+func TestRegisterWithRetryCancelled(t *testing.T) {
+	registry := &flakyRegistry{failFirst: 1 << 30}
+	manager := NewServiceManager(registry, zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := manager.RegisterWithRetry(ctx, &ServiceInfo{Name: "test-service"}, testBackoff)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if registry.registers.Load() < 2 {
+		t.Errorf("Expected registration to be retried, got %d attempts", registry.registers.Load())
+	}
+}
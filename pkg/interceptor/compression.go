@@ -0,0 +1,42 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/compression"
+	"google.golang.org/grpc"
+)
+
+// CompressionUnaryInterceptor 一元调用响应压缩拦截器
+// 客户端在 grpc-accept-encoding 中声明支持 name 时，响应使用该算法压缩；否则保持 gRPC 默认行为
+func CompressionUnaryInterceptor(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		setSendCompressor(ctx, name)
+		return handler(ctx, req)
+	}
+}
+
+// CompressionStreamInterceptor 流式调用响应压缩拦截器
+func CompressionStreamInterceptor(name string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		setSendCompressor(stream.Context(), name)
+		return handler(srv, stream)
+	}
+}
+
+// setSendCompressor 在客户端支持时设置响应压缩算法
+func setSendCompressor(ctx context.Context, name string) {
+	if !compression.IsRegistered(name) {
+		return
+	}
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return
+	}
+	for _, s := range supported {
+		if s == name {
+			grpc.SetSendCompressor(ctx, name)
+			return
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package interceptor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/compression"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/stats"
+)
+
+// compressionRecorder 记录客户端收到的响应头中的压缩算法
+type compressionRecorder struct {
+	mu          sync.Mutex
+	compression string
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok && header.Client {
+		r.mu.Lock()
+		r.compression = header.Compression
+		r.mu.Unlock()
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *compressionRecorder) get() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.compression
+}
+
+func TestCompressionInterceptorNegotiation(t *testing.T) {
+	server := grpc.NewServer(grpc.UnaryInterceptor(CompressionUnaryInterceptor(compression.Deflate)))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	recorder := &compressionRecorder{}
+	conn := startBufconnServer(t, server, grpc.WithStatsHandler(recorder))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 请求不压缩，客户端在 grpc-accept-encoding 中声明支持 deflate，响应由拦截器协商为 deflate
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if got := recorder.get(); got != compression.Deflate {
+		t.Errorf("Expected response compressed with deflate, got %q", got)
+	}
+}
+
+func TestCompressionInterceptorUnregistered(t *testing.T) {
+	server := grpc.NewServer(grpc.UnaryInterceptor(CompressionUnaryInterceptor("snappy")))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+
+	recorder := &compressionRecorder{}
+	conn := startBufconnServer(t, server, grpc.WithStatsHandler(recorder))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 未注册的算法不影响调用，响应不压缩
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if got := recorder.get(); got != "" {
+		t.Errorf("Expected uncompressed response, got %q", got)
+	}
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineUnaryClientInterceptor 一元调用客户端截止时间传递拦截器
+// 在服务端处理请求时发起的下游调用会继承调用上下文的截止时间，并预留 hopBudget 给当前服务处理下游响应，
+// 剩余时间不足 hopBudget 时直接返回 DeadlineExceeded，避免下游在必然超时的请求上浪费资源
+func DeadlineUnaryClientInterceptor(hopBudget time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel, err := propagateDeadline(ctx, hopBudget)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// DeadlineStreamClientInterceptor 流式调用客户端截止时间传递拦截器
+func DeadlineStreamClientInterceptor(hopBudget time.Duration) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, cancel, err := propagateDeadline(ctx, hopBudget)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		// 流结束后由 gRPC 释放上下文，这里在上下文结束时取消计时器
+		go func() {
+			<-stream.Context().Done()
+			cancel()
+		}()
+		return stream, nil
+	}
+}
+
+// TimeoutUnaryClientInterceptor 一元调用客户端默认超时拦截器
+// 调用上下文没有截止时间时设置 timeout 后超时，避免下游无响应时调用无限期挂起；timeout 不大于 0 时不做处理
+func TimeoutUnaryClientInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// propagateDeadline 根据入站请求的截止时间计算下游调用的截止时间
+// 上下文不属于服务端请求或没有截止时间时保持不变
+func propagateDeadline(ctx context.Context, hopBudget time.Duration) (context.Context, context.CancelFunc, error) {
+	noop := func() {}
+	if _, ok := metadata.FromIncomingContext(ctx); !ok {
+		return ctx, noop, nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok || hopBudget <= 0 {
+		return ctx, noop, nil
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= hopBudget {
+		return nil, noop, status.Errorf(codes.DeadlineExceeded,
+			"remaining deadline %v is within the hop budget %v", remaining, hopBudget)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, deadline.Add(-hopBudget))
+	return ctx, cancel, nil
+}
@@ -0,0 +1,149 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startBufconnServer 启动测试服务器并返回连接到它的客户端连接
+func startBufconnServer(t *testing.T, server *grpc.Server, opts ...grpc.DialOption) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestDeadlinePropagation(t *testing.T) {
+	const hopBudget = 200 * time.Millisecond
+
+	// 下游服务记录收到的截止时间
+	var downstreamDeadline time.Time
+	downstream := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		downstreamDeadline, _ = ctx.Deadline()
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(downstream, health.NewServer())
+	downstreamConn := startBufconnServer(t, downstream, grpc.WithUnaryInterceptor(DeadlineUnaryClientInterceptor(hopBudget)))
+
+	// 上游服务在处理请求时调用下游
+	var inboundDeadline time.Time
+	upstream := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		inboundDeadline, _ = ctx.Deadline()
+		if _, err := grpc_health_v1.NewHealthClient(downstreamConn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(upstream, health.NewServer())
+	upstreamConn := startBufconnServer(t, upstream)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(upstreamConn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	if inboundDeadline.IsZero() || downstreamDeadline.IsZero() {
+		t.Fatalf("Expected deadlines on both hops, got inbound %v downstream %v", inboundDeadline, downstreamDeadline)
+	}
+	// 截止时间在传输中以剩余时长表示，允许少量误差
+	if gap := inboundDeadline.Sub(downstreamDeadline); gap < hopBudget-50*time.Millisecond {
+		t.Errorf("Expected downstream deadline to be about %v shorter than inbound, got %v", hopBudget, gap)
+	}
+}
+
+func TestDeadlinePropagationWithinBudget(t *testing.T) {
+	interceptor := DeadlineUnaryClientInterceptor(time.Second)
+
+	ctx, cancel := context.WithTimeout(metadata.NewIncomingContext(context.Background(), metadata.MD{}), 500*time.Millisecond)
+	defer cancel()
+
+	invoked := false
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		invoked = true
+		return nil
+	}
+
+	err := interceptor(ctx, "/test.Service/Method", nil, nil, nil, invoker)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+	if invoked {
+		t.Error("Expected call within hop budget not to be sent")
+	}
+}
+
+func TestDeadlinePropagationOutsideServer(t *testing.T) {
+	interceptor := DeadlineUnaryClientInterceptor(time.Second)
+
+	// 非服务端请求上下文保持调用方设置的截止时间
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+
+	var got time.Time
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = ctx.Deadline()
+		return nil
+	}
+
+	if err := interceptor(ctx, "/test.Service/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !got.Equal(expected) {
+		t.Errorf("Expected deadline %v to be unchanged, got %v", expected, got)
+	}
+}
+
+func TestTimeoutKeepsCallerDeadline(t *testing.T) {
+	interceptor := TimeoutUnaryClientInterceptor(time.Second)
+
+	var got time.Time
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		got, _ = ctx.Deadline()
+		return nil
+	}
+
+	// 调用方设置的截止时间优先于默认超时
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+	if err := interceptor(ctx, "/test.Service/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !got.Equal(expected) {
+		t.Errorf("Expected deadline %v to be unchanged, got %v", expected, got)
+	}
+
+	if err := interceptor(context.Background(), "/test.Service/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if remaining := time.Until(got); remaining <= 0 || remaining > time.Second {
+		t.Errorf("Expected default deadline within 1s, got %v", remaining)
+	}
+}
@@ -0,0 +1,101 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// deprecationLogInterval 同一废弃方法两次告警日志的最小间隔
+const deprecationLogInterval = time.Minute
+
+// grpc 废弃方法调用次数
+var grpcDeprecatedMethodCalls = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_deprecated_method_calls_total",
+		Help: "Total number of calls to deprecated gRPC methods",
+	},
+	[]string{"method"},
+)
+
+// deprecationTracker 记录废弃方法的调用
+type deprecationTracker struct {
+	logger   *zap.Logger
+	methods  map[string]struct{}
+	header   string
+	interval time.Duration
+	
+	mu         sync.Mutex
+	lastLogged map[string]time.Time
+}
+
+// newDeprecationTracker 创建废弃方法调用记录器，方法名为完整方法名，如 /pkg.Service/Method
+func newDeprecationTracker(logger *zap.Logger, methods []string, header string) *deprecationTracker {
+	t := &deprecationTracker{
+		logger:     logger,
+		methods:    make(map[string]struct{}, len(methods)),
+		header:     strings.ToLower(header),
+		interval:   deprecationLogInterval,
+		lastLogged: make(map[string]time.Time),
+	}
+	for _, method := range methods {
+		if !strings.HasPrefix(method, "/") {
+			method = "/" + method
+		}
+		t.methods[method] = struct{}{}
+	}
+	return t
+}
+
+// track 检查方法是否已废弃，已废弃时记录指标和限频告警日志
+func (t *deprecationTracker) track(method string) bool {
+	if _, ok := t.methods[method]; !ok {
+		return false
+	}
+	
+	grpcDeprecatedMethodCalls.WithLabelValues(method).Inc()
+	
+	now := time.Now()
+	t.mu.Lock()
+	last, logged := t.lastLogged[method]
+	shouldLog := !logged || now.Sub(last) >= t.interval
+	if shouldLog {
+		t.lastLogged[method] = now
+	}
+	t.mu.Unlock()
+	
+	if shouldLog {
+		t.logger.Warn("Deprecated gRPC method called", zap.String("method", method))
+	}
+	return true
+}
+
+// DeprecationUnaryInterceptor 一元调用废弃方法拦截器
+// 调用废弃方法时记录 grpc_deprecated_method_calls_total 指标和限频告警日志，header 非空时在响应头中标记
+func DeprecationUnaryInterceptor(logger *zap.Logger, methods []string, header string) grpc.UnaryServerInterceptor {
+	tracker := newDeprecationTracker(logger, methods, header)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if tracker.track(info.FullMethod) && tracker.header != "" {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(tracker.header, "true"))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// DeprecationStreamInterceptor 流式调用废弃方法拦截器
+func DeprecationStreamInterceptor(logger *zap.Logger, methods []string, header string) grpc.StreamServerInterceptor {
+	tracker := newDeprecationTracker(logger, methods, header)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if tracker.track(info.FullMethod) && tracker.header != "" {
+			_ = stream.SetHeader(metadata.Pairs(tracker.header, "true"))
+		}
+		return handler(srv, stream)
+	}
+}
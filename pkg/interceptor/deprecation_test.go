@@ -0,0 +1,46 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+func TestDeprecationUnaryInterceptor(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	interceptor := DeprecationUnaryInterceptor(zap.New(core), []string{"test.Service/OldMethod"}, "")
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	deprecated := &grpc.UnaryServerInfo{FullMethod: "/test.Service/OldMethod"}
+	current := &grpc.UnaryServerInfo{FullMethod: "/test.Service/NewMethod"}
+
+	counter := grpcDeprecatedMethodCalls.WithLabelValues("/test.Service/OldMethod")
+	before := testutil.ToFloat64(counter)
+
+	for i := 0; i < 3; i++ {
+		if _, err := interceptor(context.Background(), "request", deprecated, handler); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if _, err := interceptor(context.Background(), "request", current, handler); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := testutil.ToFloat64(counter) - before; got != 3 {
+		t.Errorf("Expected deprecated counter to increase by 3, got %v", got)
+	}
+	if got := testutil.ToFloat64(grpcDeprecatedMethodCalls.WithLabelValues("/test.Service/NewMethod")); got != 0 {
+		t.Errorf("Expected no deprecated calls for current method, got %v", got)
+	}
+
+	// 告警日志限频，同一方法在间隔内只记录一次
+	if logs.Len() != 1 {
+		t.Errorf("Expected 1 rate-limited warning, got %d", logs.Len())
+	}
+}
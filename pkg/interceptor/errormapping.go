@@ -0,0 +1,127 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// 处理器可以返回（或用 fmt.Errorf("...: %w", err) 包装）以下哨兵错误，由错误映射拦截器转换为对应的 gRPC 状态码
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrInvalid            = errors.New("invalid argument")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrUnauthenticated    = errors.New("unauthenticated")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrUnavailable        = errors.New("unavailable")
+)
+
+// sentinelCodes 哨兵错误与 gRPC 状态码的对应关系
+var sentinelCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{ErrNotFound, codes.NotFound},
+	{ErrInvalid, codes.InvalidArgument},
+	{ErrAlreadyExists, codes.AlreadyExists},
+	{ErrPermissionDenied, codes.PermissionDenied},
+	{ErrUnauthenticated, codes.Unauthenticated},
+	{ErrFailedPrecondition, codes.FailedPrecondition},
+	{ErrUnavailable, codes.Unavailable},
+}
+
+// ErrorClassifier 将处理器返回的错误分类为 gRPC 状态码，无法分类时返回 codes.Unknown
+type ErrorClassifier func(err error) codes.Code
+
+// ErrorDetailer 返回附加到错误状态上的详情，如 errdetails.BadRequest
+type ErrorDetailer func(err error) []protoadapt.MessageV1
+
+// ErrorMappingOption 错误映射拦截器选项
+type ErrorMappingOption func(*errorMappingOptions)
+
+// errorMappingOptions 错误映射拦截器配置
+type errorMappingOptions struct {
+	classifier ErrorClassifier
+	detailer   ErrorDetailer
+}
+
+// WithErrorClassifier 设置自定义错误分类函数，优先于哨兵错误匹配
+func WithErrorClassifier(classifier ErrorClassifier) ErrorMappingOption {
+	return func(o *errorMappingOptions) {
+		o.classifier = classifier
+	}
+}
+
+// WithErrorDetails 设置错误详情函数，返回的详情附加到映射后的状态上
+func WithErrorDetails(detailer ErrorDetailer) ErrorMappingOption {
+	return func(o *errorMappingOptions) {
+		o.detailer = detailer
+	}
+}
+
+// ErrorMappingUnaryInterceptor 一元调用错误映射拦截器
+// 处理器返回的普通错误按分类函数或哨兵错误转换为 gRPC 状态，消息保持为原错误信息；已经是 gRPC 状态的错误保持不变
+func ErrorMappingUnaryInterceptor(opts ...ErrorMappingOption) grpc.UnaryServerInterceptor {
+	options := newErrorMappingOptions(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, options.mapError(err)
+	}
+}
+
+// ErrorMappingStreamInterceptor 流式调用错误映射拦截器
+func ErrorMappingStreamInterceptor(opts ...ErrorMappingOption) grpc.StreamServerInterceptor {
+	options := newErrorMappingOptions(opts)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return options.mapError(handler(srv, stream))
+	}
+}
+
+// newErrorMappingOptions 应用错误映射拦截器选项
+func newErrorMappingOptions(opts []ErrorMappingOption) *errorMappingOptions {
+	o := &errorMappingOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// mapError 将错误转换为 gRPC 状态错误
+func (o *errorMappingOptions) mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	st := status.New(o.classify(err), err.Error())
+	if o.detailer != nil {
+		if details := o.detailer(err); len(details) > 0 {
+			if withDetails, detailErr := st.WithDetails(details...); detailErr == nil {
+				st = withDetails
+			}
+		}
+	}
+	return st.Err()
+}
+
+// classify 依次使用分类函数和哨兵错误确定状态码
+func (o *errorMappingOptions) classify(err error) codes.Code {
+	if o.classifier != nil {
+		if code := o.classifier(err); code != codes.Unknown {
+			return code
+		}
+	}
+	for _, sentinel := range sentinelCodes {
+		if errors.Is(err, sentinel.err) {
+			return sentinel.code
+		}
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,133 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+func TestErrorMappingSentinels(t *testing.T) {
+	interceptor := ErrorMappingUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{ErrNotFound, codes.NotFound},
+		{ErrInvalid, codes.InvalidArgument},
+		{ErrAlreadyExists, codes.AlreadyExists},
+		{ErrPermissionDenied, codes.PermissionDenied},
+		{ErrUnauthenticated, codes.Unauthenticated},
+		{ErrFailedPrecondition, codes.FailedPrecondition},
+		{ErrUnavailable, codes.Unavailable},
+		{fmt.Errorf("user 42: %w", ErrNotFound), codes.NotFound},
+		{errors.New("boom"), codes.Unknown},
+	}
+
+	for _, tt := range tests {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, tt.err
+		}
+		_, err := interceptor(context.Background(), "request", info, handler)
+		st, ok := status.FromError(err)
+		if !ok {
+			t.Errorf("%v: expected gRPC status error, got %v", tt.err, err)
+			continue
+		}
+		if st.Code() != tt.code {
+			t.Errorf("%v: expected code %v, got %v", tt.err, tt.code, st.Code())
+		}
+		if st.Message() != tt.err.Error() {
+			t.Errorf("%v: expected message %q, got %q", tt.err, tt.err.Error(), st.Message())
+		}
+	}
+}
+
+func TestErrorMappingPassThrough(t *testing.T) {
+	interceptor := ErrorMappingUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	// 已经是 gRPC 状态的错误保持不变
+	original := status.Error(codes.ResourceExhausted, "quota exceeded")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, original
+	}
+	if _, err := interceptor(context.Background(), "request", info, handler); err != original {
+		t.Errorf("Expected status error to pass through, got %v", err)
+	}
+
+	// 成功调用保持响应
+	handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	resp, err := interceptor(context.Background(), "request", info, handler)
+	if err != nil || resp != "response" {
+		t.Errorf("Expected response to pass through, got %v %v", resp, err)
+	}
+}
+
+func TestErrorMappingClassifierAndDetails(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	interceptor := ErrorMappingUnaryInterceptor(
+		WithErrorClassifier(func(err error) codes.Code {
+			if errors.Is(err, errQuota) {
+				return codes.ResourceExhausted
+			}
+			return codes.Unknown
+		}),
+		WithErrorDetails(func(err error) []protoadapt.MessageV1 {
+			if !errors.Is(err, ErrInvalid) {
+				return nil
+			}
+			return []protoadapt.MessageV1{&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "name", Description: "must not be empty"}},
+			}}
+		}),
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errQuota
+	}
+	if _, err := interceptor(context.Background(), "request", info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected classifier to map to ResourceExhausted, got %v", err)
+	}
+
+	// 分类函数无法识别时回退到哨兵错误，并附加详情
+	handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("create user: %w", ErrInvalid)
+	}
+	_, err := interceptor(context.Background(), "request", info, handler)
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", st.Code())
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("Expected 1 detail, got %d", len(details))
+	}
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	if !ok || badRequest.FieldViolations[0].Field != "name" {
+		t.Errorf("Expected BadRequest detail for field name, got %v", details[0])
+	}
+}
+
+func TestErrorMappingStreamInterceptor(t *testing.T) {
+	interceptor := ErrorMappingStreamInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/StreamMethod"}
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return ErrNotFound
+	}
+	if err := interceptor(nil, &mockServerStream{}, info, handler); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package interceptor
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultServerIdentityHeader 默认的服务器标识响应头
+const DefaultServerIdentityHeader = "server-id"
+
+// ResolveServerIdentity 解析服务器标识
+// 优先使用配置值，其次为 HOSTNAME 环境变量（Kubernetes 中为 Pod 名称），最后为系统主机名
+func ResolveServerIdentity(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if hostname := os.Getenv("HOSTNAME"); hostname != "" {
+		return hostname
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// ServerIdentityUnaryInterceptor 一元调用服务器标识拦截器，在响应头中携带服务器标识
+func ServerIdentityUnaryInterceptor(header, identity string) grpc.UnaryServerInterceptor {
+	md := identityMetadata(header, identity)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// 响应头发送失败不影响请求处理
+		_ = grpc.SetHeader(ctx, md)
+		return handler(ctx, req)
+	}
+}
+
+// ServerIdentityStreamInterceptor 流式调用服务器标识拦截器，在响应头中携带服务器标识
+func ServerIdentityStreamInterceptor(header, identity string) grpc.StreamServerInterceptor {
+	md := identityMetadata(header, identity)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		_ = stream.SetHeader(md)
+		return handler(srv, stream)
+	}
+}
+
+// identityMetadata 构建服务器标识元数据，元数据键必须为小写
+func identityMetadata(header, identity string) metadata.MD {
+	if header == "" {
+		header = DefaultServerIdentityHeader
+	}
+	return metadata.Pairs(strings.ToLower(header), identity)
+}
@@ -0,0 +1,73 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServerIdentityInterceptor(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(ServerIdentityUnaryInterceptor("Server-ID", "replica-1")),
+		grpc.StreamInterceptor(ServerIdentityStreamInterceptor("Server-ID", "replica-1")),
+	)
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// 一元调用
+	var header metadata.MD
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if got := header.Get("server-id"); len(got) != 1 || got[0] != "replica-1" {
+		t.Errorf("Expected server-id header replica-1, got %v", got)
+	}
+
+	// 流式调用
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health watch failed: %v", err)
+	}
+	streamHeader, err := stream.Header()
+	if err != nil {
+		t.Fatalf("Failed to read stream header: %v", err)
+	}
+	if got := streamHeader.Get("server-id"); len(got) != 1 || got[0] != "replica-1" {
+		t.Errorf("Expected stream server-id header replica-1, got %v", got)
+	}
+}
+
+func TestResolveServerIdentity(t *testing.T) {
+	t.Setenv("HOSTNAME", "pod-abc")
+
+	if got := ResolveServerIdentity("configured"); got != "configured" {
+		t.Errorf("Expected configured identity, got %s", got)
+	}
+	if got := ResolveServerIdentity(""); got != "pod-abc" {
+		t.Errorf("Expected identity from HOSTNAME, got %s", got)
+	}
+}
@@ -7,35 +7,35 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 // LoggingUnaryInterceptor 一元调用日志拦截器
-func LoggingUnaryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+func LoggingUnaryInterceptor(logger *zap.Logger, opts ...LoggingOption) grpc.UnaryServerInterceptor {
+	options := newLoggingOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		start := time.Now()
 		
-		// 调用处理器
+		// 调用处理器，内层认证拦截器通过 WithAuthSubject 写入的主体在调用结束后记录
+		ctx = withAuthSubjectHolder(ctx)
+		defer options.logPanic(ctx, logger, "unary", info.FullMethod, start)
 		resp, err := handler(ctx, req)
 		
 		// 记录日志
-		duration := time.Since(start)
-		code := codes.OK
-		if err != nil {
-			code = status.Code(err)
-		}
-		
-		fields := []zap.Field{
-			zap.String("method", info.FullMethod),
-			zap.Duration("duration", duration),
-			zap.String("code", code.String()),
-		}
+		options.logCall(ctx, logger, "unary", info.FullMethod, start, err)
 		
-		if err != nil {
-			fields = append(fields, zap.Error(err))
-			logger.Error("gRPC unary call failed", fields...)
-		} else {
-			logger.Info("gRPC unary call completed", fields...)
+		// 载荷只在 debug 级别记录
+		if options.logPayloads && logger.Core().Enabled(zap.DebugLevel) {
+			payloadFields := []zap.Field{
+				zap.String("method", info.FullMethod),
+				zap.String("request", options.formatPayload(info.FullMethod, req)),
+			}
+			if err == nil {
+				payloadFields = append(payloadFields, zap.String("response", options.formatPayload(info.FullMethod, resp)))
+			}
+			logger.Debug("gRPC unary call payload", payloadFields...)
 		}
 		
 		return resp, err
@@ -43,35 +43,155 @@ func LoggingUnaryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
 }
 
 // LoggingStreamInterceptor 流式调用日志拦截器
-func LoggingStreamInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor {
+func LoggingStreamInterceptor(logger *zap.Logger, opts ...LoggingOption) grpc.StreamServerInterceptor {
+	options := newLoggingOptions(opts)
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		start := time.Now()
 		
 		// 调用处理器
-		err := handler(srv, stream)
-		
-		// 记录日志
-		duration := time.Since(start)
-		code := codes.OK
-		if err != nil {
-			code = status.Code(err)
-		}
-		
-		fields := []zap.Field{
-			zap.String("method", info.FullMethod),
-			zap.Duration("duration", duration),
-			zap.String("code", code.String()),
+		ctx := withAuthSubjectHolder(stream.Context())
+		streamFields := []zap.Field{
 			zap.Bool("client_stream", info.IsClientStream),
 			zap.Bool("server_stream", info.IsServerStream),
 		}
+		defer options.logPanic(ctx, logger, "stream", info.FullMethod, start, streamFields...)
+		err := handler(srv, &loggingServerStream{ServerStream: stream, ctx: ctx})
 		
-		if err != nil {
-			fields = append(fields, zap.Error(err))
-			logger.Error("gRPC stream call failed", fields...)
-		} else {
-			logger.Info("gRPC stream call completed", fields...)
-		}
+		// 记录日志
+		options.logCall(ctx, logger, "stream", info.FullMethod, start, err, streamFields...)
 		
 		return err
 	}
+}
+
+// loggingServerStream 携带主体容器的服务端流
+type loggingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带主体容器的上下文
+func (s *loggingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// callerFields 返回调用方信息：对端地址、user-agent、:authority 和已认证主体
+// 只读取这几个元数据键，authorization 等凭证不会被记录
+func callerFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	c := callerFromContext(ctx)
+	if c.peer != "" {
+		fields = append(fields, zap.String("peer", c.peer))
+	}
+	if c.userAgent != "" {
+		fields = append(fields, zap.String("user_agent", c.userAgent))
+	}
+	if c.authority != "" {
+		fields = append(fields, zap.String("authority", c.authority))
+	}
+	if subject := AuthSubject(ctx); subject != "" {
+		fields = append(fields, zap.String("subject", subject))
+	}
+	return fields
+}
+
+// caller 调用方信息
+type caller struct {
+	peer      string
+	userAgent string
+	authority string
+}
+
+// callerFromContext 从上下文读取对端地址、user-agent 和 :authority
+func callerFromContext(ctx context.Context) caller {
+	var c caller
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		c.peer = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			c.userAgent = values[0]
+		}
+		if values := md.Get(":authority"); len(values) > 0 {
+			c.authority = values[0]
+		}
+	}
+	return c
+}
+
+// logCall 记录一次调用：配置了访问日志时写入访问日志，否则失败的调用以 error 级别记录，成功的调用按慢请求阈值记录
+func (o *loggingOptions) logCall(ctx context.Context, logger *zap.Logger, callType, method string, start time.Time, err error, extra ...zap.Field) {
+	duration := time.Since(start)
+	code := codes.OK
+	if err != nil {
+		code = status.Code(err)
+	}
+	
+	if o.accessLogger != nil {
+		o.logAccess(ctx, callType, method, code, duration, err)
+		return
+	}
+	
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.Duration("duration", duration),
+		zap.String("code", code.String()),
+	}
+	fields = append(fields, extra...)
+	fields = append(fields, callerFields(ctx)...)
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+		logger.Error("gRPC "+callType+" call failed", fields...)
+		return
+	}
+	o.logCompleted(logger, "gRPC "+callType+" call", duration, fields)
+}
+
+// logPanic 处理器 panic 时按恢复拦截器返回的 Internal 记录调用，再将 panic 交给外层的恢复拦截器
+// 需直接通过 defer 调用
+func (o *loggingOptions) logPanic(ctx context.Context, logger *zap.Logger, callType, method string, start time.Time, extra ...zap.Field) {
+	if r := recover(); r != nil {
+		o.logCall(ctx, logger, callType, method, start, errRecovered, extra...)
+		panic(r)
+	}
+}
+
+// logAccess 以固定字段写入一条访问日志
+func (o *loggingOptions) logAccess(ctx context.Context, callType, method string, code codes.Code, duration time.Duration, err error) {
+	c := callerFromContext(ctx)
+	errMsg := ""
+	if err != nil {
+		errMsg = status.Convert(err).Message()
+	}
+	o.accessLogger.Info("",
+		zap.String("type", callType),
+		zap.String("method", method),
+		zap.String("code", code.String()),
+		zap.Float64("duration_ms", float64(duration)/float64(time.Millisecond)),
+		zap.String("peer", c.peer),
+		zap.String("user_agent", c.userAgent),
+		zap.String("authority", c.authority),
+		zap.String("subject", AuthSubject(ctx)),
+		zap.String("request_id", RequestIDFromContext(ctx)),
+		zap.String("error", errMsg),
+	)
+}
+
+// logCompleted 记录成功完成的调用
+// 未设置慢请求阈值时以 info 级别记录，否则按耗时区分 debug 和 warn
+func (o *loggingOptions) logCompleted(logger *zap.Logger, call string, duration time.Duration, fields []zap.Field) {
+	threshold := o.slowThreshold.Get()
+	switch {
+	case threshold <= 0:
+		logger.Info(call+" completed", fields...)
+	case duration >= threshold:
+		fields = append(fields, zap.Duration("slow_threshold", threshold))
+		logger.Warn(call+" slow", fields...)
+	default:
+		logger.Debug(call+" completed", fields...)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,232 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoggingSlowThreshold(t *testing.T) {
+	const threshold = 20 * time.Millisecond
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Echo"}
+
+	tests := []struct {
+		name    string
+		sleep   time.Duration
+		err     error
+		level   zapcore.Level
+		message string
+	}{
+		{"fast", 0, nil, zapcore.DebugLevel, "gRPC unary call completed"},
+		{"slow", 2 * threshold, nil, zapcore.WarnLevel, "gRPC unary call slow"},
+		{"slow error", 2 * threshold, status.Error(codes.Internal, "boom"), zapcore.ErrorLevel, "gRPC unary call failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			interceptor := LoggingUnaryInterceptor(zap.New(core), WithSlowThreshold(threshold))
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				time.Sleep(tt.sleep)
+				return nil, tt.err
+			}
+			interceptor(context.Background(), nil, info, handler)
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 log entry, got %d", len(entries))
+			}
+			if entries[0].Level != tt.level || entries[0].Message != tt.message {
+				t.Errorf("Expected %s %q, got %s %q", tt.level, tt.message, entries[0].Level, entries[0].Message)
+			}
+			if tt.level == zapcore.WarnLevel {
+				if _, ok := entries[0].ContextMap()["slow_threshold"]; !ok {
+					t.Error("Expected slow_threshold field on slow call")
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingStreamSlowThreshold(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	interceptor := LoggingStreamInterceptor(zap.New(core), WithSlowThreshold(10*time.Millisecond))
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+	interceptor(nil, &mockServerStream{}, info, handler)
+
+	if entries := logs.FilterMessage("gRPC stream call slow").All(); len(entries) != 1 || entries[0].Level != zapcore.WarnLevel {
+		t.Errorf("Expected one warn slow stream log, got %v", logs.All())
+	}
+}
+
+func TestLoggingWithoutSlowThresholdUsesInfo(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	interceptor := LoggingUnaryInterceptor(zap.New(core))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Echo"}, handler)
+
+	if entries := logs.FilterMessage("gRPC unary call completed").All(); len(entries) != 1 || entries[0].Level != zapcore.InfoLevel {
+		t.Errorf("Expected one info completion log, got %v", logs.All())
+	}
+}
+
+// callerContext 返回携带对端地址和请求元数据的上下文
+func callerContext() context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 52311},
+	})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(
+		"user-agent", "grpc-go/1.74.2",
+		":authority", "orders.internal:9090",
+		"authorization", "Bearer secret-token",
+	))
+}
+
+// assertCallerFields 检查日志包含调用方字段且不包含凭证
+func assertCallerFields(t *testing.T, entry observer.LoggedEntry, subject string) {
+	t.Helper()
+	fields := entry.ContextMap()
+	expected := map[string]string{
+		"peer":       "10.0.0.7:52311",
+		"user_agent": "grpc-go/1.74.2",
+		"authority":  "orders.internal:9090",
+		"subject":    subject,
+	}
+	for key, value := range expected {
+		if fields[key] != value {
+			t.Errorf("Expected %s %q, got %v", key, value, fields[key])
+		}
+	}
+	for key, value := range fields {
+		if s, ok := value.(string); ok && strings.Contains(s, "secret-token") {
+			t.Errorf("Expected credentials not to be logged, found in %s", key)
+		}
+	}
+}
+
+func TestLoggingCallerFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	interceptor := LoggingUnaryInterceptor(zap.New(core))
+
+	// 内层认证拦截器写入的主体在调用结束后记录
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		WithAuthSubject(ctx, "user-42")
+		return nil, nil
+	}
+	interceptor(callerContext(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Echo"}, handler)
+
+	entries := logs.FilterMessage("gRPC unary call completed").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one completion log, got %v", logs.All())
+	}
+	assertCallerFields(t, entries[0], "user-42")
+}
+
+func TestLoggingStreamCallerFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	interceptor := LoggingStreamInterceptor(zap.New(core))
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		WithAuthSubject(stream.Context(), "service-account")
+		return status.Error(codes.PermissionDenied, "denied")
+	}
+	stream := &contextServerStream{ctx: callerContext()}
+	interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}, handler)
+
+	entries := logs.FilterMessage("gRPC stream call failed").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one failure log, got %v", logs.All())
+	}
+	assertCallerFields(t, entries[0], "service-account")
+}
+
+func TestLoggingAccessLog(t *testing.T) {
+	mainCore, mainLogs := observer.New(zapcore.DebugLevel)
+	accessCore, accessLogs := observer.New(zapcore.InfoLevel)
+	opts := []LoggingOption{WithAccessLog(zap.New(accessCore))}
+	unary := LoggingUnaryInterceptor(zap.New(mainCore), opts...)
+	stream := LoggingStreamInterceptor(zap.New(mainCore), opts...)
+
+	unary(callerContext(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Echo"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			WithAuthSubject(ctx, "user-42")
+			return nil, nil
+		})
+	stream(nil, &contextServerStream{ctx: callerContext()}, &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			return status.Error(codes.PermissionDenied, "denied")
+		})
+
+	// 调用记录只写入访问日志
+	if mainLogs.Len() != 0 {
+		t.Errorf("Expected no call records in application logger, got %v", mainLogs.All())
+	}
+	entries := accessLogs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 access log entries, got %v", entries)
+	}
+
+	// 每条记录包含相同的字段，缺失的值为空字符串
+	keys := []string{"type", "method", "code", "duration_ms", "peer", "user_agent", "authority", "subject", "request_id", "error"}
+	for _, entry := range entries {
+		fields := entry.ContextMap()
+		for _, key := range keys {
+			if _, ok := fields[key]; !ok {
+				t.Errorf("Expected field %s in access log entry %v", key, fields)
+			}
+		}
+		if len(fields) != len(keys) {
+			t.Errorf("Expected exactly %d fields, got %v", len(keys), fields)
+		}
+	}
+
+	unaryFields := entries[0].ContextMap()
+	if unaryFields["type"] != "unary" || unaryFields["method"] != "/test.Service/Echo" || unaryFields["code"] != "OK" ||
+		unaryFields["subject"] != "user-42" || unaryFields["peer"] != "10.0.0.7:52311" || unaryFields["error"] != "" {
+		t.Errorf("Unexpected unary access log entry %v", unaryFields)
+	}
+	streamFields := entries[1].ContextMap()
+	if streamFields["type"] != "stream" || streamFields["code"] != "PermissionDenied" || streamFields["error"] != "denied" {
+		t.Errorf("Unexpected stream access log entry %v", streamFields)
+	}
+}
+
+func TestAuthSubject(t *testing.T) {
+	if subject := AuthSubject(context.Background()); subject != "" {
+		t.Errorf("Expected empty subject, got %q", subject)
+	}
+	ctx := WithAuthSubject(context.Background(), "user-42")
+	if subject := AuthSubject(ctx); subject != "user-42" {
+		t.Errorf("Expected user-42, got %q", subject)
+	}
+}
+
+// contextServerStream 返回指定上下文的服务端流
+type contextServerStream struct {
+	mockServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,111 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestMessageSizeInterceptor(t *testing.T) {
+	const limit = 1024
+	const checkMethod = "/grpc.health.v1.Health/Check"
+	const watchMethod = "/grpc.health.v1.Health/Watch"
+
+	lis := bufconn.Listen(1024 * 1024)
+	core, logs := observer.New(zap.WarnLevel)
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(limit),
+		grpc.StatsHandler(MessageSizeStatsHandler(limit, zap.New(core))),
+		grpc.UnaryInterceptor(MessageSizeUnaryInterceptor(limit)),
+		grpc.StreamInterceptor(MessageSizeStreamInterceptor(limit)),
+	)
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// 未超限的请求正常处理
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	// 超过配置上限的一元请求由框架按配置上限拒绝，服务端记录方法名和上限
+	before := testutil.ToFloat64(grpcMessageSizeRejections.WithLabelValues(checkMethod))
+	oversized := &grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", limit+100)}
+	_, err = client.Check(context.Background(), oversized)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted, got %v", err)
+	}
+	// 框架先发送状态再通知 stats.Handler，指标可能稍晚于客户端收到错误
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(grpcMessageSizeRejections.WithLabelValues(checkMethod))-before < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := testutil.ToFloat64(grpcMessageSizeRejections.WithLabelValues(checkMethod)) - before; got != 1 {
+		t.Errorf("Expected rejection counter to increase by 1, got %v", got)
+	}
+	entries := logs.FilterMessage("Rejected oversized gRPC request").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected one rejection log, got %d", len(entries))
+	}
+	if msg := entries[0].ContextMap()["error"].(string); !strings.Contains(msg, checkMethod) || !strings.Contains(msg, "1024 bytes") {
+		t.Errorf("Expected rejection log to mention method and limit, got %q", msg)
+	}
+
+	// 超过配置上限的流请求同样由框架拒绝，拦截器记录指标
+	before = testutil.ToFloat64(grpcMessageSizeRejections.WithLabelValues(watchMethod))
+	stream, err := client.Watch(context.Background(), oversized)
+	if err != nil {
+		t.Fatalf("Failed to start watch: %v", err)
+	}
+	if _, err = stream.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected ResourceExhausted, got %v", err)
+	}
+	deadline = time.Now().Add(time.Second)
+	for testutil.ToFloat64(grpcMessageSizeRejections.WithLabelValues(watchMethod))-before < 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := testutil.ToFloat64(grpcMessageSizeRejections.WithLabelValues(watchMethod)) - before; got != 1 {
+		t.Errorf("Expected stream rejection counter to increase by 1, got %v", got)
+	}
+}
+
+func TestMessageSizeUnaryInterceptor(t *testing.T) {
+	// 框架未限制接收大小时由拦截器按序列化大小校验
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return req, nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	intercept := MessageSizeUnaryInterceptor(16)
+
+	if _, err := intercept(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "small"}, info, handler); err != nil {
+		t.Errorf("Expected small request to pass, got %v", err)
+	}
+	_, err := intercept(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: strings.Repeat("x", 32)}, info, handler)
+	if status.Code(err) != codes.ResourceExhausted || !strings.Contains(status.Convert(err).Message(), "/test.Service/Method") {
+		t.Errorf("Expected ResourceExhausted mentioning the method, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package interceptor
+
+import (
+	"context"
+	"path"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// methodACL 方法访问控制规则
+type methodACL struct {
+	allow []string
+	deny  []string
+	code  codes.Code
+}
+
+// MethodACLCode 将配置中的状态码名称转换为 gRPC 状态码，PERMISSION_DENIED 以外的值均返回 Unimplemented
+func MethodACLCode(name string) codes.Code {
+	if name == "PERMISSION_DENIED" {
+		return codes.PermissionDenied
+	}
+	return codes.Unimplemented
+}
+
+// MethodACLUnaryInterceptor 一元调用方法访问控制拦截器
+// 规则为 path.Match 模式，如 /pkg.Service/* 匹配整个服务；命中 deny 或 allow 非空且未命中 allow 时，
+// 在处理器执行前返回 code 状态；健康检查、反射和 channelz 服务不受限制
+func MethodACLUnaryInterceptor(allow, deny []string, code codes.Code) grpc.UnaryServerInterceptor {
+	acl := &methodACL{allow: allow, deny: deny, code: code}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := acl.check(info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MethodACLStreamInterceptor 流式调用方法访问控制拦截器
+func MethodACLStreamInterceptor(allow, deny []string, code codes.Code) grpc.StreamServerInterceptor {
+	acl := &methodACL{allow: allow, deny: deny, code: code}
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := acl.check(info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// check 检查方法是否允许调用，不允许时返回对应状态
+func (a *methodACL) check(fullMethod string) error {
+	if isInfrastructureMethod(fullMethod) {
+		return nil
+	}
+	if matchMethod(a.deny, fullMethod) || (len(a.allow) > 0 && !matchMethod(a.allow, fullMethod)) {
+		return status.Errorf(a.code, "method %s is not available", fullMethod)
+	}
+	return nil
+}
+
+// matchMethod 检查方法是否匹配任一模式，无效模式视为不匹配
+func matchMethod(patterns []string, fullMethod string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, fullMethod); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodACLUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name   string
+		allow  []string
+		deny   []string
+		code   codes.Code
+		method string
+		want   codes.Code
+	}{
+		{name: "denied method", deny: []string{"/admin.AdminService/*"}, code: codes.PermissionDenied, method: "/admin.AdminService/Reset", want: codes.PermissionDenied},
+		{name: "not denied", deny: []string{"/admin.AdminService/*"}, code: codes.PermissionDenied, method: "/user.UserService/GetUser", want: codes.OK},
+		{name: "allowed service", allow: []string{"/user.UserService/*"}, code: codes.Unimplemented, method: "/user.UserService/GetUser", want: codes.OK},
+		{name: "not in allow list", allow: []string{"/user.UserService/*"}, code: codes.Unimplemented, method: "/admin.AdminService/Reset", want: codes.Unimplemented},
+		{name: "deny overrides allow", allow: []string{"/user.UserService/*"}, deny: []string{"/user.UserService/DeleteUser"}, code: codes.Unimplemented, method: "/user.UserService/DeleteUser", want: codes.Unimplemented},
+		{name: "health check exempt", allow: []string{"/user.UserService/*"}, code: codes.Unimplemented, method: "/grpc.health.v1.Health/Check", want: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interceptor := MethodACLUnaryInterceptor(tt.allow, tt.deny, tt.code)
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return "response", nil
+			}
+
+			_, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+			// 被拦截的方法不调用处理器
+			if called != (tt.want == codes.OK) {
+				t.Errorf("Expected handler called %v, got %v", tt.want == codes.OK, called)
+			}
+		})
+	}
+}
+
+func TestMethodACLStreamInterceptor(t *testing.T) {
+	interceptor := MethodACLStreamInterceptor(nil, []string{"/admin.AdminService/*"}, codes.Unimplemented)
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}
+
+	err := interceptor(nil, &mockServerStream{}, &grpc.StreamServerInfo{FullMethod: "/admin.AdminService/Watch"}, handler)
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented, got %v", err)
+	}
+	if err := interceptor(nil, &mockServerStream{}, &grpc.StreamServerInfo{FullMethod: "/user.UserService/Watch"}, handler); err != nil {
+		t.Errorf("Expected allowed stream to pass, got %v", err)
+	}
+}
+
+func TestMethodACLCode(t *testing.T) {
+	if MethodACLCode("PERMISSION_DENIED") != codes.PermissionDenied {
+		t.Error("Expected PermissionDenied")
+	}
+	if MethodACLCode("UNIMPLEMENTED") != codes.Unimplemented || MethodACLCode("") != codes.Unimplemented {
+		t.Error("Expected Unimplemented by default")
+	}
+}
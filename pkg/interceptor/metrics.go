@@ -2,103 +2,286 @@ package interceptor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-var (
+// Metrics 注册到指定注册表的服务端请求指标
+// 多个应用在同一进程中运行时，各自使用独立的注册表可以避免指标冲突
+type Metrics struct {
+	registerer prometheus.Registerer
+
 	// gRPC 请求总数
-	grpcRequestsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "grpc_requests_total",
-			Help: "Total number of gRPC requests",
-		},
-		[]string{"method", "code"},
-	)
-	
-	// gRPC 请求持续时间
-	grpcRequestDuration = promauto.NewHistogramVec(
+	requestsTotal *prometheus.CounterVec
+	// gRPC 当前活跃请求数
+	activeRequests *prometheus.GaugeVec
+	// 流式调用发送和接收的消息数
+	streamMsgsSent     *prometheus.CounterVec
+	streamMsgsReceived *prometheus.CounterVec
+	// gRPC 请求持续时间，桶边界可通过 ConfigureLatencyBuckets 调整
+	requestDuration atomic.Pointer[prometheus.HistogramVec]
+
+	durationMu      sync.Mutex
+	durationBuckets []float64
+}
+
+// defaultMetrics 注册到 prometheus 默认注册表的指标，供包级拦截器使用
+var defaultMetrics = NewMetrics(prometheus.DefaultRegisterer)
+
+// NewMetrics 创建注册到 registry 的请求指标，registry 为 nil 时使用 prometheus 默认注册表
+// 注册表中已存在同名指标时复用已有指标
+func NewMetrics(registry prometheus.Registerer) *Metrics {
+	if registry == nil {
+		registry = prometheus.DefaultRegisterer
+	}
+
+	m := &Metrics{
+		registerer: registry,
+		requestsTotal: registerOrExisting(registry, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_requests_total",
+				Help: "Total number of gRPC requests",
+			},
+			[]string{"method", "code"},
+		)),
+		activeRequests: registerOrExisting(registry, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "grpc_active_requests",
+				Help: "Number of active gRPC requests",
+			},
+			[]string{"method"},
+		)),
+		streamMsgsSent: registerOrExisting(registry, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_stream_msgs_sent_total",
+				Help: "Total number of messages sent on gRPC streams",
+			},
+			[]string{"method"},
+		)),
+		streamMsgsReceived: registerOrExisting(registry, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "grpc_stream_msgs_received_total",
+				Help: "Total number of messages received on gRPC streams",
+			},
+			[]string{"method"},
+		)),
+		durationBuckets: prometheus.DefBuckets,
+	}
+	m.requestDuration.Store(registerOrExisting(registry, newRequestDurationHistogram(prometheus.DefBuckets)))
+	return m
+}
+
+// registerOrExisting 注册指标，已注册同名指标时返回已有的指标
+func registerOrExisting[T prometheus.Collector](registry prometheus.Registerer, collector T) T {
+	if err := registry.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return collector
+}
+
+// newRequestDurationHistogram 创建请求持续时间直方图，不注册
+func newRequestDurationHistogram(buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "grpc_request_duration_seconds",
 			Help:    "Duration of gRPC requests in seconds",
-			Buckets: prometheus.DefBuckets,
+			Buckets: buckets,
 		},
 		[]string{"method", "code"},
 	)
-	
-	// gRPC 当前活跃请求数
-	grpcActiveRequests = promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "grpc_active_requests",
-			Help: "Number of active gRPC requests",
-		},
-		[]string{"method"},
-	)
-)
+}
 
-// MetricsUnaryInterceptor 一元调用指标拦截器
-func MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
+// ConfigureLatencyBuckets 使用指定桶边界重建请求持续时间直方图，为空时使用 prometheus.DefBuckets
+// 重建会丢弃已记录的持续时间数据，应在服务启动前调用；桶边界未变化时不做任何处理
+func (m *Metrics) ConfigureLatencyBuckets(buckets []float64) error {
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return fmt.Errorf("latency buckets must be in strictly increasing order")
+		}
+	}
+
+	m.durationMu.Lock()
+	defer m.durationMu.Unlock()
+
+	if slices.Equal(buckets, m.durationBuckets) {
+		return nil
+	}
+
+	histogram := newRequestDurationHistogram(buckets)
+	old := m.requestDuration.Load()
+	m.registerer.Unregister(old)
+	if err := m.registerer.Register(histogram); err != nil {
+		m.registerer.MustRegister(old)
+		return fmt.Errorf("failed to register request duration histogram: %w", err)
+	}
+	m.requestDuration.Store(histogram)
+	m.durationBuckets = append([]float64(nil), buckets...)
+	return nil
+}
+
+// UnaryInterceptor 返回记录到当前指标的一元调用拦截器
+func (m *Metrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		start := time.Now()
 		method := info.FullMethod
-		
+
 		// 增加活跃请求数
-		grpcActiveRequests.WithLabelValues(method).Inc()
-		defer grpcActiveRequests.WithLabelValues(method).Dec()
-		
+		m.activeRequests.WithLabelValues(method).Inc()
+		defer m.activeRequests.WithLabelValues(method).Dec()
+		defer m.observePanic(ctx, method, start)
+
 		// 调用处理器
 		resp, err := handler(ctx, req)
-		
+
 		// 记录指标
-		duration := time.Since(start).Seconds()
-		code := codes.OK
-		if err != nil {
-			code = status.Code(err)
-		}
-		
-		codeStr := strconv.Itoa(int(code))
-		grpcRequestsTotal.WithLabelValues(method, codeStr).Inc()
-		grpcRequestDuration.WithLabelValues(method, codeStr).Observe(duration)
-		
+		m.observe(ctx, method, start, err)
+
 		return resp, err
 	}
 }
 
-// MetricsStreamInterceptor 流式调用指标拦截器
-func MetricsStreamInterceptor() grpc.StreamServerInterceptor {
+// StreamInterceptor 返回记录到当前指标的流式调用拦截器
+func (m *Metrics) StreamInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		start := time.Now()
 		method := info.FullMethod
-		
+
 		// 增加活跃请求数
-		grpcActiveRequests.WithLabelValues(method).Inc()
-		defer grpcActiveRequests.WithLabelValues(method).Dec()
-		
-		// 调用处理器
-		err := handler(srv, stream)
-		
+		m.activeRequests.WithLabelValues(method).Inc()
+		defer m.activeRequests.WithLabelValues(method).Dec()
+		defer m.observePanic(stream.Context(), method, start)
+
+		// 调用处理器，统计流上收发的消息数
+		err := handler(srv, &metricsServerStream{
+			ServerStream: stream,
+			sent:         m.streamMsgsSent.WithLabelValues(method),
+			received:     m.streamMsgsReceived.WithLabelValues(method),
+		})
+
 		// 记录指标
-		duration := time.Since(start).Seconds()
-		code := codes.OK
-		if err != nil {
-			code = status.Code(err)
-		}
-		
-		codeStr := strconv.Itoa(int(code))
-		grpcRequestsTotal.WithLabelValues(method, codeStr).Inc()
-		grpcRequestDuration.WithLabelValues(method, codeStr).Observe(duration)
-		
+		m.observe(stream.Context(), method, start, err)
+
 		return err
 	}
 }
 
+// metricsServerStream 统计成功发送和接收消息数的服务端流
+type metricsServerStream struct {
+	grpc.ServerStream
+	sent     prometheus.Counter
+	received prometheus.Counter
+}
+
+func (s *metricsServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Inc()
+	}
+	return err
+}
+
+func (s *metricsServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received.Inc()
+	}
+	return err
+}
+
+// observe 记录请求次数和持续时间
+// 上下文中有已采样的 span 时，持续时间附带 trace_id 和 span_id 样本（exemplar），可从直方图跳转到对应的追踪
+func (m *Metrics) observe(ctx context.Context, method string, start time.Time, err error) {
+	duration := time.Since(start).Seconds()
+	codeStr := strconv.Itoa(int(requestCode(ctx, err)))
+	m.requestsTotal.WithLabelValues(method, codeStr).Inc()
+	observer := m.requestDuration.Load().WithLabelValues(method, codeStr)
+	if labels := traceExemplar(ctx); labels != nil {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(duration, labels)
+			return
+		}
+	}
+	observer.Observe(duration)
+}
+
+// requestCode 返回调用的状态码，与 gRPC 服务端一致，处理器直接返回的 context 错误记为 Canceled 或 DeadlineExceeded
+// 客户端已取消调用时，处理器返回的错误通常由取消引起，记为 Canceled 以便与服务端故障区分；panic 仍记为 Internal
+func requestCode(ctx context.Context, err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if errors.Is(ctx.Err(), context.Canceled) && err != errRecovered {
+		return codes.Canceled
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+	return status.FromContextError(err).Code()
+}
+
+// traceExemplar 返回上下文中已采样 span 的样本标签，没有时返回 nil
+// 未采样的追踪不会被导出，不作为样本记录
+func traceExemplar(ctx context.Context) prometheus.Labels {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() || !spanContext.IsSampled() {
+		return nil
+	}
+	return prometheus.Labels{
+		"trace_id": spanContext.TraceID().String(),
+		"span_id":  spanContext.SpanID().String(),
+	}
+}
+
+// observePanic 处理器 panic 时按恢复拦截器返回的 Internal 记录调用，再将 panic 交给外层的恢复拦截器
+// 需直接通过 defer 调用
+func (m *Metrics) observePanic(ctx context.Context, method string, start time.Time) {
+	if r := recover(); r != nil {
+		m.observe(ctx, method, start, errRecovered)
+		panic(r)
+	}
+}
+
+// DefaultMetrics 返回注册到 prometheus 默认注册表的指标
+func DefaultMetrics() *Metrics {
+	return defaultMetrics
+}
+
+// ConfigureLatencyBuckets 调整默认注册表中请求持续时间直方图的桶边界
+func ConfigureLatencyBuckets(buckets []float64) error {
+	return defaultMetrics.ConfigureLatencyBuckets(buckets)
+}
+
+// MetricsUnaryInterceptor 一元调用指标拦截器，指标注册到 prometheus 默认注册表
+func MetricsUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return defaultMetrics.UnaryInterceptor()
+}
+
+// MetricsStreamInterceptor 流式调用指标拦截器，指标注册到 prometheus 默认注册表
+func MetricsStreamInterceptor() grpc.StreamServerInterceptor {
+	return defaultMetrics.StreamInterceptor()
+}
+
 // GetMetricsRegistry 获取指标注册表
 func GetMetricsRegistry() *prometheus.Registry {
 	return prometheus.DefaultRegisterer.(*prometheus.Registry)
-}
\ No newline at end of file
+}
@@ -2,9 +2,12 @@ package interceptor
 
 import (
 	"context"
+	"slices"
+	"strconv"
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -194,6 +197,261 @@ func (m *mockServerStream) SendMsg(interface{}) error    { return nil }
 func (m *mockServerStream) RecvMsg(interface{}) error    { return nil }
 
 // BenchmarkUnaryServerInterceptor 性能测试
+// durationBucketBounds 从默认注册表中读取请求持续时间直方图的桶边界
+func durationBucketBounds(t *testing.T, method string) []float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "grpc_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" && label.GetValue() == method {
+					var bounds []float64
+					for _, bucket := range metric.GetHistogram().GetBucket() {
+						bounds = append(bounds, bucket.GetUpperBound())
+					}
+					return bounds
+				}
+			}
+		}
+	}
+	t.Fatalf("Metric grpc_request_duration_seconds for %s not found", method)
+	return nil
+}
+
+func TestConfigureLatencyBuckets(t *testing.T) {
+	buckets := []float64{0.0005, 0.001, 0.005, 0.01}
+	if err := ConfigureLatencyBuckets(buckets); err != nil {
+		t.Fatalf("Failed to configure buckets: %v", err)
+	}
+	t.Cleanup(func() { ConfigureLatencyBuckets(nil) })
+
+	interceptor := MetricsUnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/BucketMethod"}
+	if _, err := interceptor(context.Background(), "request", info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if bounds := durationBucketBounds(t, "/test.Service/BucketMethod"); !slices.Equal(bounds, buckets) {
+		t.Errorf("Expected bucket bounds %v, got %v", buckets, bounds)
+	}
+
+	// 未设置时恢复默认桶
+	if err := ConfigureLatencyBuckets(nil); err != nil {
+		t.Fatalf("Failed to restore default buckets: %v", err)
+	}
+	if _, err := interceptor(context.Background(), "request", info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bounds := durationBucketBounds(t, "/test.Service/BucketMethod"); !slices.Equal(bounds, prometheus.DefBuckets) {
+		t.Errorf("Expected default bucket bounds, got %v", bounds)
+	}
+
+	// 非递增的桶边界被拒绝
+	if err := ConfigureLatencyBuckets([]float64{0.1, 0.01}); err == nil {
+		t.Error("Expected error for buckets that are not increasing")
+	}
+}
+
+func TestNewMetricsCustomRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/IsolatedMethod"}
+	metrics.UnaryInterceptor()(context.Background(), "request", info, handler)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	expected := []string{"grpc_active_requests", "grpc_request_duration_seconds", "grpc_requests_total"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected only %v in custom registry, got %v", expected, names)
+	}
+
+	// 再次创建时复用已注册的指标
+	again := NewMetrics(registry)
+	if again.requestsTotal != metrics.requestsTotal {
+		t.Error("Expected existing collectors to be reused")
+	}
+
+	// 自定义注册表中的桶边界可以独立调整
+	if err := metrics.ConfigureLatencyBuckets([]float64{0.01, 0.1}); err != nil {
+		t.Fatalf("Failed to configure buckets: %v", err)
+	}
+	metrics.UnaryInterceptor()(context.Background(), "request", info, handler)
+	families, _ = registry.Gather()
+	for _, family := range families {
+		if family.GetName() == "grpc_request_duration_seconds" {
+			if got := len(family.GetMetric()[0].GetHistogram().GetBucket()); got != 2 {
+				t.Errorf("Expected 2 buckets, got %d", got)
+			}
+		}
+	}
+}
+
+func TestMetricsTraceExemplar(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	tp, _ := newTestTracerProvider(t)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	metrics.UnaryInterceptor()(ctx, "request", &grpc.UnaryServerInfo{FullMethod: "/test.Service/Traced"}, handler)
+	span.End()
+	metrics.UnaryInterceptor()(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: "/test.Service/Untraced"}, handler)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+	exemplars := make(map[string]map[string]string)
+	for _, family := range families {
+		if family.GetName() != "grpc_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var method string
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "method" {
+					method = label.GetValue()
+				}
+			}
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				if exemplar := bucket.GetExemplar(); exemplar != nil {
+					labels := make(map[string]string)
+					for _, label := range exemplar.GetLabel() {
+						labels[label.GetName()] = label.GetValue()
+					}
+					exemplars[method] = labels
+				}
+			}
+		}
+	}
+
+	// 有活跃 span 的调用附带 trace_id 和 span_id 样本
+	traced := exemplars["/test.Service/Traced"]
+	if traced["trace_id"] != span.SpanContext().TraceID().String() || traced["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("Expected exemplar with trace %s, got %v", span.SpanContext().TraceID(), traced)
+	}
+	if _, ok := exemplars["/test.Service/Untraced"]; ok {
+		t.Error("Expected no exemplar without an active span")
+	}
+}
+
+func TestMetricsStreamMessageCounts(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		for i := 0; i < 3; i++ {
+			if err := stream.RecvMsg(nil); err != nil {
+				return err
+			}
+		}
+		for i := 0; i < 5; i++ {
+			if err := stream.SendMsg(nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Chat"}
+	if err := metrics.StreamInterceptor()(nil, &mockServerStream{}, info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := testutil.ToFloat64(metrics.streamMsgsReceived.WithLabelValues(info.FullMethod)); got != 3 {
+		t.Errorf("Expected 3 received messages, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.streamMsgsSent.WithLabelValues(info.FullMethod)); got != 5 {
+		t.Errorf("Expected 5 sent messages, got %v", got)
+	}
+}
+
+func TestMetricsClientCancellation(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Slow"}
+
+	// 处理器等待期间客户端取消调用，直接返回 ctx.Err()
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	go func() {
+		<-started
+		cancel()
+	}()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	metrics.UnaryInterceptor()(ctx, "request", info, handler)
+
+	canceled := strconv.Itoa(int(codes.Canceled))
+	unknown := strconv.Itoa(int(codes.Unknown))
+	if got := testutil.ToFloat64(metrics.requestsTotal.WithLabelValues(info.FullMethod, canceled)); got != 1 {
+		t.Errorf("Expected 1 canceled request, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.requestsTotal.WithLabelValues(info.FullMethod, unknown)); got != 0 {
+		t.Errorf("Expected cancellation not to be recorded as Unknown, got %v", got)
+	}
+
+	// 客户端取消后处理器的 panic 仍记为 Internal
+	panicking := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	}
+	func() {
+		defer func() { recover() }()
+		metrics.UnaryInterceptor()(ctx, "request", info, panicking)
+	}()
+	internal := strconv.Itoa(int(codes.Internal))
+	if got := testutil.ToFloat64(metrics.requestsTotal.WithLabelValues(info.FullMethod, internal)); got != 1 {
+		t.Errorf("Expected panic to be recorded as Internal, got %v", got)
+	}
+}
+
+func TestRequestCode(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected codes.Code
+	}{
+		{"success", context.Background(), nil, codes.OK},
+		{"status error", context.Background(), status.Error(codes.NotFound, "missing"), codes.NotFound},
+		{"context deadline", context.Background(), context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"client canceled", canceledCtx, status.Error(codes.Unavailable, "db closed"), codes.Canceled},
+		{"success after cancel", canceledCtx, nil, codes.OK},
+	}
+	for _, tt := range tests {
+		if got := requestCode(tt.ctx, tt.err); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
 func BenchmarkUnaryServerInterceptor(b *testing.B) {
 	interceptor := MetricsUnaryInterceptor()
 	
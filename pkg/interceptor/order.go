@@ -0,0 +1,32 @@
+package interceptor
+
+// 内置拦截器名称，用于 grpc.server.interceptor_order
+const (
+	InterceptorRecovery = "recovery"
+	InterceptorLogging  = "logging"
+	InterceptorMetrics  = "metrics"
+)
+
+// DefaultInterceptorOrder 内置拦截器的默认顺序（由外到内）
+// 恢复拦截器位于最外层，日志和指标拦截器中的 panic 也能被恢复
+var DefaultInterceptorOrder = []string{InterceptorRecovery, InterceptorLogging, InterceptorMetrics}
+
+// OrderBuiltins 按 order（由外到内）排列内置拦截器，order 为空时使用 DefaultInterceptorOrder
+// 未知名称和重复名称被忽略，builtins 中未出现在 order 里的拦截器按默认顺序追加在最内层
+func OrderBuiltins[T any](order []string, builtins map[string]T) []T {
+	if len(order) == 0 {
+		order = DefaultInterceptorOrder
+	}
+	
+	var ordered []T
+	added := make(map[string]bool)
+	for _, name := range append(append([]string(nil), order...), DefaultInterceptorOrder...) {
+		interceptor, ok := builtins[name]
+		if !ok || added[name] {
+			continue
+		}
+		added[name] = true
+		ordered = append(ordered, interceptor)
+	}
+	return ordered
+}
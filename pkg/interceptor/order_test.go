@@ -0,0 +1,31 @@
+package interceptor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderBuiltins(t *testing.T) {
+	builtins := map[string]string{
+		InterceptorRecovery: "recovery",
+		InterceptorLogging:  "logging",
+		InterceptorMetrics:  "metrics",
+	}
+	tests := []struct {
+		name  string
+		order []string
+		want  []string
+	}{
+		{name: "default", want: []string{"recovery", "logging", "metrics"}},
+		{name: "explicit", order: []string{"logging", "metrics", "recovery"}, want: []string{"logging", "metrics", "recovery"}},
+		{name: "missing appended", order: []string{"metrics"}, want: []string{"metrics", "recovery", "logging"}},
+		{name: "unknown and duplicate ignored", order: []string{"auth", "logging", "logging"}, want: []string{"logging", "recovery", "metrics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OrderBuiltins(tt.order, builtins); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
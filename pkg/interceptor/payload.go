@@ -0,0 +1,106 @@
+package interceptor
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultPayloadMaxSize 载荷日志默认最大长度（字节）
+const DefaultPayloadMaxSize = 1024
+
+// PayloadRedactor 载荷脱敏钩子，返回用于记录日志的消息
+// 实现不应修改原消息，需要清除字段时应先 proto.Clone
+type PayloadRedactor func(fullMethod string, msg proto.Message) proto.Message
+
+// LoggingOption 日志拦截器选项
+type LoggingOption func(*loggingOptions)
+
+// loggingOptions 日志拦截器配置
+type loggingOptions struct {
+	logPayloads    bool
+	payloadMaxSize int
+	redactor       PayloadRedactor
+	slowThreshold  *Threshold
+	accessLogger   *zap.Logger
+}
+
+// WithPayloadLogging 启用请求和响应载荷日志，仅在日志级别为 debug 时记录
+// maxSize 为单个载荷的最大长度，超出部分截断，小于等于 0 时使用默认值
+func WithPayloadLogging(enabled bool, maxSize int) LoggingOption {
+	return func(o *loggingOptions) {
+		o.logPayloads = enabled
+		o.payloadMaxSize = maxSize
+	}
+}
+
+// WithPayloadRedactor 设置载荷脱敏钩子
+func WithPayloadRedactor(redactor PayloadRedactor) LoggingOption {
+	return func(o *loggingOptions) {
+		o.redactor = redactor
+	}
+}
+
+// WithSlowThreshold 设置慢请求阈值
+// 大于 0 时，耗时低于阈值的成功调用以 debug 级别记录，达到阈值的以 warn 级别记录；失败调用始终为 error
+func WithSlowThreshold(threshold time.Duration) LoggingOption {
+	return func(o *loggingOptions) {
+		o.slowThreshold = NewThreshold(threshold)
+	}
+}
+
+// WithDynamicSlowThreshold 使用运行时可调整的慢请求阈值，配置重载后无需重建拦截器
+func WithDynamicSlowThreshold(threshold *Threshold) LoggingOption {
+	return func(o *loggingOptions) {
+		o.slowThreshold = threshold
+	}
+}
+
+// WithAccessLog 将每次调用的记录写入访问日志器，不再写入主日志器，载荷日志仍写入主日志器
+// 访问日志字段固定为 type、method、code、duration_ms、peer、user_agent、authority、subject、request_id 和 error，
+// 缺失的值记录为空字符串，logger 为 nil 时不启用
+func WithAccessLog(logger *zap.Logger) LoggingOption {
+	return func(o *loggingOptions) {
+		o.accessLogger = logger
+	}
+}
+
+// newLoggingOptions 应用日志拦截器选项
+func newLoggingOptions(opts []LoggingOption) *loggingOptions {
+	o := &loggingOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.payloadMaxSize <= 0 {
+		o.payloadMaxSize = DefaultPayloadMaxSize
+	}
+	if o.slowThreshold == nil {
+		o.slowThreshold = NewThreshold(0)
+	}
+	return o
+}
+
+// formatPayload 将消息格式化为 JSON 并按最大长度截断
+func (o *loggingOptions) formatPayload(fullMethod string, msg interface{}) string {
+	var payload string
+	if pm, ok := msg.(proto.Message); ok {
+		if o.redactor != nil {
+			pm = o.redactor(fullMethod, pm)
+		}
+		data, err := protojson.Marshal(pm)
+		if err != nil {
+			return fmt.Sprintf("<failed to marshal payload: %v>", err)
+		}
+		payload = string(data)
+	} else {
+		payload = fmt.Sprintf("%v", msg)
+	}
+	
+	if len(payload) > o.payloadMaxSize {
+		return fmt.Sprintf("%s...(truncated, %d bytes)", payload[:o.payloadMaxSize], len(payload))
+	}
+	return payload
+}
@@ -0,0 +1,102 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// callWithPayloadLogging 使用日志拦截器执行一次调用，返回载荷日志
+func callWithPayloadLogging(t *testing.T, level zapcore.Level, req proto.Message, opts ...LoggingOption) []observer.LoggedEntry {
+	t.Helper()
+
+	core, logs := observer.New(level)
+	interceptor := LoggingUnaryInterceptor(zap.New(core), opts...)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return wrapperspb.String("pong"), nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Echo"}
+
+	if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	return logs.FilterMessage("gRPC unary call payload").All()
+}
+
+func TestPayloadLoggingDisabledByDefault(t *testing.T) {
+	entries := callWithPayloadLogging(t, zap.DebugLevel, wrapperspb.String("ping"))
+	if len(entries) != 0 {
+		t.Errorf("Expected no payload logs without the flag, got %d", len(entries))
+	}
+}
+
+func TestPayloadLoggingRequiresDebugLevel(t *testing.T) {
+	entries := callWithPayloadLogging(t, zap.InfoLevel, wrapperspb.String("ping"), WithPayloadLogging(true, 0))
+	if len(entries) != 0 {
+		t.Errorf("Expected no payload logs at info level, got %d", len(entries))
+	}
+}
+
+func TestPayloadLoggingEnabled(t *testing.T) {
+	entries := callWithPayloadLogging(t, zap.DebugLevel, wrapperspb.String("ping"), WithPayloadLogging(true, 0))
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 payload log, got %d", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	if request, _ := fields["request"].(string); !strings.Contains(request, "ping") {
+		t.Errorf("Expected request payload to contain ping, got %q", request)
+	}
+	if response, _ := fields["response"].(string); !strings.Contains(response, "pong") {
+		t.Errorf("Expected response payload to contain pong, got %q", response)
+	}
+}
+
+func TestPayloadLoggingTruncated(t *testing.T) {
+	req := wrapperspb.String(strings.Repeat("x", 100))
+	entries := callWithPayloadLogging(t, zap.DebugLevel, req, WithPayloadLogging(true, 16))
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 payload log, got %d", len(entries))
+	}
+
+	request, _ := entries[0].ContextMap()["request"].(string)
+	if !strings.Contains(request, "truncated") {
+		t.Errorf("Expected request payload to be truncated, got %q", request)
+	}
+	if strings.Count(request, "x") > 16 {
+		t.Errorf("Expected at most 16 bytes of payload, got %q", request)
+	}
+}
+
+func TestPayloadLoggingRedactor(t *testing.T) {
+	redactor := func(fullMethod string, msg proto.Message) proto.Message {
+		if _, ok := msg.(*wrapperspb.StringValue); ok {
+			return wrapperspb.String("[REDACTED]")
+		}
+		return msg
+	}
+
+	req := wrapperspb.String("secret-token")
+	entries := callWithPayloadLogging(t, zap.DebugLevel, req,
+		WithPayloadLogging(true, 0), WithPayloadRedactor(redactor))
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 payload log, got %d", len(entries))
+	}
+
+	request, _ := entries[0].ContextMap()["request"].(string)
+	if strings.Contains(request, "secret-token") || !strings.Contains(request, "[REDACTED]") {
+		t.Errorf("Expected request payload to be redacted, got %q", request)
+	}
+	if req.GetValue() != "secret-token" {
+		t.Error("Expected original request not to be modified")
+	}
+}
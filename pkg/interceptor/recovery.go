@@ -10,6 +10,9 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// errRecovered 恢复拦截器在处理器 panic 时返回的错误，内层的日志和指标拦截器以同样的错误记录 panic 的调用
+var errRecovered = status.Error(codes.Internal, "Internal server error")
+
 // RecoveryUnaryInterceptor 一元调用恢复拦截器
 func RecoveryUnaryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
@@ -23,7 +26,7 @@ func RecoveryUnaryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
 				)
 				
 				// 返回内部错误
-				err = status.Errorf(codes.Internal, "Internal server error")
+				err = errRecovered
 			}
 		}()
 		
@@ -44,7 +47,7 @@ func RecoveryStreamInterceptor(logger *zap.Logger) grpc.StreamServerInterceptor
 				)
 				
 				// 返回内部错误
-				err = status.Errorf(codes.Internal, "Internal server error")
+				err = errRecovered
 			}
 		}()
 		
@@ -0,0 +1,67 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// reflectionServicePrefixes 反射服务的方法前缀
+var reflectionServicePrefixes = []string{
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// ReflectionAuthUnaryInterceptor 一元调用反射服务访问控制拦截器
+// 反射服务只有流式方法，一元拦截器用于在自定义反射实现上保持一致
+func ReflectionAuthUnaryInterceptor(key, token string) grpc.UnaryServerInterceptor {
+	key = strings.ToLower(key)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isReflectionMethod(info.FullMethod) {
+			if err := checkReflectionToken(ctx, key, token); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ReflectionAuthStreamInterceptor 流式调用反射服务访问控制拦截器
+// 反射请求的 key 元数据与 token 不一致时返回 Unauthenticated，其他方法不受影响
+func ReflectionAuthStreamInterceptor(key, token string) grpc.StreamServerInterceptor {
+	key = strings.ToLower(key)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isReflectionMethod(info.FullMethod) {
+			if err := checkReflectionToken(stream.Context(), key, token); err != nil {
+				return err
+			}
+		}
+		return handler(srv, stream)
+	}
+}
+
+// isReflectionMethod 检查是否为反射服务的方法
+func isReflectionMethod(fullMethod string) bool {
+	for _, prefix := range reflectionServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReflectionToken 以固定时间比较请求携带的令牌，避免通过响应耗时猜测令牌；token 为空时拒绝所有请求
+func checkReflectionToken(ctx context.Context, key, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(key) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Errorf(codes.Unauthenticated, "reflection requires a valid %s metadata", key)
+}
@@ -0,0 +1,64 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestReflectionAuthStreamInterceptor(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		md     metadata.MD
+		want   codes.Code
+	}{
+		{name: "missing token", method: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", want: codes.Unauthenticated},
+		{name: "wrong token", method: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", md: metadata.Pairs("x-reflection-token", "guess"), want: codes.Unauthenticated},
+		{name: "valid token", method: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", md: metadata.Pairs("x-reflection-token", "s3cret"), want: codes.OK},
+		{name: "v1alpha guarded", method: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", want: codes.Unauthenticated},
+		{name: "other methods unaffected", method: "/test.Service/Stream", want: codes.OK},
+	}
+
+	// 元数据键不区分大小写
+	interceptor := ReflectionAuthStreamInterceptor("X-Reflection-Token", "s3cret")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+			called := false
+			handler := func(srv interface{}, stream grpc.ServerStream) error {
+				called = true
+				return nil
+			}
+
+			err := interceptor(nil, &contextServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+			if called != (tt.want == codes.OK) {
+				t.Errorf("Expected handler called=%v, got %v", tt.want == codes.OK, called)
+			}
+		})
+	}
+}
+
+func TestReflectionAuthEmptyToken(t *testing.T) {
+	interceptor := ReflectionAuthUnaryInterceptor("x-reflection-token", "")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-reflection-token", ""))
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+
+	// 未配置令牌时拒绝所有反射请求
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated with empty token, got %v", err)
+	}
+}
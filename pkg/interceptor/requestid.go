@@ -0,0 +1,98 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader 请求 ID 元数据键
+const RequestIDHeader = "x-request-id"
+
+// requestIDKey 上下文中的请求 ID 键
+type requestIDKey struct{}
+
+// ContextWithRequestID 将请求 ID 存入上下文
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 从上下文获取请求 ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestIDUnaryInterceptor 一元调用请求 ID 拦截器
+// 从请求元数据读取 x-request-id，不存在时生成新的 ID，存入上下文并在响应 trailer 中返回
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := incomingRequestID(ctx)
+		ctx = ContextWithRequestID(ctx, requestID)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(RequestIDHeader, requestID))
+		
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStreamInterceptor 流式调用请求 ID 拦截器
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		requestID := incomingRequestID(stream.Context())
+		stream.SetTrailer(metadata.Pairs(RequestIDHeader, requestID))
+		
+		return handler(srv, &requestIDServerStream{
+			ServerStream: stream,
+			ctx:          ContextWithRequestID(stream.Context(), requestID),
+		})
+	}
+}
+
+// RequestIDUnaryClientInterceptor 一元调用客户端请求 ID 拦截器，将上下文中的请求 ID 写入请求元数据
+func RequestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDStreamClientInterceptor 流式调用客户端请求 ID 拦截器
+func RequestIDStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+// incomingRequestID 读取请求元数据中的请求 ID，不存在时生成新的 ID
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return uuid.NewString()
+}
+
+// outgoingRequestID 将上下文中的请求 ID 写入请求元数据，已显式设置时保持不变
+func outgoingRequestID(ctx context.Context) context.Context {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDHeader)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDHeader, requestID)
+}
+
+// requestIDServerStream 携带请求 ID 上下文的服务端流
+type requestIDServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带请求 ID 的上下文
+func (s *requestIDServerStream) Context() context.Context {
+	return s.ctx
+}
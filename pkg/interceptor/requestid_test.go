@@ -0,0 +1,93 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startRequestIDServer 启动带请求 ID 拦截器的测试服务器，返回服务端看到的请求 ID
+func startRequestIDServer(t *testing.T) (grpc_health_v1.HealthClient, *string) {
+	t.Helper()
+
+	var seen string
+	capture := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		seen = RequestIDFromContext(ctx)
+		return handler(ctx, req)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(RequestIDUnaryInterceptor(), capture))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(RequestIDUnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return grpc_health_v1.NewHealthClient(conn), &seen
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	client, seen := startRequestIDServer(t)
+
+	var trailer metadata.MD
+	if _, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.Trailer(&trailer)); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	values := trailer.Get(RequestIDHeader)
+	if len(values) != 1 {
+		t.Fatalf("Expected request ID in trailer, got %v", values)
+	}
+	if _, err := uuid.Parse(values[0]); err != nil {
+		t.Errorf("Expected generated request ID to be a UUID, got %s", values[0])
+	}
+	if *seen != values[0] {
+		t.Errorf("Expected handler context request ID %s, got %s", values[0], *seen)
+	}
+}
+
+func TestRequestIDPropagated(t *testing.T) {
+	client, seen := startRequestIDServer(t)
+
+	ctx := ContextWithRequestID(context.Background(), "req-123")
+	var trailer metadata.MD
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}, grpc.Trailer(&trailer)); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	if *seen != "req-123" {
+		t.Errorf("Expected server to see request ID req-123, got %s", *seen)
+	}
+	if values := trailer.Get(RequestIDHeader); len(values) != 1 || values[0] != "req-123" {
+		t.Errorf("Expected trailer request ID req-123, got %v", values)
+	}
+}
+
+func TestRequestIDClientKeepsExplicitMetadata(t *testing.T) {
+	ctx := ContextWithRequestID(context.Background(), "from-context")
+	ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, "explicit")
+
+	md, _ := metadata.FromOutgoingContext(outgoingRequestID(ctx))
+	if values := md.Get(RequestIDHeader); len(values) != 1 || values[0] != "explicit" {
+		t.Errorf("Expected explicit request ID to be kept, got %v", values)
+	}
+}
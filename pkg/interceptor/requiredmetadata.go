@@ -0,0 +1,110 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requiredMetadataKey 上下文中的必需元数据键
+type requiredMetadataKey struct{}
+
+// infrastructureServicePrefixes 基础设施服务，健康检查、反射和 channelz 请求通常不经过网关，
+// 不校验必需元数据，也不受方法访问控制限制
+var infrastructureServicePrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+	"/grpc.channelz.v1.Channelz/",
+}
+
+// RequiredMetadataValue 从上下文获取必需元数据拦截器提取的值，不存在时返回空字符串
+func RequiredMetadataValue(ctx context.Context, key string) string {
+	values, _ := ctx.Value(requiredMetadataKey{}).(map[string]string)
+	return values[strings.ToLower(key)]
+}
+
+// RequiredMetadataUnaryInterceptor 一元调用必需元数据拦截器
+// 请求缺少任一元数据键（或值为空）时返回 InvalidArgument，提取的值存入上下文，可通过 RequiredMetadataValue 获取；
+// 健康检查和反射服务不做校验
+func RequiredMetadataUnaryInterceptor(keys []string) grpc.UnaryServerInterceptor {
+	keys = normalizeMetadataKeys(keys)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isInfrastructureMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		
+		values, err := extractRequiredMetadata(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, requiredMetadataKey{}, values), req)
+	}
+}
+
+// RequiredMetadataStreamInterceptor 流式调用必需元数据拦截器
+func RequiredMetadataStreamInterceptor(keys []string) grpc.StreamServerInterceptor {
+	keys = normalizeMetadataKeys(keys)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isInfrastructureMethod(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		
+		values, err := extractRequiredMetadata(stream.Context(), keys)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &requiredMetadataServerStream{
+			ServerStream: stream,
+			ctx:          context.WithValue(stream.Context(), requiredMetadataKey{}, values),
+		})
+	}
+}
+
+// normalizeMetadataKeys 将元数据键转换为小写，与 gRPC 元数据的存储方式一致
+func normalizeMetadataKeys(keys []string) []string {
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		normalized = append(normalized, strings.ToLower(key))
+	}
+	return normalized
+}
+
+// isInfrastructureMethod 检查方法是否属于基础设施服务
+func isInfrastructureMethod(fullMethod string) bool {
+	for _, prefix := range infrastructureServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractRequiredMetadata 读取必需的元数据，缺少时返回 InvalidArgument 状态
+func extractRequiredMetadata(ctx context.Context, keys []string) (map[string]string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		v := md.Get(key)
+		if len(v) == 0 || v[0] == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "missing required metadata %q", key)
+		}
+		values[key] = v[0]
+	}
+	return values, nil
+}
+
+// requiredMetadataServerStream 携带必需元数据上下文的服务端流
+type requiredMetadataServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带必需元数据的上下文
+func (s *requiredMetadataServerStream) Context() context.Context {
+	return s.ctx
+}
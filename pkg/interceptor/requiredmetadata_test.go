@@ -0,0 +1,98 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequiredMetadataUnaryInterceptor(t *testing.T) {
+	interceptor := RequiredMetadataUnaryInterceptor([]string{"X-Tenant-ID", "x-user-id"})
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	var tenant, user string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		tenant = RequiredMetadataValue(ctx, "x-tenant-id")
+		user = RequiredMetadataValue(ctx, "X-User-ID")
+		return "response", nil
+	}
+
+	// 元数据齐全时处理器可以获取提取的值
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "tenant-a", "x-user-id", "42"))
+	if _, err := interceptor(ctx, "request", info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tenant != "tenant-a" || user != "42" {
+		t.Errorf("Expected extracted values, got tenant %q user %q", tenant, user)
+	}
+
+	// 缺少或为空时返回 InvalidArgument 并指出缺少的键
+	for _, md := range []metadata.MD{
+		metadata.Pairs("x-tenant-id", "tenant-a"),
+		metadata.Pairs("x-tenant-id", "tenant-a", "x-user-id", ""),
+		nil,
+	} {
+		ctx := context.Background()
+		if md != nil {
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+		_, err := interceptor(ctx, "request", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			t.Fatal("Handler should not be called when metadata is missing")
+			return nil, nil
+		})
+		st, _ := status.FromError(err)
+		if st.Code() != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+		if md != nil && !strings.Contains(st.Message(), "x-user-id") {
+			t.Errorf("Expected missing key in message, got %q", st.Message())
+		}
+	}
+}
+
+func TestRequiredMetadataSkipsHealthAndReflection(t *testing.T) {
+	interceptor := RequiredMetadataUnaryInterceptor([]string{"x-tenant-id"})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+
+	for _, method := range []string{
+		"/grpc.health.v1.Health/Check",
+		"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+		"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+	} {
+		if _, err := interceptor(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: method}, handler); err != nil {
+			t.Errorf("Expected %s to skip metadata check, got %v", method, err)
+		}
+	}
+}
+
+func TestRequiredMetadataStreamInterceptor(t *testing.T) {
+	interceptor := RequiredMetadataStreamInterceptor([]string{"x-tenant-id"})
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}
+
+	var tenant string
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		tenant = RequiredMetadataValue(stream.Context(), "x-tenant-id")
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", "tenant-b"))
+	stream := &requiredMetadataServerStream{ServerStream: &mockServerStream{}, ctx: ctx}
+	if err := interceptor(nil, stream, info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tenant != "tenant-b" {
+		t.Errorf("Expected tenant-b, got %q", tenant)
+	}
+
+	err := interceptor(nil, &mockServerStream{}, info, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for missing metadata, got %v", err)
+	}
+}
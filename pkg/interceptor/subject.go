@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"context"
+	"sync"
+)
+
+// authSubjectKey 上下文中的已认证主体键
+type authSubjectKey struct{}
+
+// authSubjectHolder 日志拦截器放入上下文的主体容器，内层认证拦截器写入的主体可在调用结束后读取
+type authSubjectHolder struct {
+	mu      sync.Mutex
+	subject string
+}
+
+// WithAuthSubject 返回携带已认证主体（如用户 ID、服务账号）的上下文，供认证拦截器在认证成功后调用
+// 主体会出现在日志拦截器的 subject 字段中，不应传入令牌等凭证
+func WithAuthSubject(ctx context.Context, subject string) context.Context {
+	if holder, ok := ctx.Value(authSubjectHolder{}).(*authSubjectHolder); ok {
+		holder.mu.Lock()
+		holder.subject = subject
+		holder.mu.Unlock()
+	}
+	return context.WithValue(ctx, authSubjectKey{}, subject)
+}
+
+// AuthSubject 从上下文获取已认证主体，未认证时返回空字符串
+func AuthSubject(ctx context.Context) string {
+	if subject, ok := ctx.Value(authSubjectKey{}).(string); ok {
+		return subject
+	}
+	if holder, ok := ctx.Value(authSubjectHolder{}).(*authSubjectHolder); ok {
+		holder.mu.Lock()
+		defer holder.mu.Unlock()
+		return holder.subject
+	}
+	return ""
+}
+
+// withAuthSubjectHolder 在上下文中放入主体容器
+func withAuthSubjectHolder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authSubjectHolder{}, &authSubjectHolder{})
+}
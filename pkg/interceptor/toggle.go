@@ -0,0 +1,75 @@
+package interceptor
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Switch 拦截器运行时开关
+// 拦截器在服务器创建时固定，开关允许在配置重载后动态启用或禁用
+type Switch struct {
+	enabled atomic.Bool
+}
+
+// NewSwitch 创建拦截器开关
+func NewSwitch(enabled bool) *Switch {
+	s := &Switch{}
+	s.enabled.Store(enabled)
+	return s
+}
+
+// Enabled 检查开关是否启用
+func (s *Switch) Enabled() bool {
+	return s.enabled.Load()
+}
+
+// Set 设置开关状态
+func (s *Switch) Set(enabled bool) {
+	s.enabled.Store(enabled)
+}
+
+// Threshold 拦截器运行时阈值
+// 阈值在服务器创建时传入拦截器，配置重载后可以动态调整
+type Threshold struct {
+	value atomic.Int64
+}
+
+// NewThreshold 创建拦截器阈值
+func NewThreshold(value time.Duration) *Threshold {
+	t := &Threshold{}
+	t.value.Store(int64(value))
+	return t
+}
+
+// Get 返回当前阈值
+func (t *Threshold) Get() time.Duration {
+	return time.Duration(t.value.Load())
+}
+
+// Set 设置阈值
+func (t *Threshold) Set(value time.Duration) {
+	t.value.Store(int64(value))
+}
+
+// ToggleUnaryInterceptor 根据开关决定是否执行一元调用拦截器
+func ToggleUnaryInterceptor(sw *Switch, next grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !sw.Enabled() {
+			return handler(ctx, req)
+		}
+		return next(ctx, req, info, handler)
+	}
+}
+
+// ToggleStreamInterceptor 根据开关决定是否执行流式调用拦截器
+func ToggleStreamInterceptor(sw *Switch, next grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !sw.Enabled() {
+			return handler(srv, stream)
+		}
+		return next(srv, stream, info, handler)
+	}
+}
@@ -0,0 +1,75 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+)
+
+func TestToggleUnaryInterceptor(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	sw := NewSwitch(true)
+	interceptor := ToggleUnaryInterceptor(sw, LoggingUnaryInterceptor(zap.New(core)))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/TestMethod"}
+
+	if _, err := interceptor(context.Background(), "request", info, handler); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("Expected 1 log entry while enabled, got %d", logs.Len())
+	}
+
+	// 运行时关闭日志
+	sw.Set(false)
+
+	resp, err := interceptor(context.Background(), "request", info, handler)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp != "response" {
+		t.Errorf("Expected handler to still be called, got %v", resp)
+	}
+	if logs.Len() != 1 {
+		t.Errorf("Expected no new log entries while disabled, got %d", logs.Len())
+	}
+
+	// 重新开启
+	sw.Set(true)
+
+	if _, err := interceptor(context.Background(), "request", info, handler); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if logs.Len() != 2 {
+		t.Errorf("Expected 2 log entries after re-enabling, got %d", logs.Len())
+	}
+}
+
+func TestToggleStreamInterceptor(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	sw := NewSwitch(false)
+	interceptor := ToggleStreamInterceptor(sw, LoggingStreamInterceptor(zap.New(core)))
+
+	called := false
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		called = true
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/TestStream"}
+
+	if err := interceptor(nil, nil, info, handler); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !called {
+		t.Error("Expected handler to be called while disabled")
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no log entries while disabled, got %d", logs.Len())
+	}
+}
@@ -0,0 +1,149 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TracerName 追踪拦截器使用的 tracer 名称
+const TracerName = "github.com/go-grpc-kit/go-grpc-kit/pkg/interceptor"
+
+// TracingOption 追踪拦截器选项
+type TracingOption func(*tracingOptions)
+
+// tracingOptions 追踪拦截器配置
+type tracingOptions struct {
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider 设置 TracerProvider，未设置时使用 otel 全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) TracingOption {
+	return func(o *tracingOptions) {
+		o.tracerProvider = tp
+	}
+}
+
+// WithPropagator 设置上下文传播器，未设置时使用 otel 全局传播器
+func WithPropagator(propagator propagation.TextMapPropagator) TracingOption {
+	return func(o *tracingOptions) {
+		o.propagator = propagator
+	}
+}
+
+// newTracingOptions 应用追踪拦截器选项
+func newTracingOptions(opts []TracingOption) *tracingOptions {
+	o := &tracingOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.tracerProvider == nil {
+		o.tracerProvider = otel.GetTracerProvider()
+	}
+	if o.propagator == nil {
+		o.propagator = otel.GetTextMapPropagator()
+	}
+	return o
+}
+
+// TracingUnaryInterceptor 一元调用追踪拦截器
+func TracingUnaryInterceptor(opts ...TracingOption) grpc.UnaryServerInterceptor {
+	options := newTracingOptions(opts)
+	tracer := options.tracerProvider.Tracer(TracerName)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := options.startSpan(ctx, tracer, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// TracingStreamInterceptor 流式调用追踪拦截器
+func TracingStreamInterceptor(opts ...TracingOption) grpc.StreamServerInterceptor {
+	options := newTracingOptions(opts)
+	tracer := options.tracerProvider.Tracer(TracerName)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := options.startSpan(stream.Context(), tracer, info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &tracingServerStream{ServerStream: stream, ctx: ctx})
+		endSpan(span, err)
+		return err
+	}
+}
+
+// startSpan 从请求元数据中提取父 span，并为方法创建服务端 span
+func (o *tracingOptions) startSpan(ctx context.Context, tracer trace.Tracer, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = o.propagator.Extract(ctx, metadataCarrier(md))
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	attrs := []attribute.KeyValue{attribute.String("rpc.system", "grpc")}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		attrs = append(attrs,
+			attribute.String("rpc.service", name[:i]),
+			attribute.String("rpc.method", name[i+1:]))
+	}
+
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...))
+}
+
+// endSpan 记录调用的 gRPC 状态，失败时将 span 标记为错误
+func endSpan(span trace.Span, err error) {
+	st := status.Convert(err)
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(st.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, st.Message())
+	}
+}
+
+// metadataCarrier 基于 gRPC 元数据的传播载体
+type metadataCarrier metadata.MD
+
+// Get 返回键对应的第一个值
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set 设置键的值
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys 返回所有键
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// tracingServerStream 携带追踪上下文的服务端流
+type tracingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回携带 span 的上下文
+func (s *tracingServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,137 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// newTestTracerProvider 创建使用内存导出器的 TracerProvider
+func newTestTracerProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+	return tp, exporter
+}
+
+func TestTracingUnaryInterceptor(t *testing.T) {
+	tp, exporter := newTestTracerProvider(t)
+	interceptor := TracingUnaryInterceptor(WithTracerProvider(tp), WithPropagator(propagation.TraceContext{}))
+
+	// 模拟上游传入的 traceparent
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	md := metadata.MD{}
+	propagation.TraceContext{}.Inject(trace.ContextWithRemoteSpanContext(context.Background(), parent), metadataCarrier(md))
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+
+	var handlerSpan trace.SpanContext
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return "response", nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	if _, err := interceptor(ctx, "request", info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "test.Service/Method" {
+		t.Errorf("Expected span name test.Service/Method, got %s", span.Name)
+	}
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("Expected server span, got %v", span.SpanKind)
+	}
+	if span.Parent.SpanID() != parent.SpanID() || span.SpanContext.TraceID() != parent.TraceID() {
+		t.Errorf("Expected span to be a child of the incoming span context")
+	}
+	if handlerSpan.SpanID() != span.SpanContext.SpanID() {
+		t.Errorf("Expected handler context to carry the server span")
+	}
+	if span.Status.Code != otelcodes.Unset {
+		t.Errorf("Expected unset status, got %v", span.Status.Code)
+	}
+}
+
+func TestTracingUnaryInterceptorError(t *testing.T) {
+	tp, exporter := newTestTracerProvider(t)
+	interceptor := TracingUnaryInterceptor(WithTracerProvider(tp))
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	if _, err := interceptor(context.Background(), "request", info, handler); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Status.Code != otelcodes.Error || span.Status.Description != "not found" {
+		t.Errorf("Expected error status, got %+v", span.Status)
+	}
+
+	found := false
+	for _, attr := range span.Attributes {
+		if attr.Key == "rpc.grpc.status_code" {
+			found = true
+			if attr.Value.AsInt64() != int64(codes.NotFound) {
+				t.Errorf("Expected status code %d, got %d", codes.NotFound, attr.Value.AsInt64())
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected rpc.grpc.status_code attribute")
+	}
+}
+
+func TestTracingStreamInterceptor(t *testing.T) {
+	tp, exporter := newTestTracerProvider(t)
+	interceptor := TracingStreamInterceptor(WithTracerProvider(tp))
+
+	var handlerSpan trace.SpanContext
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		handlerSpan = trace.SpanContextFromContext(stream.Context())
+		return nil
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/StreamMethod", IsServerStream: true}
+	if err := interceptor(nil, &mockServerStream{}, info, handler); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "test.Service/StreamMethod" {
+		t.Errorf("Expected span name test.Service/StreamMethod, got %s", spans[0].Name)
+	}
+	if handlerSpan.SpanID() != spans[0].SpanContext.SpanID() {
+		t.Errorf("Expected stream context to carry the server span")
+	}
+}
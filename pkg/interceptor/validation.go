@@ -0,0 +1,107 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// allValidator protoc-gen-validate 生成的 ValidateAll 方法，返回所有违反的规则
+type allValidator interface {
+	ValidateAll() error
+}
+
+// validator protoc-gen-validate 生成的 Validate 方法，返回第一个违反的规则
+type validator interface {
+	Validate() error
+}
+
+// fieldViolation protoc-gen-validate 生成的字段校验错误
+type fieldViolation interface {
+	Field() string
+	Reason() string
+}
+
+// multiError protoc-gen-validate 生成的 ValidateAll 返回的多错误
+type multiError interface {
+	AllErrors() []error
+}
+
+// ValidationUnaryInterceptor 一元调用请求校验拦截器
+// 请求消息实现 ValidateAll() 或 Validate() 时在处理器执行前调用，校验失败返回带 BadRequest 详情的 InvalidArgument
+func ValidationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validateMessage(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor 流式调用请求校验拦截器，校验每条接收的消息
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validationServerStream{ServerStream: ss})
+	}
+}
+
+// validationServerStream 校验接收消息的服务端流
+type validationServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validationServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateMessage(m)
+}
+
+// validateMessage 校验消息，优先使用 ValidateAll，未实现校验方法的消息直接通过
+func validateMessage(msg interface{}) error {
+	var err error
+	switch v := msg.(type) {
+	case allValidator:
+		err = v.ValidateAll()
+	case validator:
+		err = v.Validate()
+	default:
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	
+	st := status.New(codes.InvalidArgument, err.Error())
+	if violations := fieldViolations(err); len(violations) > 0 {
+		if detailed, detailErr := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); detailErr == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// fieldViolations 将校验错误转换为 BadRequest 字段违规列表，无法识别字段的错误不计入
+func fieldViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	errs := []error{err}
+	var multi multiError
+	if errors.As(err, &multi) {
+		errs = multi.AllErrors()
+	}
+	
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, e := range errs {
+		var field fieldViolation
+		if errors.As(e, &field) {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field(),
+				Description: field.Reason(),
+			})
+		}
+	}
+	return violations
+}
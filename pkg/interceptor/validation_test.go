@@ -0,0 +1,142 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeValidationError 模拟 protoc-gen-validate 生成的字段校验错误
+type fakeValidationError struct {
+	field  string
+	reason string
+}
+
+func (e fakeValidationError) Field() string  { return e.field }
+func (e fakeValidationError) Reason() string { return e.reason }
+func (e fakeValidationError) Error() string  { return "invalid " + e.field + ": " + e.reason }
+
+// fakeMultiError 模拟 protoc-gen-validate 生成的多错误
+type fakeMultiError []error
+
+func (m fakeMultiError) Error() string      { return "multiple violations" }
+func (m fakeMultiError) AllErrors() []error { return m }
+
+// fakeRequest 只实现 Validate 的请求消息
+type fakeRequest struct {
+	name string
+}
+
+func (r *fakeRequest) Validate() error {
+	if r.name == "" {
+		return fakeValidationError{field: "name", reason: "value is required"}
+	}
+	return nil
+}
+
+// fakeAllRequest 同时实现 Validate 和 ValidateAll 的请求消息
+type fakeAllRequest struct{}
+
+func (r *fakeAllRequest) Validate() error {
+	return fakeValidationError{field: "name", reason: "value is required"}
+}
+
+func (r *fakeAllRequest) ValidateAll() error {
+	return fakeMultiError{
+		fakeValidationError{field: "name", reason: "value is required"},
+		fakeValidationError{field: "age", reason: "value must be greater than 0"},
+	}
+}
+
+func TestValidationUnaryInterceptor(t *testing.T) {
+	interceptor := ValidationUnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "response", nil
+	}
+
+	// 校验失败时不调用处理器
+	_, err := interceptor(context.Background(), &fakeRequest{}, info, handler)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+	if called {
+		t.Error("Handler should not be called when validation fails")
+	}
+	violations := badRequestViolations(t, err)
+	if len(violations) != 1 || violations[0].Field != "name" || violations[0].Description != "value is required" {
+		t.Errorf("Expected name violation, got %v", violations)
+	}
+
+	// 校验通过和未实现校验方法的消息正常处理
+	for _, req := range []interface{}{&fakeRequest{name: "alice"}, "plain request"} {
+		called = false
+		if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+			t.Errorf("Unexpected error for %v: %v", req, err)
+		}
+		if !called {
+			t.Errorf("Expected handler to be called for %v", req)
+		}
+	}
+}
+
+func TestValidationPrefersValidateAll(t *testing.T) {
+	err := validateMessage(&fakeAllRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", err)
+	}
+	if violations := badRequestViolations(t, err); len(violations) != 2 {
+		t.Errorf("Expected all violations from ValidateAll, got %v", violations)
+	}
+}
+
+func TestValidationStreamInterceptor(t *testing.T) {
+	interceptor := ValidationStreamInterceptor()
+	stream := &recvServerStream{msgs: []*fakeRequest{{name: "alice"}, {}}}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}, func(srv interface{}, ss grpc.ServerStream) error {
+		for {
+			req := &fakeRequest{}
+			if err := ss.RecvMsg(req); err != nil {
+				return err
+			}
+		}
+	})
+	// 第二条消息校验失败
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+}
+
+// recvServerStream 按顺序返回预设消息的服务端流
+type recvServerStream struct {
+	mockServerStream
+	msgs []*fakeRequest
+}
+
+func (s *recvServerStream) RecvMsg(m interface{}) error {
+	if len(s.msgs) == 0 {
+		return errors.New("no more messages")
+	}
+	*m.(*fakeRequest) = *s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return nil
+}
+
+// badRequestViolations 读取状态中的 BadRequest 字段违规
+func badRequestViolations(t *testing.T, err error) []*errdetails.BadRequest_FieldViolation {
+	t.Helper()
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			return badRequest.FieldViolations
+		}
+	}
+	t.Fatalf("Expected BadRequest details in %v", err)
+	return nil
+}
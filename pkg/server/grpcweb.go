@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/internal/grpcweb"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
+	"go.uber.org/zap"
+)
+
+// startGrpcWeb 在 server.grpc_web.port 上以 HTTP/1.1 提供默认监听器的服务，调用方需持有 s.mu
+// 不支持 TLS，浏览器通过 HTTPS 访问时由前置的负载均衡器终结 TLS
+func (s *Server) startGrpcWeb(nl *namedListener) error {
+	cfg := s.config.Server.GrpcWeb
+	if s.usesTLS() {
+		return errors.New("server.grpc_web cannot be used with TLS")
+	}
+	
+	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, cfg.Port)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC-Web on %s: %w", addr, err)
+	}
+	
+	// 流式响应持续时间不确定，不设置读写超时
+	s.grpcWebListener = listener
+	s.grpcWebServer = &http.Server{
+		Handler:           grpcweb.NewHandler(nl.grpcServer, cfg.AllowedOrigins),
+		ReadHeaderTimeout: time.Duration(s.config.Metrics.HTTPTimeouts.ReadHeaderTimeout) * time.Second,
+	}
+	
+	s.logger.Info("gRPC-Web server starting",
+		zap.String("address", listener.Addr().String()),
+		zap.Strings("allowed_origins", cfg.AllowedOrigins))
+	server := s.grpcWebServer
+	go safe.Run(s.logger, "gRPC-Web server panicked", func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("gRPC-Web server failed", zap.Error(err))
+		}
+	})
+	return nil
+}
+
+// stopGrpcWeb 优雅关闭 gRPC-Web 服务器，ctx 到期时强制关闭，调用方需持有 s.mu
+func (s *Server) stopGrpcWeb(ctx context.Context) error {
+	server := s.grpcWebServer
+	s.grpcWebServer = nil
+	s.grpcWebListener = nil
+	
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+		return fmt.Errorf("failed to shutdown gRPC-Web server: %w", err)
+	}
+	return nil
+}
+
+// GetGrpcWebAddress 获取 gRPC-Web 服务器的监听地址，未开启或未启动时返回空字符串
+func (s *Server) GetGrpcWebAddress() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	if s.grpcWebListener == nil {
+		return ""
+	}
+	return s.grpcWebListener.Addr().String()
+}
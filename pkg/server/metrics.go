@@ -0,0 +1,16 @@
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// 服务器启动后被拒绝的服务注册次数
+	grpcLateRegistrations = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "grpc_late_registrations_total",
+			Help: "Total number of service registrations rejected because the gRPC server had already started",
+		},
+	)
+)
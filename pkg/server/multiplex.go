@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-grpc-kit/go-grpc-kit/internal/portmux"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
+	"go.uber.org/zap"
+)
+
+// SetHTTPHandler 设置 server.multiplex_http 开启时与 gRPC 共用默认端口的 HTTP 处理器，需在 Start 之前调用
+// 未设置时 HTTP 请求返回 404
+func (s *Server) SetHTTPHandler(handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.httpHandler = handler
+}
+
+// usesTLS 检查 gRPC 服务器是否使用 TLS，调用方提供的凭证优先于 cfg.TLS
+func (s *Server) usesTLS() bool {
+	if s.creds != nil {
+		return s.creds.Info().SecurityProtocol != "insecure"
+	}
+	return s.config.TLS.Enabled
+}
+
+// multiplexHTTP 拆分默认监听器，HTTP/2 连接前言开头的连接交给 gRPC，其余交给 HTTP 服务器
+// TLS 连接的前几个字节是握手数据而不是连接前言，无法区分，因此不支持与 TLS 同时使用
+func (s *Server) multiplexHTTP(nl *namedListener) error {
+	if s.usesTLS() {
+		return errors.New("server.multiplex_http cannot be used with TLS")
+	}
+	
+	handler := s.httpHandler
+	if handler == nil {
+		handler = http.NotFoundHandler()
+	}
+	timeouts := s.config.Metrics.HTTPTimeouts
+	
+	nl.mux = portmux.New(nl.listener)
+	nl.listener = nl.mux.GRPCListener()
+	nl.httpServer = &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(timeouts.ReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(timeouts.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(timeouts.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(timeouts.IdleTimeout) * time.Second,
+	}
+	return nil
+}
+
+// serveMultiplexHTTP 开始分发连接并启动 HTTP 服务器
+func (s *Server) serveMultiplexHTTP(nl *namedListener) {
+	s.logger.Info("HTTP server sharing gRPC port",
+		zap.String("listener", nl.name),
+		zap.String("address", nl.listener.Addr().String()))
+	
+	go safe.Run(s.logger, "Connection multiplexer panicked", func() {
+		if err := nl.mux.Serve(); err != nil && !errors.Is(err, net.ErrClosed) {
+			s.logger.Error("Connection multiplexer failed", zap.Error(err))
+		}
+	})
+	go safe.Run(s.logger, "Multiplexed HTTP server panicked", func() {
+		if err := nl.httpServer.Serve(nl.mux.HTTPListener()); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Multiplexed HTTP server failed", zap.Error(err))
+		}
+	})
+}
+
+// stopMultiplexHTTP 优雅关闭共用端口的 HTTP 服务器并关闭连接分发
+func (s *Server) stopMultiplexHTTP(ctx context.Context, nl *namedListener) error {
+	err := nl.httpServer.Shutdown(ctx)
+	if err != nil {
+		nl.httpServer.Close()
+	}
+	nl.mux.Close()
+	if err != nil {
+		return fmt.Errorf("failed to shutdown multiplexed HTTP server: %w", err)
+	}
+	return nil
+}
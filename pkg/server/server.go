@@ -3,32 +3,145 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/grpcserver"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/logging"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/portmux"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/interceptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/stats"
 )
 
 // Server gRPC 服务器
 type Server struct {
-	config     *config.Config
-	grpcServer *grpc.Server
+	config    *config.Config
+	listeners []*namedListener
+	logger    *zap.Logger
+	services  []ServiceRegistrar
+	mu        sync.RWMutex
+	started   bool
+	healthSrv *health.Server
+	
+	// 拦截器运行时开关，配置重载后无需重启即可生效
+	loggingSwitch  *interceptor.Switch
+	recoverySwitch *interceptor.Switch
+	metricsSwitch  *interceptor.Switch
+	// 慢请求阈值，配置重载后无需重启即可生效
+	slowThreshold *interceptor.Threshold
+	
+	// 载荷日志脱敏钩子
+	payloadRedactor interceptor.PayloadRedactor
+	
+	// 追踪使用的 TracerProvider，未设置时使用 otel 全局 TracerProvider
+	tracerProvider trace.TracerProvider
+	
+	// 错误映射拦截器选项，为 nil 时不启用错误映射
+	errorMapping []interceptor.ErrorMappingOption
+	
+	// 请求指标，未设置时使用 prometheus 默认注册表
+	metrics *interceptor.Metrics
+	
+	// 调用方提供的 gRPC 服务器，设置后默认监听器使用它而不是新建服务器
+	existingServer *grpc.Server
+	// 启动前通过 GRPCServer 创建的默认监听器服务器，停止后清空，重新启动时新建
+	defaultServer *grpc.Server
+	// 业务服务是否已注册到默认监听器的服务器，启动失败后重试时避免重复注册
+	defaultRegistered bool
+	
+	// 服务已就绪以及是否被手动摘除，摘除期间健康状态保持 NOT_SERVING
+	serving  bool
+	draining bool
+	
+	// 已注册业务服务的完整服务名，健康状态随整体状态同步变化
+	healthServices []string
+	
+	// 调用方提供的传输凭证，设置后优先于 cfg.TLS
+	creds credentials.TransportCredentials
+	
+	// 开启 server.multiplex_http 时在默认端口上提供的 HTTP 处理器
+	httpHandler http.Handler
+	
+	// 调用方提供的 stats.Handler，如 otelgrpc.NewServerHandler()
+	statsHandlers []stats.Handler
+	
+	// 访问日志器，设置后日志拦截器的每次调用记录写入其中
+	accessLogger *zap.Logger
+	
+	// 开启 server.grpc_web 时提供 gRPC-Web 的 HTTP 服务器及其监听器
+	grpcWebServer   *http.Server
+	grpcWebListener net.Listener
+}
+
+// Option 服务器选项
+type Option func(*Server)
+
+// WithCredentials 使用调用方提供的传输凭证，优先于 cfg.TLS，可对接证书轮换库（certmagic、SPIFFE 等）
+func WithCredentials(creds credentials.TransportCredentials) Option {
+	return func(s *Server) {
+		s.creds = creds
+	}
+}
+
+// WithTLSConfig 使用调用方提供的 TLS 配置，优先于 cfg.TLS
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(s *Server) {
+		s.creds = credentials.NewTLS(tlsConfig)
+	}
+}
+
+// WithInsecure 显式不使用 TLS，忽略 cfg.TLS
+func WithInsecure() Option {
+	return func(s *Server) {
+		s.creds = insecure.NewCredentials()
+	}
+}
+
+// WithStatsHandler 添加 gRPC stats.Handler，可对接 otelgrpc 等基于 stats 的追踪和指标，可多次调用
+// 使用 SetGrpcServer 提供的服务器时不生效
+func WithStatsHandler(handler stats.Handler) Option {
+	return func(s *Server) {
+		s.statsHandlers = append(s.statsHandlers, handler)
+	}
+}
+
+// WithAccessLogger 使用调用方提供的访问日志器，优先于 cfg.Logging.AccessLog
+func WithAccessLogger(logger *zap.Logger) Option {
+	return func(s *Server) {
+		s.accessLogger = logger
+	}
+}
+
+// DefaultListenerName 默认监听器名称，对应 server.host 和 server.grpc_port
+const DefaultListenerName = "default"
+
+// namedListener 命名监听器，每个监听器使用独立的 gRPC 服务器
+type namedListener struct {
+	name       string
 	listener   net.Listener
-	logger     *zap.Logger
-	services   []ServiceRegistrar
-	mu         sync.RWMutex
-	started    bool
-	healthSrv  *health.Server
+	grpcServer *grpc.Server
+	
+	// 开启 server.multiplex_http 时，默认监听器的连接分发和共用端口的 HTTP 服务器
+	mux        *portmux.Mux
+	httpServer *http.Server
 }
 
 // ServiceRegistrar 服务注册接口
@@ -36,88 +149,470 @@ type ServiceRegistrar interface {
 	RegisterService(s grpc.ServiceRegistrar)
 }
 
+// NamedServiceRegistrar 可选的服务接口，声明注册器提供的完整 gRPC 服务名
+// 服务器启动时为每个服务名初始化健康状态，服务发现模块可以按服务名分别注册
+type NamedServiceRegistrar interface {
+	ServiceRegistrar
+	ServiceNames() []string
+}
+
+// declaredServiceNames 返回实现了 NamedServiceRegistrar 的服务声明的服务名，去重并保持声明顺序
+func declaredServiceNames(services []ServiceRegistrar) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, service := range services {
+		named, ok := service.(NamedServiceRegistrar)
+		if !ok {
+			continue
+		}
+		for _, name := range named.ServiceNames() {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// ReadinessWaiter 可选的服务接口，用于需要异步初始化（加载模型、预热缓存等）的服务
+// 服务器启动后调用 WaitReady，全部返回后才将健康状态设为 SERVING
+type ReadinessWaiter interface {
+	WaitReady(ctx context.Context) error
+}
+
+// ServiceDescriber 可选的服务接口，声明服务的完整服务名以及是否在反射服务中公开
+// exposeReflection 为 false 的服务照常提供调用，但不出现在反射服务的服务列表中
+type ServiceDescriber interface {
+	ServiceDescriptor() (name string, exposeReflection bool)
+}
+
+// Lifecycle 可选的服务接口，用于需要感知服务器生命周期的服务，如向已连接的流广播消息的发布订阅服务
+// 服务就绪、健康状态设为 SERVING 后调用 OnServerReady，每次启动只调用一次；
+// 停止时在健康状态设为 NOT_SERVING 后、优雅关闭前调用 OnServerStopping，服务应在其中结束长期存在的流，
+// 否则优雅关闭会一直等待到超时。OnServerStopping 调用时持有服务器锁，不能在其中调用 Server 的方法
+type Lifecycle interface {
+	OnServerReady()
+	OnServerStopping()
+}
+
+// notifyServerReady 依次通知实现了 Lifecycle 的服务服务器已就绪
+func notifyServerReady(services []ServiceRegistrar) {
+	for _, service := range services {
+		if lifecycle, ok := service.(Lifecycle); ok {
+			lifecycle.OnServerReady()
+		}
+	}
+}
+
+// notifyServerStopping 依次通知实现了 Lifecycle 的服务服务器即将停止
+func notifyServerStopping(services []ServiceRegistrar) {
+	for _, service := range services {
+		if lifecycle, ok := service.(Lifecycle); ok {
+			lifecycle.OnServerStopping()
+		}
+	}
+}
+
+// reflectionHiddenServices 返回声明不在反射服务中公开的服务名
+func reflectionHiddenServices(services []ServiceRegistrar) map[string]bool {
+	hidden := make(map[string]bool)
+	for _, service := range services {
+		if describer, ok := service.(ServiceDescriber); ok {
+			if name, expose := describer.ServiceDescriptor(); !expose {
+				hidden[name] = true
+			}
+		}
+	}
+	return hidden
+}
+
+// waitServicesReady 依次等待实现了 ReadinessWaiter 的服务就绪
+func waitServicesReady(ctx context.Context, services []ServiceRegistrar) error {
+	for _, service := range services {
+		if waiter, ok := service.(ReadinessWaiter); ok {
+			if err := waiter.WaitReady(ctx); err != nil {
+				return fmt.Errorf("service %T not ready: %w", service, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CombineServices 将多个服务注册器组合为一个，注册时按顺序调用各服务的 RegisterService
+func CombineServices(services ...ServiceRegistrar) ServiceRegistrar {
+	return combinedServices(services)
+}
+
+// combinedServices 组合服务注册器
+type combinedServices []ServiceRegistrar
+
+// RegisterService 依次注册所有服务
+func (c combinedServices) RegisterService(s grpc.ServiceRegistrar) {
+	for _, service := range c {
+		if service != nil {
+			service.RegisterService(s)
+		}
+	}
+}
+
+// WaitReady 等待组合中实现了 ReadinessWaiter 的服务就绪
+func (c combinedServices) WaitReady(ctx context.Context) error {
+	return waitServicesReady(ctx, c)
+}
+
+// ServiceNames 返回组合中各服务声明的服务名
+func (c combinedServices) ServiceNames() []string {
+	return declaredServiceNames(c)
+}
+
+// OnServerReady 通知组合中实现了 Lifecycle 的服务服务器已就绪
+func (c combinedServices) OnServerReady() {
+	notifyServerReady(c)
+}
+
+// OnServerStopping 通知组合中实现了 Lifecycle 的服务服务器即将停止
+func (c combinedServices) OnServerStopping() {
+	notifyServerStopping(c)
+}
+
 // New 创建新的 gRPC 服务器
-func New(cfg *config.Config, logger *zap.Logger) *Server {
-	return &Server{
+func New(cfg *config.Config, logger *zap.Logger, opts ...Option) *Server {
+	s := &Server{
 		config:    cfg,
 		logger:    logger,
 		services:  make([]ServiceRegistrar, 0),
 		healthSrv: health.NewServer(),
+		
+		loggingSwitch:  interceptor.NewSwitch(cfg.GRPC.Server.EnableLogging),
+		recoverySwitch: interceptor.NewSwitch(cfg.GRPC.Server.EnableRecovery),
+		metricsSwitch:  interceptor.NewSwitch(cfg.GRPC.Server.EnableMetrics),
+		slowThreshold:  interceptor.NewThreshold(time.Duration(cfg.GRPC.Server.SlowThreshold) * time.Millisecond),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	
+	// 访问日志无法打开时调用记录仍写入主日志器
+	if s.accessLogger == nil && cfg.Logging.AccessLog.Enabled {
+		accessLogger, err := logging.NewAccessLogger(cfg.Logging.AccessLog)
+		if err != nil {
+			logger.Warn("Failed to create access logger, logging calls to application logger", zap.Error(err))
+		} else {
+			s.accessLogger = accessLogger
+		}
+	}
+	return s
 }
 
+// ErrServerStarted 服务器启动后注册服务时返回
+var ErrServerStarted = errors.New("cannot register service after server started")
+
 // RegisterService 注册服务
+// 服务器启动后注册的服务会被丢弃，需要确认结果时使用 TryRegisterService
 func (s *Server) RegisterService(service ServiceRegistrar) {
+	s.TryRegisterService(service)
+}
+
+// TryRegisterService 注册服务，服务器已启动时丢弃注册并返回 ErrServerStarted
+func (s *Server) TryRegisterService(service ServiceRegistrar) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
 	if s.started {
-		s.logger.Warn("Cannot register service after server started")
-		return
+		grpcLateRegistrations.Inc()
+		s.logger.Warn("Cannot register service after server started",
+			zap.String("service", fmt.Sprintf("%T", service)))
+		return ErrServerStarted
 	}
 	
 	s.services = append(s.services, service)
+	return nil
 }
 
 // Start 启动服务器
+// 除默认监听器外还会启动 server.listeners 中配置的监听器，所有监听器共享已注册的服务
+// 服务实现 ReadinessWaiter 时，Start 会等待其就绪后才将健康状态设为 SERVING 并返回
 func (s *Server) Start() error {
+	if err := s.Serve(); err != nil {
+		return err
+	}
+	return s.MarkServing(context.Background())
+}
+
+// Serve 启动监听器并开始服务，健康状态保持 NOT_SERVING 直到调用 MarkServing
+// 用于在服务开始接收连接后还需等待其他依赖就绪的场景
+func (s *Server) Serve() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
+	return s.serve()
+}
+
+// MarkServing 等待实现了 ReadinessWaiter 的服务就绪后将健康状态设为 SERVING，
+// 随后通知实现了 Lifecycle 的服务；ctx 取消时返回错误且保持 NOT_SERVING
+func (s *Server) MarkServing(ctx context.Context) error {
+	s.mu.RLock()
+	services := s.services
+	s.mu.RUnlock()
+	
+	// 等待服务就绪时不持有锁，允许并发 Stop
+	if err := waitServicesReady(ctx, services); err != nil {
+		return err
+	}
+	
+	s.mu.Lock()
+	
+	// 等待期间已停止或被摘除时保持不可用状态
+	ready := s.started && !s.serving
 	if s.started {
-		return fmt.Errorf("server already started")
+		s.serving = true
+		if !s.draining {
+			s.setServingStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+		}
 	}
+	s.mu.Unlock()
 	
-	// 创建监听器
-	addr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.GRPCPort)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	// 通知时不持有锁，回调中可以调用 Server 的方法
+	if ready {
+		notifyServerReady(services)
 	}
-	s.listener = listener
 	
-	// 创建 gRPC 服务器选项
-	opts, err := s.buildServerOptions()
-	if err != nil {
-		return fmt.Errorf("failed to build server options: %w", err)
+	return nil
+}
+
+// Drain 将健康状态设为 NOT_SERVING 但继续处理请求，用于维护前将实例从负载均衡中摘除
+func (s *Server) Drain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.draining = true
+	s.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
+
+// Undrain 取消摘除，服务已就绪时恢复 SERVING
+func (s *Server) Undrain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.draining = false
+	if s.started && s.serving {
+		s.setServingStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+}
+
+// IsDraining 检查实例是否已被摘除
+func (s *Server) IsDraining() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.draining
+}
+
+// serve 创建监听器并开始服务，调用方需持有 s.mu
+// 服务就绪前健康状态为 NOT_SERVING
+func (s *Server) serve() error {
+	if s.started {
+		return fmt.Errorf("server already started")
 	}
 	
-	// 创建 gRPC 服务器
-	s.grpcServer = grpc.NewServer(opts...)
+	// 调用方提供的服务器停止后无法再次启动
+	for _, nl := range s.listeners {
+		if s.existingServer != nil && nl.grpcServer == s.existingServer {
+			return fmt.Errorf("existing gRPC server cannot be restarted after stop")
+		}
+	}
 	
-	// 注册健康检查服务
-	grpc_health_v1.RegisterHealthServer(s.grpcServer, s.healthSrv)
+	listeners, err := s.listenerConfigs()
+	if err != nil {
+		return err
+	}
 	
-	// 根据配置注册反射服务
-	if s.config.GRPC.Server.EnableReflection {
-		reflection.Register(s.grpcServer)
+	s.listeners = nil
+	for _, cfg := range listeners {
+		nl, err := s.newListener(cfg)
+		if err != nil {
+			// 释放已创建的监听器
+			for _, created := range s.listeners {
+				created.listener.Close()
+			}
+			s.listeners = nil
+			return err
+		}
+		s.listeners = append(s.listeners, nl)
 	}
 	
-	// 注册业务服务
-	for _, service := range s.services {
-		service.RegisterService(s.grpcServer)
+	// gRPC-Web 使用默认监听器的 gRPC 服务器
+	if s.config.Server.GrpcWeb.Enabled {
+		if err := s.startGrpcWeb(s.listeners[0]); err != nil {
+			for _, created := range s.listeners {
+				created.listener.Close()
+			}
+			s.listeners = nil
+			return err
+		}
 	}
 	
 	s.started = true
+	s.healthServices = s.registeredServiceNames()
 	
-	s.logger.Info("gRPC server starting", 
-		zap.String("address", addr),
-		zap.Int("services", len(s.services)))
+	// 就绪前报告不可用
+	s.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
 	
 	// 启动服务器
-	go func() {
-		if err := s.grpcServer.Serve(listener); err != nil {
-			s.logger.Error("gRPC server failed", zap.Error(err))
+	for _, nl := range s.listeners {
+		s.logger.Info("gRPC server starting",
+			zap.String("listener", nl.name),
+			zap.String("address", nl.listener.Addr().String()),
+			zap.Int("services", len(s.services)))
+		
+		if nl.mux != nil {
+			s.serveMultiplexHTTP(nl)
 		}
-	}()
-	
-	// 设置健康状态
-	s.healthSrv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+		go safe.Run(s.logger, "gRPC server panicked", func() {
+			if err := nl.grpcServer.Serve(nl.listener); err != nil {
+				s.logger.Error("gRPC server failed",
+					zap.String("listener", nl.name),
+					zap.Error(err))
+			}
+		}, zap.String("listener", nl.name))
+	}
 	
 	return nil
 }
 
-// Stop 停止服务器
+// listenerConfigs 返回默认监听器和额外配置的监听器
+func (s *Server) listenerConfigs() ([]config.ListenerConfig, error) {
+	listeners := []config.ListenerConfig{{
+		Name:             DefaultListenerName,
+		Host:             s.config.Server.Host,
+		Port:             s.config.Server.GRPCPort,
+		EnableReflection: s.config.GRPC.Server.EnableReflection,
+	}}
+	
+	names := map[string]bool{DefaultListenerName: true}
+	for _, listener := range s.config.Server.Listeners {
+		if listener.Name == "" {
+			return nil, fmt.Errorf("listener name must not be empty")
+		}
+		if names[listener.Name] {
+			return nil, fmt.Errorf("duplicate listener name %s", listener.Name)
+		}
+		names[listener.Name] = true
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// newListener 创建监听器及其 gRPC 服务器
+func (s *Server) newListener(cfg config.ListenerConfig) (*namedListener, error) {
+	listener, err := grpcserver.Listen(&s.config.Server, cfg.Host, cfg.Port, s.logger)
+	if err != nil {
+		return nil, err
+	}
+	listener = grpcserver.LimitListener(listener, s.config.GRPC.Server.MaxConnections)
+	
+	var grpcServer *grpc.Server
+	if cfg.Name == DefaultListenerName {
+		if s.existingServer != nil {
+			// 使用调用方提供的服务器，配置中的服务器选项不生效
+			s.logger.Info("Using existing gRPC server, server options from config are ignored")
+		}
+		grpcServer, err = s.defaultGRPCServer()
+		if err != nil {
+			listener.Close()
+			return nil, err
+		}
+	} else {
+		// 每个监听器使用独立的服务器选项
+		opts, err := s.buildServerOptions()
+		if err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to build server options: %w", err)
+		}
+		
+		// 创建 gRPC 服务器
+		grpcServer = grpc.NewServer(opts...)
+	}
+	
+	// 注册健康检查服务，配置禁用或调用方提供的服务器已注册时跳过
+	if !s.config.GRPC.Server.DisableDefaultHealth && !grpcserver.HasService(grpcServer, grpc_health_v1.Health_ServiceDesc.ServiceName) {
+		grpc_health_v1.RegisterHealthServer(grpcServer, s.healthSrv)
+	}
+	
+	// 根据配置注册反射服务，声明不公开的服务不出现在服务列表中
+	if cfg.EnableReflection && !grpcserver.HasService(grpcServer, grpc_reflection_v1.ServerReflection_ServiceDesc.ServiceName) {
+		grpcserver.RegisterReflection(grpcServer, reflectionHiddenServices(s.services))
+	}
+	
+	// 根据配置注册 channelz 服务
+	if s.config.GRPC.Server.EnableChannelz && !grpcserver.HasService(grpcServer, channelzServiceName) {
+		channelzservice.RegisterChannelzServiceToServer(grpcServer)
+	}
+	
+	// 注册业务服务
+	if cfg.Name != DefaultListenerName || !s.defaultRegistered {
+		for _, service := range s.services {
+			service.RegisterService(grpcServer)
+		}
+		if cfg.Name == DefaultListenerName {
+			s.defaultRegistered = true
+		}
+	}
+	
+	nl := &namedListener{
+		name:       cfg.Name,
+		listener:   listener,
+		grpcServer: grpcServer,
+	}
+	
+	// 默认监听器与 HTTP 服务器共用端口
+	if cfg.Name == DefaultListenerName && s.config.Server.MultiplexHTTP {
+		if err := s.multiplexHTTP(nl); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return nl, nil
+}
+
+// registeredServiceNames 返回各监听器上注册的业务服务名以及 NamedServiceRegistrar 声明的服务名，
+// 不含健康检查和反射服务，调用方需持有 s.mu
+func (s *Server) registeredServiceNames() []string {
+	seen := map[string]bool{
+		grpc_health_v1.Health_ServiceDesc.ServiceName:               true,
+		grpc_reflection_v1.ServerReflection_ServiceDesc.ServiceName: true,
+		"grpc.reflection.v1alpha.ServerReflection":                  true,
+		channelzServiceName: true,
+	}
+	var names []string
+	for _, nl := range s.listeners {
+		for name := range nl.grpcServer.GetServiceInfo() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range declaredServiceNames(s.services) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setServingStatus 同时设置整体和各业务服务的健康状态，调用方需持有 s.mu
+// 客户端 Watch 某个服务时可以观察到就绪、摘除和关闭引起的状态变化
+func (s *Server) setServingStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	s.healthSrv.SetServingStatus("", status)
+	for _, name := range s.healthServices {
+		s.healthSrv.SetServingStatus(name, status)
+	}
+}
+
+// Stop 停止服务器，所有监听器同时优雅关闭
 func (s *Server) Stop(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -129,12 +624,30 @@ func (s *Server) Stop(ctx context.Context) error {
 	s.logger.Info("Stopping gRPC server...")
 	
 	// 设置健康状态为不可用
-	s.healthSrv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	s.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	
+	// 通知服务结束长期存在的流，避免优雅关闭等待到超时
+	notifyServerStopping(s.services)
+	
+	// 先关闭 gRPC-Web 服务器，等待其正在处理的调用完成
+	if s.grpcWebServer != nil {
+		if err := s.stopGrpcWeb(ctx); err != nil {
+			s.logger.Warn("Failed to stop gRPC-Web server", zap.Error(err))
+		}
+	}
 	
 	// 优雅关闭
 	done := make(chan struct{})
 	go func() {
-		s.grpcServer.GracefulStop()
+		var wg sync.WaitGroup
+		for _, nl := range s.listeners {
+			wg.Add(1)
+			go func(server *grpc.Server) {
+				defer wg.Done()
+				server.GracefulStop()
+			}(nl.grpcServer)
+		}
+		wg.Wait()
 		close(done)
 	}()
 	
@@ -144,10 +657,24 @@ func (s *Server) Stop(ctx context.Context) error {
 		s.logger.Info("gRPC server stopped gracefully")
 	case <-ctx.Done():
 		s.logger.Warn("Force stopping gRPC server due to timeout")
-		s.grpcServer.Stop()
+		for _, nl := range s.listeners {
+			nl.grpcServer.Stop()
+		}
+	}
+	
+	// 关闭共用端口的 HTTP 服务器
+	for _, nl := range s.listeners {
+		if nl.mux != nil {
+			if err := s.stopMultiplexHTTP(ctx, nl); err != nil {
+				s.logger.Warn("Failed to stop multiplexed HTTP server", zap.Error(err))
+			}
+		}
 	}
 	
 	s.started = false
+	s.serving = false
+	s.defaultServer = nil
+	s.defaultRegistered = false
 	return nil
 }
 
@@ -167,11 +694,7 @@ func (s *Server) buildServerOptions() ([]grpc.ServerOption, error) {
 	}
 	
 	// 设置 Keepalive 配置
-	if s.config.GRPC.Server.KeepaliveTime > 0 {
-		keepaliveParams := keepalive.ServerParameters{
-			Time:    time.Duration(s.config.GRPC.Server.KeepaliveTime) * time.Second,
-			Timeout: time.Duration(s.config.GRPC.Server.KeepaliveTimeout) * time.Second,
-		}
+	if keepaliveParams, ok := grpcserver.KeepaliveParameters(&s.config.GRPC.Server); ok {
 		opts = append(opts, grpc.KeepaliveParams(keepaliveParams))
 	}
 	
@@ -188,6 +711,9 @@ func (s *Server) buildServerOptions() ([]grpc.ServerOption, error) {
 		opts = append(opts, grpc.ConnectionTimeout(time.Duration(s.config.GRPC.Server.ConnectionTimeout)*time.Second))
 	}
 	
+	// 设置流控窗口和头部列表大小
+	opts = append(opts, s.flowControlOptions()...)
+	
 	// 构建拦截器链
 	unaryInterceptors, streamInterceptors := s.buildInterceptors()
 	
@@ -198,8 +724,19 @@ func (s *Server) buildServerOptions() ([]grpc.ServerOption, error) {
 		opts = append(opts, grpc.ChainStreamInterceptor(streamInterceptors...))
 	}
 	
-	// TLS 配置
-	if s.config.TLS.Enabled {
+	for _, handler := range s.statsHandlers {
+		opts = append(opts, grpc.StatsHandler(handler))
+	}
+	
+	// 一元请求超限时由框架直接拒绝，通过 stats.Handler 记录方法名和上限
+	if serverCfg := s.config.GRPC.Server; serverCfg.EnableMessageSizeCheck {
+		opts = append(opts, grpc.StatsHandler(interceptor.MessageSizeStatsHandler(serverCfg.MaxRecvMsgSize, s.logger)))
+	}
+	
+	// TLS 配置，调用方提供的凭证优先
+	if s.creds != nil {
+		opts = append(opts, grpc.Creds(s.creds))
+	} else if s.config.TLS.Enabled {
 		creds, err := s.buildTLSCredentials()
 		if err != nil {
 			return nil, fmt.Errorf("failed to build TLS credentials: %w", err)
@@ -210,6 +747,22 @@ func (s *Server) buildServerOptions() ([]grpc.ServerOption, error) {
 	return opts, nil
 }
 
+// flowControlOptions 返回流控窗口和头部列表大小选项，值为 0 时使用 gRPC 默认值
+func (s *Server) flowControlOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	serverCfg := s.config.GRPC.Server
+	if serverCfg.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(serverCfg.InitialWindowSize))
+	}
+	if serverCfg.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(serverCfg.InitialConnWindowSize))
+	}
+	if serverCfg.MaxHeaderListSize > 0 {
+		opts = append(opts, grpc.MaxHeaderListSize(serverCfg.MaxHeaderListSize))
+	}
+	return opts
+}
+
 // buildTLSCredentials 构建 TLS 凭证
 func (s *Server) buildTLSCredentials() (credentials.TransportCredentials, error) {
 	if s.config.TLS.CertFile == "" || s.config.TLS.KeyFile == "" {
@@ -235,42 +788,280 @@ func (s *Server) buildTLSCredentials() (credentials.TransportCredentials, error)
 	return credentials.NewTLS(tlsConfig), nil
 }
 
+// chainInterceptor 服务端拦截器链中的一项
+type chainInterceptor struct {
+	name string
+	// enabled 拦截器当前是否执行
+	enabled bool
+	// toggled 为 true 时拦截器始终加入拦截器链，由运行时开关决定是否执行
+	toggled bool
+	build   func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor)
+}
+
+// interceptorChain 按由外到内的顺序返回配置的服务端拦截器，buildInterceptors 和 EnabledInterceptors 共用同一顺序
+func (s *Server) interceptorChain() []chainInterceptor {
+	serverCfg := s.config.GRPC.Server
+	
+	// 追踪拦截器位于最前，span 覆盖整个拦截器链；请求 ID 拦截器位于追踪之后，后续拦截器可从上下文获取请求 ID
+	chain := []chainInterceptor{
+		{name: "tracing", enabled: serverCfg.EnableTracing, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.TracingUnaryInterceptor(s.tracingOptions()...), interceptor.TracingStreamInterceptor(s.tracingOptions()...)
+		}},
+		{name: "request_id", enabled: serverCfg.EnableRequestID, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.RequestIDUnaryInterceptor(), interceptor.RequestIDStreamInterceptor()
+		}},
+	}
+	
+	// 内置拦截器始终加入拦截器链，是否执行由运行时开关决定，顺序由 interceptor_order 配置
+	chain = append(chain, interceptor.OrderBuiltins(serverCfg.InterceptorOrder, map[string]chainInterceptor{
+		interceptor.InterceptorRecovery: {name: interceptor.InterceptorRecovery, enabled: s.recoverySwitch.Enabled(), toggled: true, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.ToggleUnaryInterceptor(s.recoverySwitch, interceptor.RecoveryUnaryInterceptor(s.logger)),
+				interceptor.ToggleStreamInterceptor(s.recoverySwitch, interceptor.RecoveryStreamInterceptor(s.logger))
+		}},
+		interceptor.InterceptorLogging: {name: interceptor.InterceptorLogging, enabled: s.loggingSwitch.Enabled(), toggled: true, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.ToggleUnaryInterceptor(s.loggingSwitch, interceptor.LoggingUnaryInterceptor(s.logger, s.loggingOptions()...)),
+				interceptor.ToggleStreamInterceptor(s.loggingSwitch, interceptor.LoggingStreamInterceptor(s.logger, s.loggingOptions()...))
+		}},
+		interceptor.InterceptorMetrics: {name: interceptor.InterceptorMetrics, enabled: s.metricsSwitch.Enabled(), toggled: true, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			// 请求持续时间直方图使用配置的桶边界
+			metrics := s.requestMetrics()
+			if err := metrics.ConfigureLatencyBuckets(s.config.Metrics.LatencyBuckets); err != nil {
+				s.logger.Warn("Failed to configure latency buckets, keeping previous buckets", zap.Error(err))
+			}
+			return interceptor.ToggleUnaryInterceptor(s.metricsSwitch, metrics.UnaryInterceptor()),
+				interceptor.ToggleStreamInterceptor(s.metricsSwitch, metrics.StreamInterceptor())
+		}},
+	})...)
+	
+	// 校验和访问控制类拦截器位于内置拦截器之后，以便记录被拒绝的请求；错误映射位于最内层，日志和指标记录映射后的状态码
+	acl := serverCfg.MethodACL
+	chain = append(chain,
+		chainInterceptor{name: "message_size", enabled: serverCfg.EnableMessageSizeCheck, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.MessageSizeUnaryInterceptor(serverCfg.MaxRecvMsgSize), interceptor.MessageSizeStreamInterceptor(serverCfg.MaxRecvMsgSize)
+		}},
+		chainInterceptor{name: "reflection_auth", enabled: serverCfg.ReflectionAuth.Enabled, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			auth := serverCfg.ReflectionAuth
+			return interceptor.ReflectionAuthUnaryInterceptor(auth.MetadataKey, auth.Token), interceptor.ReflectionAuthStreamInterceptor(auth.MetadataKey, auth.Token)
+		}},
+		chainInterceptor{name: "method_acl", enabled: len(acl.Allow) > 0 || len(acl.Deny) > 0, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			code := interceptor.MethodACLCode(acl.Code)
+			return interceptor.MethodACLUnaryInterceptor(acl.Allow, acl.Deny, code), interceptor.MethodACLStreamInterceptor(acl.Allow, acl.Deny, code)
+		}},
+		chainInterceptor{name: "required_metadata", enabled: len(serverCfg.RequiredMetadata) > 0, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.RequiredMetadataUnaryInterceptor(serverCfg.RequiredMetadata), interceptor.RequiredMetadataStreamInterceptor(serverCfg.RequiredMetadata)
+		}},
+		chainInterceptor{name: "validation", enabled: serverCfg.EnableValidation, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.ValidationUnaryInterceptor(), interceptor.ValidationStreamInterceptor()
+		}},
+		chainInterceptor{name: "deprecation", enabled: len(serverCfg.DeprecatedMethods) > 0, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.DeprecationUnaryInterceptor(s.logger, serverCfg.DeprecatedMethods, serverCfg.DeprecationHeader),
+				interceptor.DeprecationStreamInterceptor(s.logger, serverCfg.DeprecatedMethods, serverCfg.DeprecationHeader)
+		}},
+		chainInterceptor{name: "server_identity", enabled: serverCfg.EnableServerIdentity, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			identity := interceptor.ResolveServerIdentity(serverCfg.ServerIdentity)
+			return interceptor.ServerIdentityUnaryInterceptor(serverCfg.ServerIdentityHeader, identity),
+				interceptor.ServerIdentityStreamInterceptor(serverCfg.ServerIdentityHeader, identity)
+		}},
+		chainInterceptor{name: "compression", enabled: serverCfg.EnableCompression, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.CompressionUnaryInterceptor(serverCfg.CompressionLevel), interceptor.CompressionStreamInterceptor(serverCfg.CompressionLevel)
+		}},
+		chainInterceptor{name: "error_mapping", enabled: s.errorMapping != nil, build: func() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+			return interceptor.ErrorMappingUnaryInterceptor(s.errorMapping...), interceptor.ErrorMappingStreamInterceptor(s.errorMapping...)
+		}},
+	)
+	return chain
+}
+
 // buildInterceptors 构建拦截器链
 func (s *Server) buildInterceptors() ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
 	var unaryInterceptors []grpc.UnaryServerInterceptor
 	var streamInterceptors []grpc.StreamServerInterceptor
 	
-	// 根据配置添加拦截器
-	if s.config.GRPC.Server.EnableLogging {
-		unaryInterceptors = append(unaryInterceptors, interceptor.LoggingUnaryInterceptor(s.logger))
-		streamInterceptors = append(streamInterceptors, interceptor.LoggingStreamInterceptor(s.logger))
+	for _, entry := range s.interceptorChain() {
+		if !entry.enabled && !entry.toggled {
+			continue
+		}
+		unary, stream := entry.build()
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
 	}
 	
-	if s.config.GRPC.Server.EnableRecovery {
-		unaryInterceptors = append(unaryInterceptors, interceptor.RecoveryUnaryInterceptor(s.logger))
-		streamInterceptors = append(streamInterceptors, interceptor.RecoveryStreamInterceptor(s.logger))
+	return unaryInterceptors, streamInterceptors
+}
+
+// EnabledInterceptors 按拦截器链顺序返回当前执行的服务端拦截器名，内置拦截器按运行时开关判断
+// 使用 SetGrpcServer 提供的服务器时配置中的拦截器不生效，返回空列表
+func (s *Server) EnabledInterceptors() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	if s.existingServer != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range s.interceptorChain() {
+		if entry.enabled {
+			names = append(names, entry.name)
+		}
 	}
+	return names
+}
+
+// loggingOptions 构建日志拦截器选项
+func (s *Server) loggingOptions() []interceptor.LoggingOption {
+	opts := []interceptor.LoggingOption{
+		interceptor.WithPayloadLogging(s.config.GRPC.Server.LogPayloads, s.config.GRPC.Server.LogPayloadMaxSize),
+		interceptor.WithDynamicSlowThreshold(s.slowThreshold),
+	}
+	if s.payloadRedactor != nil {
+		opts = append(opts, interceptor.WithPayloadRedactor(s.payloadRedactor))
+	}
+	if s.accessLogger != nil {
+		opts = append(opts, interceptor.WithAccessLog(s.accessLogger))
+	}
+	return opts
+}
+
+// SetMetricsRegistry 将请求指标注册到指定注册表而不是 prometheus 默认注册表，需在 Start 之前调用
+func (s *Server) SetMetricsRegistry(registry prometheus.Registerer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	
-	if s.config.GRPC.Server.EnableMetrics {
-		unaryInterceptors = append(unaryInterceptors, interceptor.MetricsUnaryInterceptor())
-		streamInterceptors = append(streamInterceptors, interceptor.MetricsStreamInterceptor())
+	s.metrics = interceptor.NewMetrics(registry)
+}
+
+// requestMetrics 返回服务器使用的请求指标
+func (s *Server) requestMetrics() *interceptor.Metrics {
+	if s.metrics != nil {
+		return s.metrics
 	}
+	return interceptor.DefaultMetrics()
+}
+
+// SetErrorMapping 启用错误映射拦截器，将处理器返回的哨兵错误或自定义分类的错误转换为 gRPC 状态，需在 Start 之前调用
+func (s *Server) SetErrorMapping(opts ...interceptor.ErrorMappingOption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	
-	// TODO: 添加 tracing 拦截器支持
-	// if s.config.GRPC.Server.EnableTracing {
-	//     unaryInterceptors = append(unaryInterceptors, interceptor.TracingUnaryInterceptor())
-	//     streamInterceptors = append(streamInterceptors, interceptor.TracingStreamInterceptor())
-	// }
+	s.errorMapping = append([]interceptor.ErrorMappingOption{}, opts...)
+}
+
+// SetPayloadRedactor 设置载荷日志脱敏钩子，需在 Start 之前调用
+func (s *Server) SetPayloadRedactor(redactor interceptor.PayloadRedactor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	
-	return unaryInterceptors, streamInterceptors
+	s.payloadRedactor = redactor
+}
+
+// tracingOptions 构建追踪拦截器选项
+func (s *Server) tracingOptions() []interceptor.TracingOption {
+	if s.tracerProvider == nil {
+		return nil
+	}
+	return []interceptor.TracingOption{interceptor.WithTracerProvider(s.tracerProvider)}
 }
 
-// GetAddress 获取服务器地址
+// SetTracerProvider 设置追踪使用的 TracerProvider，需在 Start 之前调用
+func (s *Server) SetTracerProvider(tp trace.TracerProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.tracerProvider = tp
+}
+
+// SetGrpcServer 使用调用方提供的 gRPC 服务器，需在 Start 之前调用
+// 默认监听器将使用该服务器而不是新建服务器，配置中的服务器选项和内置拦截器不生效；
+// 健康检查、反射（未注册时）和已注册的业务服务仍会注册到该服务器，因此不能提前调用其 Serve
+func (s *Server) SetGrpcServer(grpcServer *grpc.Server) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	s.existingServer = grpcServer
+}
+
+// ApplyInterceptorConfig 应用拦截器配置，用于配置重载后动态启用或禁用内置拦截器并调整慢请求阈值
+func (s *Server) ApplyInterceptorConfig(cfg *config.GRPCServerConfig) {
+	s.loggingSwitch.Set(cfg.EnableLogging)
+	s.recoverySwitch.Set(cfg.EnableRecovery)
+	s.metricsSwitch.Set(cfg.EnableMetrics)
+	s.slowThreshold.Set(time.Duration(cfg.SlowThreshold) * time.Millisecond)
+	
+	s.logger.Info("gRPC server interceptor config applied",
+		zap.Bool("logging", cfg.EnableLogging),
+		zap.Bool("recovery", cfg.EnableRecovery),
+		zap.Bool("metrics", cfg.EnableMetrics),
+		zap.Int("slow_threshold_ms", cfg.SlowThreshold))
+}
+
+// GRPCServer 返回默认监听器使用的 *grpc.Server
+// Start 或 Serve 之前调用时按配置创建服务器，启动时默认监听器使用同一个服务器，
+// 因此可以在启动前注册 channelz、xDS 等不是 ServiceRegistrar 的服务；启动后再注册服务或修改服务器是不安全的。
+// 创建后再调用 SetMetricsRegistry 等影响服务器选项的方法不再生效；服务器选项构建失败时返回 nil，Start 会返回同样的错误
+func (s *Server) GRPCServer() *grpc.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if s.started {
+		for _, nl := range s.listeners {
+			if nl.name == DefaultListenerName {
+				return nl.grpcServer
+			}
+		}
+		return nil
+	}
+	
+	grpcServer, err := s.defaultGRPCServer()
+	if err != nil {
+		s.logger.Warn("Failed to create gRPC server", zap.Error(err))
+		return nil
+	}
+	return grpcServer
+}
+
+// defaultGRPCServer 返回默认监听器的 gRPC 服务器，优先使用调用方提供的服务器，尚未创建时按配置创建，调用方需持有 s.mu
+func (s *Server) defaultGRPCServer() (*grpc.Server, error) {
+	if s.existingServer != nil {
+		return s.existingServer, nil
+	}
+	if s.defaultServer == nil {
+		opts, err := s.buildServerOptions()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build server options: %w", err)
+		}
+		s.defaultServer = grpc.NewServer(opts...)
+	}
+	return s.defaultServer, nil
+}
+
+// GetAddress 获取默认监听器地址
 func (s *Server) GetAddress() string {
-	if s.listener == nil {
-		return ""
+	return s.GetListenerAddress(DefaultListenerName)
+}
+
+// GetListenerAddress 获取指定监听器的地址，监听器不存在或未启动时返回空字符串
+func (s *Server) GetListenerAddress(name string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	for _, nl := range s.listeners {
+		if nl.name == name {
+			return nl.listener.Addr().String()
+		}
 	}
-	return s.listener.Addr().String()
+	return ""
+}
+
+// ServiceNames 返回启动时各监听器上注册的业务服务名，不含健康检查和反射服务，启动前返回空
+func (s *Server) ServiceNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.healthServices...)
+}
+
+// TLSEnabled 检查 gRPC 服务器是否使用 TLS
+func (s *Server) TLSEnabled() bool {
+	return s.usesTLS()
 }
 
 // IsHealthy 检查服务器健康状态
@@ -1,16 +1,54 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
 	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
+	greeterpb "github.com/go-grpc-kit/go-grpc-kit/examples/simple/proto"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc"
+	channelzgrpc "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // TestService 测试服务
@@ -103,6 +141,50 @@ func TestRegisterServiceAfterStart(t *testing.T) {
 	}
 }
 
+func TestTryRegisterServiceAfterStart(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+
+	// 启动前注册成功
+	if err := server.TryRegisterService(namedService("test.Early")); err != nil {
+		t.Fatalf("Expected registration before start to succeed, got %v", err)
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	before := testutil.ToFloat64(grpcLateRegistrations)
+	err := server.TryRegisterService(namedService("test.Late"))
+	if !errors.Is(err, ErrServerStarted) {
+		t.Errorf("Expected ErrServerStarted, got %v", err)
+	}
+	server.RegisterService(namedService("test.Later"))
+
+	if got := testutil.ToFloat64(grpcLateRegistrations) - before; got != 2 {
+		t.Errorf("Expected late registration counter to increase by 2, got %v", got)
+	}
+	if len(server.services) != 1 {
+		t.Errorf("Expected 1 service, got %d", len(server.services))
+	}
+}
+
 func TestStartAndStop(t *testing.T) {
 	cfg := &config.Config{
 		Server: config.ServerConfig{
@@ -155,6 +237,51 @@ func TestStartAndStop(t *testing.T) {
 	}
 }
 
+func TestStartPortInUse(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer occupied.Close()
+	port := occupied.Addr().(*net.TCPAddr).Port
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{Host: "127.0.0.1", GRPCPort: port},
+	}
+	server := New(cfg, zap.NewNop())
+
+	// 端口被占用时返回指明端口和原因的错误
+	err = server.Start()
+	if err == nil {
+		server.Stop(context.Background())
+		t.Fatal("Expected error when port is in use")
+	}
+	if !errors.Is(err, syscall.EADDRINUSE) {
+		t.Errorf("Expected EADDRINUSE, got %v", err)
+	}
+	expected := fmt.Sprintf("port %d is already in use", port)
+	if !strings.Contains(err.Error(), expected) || !strings.Contains(err.Error(), "server.auto_port") {
+		t.Errorf("Expected error to contain %q and suggest server.auto_port, got %v", expected, err)
+	}
+
+	// 开启 auto_port 后改为监听随机空闲端口
+	core, logs := observer.New(zap.InfoLevel)
+	cfg.Server.AutoPort = true
+	server = New(cfg, zap.New(core))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Expected auto_port to fall back to a free port, got %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	_, actual, _ := net.SplitHostPort(server.GetAddress())
+	if actual == strconv.Itoa(port) || actual == "0" {
+		t.Errorf("Expected a different free port, got %s", server.GetAddress())
+	}
+	if logs.FilterMessage("gRPC port already in use, listening on a random free port").Len() != 1 {
+		t.Error("Expected fallback port to be logged")
+	}
+}
+
 func TestGetAddress(t *testing.T) {
 	cfg := &config.Config{
 		Server: config.ServerConfig{
@@ -253,6 +380,163 @@ func TestHealthCheck(t *testing.T) {
 	}
 }
 
+func TestServerTracing(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+				EnableTracing:  true,
+			},
+		},
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	server := New(cfg, zap.NewNop())
+	server.SetTracerProvider(tp)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Name != "grpc.health.v1.Health/Check" {
+		t.Errorf("Expected span name grpc.health.v1.Health/Check, got %s", spans[0].Name)
+	}
+}
+
+func TestSetGrpcServer(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+	}
+
+	// 调用方自带拦截器并已注册健康检查的服务器
+	intercepted := 0
+	existing := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		intercepted++
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(existing, &TestService{})
+
+	server := New(cfg, zap.NewNop())
+	server.SetGrpcServer(existing)
+	server.RegisterService(namedService("test.Registered"))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	if _, ok := existing.GetServiceInfo()["test.Registered"]; !ok {
+		t.Error("Expected registered services on the existing server")
+	}
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if intercepted != 1 {
+		t.Errorf("Expected caller's interceptor to handle the call, got %d calls", intercepted)
+	}
+
+	server.Stop(ctx)
+
+	// 停止后的服务器无法再次启动
+	if err := server.Start(); err == nil {
+		t.Error("Expected error when restarting with an existing server")
+	}
+}
+
+func TestMaxConnections(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+				MaxConnections: 1,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	check := func(conn *grpc.ClientConn, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		_, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	first, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer first.Close()
+	if err := check(first, 5*time.Second); err != nil {
+		t.Fatalf("First connection health check failed: %v", err)
+	}
+
+	// 超出上限的连接在已有连接关闭前不会被接受
+	second, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer second.Close()
+	if err := check(second, 300*time.Millisecond); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("Expected excess connection to be queued, got %v", err)
+	}
+
+	// 第一个连接关闭后排队的连接被接受
+	first.Close()
+	if err := check(second, 5*time.Second); err != nil {
+		t.Errorf("Expected queued connection to be served after the first closed, got %v", err)
+	}
+}
+
 func TestSetHealthStatus(t *testing.T) {
 	cfg := &config.Config{}
 	logger := zap.NewNop()
@@ -264,6 +548,110 @@ func TestSetHealthStatus(t *testing.T) {
 	// 这里主要测试方法不会panic，具体的健康状态检查需要集成测试
 }
 
+func TestDrainBeforeServing(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+	}
+	server := New(cfg, zap.NewNop())
+
+	if err := server.Serve(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	checkStatus := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := server.healthSrv.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		return resp.Status
+	}
+
+	// 就绪前摘除，就绪后仍保持 NOT_SERVING
+	server.Drain()
+	if err := server.MarkServing(context.Background()); err != nil {
+		t.Fatalf("Failed to mark serving: %v", err)
+	}
+	if status := checkStatus(); status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected NOT_SERVING while drained, got %v", status)
+	}
+
+	server.Undrain()
+	if status := checkStatus(); status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING after undrain, got %v", status)
+	}
+}
+
+func TestPerServiceHealthWatch(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(namedService("test.OrderService"))
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := grpc_health_v1.NewHealthClient(conn).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "test.OrderService"})
+	if err != nil {
+		t.Fatalf("Failed to watch health: %v", err)
+	}
+
+	expectStatus := func(expected grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive health update: %v", err)
+		}
+		if resp.Status != expected {
+			t.Errorf("Expected %v, got %v", expected, resp.Status)
+		}
+	}
+
+	// 业务服务启动后为 SERVING，摘除和恢复时推送状态变化
+	expectStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+	server.Drain()
+	expectStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	server.Undrain()
+	expectStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+
+	// 健康检查和反射服务不单独设置状态
+	resp, err := server.healthSrv.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: grpc_health_v1.Health_ServiceDesc.ServiceName})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected health service itself to have no status, got %v %v", resp, err)
+	}
+}
+
 func TestBuildServerOptions(t *testing.T) {
 	cfg := &config.Config{
 		GRPC: config.GRPCConfig{
@@ -289,6 +677,54 @@ func TestBuildServerOptions(t *testing.T) {
 	}
 }
 
+func TestFlowControlOptions(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{Host: "localhost"},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	if opts := server.flowControlOptions(); len(opts) != 0 {
+		t.Errorf("Expected no flow control options by default, got %d", len(opts))
+	}
+
+	cfg.GRPC.Server.InitialWindowSize = 1 << 20
+	cfg.GRPC.Server.InitialConnWindowSize = 4 << 20
+	cfg.GRPC.Server.MaxHeaderListSize = 1024
+	if opts := server.flowControlOptions(); len(opts) != 3 {
+		t.Fatalf("Expected 3 flow control options, got %d", len(opts))
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Expected health check to succeed, got %v", err)
+	}
+
+	// 超过 max_header_list_size 的请求头被服务器拒绝
+	large := metadata.AppendToOutgoingContext(ctx, "x-large", strings.Repeat("x", 4096))
+	if _, err := client.Check(large, &grpc_health_v1.HealthCheckRequest{}); err == nil {
+		t.Error("Expected request with oversized headers to fail")
+	}
+}
+
 func TestBuildServerOptionsWithTLS(t *testing.T) {
 	cfg := &config.Config{
 		GRPC: config.GRPCConfig{
@@ -312,23 +748,314 @@ func TestBuildServerOptionsWithTLS(t *testing.T) {
 	}
 }
 
-// BenchmarkServerStart 性能测试
-func BenchmarkServerStart(b *testing.B) {
-	cfg := &config.Config{
-		Server: config.ServerConfig{
-			Host:     "localhost",
-			GRPCPort: 0,
-		},
-		GRPC: config.GRPCConfig{
-			Server: config.GRPCServerConfig{
-				MaxRecvMsgSize: 4 * 1024 * 1024,
-				MaxSendMsgSize: 4 * 1024 * 1024,
-			},
-		},
-	}
-	logger := zap.NewNop()
+// selfSignedTLSConfig 生成 localhost 自签名证书的 TLS 配置
+func selfSignedTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
 
-	b.ResetTimer()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+}
+
+func TestCredentialsOverrideConfigTLS(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Server: config.ServerConfig{
+				Host:     "localhost",
+				GRPCPort: 0,
+			},
+			GRPC: config.GRPCConfig{
+				Server: config.GRPCServerConfig{
+					MaxRecvMsgSize: 4 * 1024 * 1024,
+					MaxSendMsgSize: 4 * 1024 * 1024,
+				},
+			},
+			// 配置中的证书文件无效，只有调用方提供的凭证生效时服务器才能启动
+			TLS: config.TLSConfig{
+				Enabled:  true,
+				CertFile: "invalid-cert.pem",
+				KeyFile:  "invalid-key.pem",
+			},
+		}
+	}
+
+	checkHealth := func(t *testing.T, server *Server, creds credentials.TransportCredentials) {
+		t.Helper()
+		if err := server.Start(); err != nil {
+			t.Fatalf("Failed to start server: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Stop(ctx)
+		}()
+
+		conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(creds))
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+	}
+
+	t.Run("tls config", func(t *testing.T) {
+		server := New(newConfig(), zap.NewNop(), WithTLSConfig(selfSignedTLSConfig(t)))
+		checkHealth(t, server, credentials.NewTLS(&tls.Config{InsecureSkipVerify: true}))
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		creds := credentials.NewTLS(selfSignedTLSConfig(t))
+		server := New(newConfig(), zap.NewNop(), WithCredentials(creds))
+		checkHealth(t, server, credentials.NewTLS(&tls.Config{InsecureSkipVerify: true}))
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		server := New(newConfig(), zap.NewNop(), WithInsecure())
+		checkHealth(t, server, insecure.NewCredentials())
+	})
+}
+
+// recordingStatsHandler 统计 HandleRPC 收到的服务端调用结束事件
+type recordingStatsHandler struct {
+	ended atomic.Int32
+}
+
+func (h *recordingStatsHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *recordingStatsHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if _, ok := s.(*stats.End); ok && !s.IsClient() {
+		h.ended.Add(1)
+	}
+}
+
+func (h *recordingStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *recordingStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {}
+
+func TestWithStatsHandler(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	handler := &recordingStatsHandler{}
+	server := New(cfg, zap.NewNop(), WithStatsHandler(handler))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+
+	// 服务端结束事件可能在客户端收到响应后才上报
+	deadline := time.Now().Add(5 * time.Second)
+	for handler.ended.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if handler.ended.Load() == 0 {
+		t.Error("Expected stats handler to receive the RPC end event")
+	}
+}
+
+func TestReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_REUSEPORT test only runs on linux")
+	}
+
+	newConfig := func(port int) *config.Config {
+		return &config.Config{
+			Server: config.ServerConfig{
+				Host:      "127.0.0.1",
+				GRPCPort:  port,
+				ReusePort: true,
+			},
+			GRPC: config.GRPCConfig{
+				Server: config.GRPCServerConfig{
+					MaxRecvMsgSize: 4 * 1024 * 1024,
+					MaxSendMsgSize: 4 * 1024 * 1024,
+				},
+			},
+		}
+	}
+	stop := func(server *Server) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}
+
+	old := New(newConfig(0), zap.NewNop())
+	if err := old.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer stop(old)
+
+	_, portStr, _ := net.SplitHostPort(old.GetAddress())
+	port, _ := strconv.Atoi(portStr)
+
+	// 新进程在旧进程仍在监听时绑定同一端口
+	upgraded := New(newConfig(port), zap.NewNop())
+	if err := upgraded.Start(); err != nil {
+		t.Fatalf("Expected second server to bind port %d, got %v", port, err)
+	}
+	defer stop(upgraded)
+
+	// 未启用时绑定失败
+	cfg := newConfig(port)
+	cfg.Server.ReusePort = false
+	if err := New(cfg, zap.NewNop()).Start(); err == nil {
+		t.Error("Expected bind to fail without reuse_port")
+	}
+}
+
+func TestMultiplexHTTP(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "127.0.0.1",
+			GRPCPort:      0,
+			MultiplexHTTP: true,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server.SetHTTPHandler(mux)
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	// 同一端口响应 gRPC 健康检查
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING status, got %v", resp.Status)
+	}
+
+	// 同一端口响应 HTTP 请求
+	httpResp, err := http.Get("http://" + server.GetAddress() + "/metrics")
+	if err != nil {
+		t.Fatalf("HTTP request failed: %v", err)
+	}
+	body, _ := io.ReadAll(httpResp.Body)
+	httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", httpResp.StatusCode)
+	}
+	if !strings.Contains(string(body), "go_goroutines") {
+		t.Error("Expected metrics in response body")
+	}
+
+	httpResp, err = http.Get("http://" + server.GetAddress() + "/missing")
+	if err != nil {
+		t.Fatalf("HTTP request failed: %v", err)
+	}
+	httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", httpResp.StatusCode)
+	}
+}
+
+func TestMultiplexHTTPWithTLS(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "127.0.0.1",
+			GRPCPort:      0,
+			MultiplexHTTP: true,
+		},
+	}
+	server := New(cfg, zap.NewNop(), WithCredentials(credentials.NewTLS(selfSignedTLSConfig(t))))
+	if err := server.Start(); err == nil {
+		server.Stop(context.Background())
+		t.Fatal("Expected start to fail when multiplex_http is used with TLS")
+	}
+}
+
+// BenchmarkServerStart 性能测试
+func BenchmarkServerStart(b *testing.B) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	logger := zap.NewNop()
+
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		server := New(cfg, logger)
 		err := server.Start()
@@ -340,4 +1067,1124 @@ func BenchmarkServerStart(b *testing.B) {
 		server.Stop(ctx)
 		cancel()
 	}
-}
\ No newline at end of file
+}
+func TestEnabledInterceptors(t *testing.T) {
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				EnableRecovery:         true,
+				EnableMetrics:          true,
+				EnableRequestID:        true,
+				EnableValidation:       true,
+				EnableMessageSizeCheck: true,
+				MaxRecvMsgSize:         1024,
+				MethodACL:              config.MethodACLConfig{Deny: []string{"/admin.*"}},
+				DeprecatedMethods:      []string{"/test.Service/Old"},
+				EnableCompression:      true,
+				InterceptorOrder:       []string{"metrics", "recovery"},
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.SetErrorMapping()
+
+	expected := "request_id,metrics,recovery,message_size,method_acl,validation,deprecation,compression,error_mapping"
+	if got := strings.Join(server.EnabledInterceptors(), ","); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	// 与实际构建的拦截器链一致，关闭的内置拦截器仍在链中但不执行
+	unaryInterceptors, streamInterceptors := server.buildInterceptors()
+	if len(unaryInterceptors) != len(server.EnabledInterceptors())+1 || len(streamInterceptors) != len(unaryInterceptors) {
+		t.Errorf("Expected chain to hold enabled interceptors plus the disabled logging builtin, got %d unary and %d stream",
+			len(unaryInterceptors), len(streamInterceptors))
+	}
+
+	// 运行时开关关闭后不再列出
+	server.ApplyInterceptorConfig(&config.GRPCServerConfig{EnableRecovery: true})
+	expected = "request_id,recovery,message_size,method_acl,validation,deprecation,compression,error_mapping"
+	if got := strings.Join(server.EnabledInterceptors(), ","); got != expected {
+		t.Errorf("Expected %s after disabling metrics, got %s", expected, got)
+	}
+}
+
+func TestApplyInterceptorConfig(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				EnableLogging: true,
+			},
+		},
+	}
+	server := New(cfg, zap.New(core))
+
+	unaryInterceptors, _ := server.buildInterceptors()
+	chain := func() {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "response", nil
+		}
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/TestMethod"}
+		if _, err := chainUnary(unaryInterceptors, info, handler)(context.Background(), "request"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	chain()
+	if logs.FilterMessage("gRPC unary call completed").Len() != 1 {
+		t.Fatalf("Expected logging interceptor to emit while enabled")
+	}
+
+	// 模拟配置重载关闭日志
+	server.ApplyInterceptorConfig(&config.GRPCServerConfig{EnableLogging: false})
+
+	chain()
+	if logs.FilterMessage("gRPC unary call completed").Len() != 1 {
+		t.Error("Expected logging interceptor to stop emitting after being disabled")
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	path := filepath.Join(t.TempDir(), "access.log")
+	cfg := &config.Config{
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{EnableLogging: true},
+		},
+		Logging: config.LoggingConfig{
+			AccessLog: config.AccessLogConfig{Enabled: true, OutputPaths: []string{path}},
+		},
+	}
+	server := New(cfg, zap.New(core))
+
+	unaryInterceptors, _ := server.buildInterceptors()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/TestMethod"}
+	if _, err := chainUnary(unaryInterceptors, info, handler)(context.Background(), "request"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	server.accessLogger.Sync()
+
+	// 调用记录写入访问日志，不写入主日志器
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read access log: %v", err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Expected one JSON access log line, got %q: %v", data, err)
+	}
+	if entry["method"] != "/test.Service/TestMethod" || entry["code"] != "OK" {
+		t.Errorf("Unexpected access log entry %v", entry)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("Expected no call records in application logger, got %v", logs.All())
+	}
+}
+
+// chatService 示例 Greeter 服务的 Chat 实现，收到 panic 消息时触发 panic
+type chatService struct {
+	greeterpb.UnimplementedGreeterServer
+}
+
+func (c *chatService) RegisterService(server grpc.ServiceRegistrar) {
+	greeterpb.RegisterGreeterServer(server, c)
+}
+
+func (c *chatService) Chat(stream greeterpb.Greeter_ChatServer) error {
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Text == "panic" {
+			panic("chat panic")
+		}
+		if err := stream.Send(&greeterpb.ChatMessage{Sender: "server", Text: msg.Text}); err != nil {
+			return err
+		}
+	}
+}
+
+func TestStreamInterceptorChain(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+				EnableLogging:  true,
+				EnableMetrics:  true,
+				EnableRecovery: true,
+			},
+		},
+	}
+	core, logs := observer.New(zap.InfoLevel)
+	registry := prometheus.NewRegistry()
+	server := New(cfg, zap.New(core))
+	server.SetMetricsRegistry(registry)
+	server.RegisterService(&chatService{})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := greeterpb.NewGreeterClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 正常的双向流：每条消息得到一条回复
+	stream, err := client.Chat(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	for _, text := range []string{"one", "two", "three"} {
+		if err := stream.Send(&greeterpb.ChatMessage{Sender: "client", Text: text}); err != nil {
+			t.Fatalf("Failed to send: %v", err)
+		}
+		reply, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Failed to receive: %v", err)
+		}
+		if reply.Text != text {
+			t.Errorf("Expected echo %q, got %q", text, reply.Text)
+		}
+	}
+	stream.CloseSend()
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Expected EOF after close, got %v", err)
+	}
+
+	// 处理器 panic 时由恢复拦截器转换为 Internal
+	stream, err = client.Chat(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	stream.Send(&greeterpb.ChatMessage{Sender: "client", Text: "panic"})
+	if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+		t.Fatalf("Expected Internal after panic, got %v", err)
+	}
+
+	// 服务端在返回状态后才记录指标和日志；恢复拦截器位于最外层，panic 的调用以 Internal 计入请求数
+	const method = "/greeter.Greeter/Chat"
+	expected := []string{
+		`grpc_requests_total{code="0",method="` + method + `"} 1`,
+		`grpc_requests_total{code="13",method="` + method + `"} 1`,
+		`grpc_stream_msgs_received_total{method="` + method + `"} 4`,
+		`grpc_stream_msgs_sent_total{method="` + method + `"} 3`,
+	}
+	var body string
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rr := httptest.NewRecorder()
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+		body = rr.Body.String()
+		missing := false
+		for _, line := range expected {
+			if !strings.Contains(body, line) {
+				missing = true
+			}
+		}
+		if !missing || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected metric %s, got:\n%s", line, body)
+		}
+	}
+
+	if logs.FilterMessage("gRPC stream call completed").Len() != 1 {
+		t.Errorf("Expected one completed stream log, got %d", logs.FilterMessage("gRPC stream call completed").Len())
+	}
+	if logs.FilterMessage("gRPC stream call panic recovered").Len() != 1 {
+		t.Error("Expected panic to be logged by the recovery interceptor")
+	}
+	if logs.FilterMessage("gRPC stream call failed").Len() != 1 {
+		t.Error("Expected recovered stream to be logged as failed")
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+	}{
+		{name: "default", order: nil},
+		{name: "recovery outermost", order: []string{"recovery", "metrics", "logging"}},
+		{name: "recovery innermost", order: []string{"logging", "metrics", "recovery"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GRPC: config.GRPCConfig{
+					Server: config.GRPCServerConfig{
+						EnableLogging:    true,
+						EnableMetrics:    true,
+						EnableRecovery:   true,
+						InterceptorOrder: tt.order,
+					},
+				},
+			}
+			core, logs := observer.New(zap.InfoLevel)
+			registry := prometheus.NewRegistry()
+			server := New(cfg, zap.New(core))
+			server.SetMetricsRegistry(registry)
+			
+			// 内置拦截器之后的拦截器 panic
+			unaryInterceptors, _ := server.buildInterceptors()
+			unaryInterceptors = append(unaryInterceptors, func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				panic("downstream interceptor failed")
+			})
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			}
+			
+			const method = "/test.Service/Method"
+			_, err := chainUnary(unaryInterceptors, &grpc.UnaryServerInfo{FullMethod: method}, handler)(context.Background(), "request")
+			if status.Code(err) != codes.Internal {
+				t.Fatalf("Expected Internal after panic, got %v", err)
+			}
+			
+			rr := httptest.NewRecorder()
+			promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+			if line := `grpc_requests_total{code="13",method="` + method + `"} 1`; !strings.Contains(rr.Body.String(), line) {
+				t.Errorf("Expected metric %s, got:\n%s", line, rr.Body.String())
+			}
+			if logs.FilterMessage("gRPC unary call failed").Len() != 1 {
+				t.Error("Expected panicked call to be logged as failed")
+			}
+		})
+	}
+}
+
+// chainUnary 按 grpc.ChainUnaryInterceptor 的顺序将拦截器串联到处理器上
+func chainUnary(interceptors []grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		next, current := handler, interceptors[i]
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return current(ctx, req, info, next)
+		}
+	}
+	return handler
+}
+
+// helloService 示例 Greeter 服务的 SayHello 实现，在 trailer 中返回请求的名字
+type helloService struct {
+	greeterpb.UnimplementedGreeterServer
+}
+
+func (h *helloService) RegisterService(server grpc.ServiceRegistrar) {
+	greeterpb.RegisterGreeterServer(server, h)
+}
+
+func (h *helloService) SayHello(ctx context.Context, req *greeterpb.HelloRequest) (*greeterpb.HelloResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	grpc.SetTrailer(ctx, metadata.Pairs("x-greeted", req.Name))
+	return &greeterpb.HelloResponse{Message: "Hello " + req.Name}, nil
+}
+
+func TestGrpcWeb(t *testing.T) {
+	const origin = "https://app.example.com"
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:    "localhost",
+			GrpcWeb: config.GrpcWebConfig{Enabled: true, AllowedOrigins: []string{origin}},
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(&helloService{})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+	url := "http://" + server.GetGrpcWebAddress() + "/greeter.Greeter/SayHello"
+	
+	// call 发送 gRPC-Web 帧格式的请求，返回响应中的消息帧和 trailer 帧
+	call := func(name string) (*http.Response, [][]byte, string) {
+		t.Helper()
+		msg, err := proto.Marshal(&greeterpb.HelloRequest{Name: name})
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		body := make([]byte, 5, 5+len(msg))
+		binary.BigEndian.PutUint32(body[1:], uint32(len(msg)))
+		req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(append(body, msg...)))
+		req.Header.Set("Content-Type", "application/grpc-web+proto")
+		req.Header.Set("X-Grpc-Web", "1")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to call gRPC-Web: %v", err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Failed to read response: %v", err)
+		}
+		
+		var messages [][]byte
+		var trailer string
+		for len(data) >= 5 {
+			n := int(binary.BigEndian.Uint32(data[1:5]))
+			if len(data) < 5+n {
+				t.Fatalf("Truncated frame in response %q", data)
+			}
+			if data[0]&0x80 != 0 {
+				trailer = string(data[5 : 5+n])
+			} else {
+				messages = append(messages, data[5:5+n])
+			}
+			data = data[5+n:]
+		}
+		if len(data) != 0 {
+			t.Fatalf("Unexpected trailing bytes %q", data)
+		}
+		return resp, messages, trailer
+	}
+	
+	resp, messages, trailer := call("web")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/grpc-web+proto" {
+		t.Errorf("Expected 200 application/grpc-web+proto, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if resp.Header.Get("Access-Control-Allow-Origin") != origin {
+		t.Errorf("Expected CORS header for %s, got %q", origin, resp.Header.Get("Access-Control-Allow-Origin"))
+	}
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message frame, got %d", len(messages))
+	}
+	reply := &greeterpb.HelloResponse{}
+	if err := proto.Unmarshal(messages[0], reply); err != nil || reply.Message != "Hello web" {
+		t.Errorf("Expected reply 'Hello web', got %v, %v", reply, err)
+	}
+	if !strings.Contains(trailer, "grpc-status: 0\r\n") || !strings.Contains(trailer, "x-greeted: web\r\n") {
+		t.Errorf("Expected OK status and custom trailer, got %q", trailer)
+	}
+	
+	// 错误状态通过 trailer 帧返回
+	_, messages, trailer = call("")
+	if len(messages) != 0 || !strings.Contains(trailer, "grpc-status: 3\r\n") || !strings.Contains(trailer, "grpc-message: name is required\r\n") {
+		t.Errorf("Expected InvalidArgument trailer without messages, got %d messages, %q", len(messages), trailer)
+	}
+	
+	// CORS 预检：允许的来源返回 204，其他来源被拒绝
+	preflight := func(origin string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodOptions, url, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send preflight: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := preflight(origin); resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Headers") != "content-type,x-grpc-web" {
+		t.Errorf("Expected preflight to be allowed, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := preflight("https://evil.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected preflight from unknown origin to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+// namedService 按服务名注册空服务的测试注册器
+type namedService string
+
+func (n namedService) RegisterService(server grpc.ServiceRegistrar) {
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: string(n),
+		HandlerType: (*interface{})(nil),
+	}, struct{}{})
+}
+
+// multiService 注册多个服务并声明服务名的注册器
+// 未通过 grpc 注册的服务名（如由 UnknownServiceHandler 处理）同样声明在 ServiceNames 中
+type multiService []string
+
+func (m multiService) RegisterService(server grpc.ServiceRegistrar) {
+	namedService(m[0]).RegisterService(server)
+}
+
+func (m multiService) ServiceNames() []string {
+	return m
+}
+
+func TestNamedServiceRegistrarHealth(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(CombineServices(
+		multiService{"test.OrderService", "test.InventoryService"},
+		multiService{"test.PaymentService", "test.OrderService"},
+	))
+
+	if err := server.Serve(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	names := []string{"test.OrderService", "test.InventoryService", "test.PaymentService"}
+	checkStatus := func(name string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := server.healthSrv.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: name})
+		if err != nil {
+			t.Fatalf("Health check for %s failed: %v", name, err)
+		}
+		return resp.Status
+	}
+
+	// 启动后、就绪前每个声明的服务名均为 NOT_SERVING
+	for _, name := range names {
+		if got := checkStatus(name); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("Expected %s to be NOT_SERVING before ready, got %v", name, got)
+		}
+	}
+
+	if err := server.MarkServing(context.Background()); err != nil {
+		t.Fatalf("Failed to mark serving: %v", err)
+	}
+	for _, name := range names {
+		if got := checkStatus(name); got != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("Expected %s to be SERVING, got %v", name, got)
+		}
+	}
+}
+
+func TestCombineServices(t *testing.T) {
+	combined := CombineServices(
+		&TestService{},
+		namedService("test.OrderService"),
+		CombineServices(namedService("test.UserService"), nil),
+	)
+
+	grpcServer := grpc.NewServer()
+	combined.RegisterService(grpcServer)
+
+	info := grpcServer.GetServiceInfo()
+	for _, name := range []string{"grpc.health.v1.Health", "test.OrderService", "test.UserService"} {
+		if _, exists := info[name]; !exists {
+			t.Errorf("Expected service %s to be registered", name)
+		}
+	}
+	if len(info) != 3 {
+		t.Errorf("Expected 3 registered services, got %d", len(info))
+	}
+}
+
+func TestMultipleListeners(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "127.0.0.1",
+			GRPCPort: 0,
+			Listeners: []config.ListenerConfig{
+				{Name: "internal", Host: "127.0.0.1", Port: 0, EnableReflection: true},
+			},
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize:   4 * 1024 * 1024,
+				MaxSendMsgSize:   4 * 1024 * 1024,
+				EnableReflection: false,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	external := server.GetAddress()
+	internal := server.GetListenerAddress("internal")
+	if external == "" || internal == "" || external == internal {
+		t.Fatalf("Expected two distinct listener addresses, got %q and %q", external, internal)
+	}
+	if server.GetListenerAddress("missing") != "" {
+		t.Error("Expected empty address for unknown listener")
+	}
+
+	for name, addr := range map[string]string{"default": external, "internal": internal} {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("Failed to create client for %s: %v", name, err)
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// 所有监听器都提供健康检查
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil || resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("Health check on %s listener failed: %v", name, err)
+		}
+
+		// 只有 internal 监听器开启反射
+		stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		if err != nil {
+			t.Fatalf("Failed to open reflection stream on %s: %v", name, err)
+		}
+		err = stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+		})
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		if name == "internal" && err != nil {
+			t.Errorf("Expected reflection on internal listener, got %v", err)
+		}
+		if name == "default" && status.Code(err) != codes.Unimplemented {
+			t.Errorf("Expected reflection to be unimplemented on default listener, got %v", err)
+		}
+	}
+}
+
+// hiddenService 可调用但不在反射服务中公开的测试服务
+type hiddenService struct{}
+
+func (hiddenService) RegisterService(server grpc.ServiceRegistrar) {
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.InternalService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Ping",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				return &emptypb.Empty{}, nil
+			},
+		}},
+	}, struct{}{})
+}
+
+func (hiddenService) ServiceDescriptor() (string, bool) {
+	return "test.InternalService", false
+}
+
+func TestReflectionHiddenService(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				EnableReflection: true,
+				MaxRecvMsgSize:   4 * 1024 * 1024,
+				MaxSendMsgSize:   4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(namedService("test.PublicService"))
+	server.RegisterService(hiddenService{})
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 未公开的服务仍可调用
+	if err := conn.Invoke(ctx, "/test.InternalService/Ping", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+		t.Fatalf("Expected hidden service to be callable, got %v", err)
+	}
+
+	stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open reflection stream: %v", err)
+	}
+	if err := stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatalf("Failed to send reflection request: %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Failed to receive reflection response: %v", err)
+	}
+
+	listed := make(map[string]bool)
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		listed[service.GetName()] = true
+	}
+	if !listed["test.PublicService"] {
+		t.Errorf("Expected test.PublicService in reflection listing, got %v", listed)
+	}
+	if listed["test.InternalService"] {
+		t.Errorf("Expected test.InternalService to be absent from reflection listing, got %v", listed)
+	}
+}
+
+func TestGRPCServerAccessor(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(hiddenService{})
+
+	// 启动前即可获取服务器，启动后默认监听器使用同一个服务器
+	before := server.GRPCServer()
+	if before == nil {
+		t.Fatal("Expected gRPC server before Start")
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	grpcServer := server.GRPCServer()
+	if grpcServer != before {
+		t.Fatal("Expected the default listener to serve the server returned before Start")
+	}
+	info := grpcServer.GetServiceInfo()
+	for _, name := range []string{grpc_health_v1.Health_ServiceDesc.ServiceName, "test.InternalService"} {
+		if _, ok := info[name]; !ok {
+			t.Errorf("Expected %s to be registered on the default server", name)
+		}
+	}
+}
+
+func TestGRPCServerRegisterBeforeStart(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+
+	// 不实现 ServiceRegistrar 的服务直接注册到服务器
+	greeterpb.RegisterGreeterServer(server.GRPCServer(), &helloService{})
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := greeterpb.NewGreeterClient(conn).SayHello(ctx, &greeterpb.HelloRequest{Name: "kit"})
+	if err != nil {
+		t.Fatalf("Expected service registered before Start to be served, got %v", err)
+	}
+	if resp.Message != "Hello kit" {
+		t.Errorf("Unexpected response %q", resp.Message)
+	}
+
+	// 停止后重新启动时新建服务器
+	if err := server.Stop(ctx); err != nil {
+		t.Fatalf("Failed to stop server: %v", err)
+	}
+	restarted := server.GRPCServer()
+	if _, ok := restarted.GetServiceInfo()["greeter.Greeter"]; ok {
+		t.Error("Expected a new gRPC server after Stop")
+	}
+}
+
+func TestChannelz(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				EnableChannelz: true,
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := channelzgrpc.NewChannelzClient(conn).GetTopChannels(ctx, &channelzgrpc.GetTopChannelsRequest{})
+	if err != nil {
+		t.Fatalf("GetTopChannels failed: %v", err)
+	}
+	// 测试客户端连接本身就是一个顶层通道
+	if len(resp.GetChannel()) == 0 {
+		t.Error("Expected at least one top channel")
+	}
+
+	// channelz 服务不单独设置健康状态
+	if _, err := server.healthSrv.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: channelzServiceName}); status.Code(err) != codes.NotFound {
+		t.Errorf("Expected channelz service to have no health status, got %v", err)
+	}
+
+	// HTTP 摘要列出本进程的服务器
+	rec := httptest.NewRecorder()
+	ChannelzHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/channelz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var summary ChannelzSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to decode summary: %v", err)
+	}
+	if len(summary.Servers) == 0 || len(summary.Channels) == 0 {
+		t.Errorf("Expected servers and channels in summary, got %+v", summary)
+	}
+}
+
+func TestChannelzDisabledByDefault(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+	}
+	server := New(cfg, zap.NewNop())
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	if _, ok := server.GRPCServer().GetServiceInfo()[channelzServiceName]; ok {
+		t.Error("Expected channelz service not to be registered by default")
+	}
+}
+
+func TestReflectionAuth(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				EnableReflection: true,
+				MaxRecvMsgSize:   4 * 1024 * 1024,
+				MaxSendMsgSize:   4 * 1024 * 1024,
+				ReflectionAuth: config.ReflectionAuthConfig{
+					Enabled:     true,
+					MetadataKey: "x-reflection-token",
+					Token:       "s3cret",
+				},
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(namedService("test.PublicService"))
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	listServices := func(ctx context.Context) error {
+		stream, err := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+		}); err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 未携带令牌的反射请求被拒绝
+	if err := listServices(ctx); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without token, got %v", err)
+	}
+	if err := listServices(metadata.AppendToOutgoingContext(ctx, "x-reflection-token", "wrong")); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated with wrong token, got %v", err)
+	}
+
+	// 携带令牌后可以正常使用
+	if err := listServices(metadata.AppendToOutgoingContext(ctx, "x-reflection-token", "s3cret")); err != nil {
+		t.Errorf("Expected reflection to succeed with token, got %v", err)
+	}
+
+	// 业务方法不需要令牌
+	if err := conn.Invoke(ctx, "/test.PublicService/Ping", &emptypb.Empty{}, &emptypb.Empty{}); status.Code(err) == codes.Unauthenticated {
+		t.Errorf("Expected service methods not to require reflection token, got %v", err)
+	}
+}
+
+// broadcastService 保持 Chat 流直到服务器停止的服务，记录生命周期回调
+type broadcastService struct {
+	greeterpb.UnimplementedGreeterServer
+	events   chan string
+	opened   chan struct{}
+	stopping chan struct{}
+}
+
+func (b *broadcastService) RegisterService(server grpc.ServiceRegistrar) {
+	greeterpb.RegisterGreeterServer(server, b)
+}
+
+func (b *broadcastService) OnServerReady() {
+	b.events <- "ready"
+}
+
+func (b *broadcastService) OnServerStopping() {
+	b.events <- "stopping"
+	close(b.stopping)
+}
+
+func (b *broadcastService) Chat(stream greeterpb.Greeter_ChatServer) error {
+	b.opened <- struct{}{}
+	select {
+	case <-b.stopping:
+		return nil
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	}
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize: 4 * 1024 * 1024,
+				MaxSendMsgSize: 4 * 1024 * 1024,
+			},
+		},
+	}
+	service := &broadcastService{
+		events:   make(chan string, 4),
+		opened:   make(chan struct{}, 1),
+		stopping: make(chan struct{}),
+	}
+	server := New(cfg, zap.NewNop())
+	server.RegisterService(CombineServices(service))
+
+	nextEvent := func() string {
+		t.Helper()
+		select {
+		case event := <-service.events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected lifecycle callback")
+			return ""
+		}
+	}
+	noEvent := func(when string) {
+		t.Helper()
+		select {
+		case event := <-service.events:
+			t.Errorf("Unexpected %s callback %s", when, event)
+		default:
+		}
+	}
+
+	// 开始监听但未就绪时不通知
+	if err := server.Serve(); err != nil {
+		t.Fatalf("Failed to serve: %v", err)
+	}
+	noEvent("before ready")
+	if err := server.MarkServing(context.Background()); err != nil {
+		t.Fatalf("Failed to mark serving: %v", err)
+	}
+	if event := nextEvent(); event != "ready" {
+		t.Fatalf("Expected ready, got %s", event)
+	}
+	server.MarkServing(context.Background())
+	noEvent("repeated ready")
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stream, err := greeterpb.NewGreeterClient(conn).Chat(ctx)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	stream.Send(&greeterpb.ChatMessage{Sender: "client", Text: "subscribe"})
+	select {
+	case <-service.opened:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected stream to reach the service")
+	}
+
+	// 停止前通知服务结束流，优雅关闭无需等待到超时
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopCancel()
+	start := time.Now()
+	if err := server.Stop(stopCtx); err != nil {
+		t.Fatalf("Failed to stop: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected graceful stop after streams end, took %v", elapsed)
+	}
+	if event := nextEvent(); event != "stopping" {
+		t.Fatalf("Expected stopping, got %s", event)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected stream to end cleanly, got %v", err)
+	}
+}
+
+func TestDisableDefaultHealth(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:     "localhost",
+			GRPCPort: 0,
+		},
+		GRPC: config.GRPCConfig{
+			Server: config.GRPCServerConfig{
+				MaxRecvMsgSize:       4 * 1024 * 1024,
+				MaxSendMsgSize:       4 * 1024 * 1024,
+				DisableDefaultHealth: true,
+			},
+		},
+	}
+	server := New(cfg, zap.NewNop())
+	// 用户自行注册的健康服务不与内置服务冲突
+	server.RegisterService(&TestService{})
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient(server.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	client := grpc_health_v1.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 摘除只影响内置健康服务，用户实现的 Check 照常返回
+	server.Drain()
+	resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected user health service to report SERVING, got %v", resp.Status)
+	}
+
+	// 用户实现没有 Watch，说明内置健康服务未注册
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("Expected Unimplemented from user health service Watch, got %v", err)
+	}
+}
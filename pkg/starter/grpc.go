@@ -2,13 +2,16 @@ package starter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/logging"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -19,13 +22,114 @@ type GrpcApplication struct {
 	logger   *zap.Logger
 	services []ServiceRegistrar
 	modules  []Module
+
+	// 追踪使用的 TracerProvider，由 WithTracerProvider 设置
+	tracerProvider trace.TracerProvider
+
+	// 调用方提供的 gRPC 服务器，由 WithExistingGrpcServer 设置
+	existingGrpcServer *grpc.Server
+
+	// 关闭超时，由 WithAppShutdownTimeout 设置
+	shutdownTimeout time.Duration
 }
 
+// defaultShutdownTimeout 未设置时的关闭超时
+const defaultShutdownTimeout = 30 * time.Second
+
 // ServiceRegistrar 服务注册接口
 type ServiceRegistrar interface {
 	RegisterService(s grpc.ServiceRegistrar)
 }
 
+// NamedServiceRegistrar 可选的服务接口，声明注册器提供的完整 gRPC 服务名
+// 服务器模块为每个服务名设置健康状态，服务发现模块按服务名分别注册
+type NamedServiceRegistrar interface {
+	ServiceRegistrar
+	ServiceNames() []string
+}
+
+// declaredServiceNames 返回实现了 NamedServiceRegistrar 的服务声明的服务名，去重并保持声明顺序
+func declaredServiceNames(services []ServiceRegistrar) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, service := range services {
+		named, ok := service.(NamedServiceRegistrar)
+		if !ok {
+			continue
+		}
+		for _, name := range named.ServiceNames() {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// ReadinessWaiter 可选的服务接口，用于需要异步初始化（加载模型、预热缓存等）的服务
+// 服务器启动后调用 WaitReady，全部返回后才将健康状态设为 SERVING，随后启动的服务发现模块才注册服务
+type ReadinessWaiter interface {
+	WaitReady(ctx context.Context) error
+}
+
+// Lifecycle 可选的服务接口，用于需要感知服务器生命周期的服务，如向已连接的流广播消息的发布订阅服务
+// 服务就绪、健康状态设为 SERVING 后调用 OnServerReady；停止时在优雅关闭前调用 OnServerStopping，
+// 服务应在其中结束长期存在的流。OnServerStopping 调用时持有服务器模块的锁，不能在其中调用模块的方法
+type Lifecycle interface {
+	OnServerReady()
+	OnServerStopping()
+}
+
+// ServiceDescriber 可选的服务接口，声明服务的完整服务名以及是否在反射服务中公开
+// exposeReflection 为 false 的服务照常提供调用，但不出现在反射服务的服务列表中
+type ServiceDescriber interface {
+	ServiceDescriptor() (name string, exposeReflection bool)
+}
+
+// reflectionHiddenServices 返回声明不在反射服务中公开的服务名
+func reflectionHiddenServices(services []ServiceRegistrar) map[string]bool {
+	hidden := make(map[string]bool)
+	for _, service := range services {
+		if describer, ok := service.(ServiceDescriber); ok {
+			if name, expose := describer.ServiceDescriptor(); !expose {
+				hidden[name] = true
+			}
+		}
+	}
+	return hidden
+}
+
+// waitServicesReady 依次等待实现了 ReadinessWaiter 的服务就绪
+func waitServicesReady(ctx context.Context, services []ServiceRegistrar) error {
+	for _, service := range services {
+		if waiter, ok := service.(ReadinessWaiter); ok {
+			if err := waiter.WaitReady(ctx); err != nil {
+				return fmt.Errorf("service %T not ready: %w", service, err)
+			}
+		}
+	}
+	return nil
+}
+
+// notifyServerReady 依次通知实现了 Lifecycle 的服务服务器已就绪
+func notifyServerReady(services []ServiceRegistrar) {
+	for _, service := range services {
+		if lifecycle, ok := service.(Lifecycle); ok {
+			lifecycle.OnServerReady()
+		}
+	}
+}
+
+// notifyServerStopping 依次通知实现了 Lifecycle 的服务服务器即将停止
+func notifyServerStopping(services []ServiceRegistrar) {
+	for _, service := range services {
+		if lifecycle, ok := service.(Lifecycle); ok {
+			lifecycle.OnServerStopping()
+		}
+	}
+}
+
 // Module 模块接口
 type Module interface {
 	Name() string
@@ -35,6 +139,28 @@ type Module interface {
 	Stop(ctx context.Context) error
 }
 
+// ShutdownPrioritizer 可选的模块接口，用于控制停止顺序
+// 优先级越高越先停止，相同优先级按启动顺序反向停止；未实现时优先级为 0
+type ShutdownPrioritizer interface {
+	ShutdownPriority() int
+}
+
+// ModuleDependencies 可选的模块接口，用于声明依赖的模块
+// 依赖的模块会先于当前模块初始化和启动，并在其之后停止；未实现时没有依赖
+type ModuleDependencies interface {
+	Dependencies() []string
+}
+
+// ModuleStartBefore 可选的模块接口，用于声明需要晚于当前模块启动的模块
+// 效果等同于这些模块依赖当前模块，可用于让用户模块（如数据库连接池）先于 grpc-server 等内置模块启动，并在其之后停止
+type ModuleStartBefore interface {
+	StartBefore() []string
+}
+
+// GrpcServerShutdownPriority gRPC 服务器模块的停止优先级
+// 低于默认值，保证依赖它的模块（指标、服务发现）先停止
+const GrpcServerShutdownPriority = -1000
+
 // New 创建新的 gRPC 应用
 func New(opts ...AppOption) *GrpcApplication {
 	// 加载默认配置
@@ -46,6 +172,8 @@ func New(opts ...AppOption) *GrpcApplication {
 		logger:   nil,
 		services: make([]ServiceRegistrar, 0),
 		modules:  make([]Module, 0),
+
+		shutdownTimeout: defaultShutdownTimeout,
 	}
 
 	// 应用选项
@@ -78,17 +206,37 @@ func (app *GrpcApplication) RegisterModule(module Module) *GrpcApplication {
 	return app
 }
 
+// ApplyConfig 应用重载后的配置
+// 目前支持动态启用或禁用内置拦截器和调整慢请求阈值，其余配置需要重启后生效
+func (app *GrpcApplication) ApplyConfig(cfg *config.Config) {
+	for _, module := range app.modules {
+		if serverModule, ok := module.(*GrpcServerModule); ok {
+			serverModule.ApplyInterceptorConfig(&cfg.GRPC.Server)
+		}
+	}
+}
+
 // Run 运行应用
 func (app *GrpcApplication) Run() error {
 	app.logger.Info("Starting gRPC application",
 		zap.String("service", "grpc-service"),
 		zap.String("version", "1.0.0"))
 
+	// 按依赖关系排序模块
+	if err := app.sortModules(); err != nil {
+		return fmt.Errorf("failed to resolve module order: %w", err)
+	}
+
 	// 初始化模块
 	if err := app.initializeModules(); err != nil {
 		return fmt.Errorf("failed to initialize modules: %w", err)
 	}
 
+	// 启动模块前注册信号，避免启动期间收到的信号直接终止进程
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
 	// 启动模块
 	ctx := context.Background()
 	if err := app.startModules(ctx); err != nil {
@@ -98,7 +246,7 @@ func (app *GrpcApplication) Run() error {
 	app.logger.Info("Application started successfully")
 
 	// 等待关闭信号
-	app.waitForShutdown()
+	app.waitForShutdown(sigChan)
 
 	// 优雅关闭
 	return app.shutdown()
@@ -107,7 +255,14 @@ func (app *GrpcApplication) Run() error {
 // autoRegisterModules 自动注册模块
 func (app *GrpcApplication) autoRegisterModules() {
 	// 注册 gRPC 服务器模块
-	app.RegisterModule(NewGrpcServerModule(app.config, app.logger))
+	serverModule := NewGrpcServerModule(app.config, app.logger)
+	if app.tracerProvider != nil {
+		serverModule.SetTracerProvider(app.tracerProvider)
+	}
+	if app.existingGrpcServer != nil {
+		serverModule.SetGrpcServer(app.existingGrpcServer)
+	}
+	app.RegisterModule(serverModule)
 
 	// 根据配置注册其他模块
 	if app.config.Metrics.Enabled {
@@ -124,6 +279,92 @@ func (app *GrpcApplication) autoRegisterModules() {
 	}
 }
 
+// moduleDependencies 返回每个模块依赖的模块下标，包括 Dependencies 和其他模块通过 StartBefore 声明的依赖
+// 引用不存在的模块时忽略该依赖并返回第一个错误
+func moduleDependencies(modules []Module) ([][]int, error) {
+	index := make(map[string]int, len(modules))
+	for i, module := range modules {
+		index[module.Name()] = i
+	}
+
+	var firstErr error
+	deps := make([][]int, len(modules))
+	for i, module := range modules {
+		if m, ok := module.(ModuleDependencies); ok {
+			for _, name := range m.Dependencies() {
+				j, ok := index[name]
+				if !ok {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("module %s depends on unknown module %s", module.Name(), name)
+					}
+					continue
+				}
+				deps[i] = append(deps[i], j)
+			}
+		}
+		if m, ok := module.(ModuleStartBefore); ok {
+			for _, name := range m.StartBefore() {
+				j, ok := index[name]
+				if !ok {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("module %s starts before unknown module %s", module.Name(), name)
+					}
+					continue
+				}
+				deps[j] = append(deps[j], i)
+			}
+		}
+	}
+	return deps, firstErr
+}
+
+// sortModules 按声明的依赖对模块进行拓扑排序
+// 没有依赖约束的模块保持注册顺序，依赖不存在或存在循环依赖时返回错误
+func (app *GrpcApplication) sortModules() error {
+	deps, err := moduleDependencies(app.modules)
+	if err != nil {
+		return err
+	}
+
+	// 每轮按注册顺序选出第一个依赖均已就绪的模块
+	sorted := make([]Module, 0, len(app.modules))
+	placed := make([]bool, len(app.modules))
+	for len(sorted) < len(app.modules) {
+		next := -1
+		for i := range app.modules {
+			if !placed[i] && dependenciesPlaced(deps[i], placed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var pending []string
+			for i, module := range app.modules {
+				if !placed[i] {
+					pending = append(pending, module.Name())
+				}
+			}
+			return fmt.Errorf("circular module dependency among %v", pending)
+		}
+
+		placed[next] = true
+		sorted = append(sorted, app.modules[next])
+	}
+
+	app.modules = sorted
+	return nil
+}
+
+// dependenciesPlaced 判断依赖的模块是否都已排好
+func dependenciesPlaced(deps []int, placed []bool) bool {
+	for _, dep := range deps {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
 // initializeModules 初始化模块
 func (app *GrpcApplication) initializeModules() error {
 	for _, module := range app.modules {
@@ -156,40 +397,98 @@ func (app *GrpcApplication) startModules(ctx context.Context) error {
 }
 
 // waitForShutdown 等待关闭信号
-func (app *GrpcApplication) waitForShutdown() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
+func (app *GrpcApplication) waitForShutdown(sigChan <-chan os.Signal) {
 	sig := <-sigChan
 	app.logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
 }
 
 // shutdown 优雅关闭
+// 所有模块都会尝试停止，停止失败的错误汇总后返回
 func (app *GrpcApplication) shutdown() error {
 	app.logger.Info("Shutting down application...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), app.shutdownTimeout)
 	defer cancel()
 
-	// 反向停止模块
-	for i := len(app.modules) - 1; i >= 0; i-- {
-		module := app.modules[i]
-		if !module.Enabled() {
-			continue
-		}
-
+	var errs []error
+	for _, module := range app.stopOrder() {
 		app.logger.Info("Stopping module", zap.String("module", module.Name()))
 		if err := module.Stop(ctx); err != nil {
 			app.logger.Error("Failed to stop module",
 				zap.String("module", module.Name()),
 				zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to stop module %s: %w", module.Name(), err))
 		}
 	}
 
+	if len(errs) > 0 {
+		app.logger.Error("Application shutdown completed with errors", zap.Int("failed_modules", len(errs)))
+		return errors.Join(errs...)
+	}
+
 	app.logger.Info("Application shutdown completed")
 	return nil
 }
 
+// stopOrder 返回已启用模块的停止顺序
+// 模块总在依赖它的模块之后停止；满足依赖约束的模块中按停止优先级从高到低选择，相同优先级按启动顺序反向停止
+func (app *GrpcApplication) stopOrder() []Module {
+	var modules []Module
+	for i := len(app.modules) - 1; i >= 0; i-- {
+		if app.modules[i].Enabled() {
+			modules = append(modules, app.modules[i])
+		}
+	}
+
+	// 依赖错误已在 sortModules 中报告，这里只使用能解析的依赖
+	deps, _ := moduleDependencies(modules)
+	dependents := make([]int, len(modules))
+	for _, ds := range deps {
+		for _, j := range ds {
+			dependents[j]++
+		}
+	}
+
+	order := make([]Module, 0, len(modules))
+	stopped := make([]bool, len(modules))
+	for len(order) < len(modules) {
+		next := pickStop(modules, stopped, dependents, true)
+		if next < 0 {
+			// 存在循环依赖时只按优先级停止剩余模块
+			next = pickStop(modules, stopped, dependents, false)
+		}
+
+		stopped[next] = true
+		order = append(order, modules[next])
+		for _, j := range deps[next] {
+			dependents[j]--
+		}
+	}
+	return order
+}
+
+// pickStop 选出下一个停止的模块，checkDependents 为 true 时只考虑依赖它的模块均已停止的模块
+func pickStop(modules []Module, stopped []bool, dependents []int, checkDependents bool) int {
+	next := -1
+	for i, module := range modules {
+		if stopped[i] || (checkDependents && dependents[i] > 0) {
+			continue
+		}
+		if next < 0 || shutdownPriority(module) > shutdownPriority(modules[next]) {
+			next = i
+		}
+	}
+	return next
+}
+
+// shutdownPriority 获取模块的停止优先级
+func shutdownPriority(module Module) int {
+	if p, ok := module.(ShutdownPrioritizer); ok {
+		return p.ShutdownPriority()
+	}
+	return 0
+}
+
 // createDefaultLogger 创建默认日志器
 func createDefaultLogger(cfg *config.Config) *zap.Logger {
 	var level zap.AtomicLevel
@@ -206,15 +505,5 @@ func createDefaultLogger(cfg *config.Config) *zap.Logger {
 		level = zap.NewAtomicLevelAt(zap.InfoLevel)
 	}
 
-	config := zap.Config{
-		Level:            level,
-		Development:      false,
-		Encoding:         cfg.Logging.Format,
-		EncoderConfig:    zap.NewProductionEncoderConfig(),
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
-	}
-
-	logger, _ := config.Build()
-	return logger
+	return logging.NewWithFallback(cfg.Logging, level)
 }
\ No newline at end of file
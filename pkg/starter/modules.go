@@ -2,22 +2,31 @@ package starter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/grpcserver"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/logging"
+	"github.com/go-grpc-kit/go-grpc-kit/internal/safe"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/interceptor"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/version"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	channelzservice "google.golang.org/grpc/channelz/service"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
 )
 
 // GrpcServerModule gRPC 服务器模块
@@ -27,17 +36,56 @@ type GrpcServerModule struct {
 	grpcServer *grpc.Server
 	listener   net.Listener
 	healthSrv  *health.Server
-	started    bool
-	mu         sync.RWMutex
+	// initialized 与 started 以及 grpcServer、listener 均由 mu 保护
+	initialized bool
+	started     bool
+	mu          sync.RWMutex
+
+	// 拦截器运行时开关，配置重载后无需重启即可生效
+	loggingSwitch  *interceptor.Switch
+	recoverySwitch *interceptor.Switch
+	metricsSwitch  *interceptor.Switch
+	// 慢请求阈值，配置重载后无需重启即可生效
+	slowThreshold *interceptor.Threshold
+
+	// 追踪使用的 TracerProvider，未设置时使用 otel 全局 TracerProvider
+	tracerProvider trace.TracerProvider
+
+	// 访问日志器，开启 logging.access_log 时日志拦截器的每次调用记录写入其中
+	accessLogger *zap.Logger
+
+	// 调用方提供的 gRPC 服务器，设置后不再新建服务器
+	existingServer *grpc.Server
+
+	// 已注册的业务服务，启动后等待其就绪
+	services []ServiceRegistrar
+	// 业务服务声明的服务名，与整体状态一起设置健康状态
+	healthServices []string
 }
 
 // NewGrpcServerModule 创建 gRPC 服务器模块
 func NewGrpcServerModule(cfg *config.Config, logger *zap.Logger) *GrpcServerModule {
-	return &GrpcServerModule{
+	m := &GrpcServerModule{
 		config:    cfg,
 		logger:    logger,
 		healthSrv: health.NewServer(),
+
+		loggingSwitch:  interceptor.NewSwitch(cfg.GRPC.Server.EnableLogging),
+		recoverySwitch: interceptor.NewSwitch(cfg.GRPC.Server.EnableRecovery),
+		metricsSwitch:  interceptor.NewSwitch(cfg.GRPC.Server.EnableMetrics),
+		slowThreshold:  interceptor.NewThreshold(time.Duration(cfg.GRPC.Server.SlowThreshold) * time.Millisecond),
+	}
+
+	// 访问日志无法打开时调用记录仍写入主日志器
+	if cfg.Logging.AccessLog.Enabled {
+		accessLogger, err := logging.NewAccessLogger(cfg.Logging.AccessLog)
+		if err != nil {
+			logger.Warn("Failed to create access logger, logging calls to application logger", zap.Error(err))
+		} else {
+			m.accessLogger = accessLogger
+		}
 	}
+	return m
 }
 
 func (m *GrpcServerModule) Name() string {
@@ -48,82 +96,167 @@ func (m *GrpcServerModule) Enabled() bool {
 	return true // gRPC 服务器总是启用
 }
 
+// ShutdownPriority gRPC 服务器最后停止
+func (m *GrpcServerModule) ShutdownPriority() int {
+	return GrpcServerShutdownPriority
+}
+
 func (m *GrpcServerModule) Initialize(app *GrpcApplication) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 已初始化（包括已启动）时不重复绑定端口
+	if m.initialized {
+		m.logger.Warn("gRPC server already initialized, skipping")
+		return nil
+	}
+
+	// 调用方提供的服务器停止后无法再次使用
+	if m.existingServer != nil && m.grpcServer == m.existingServer {
+		return fmt.Errorf("existing gRPC server cannot be reused after stop")
+	}
+
+	if m.config.Server.MultiplexHTTP {
+		m.logger.Warn("server.multiplex_http is only supported by the app framework, ignoring")
+	}
+	if m.config.Server.GrpcWeb.Enabled {
+		m.logger.Warn("server.grpc_web is only supported by the app framework, ignoring")
+	}
+
 	// 创建监听器
-	addr := fmt.Sprintf("%s:%d", m.config.Server.Host, m.config.Server.GRPCPort)
-	listener, err := net.Listen("tcp", addr)
+	listener, err := grpcserver.Listen(&m.config.Server, m.config.Server.Host, m.config.Server.GRPCPort, m.logger)
 	if err != nil {
-		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		return err
 	}
-	m.listener = listener
+	m.listener = grpcserver.LimitListener(listener, m.config.GRPC.Server.MaxConnections)
 
-	// 构建服务器选项
-	opts := m.buildServerOptions()
+	if m.existingServer != nil {
+		// 使用调用方提供的服务器，配置中的服务器选项不生效
+		m.grpcServer = m.existingServer
+		m.logger.Info("Using existing gRPC server, server options from config are ignored")
+	} else {
+		// 构建服务器选项
+		opts := m.buildServerOptions()
 
-	// 创建 gRPC 服务器
-	m.grpcServer = grpc.NewServer(opts...)
+		// 创建 gRPC 服务器
+		m.grpcServer = grpc.NewServer(opts...)
+	}
 
-	// 注册健康检查服务
-	grpc_health_v1.RegisterHealthServer(m.grpcServer, m.healthSrv)
+	// 注册健康检查服务，配置禁用或调用方提供的服务器已注册时跳过
+	if !m.config.GRPC.Server.DisableDefaultHealth && !grpcserver.HasService(m.grpcServer, grpc_health_v1.Health_ServiceDesc.ServiceName) {
+		grpc_health_v1.RegisterHealthServer(m.grpcServer, m.healthSrv)
+	}
 
-	// 根据配置注册反射服务
-	if m.config.GRPC.Server.EnableReflection {
-		reflection.Register(m.grpcServer)
+	// 根据配置注册反射服务，声明不公开的服务不出现在服务列表中
+	if m.config.GRPC.Server.EnableReflection && !grpcserver.HasService(m.grpcServer, grpc_reflection_v1.ServerReflection_ServiceDesc.ServiceName) {
+		grpcserver.RegisterReflection(m.grpcServer, reflectionHiddenServices(app.services))
+	}
+
+	// 根据配置注册 channelz 服务
+	if m.config.GRPC.Server.EnableChannelz && !grpcserver.HasService(m.grpcServer, "grpc.channelz.v1.Channelz") {
+		channelzservice.RegisterChannelzServiceToServer(m.grpcServer)
 	}
 
 	// 注册业务服务
 	for _, service := range app.services {
 		service.RegisterService(m.grpcServer)
 	}
+	m.services = app.services
+	m.healthServices = declaredServiceNames(app.services)
 
+	m.initialized = true
 	m.logger.Info("gRPC server initialized",
-		zap.String("address", addr),
+		zap.String("address", m.listener.Addr().String()),
 		zap.Int("services", len(app.services)))
 
 	return nil
 }
 
+// Start 启动服务器
+// 服务实现 ReadinessWaiter 时，等待其就绪后才将健康状态设为 SERVING，随后通知实现了 Lifecycle 的服务
 func (m *GrpcServerModule) Start(ctx context.Context) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	if m.started {
+		m.mu.Unlock()
 		return nil
 	}
+	if !m.initialized {
+		m.mu.Unlock()
+		return fmt.Errorf("gRPC server module not initialized")
+	}
 
-	// 启动服务器
-	go func() {
-		if err := m.grpcServer.Serve(m.listener); err != nil {
+	// 就绪前报告不可用
+	m.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	// 启动服务器，使用局部变量避免与 Stop 竞争
+	server, listener, services := m.grpcServer, m.listener, m.services
+	go safe.Run(m.logger, "gRPC server panicked", func() {
+		if err := server.Serve(listener); err != nil {
 			m.logger.Error("gRPC server failed", zap.Error(err))
 		}
-	}()
-
-	// 设置健康状态
-	m.healthSrv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	})
 
 	m.started = true
-	m.logger.Info("gRPC server started", zap.String("address", m.listener.Addr().String()))
+	m.mu.Unlock()
+	m.logger.Info("gRPC server started", zap.String("address", listener.Addr().String()))
+
+	// 等待服务就绪时不持有锁，允许并发 Stop
+	if err := waitServicesReady(ctx, services); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+
+	// 等待期间已停止时保持不可用状态
+	ready := m.started && m.grpcServer == server
+	if ready {
+		m.setServingStatus(grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+	m.mu.Unlock()
+
+	if ready {
+		notifyServerReady(services)
+	}
 
 	return nil
 }
 
+// setServingStatus 同时设置整体和各声明服务的健康状态，调用方需持有 m.mu
+func (m *GrpcServerModule) setServingStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	m.healthSrv.SetServingStatus("", status)
+	for _, name := range m.healthServices {
+		m.healthSrv.SetServingStatus(name, status)
+	}
+}
+
+// Stop 停止服务器
+// 停止后的 gRPC 服务器无法再次启动，需要重新 Initialize
 func (m *GrpcServerModule) Stop(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if !m.started {
+		// 已初始化但未启动时释放监听端口
+		if m.initialized {
+			m.listener.Close()
+			m.initialized = false
+		}
 		return nil
 	}
 
 	m.logger.Info("Stopping gRPC server...")
 
 	// 设置健康状态为不可用
-	m.healthSrv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	m.setServingStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	// 通知服务结束长期存在的流，避免优雅关闭等待到超时
+	notifyServerStopping(m.services)
 
 	// 优雅关闭
+	server := m.grpcServer
 	done := make(chan struct{})
 	go func() {
-		m.grpcServer.GracefulStop()
+		server.GracefulStop()
 		close(done)
 	}()
 
@@ -133,10 +266,11 @@ func (m *GrpcServerModule) Stop(ctx context.Context) error {
 		m.logger.Info("gRPC server stopped gracefully")
 	case <-ctx.Done():
 		m.logger.Warn("Force stopping gRPC server due to timeout")
-		m.grpcServer.Stop()
+		server.Stop()
 	}
 
 	m.started = false
+	m.initialized = false
 	return nil
 }
 
@@ -149,17 +283,18 @@ func (m *GrpcServerModule) buildServerOptions() []grpc.ServerOption {
 		grpc.MaxSendMsgSize(m.config.GRPC.Server.MaxSendMsgSize),
 	)
 
+	// 一元请求超限时由框架直接拒绝，通过 stats.Handler 记录方法名和上限
+	if serverCfg := m.config.GRPC.Server; serverCfg.EnableMessageSizeCheck {
+		opts = append(opts, grpc.StatsHandler(interceptor.MessageSizeStatsHandler(serverCfg.MaxRecvMsgSize, m.logger)))
+	}
+
 	// 设置连接配置
 	if m.config.GRPC.Server.MaxConcurrentStreams > 0 {
 		opts = append(opts, grpc.MaxConcurrentStreams(m.config.GRPC.Server.MaxConcurrentStreams))
 	}
 
 	// 设置 Keepalive 配置
-	if m.config.GRPC.Server.KeepaliveTime > 0 {
-		keepaliveParams := keepalive.ServerParameters{
-			Time:    time.Duration(m.config.GRPC.Server.KeepaliveTime) * time.Second,
-			Timeout: time.Duration(m.config.GRPC.Server.KeepaliveTimeout) * time.Second,
-		}
+	if keepaliveParams, ok := grpcserver.KeepaliveParameters(&m.config.GRPC.Server); ok {
 		opts = append(opts, grpc.KeepaliveParams(keepaliveParams))
 	}
 
@@ -176,6 +311,17 @@ func (m *GrpcServerModule) buildServerOptions() []grpc.ServerOption {
 		opts = append(opts, grpc.ConnectionTimeout(time.Duration(m.config.GRPC.Server.ConnectionTimeout)*time.Second))
 	}
 
+	// 设置流控窗口和头部列表大小，值为 0 时使用 gRPC 默认值
+	if m.config.GRPC.Server.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(m.config.GRPC.Server.InitialWindowSize))
+	}
+	if m.config.GRPC.Server.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(m.config.GRPC.Server.InitialConnWindowSize))
+	}
+	if m.config.GRPC.Server.MaxHeaderListSize > 0 {
+		opts = append(opts, grpc.MaxHeaderListSize(m.config.GRPC.Server.MaxHeaderListSize))
+	}
+
 	// 构建拦截器链
 	unaryInterceptors, streamInterceptors := m.buildInterceptors()
 
@@ -194,33 +340,151 @@ func (m *GrpcServerModule) buildInterceptors() ([]grpc.UnaryServerInterceptor, [
 	var unaryInterceptors []grpc.UnaryServerInterceptor
 	var streamInterceptors []grpc.StreamServerInterceptor
 
-	// 根据配置添加拦截器
-	if m.config.GRPC.Server.EnableLogging {
-		unaryInterceptors = append(unaryInterceptors, interceptor.LoggingUnaryInterceptor(m.logger))
-		streamInterceptors = append(streamInterceptors, interceptor.LoggingStreamInterceptor(m.logger))
+	// 追踪拦截器位于最前，span 覆盖整个拦截器链
+	if m.config.GRPC.Server.EnableTracing {
+		unaryInterceptors = append(unaryInterceptors, interceptor.TracingUnaryInterceptor(m.tracingOptions()...))
+		streamInterceptors = append(streamInterceptors, interceptor.TracingStreamInterceptor(m.tracingOptions()...))
 	}
 
-	if m.config.GRPC.Server.EnableRecovery {
-		unaryInterceptors = append(unaryInterceptors, interceptor.RecoveryUnaryInterceptor(m.logger))
-		streamInterceptors = append(streamInterceptors, interceptor.RecoveryStreamInterceptor(m.logger))
+	// 请求 ID 拦截器位于追踪之后，后续拦截器可从上下文获取请求 ID
+	if m.config.GRPC.Server.EnableRequestID {
+		unaryInterceptors = append(unaryInterceptors, interceptor.RequestIDUnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, interceptor.RequestIDStreamInterceptor())
 	}
 
-	if m.config.GRPC.Server.EnableMetrics {
-		unaryInterceptors = append(unaryInterceptors, interceptor.MetricsUnaryInterceptor())
-		streamInterceptors = append(streamInterceptors, interceptor.MetricsStreamInterceptor())
+	loggingOpts := []interceptor.LoggingOption{
+		interceptor.WithPayloadLogging(m.config.GRPC.Server.LogPayloads, m.config.GRPC.Server.LogPayloadMaxSize),
+		interceptor.WithDynamicSlowThreshold(m.slowThreshold),
+	}
+	if m.accessLogger != nil {
+		loggingOpts = append(loggingOpts, interceptor.WithAccessLog(m.accessLogger))
 	}
 
-	// TODO: 添加 Tracing 拦截器支持
-	// if m.config.GRPC.Server.EnableTracing {
-	//     unaryInterceptors = append(unaryInterceptors, interceptor.TracingUnaryInterceptor())
-	//     streamInterceptors = append(streamInterceptors, interceptor.TracingStreamInterceptor())
-	// }
+	// 请求持续时间直方图使用配置的桶边界
+	if err := interceptor.ConfigureLatencyBuckets(m.config.Metrics.LatencyBuckets); err != nil {
+		m.logger.Warn("Failed to configure latency buckets, keeping previous buckets", zap.Error(err))
+	}
+
+	// 内置拦截器始终加入拦截器链，是否执行由运行时开关决定，顺序由 interceptor_order 配置
+	order := m.config.GRPC.Server.InterceptorOrder
+	unaryInterceptors = append(unaryInterceptors, interceptor.OrderBuiltins(order, map[string]grpc.UnaryServerInterceptor{
+		interceptor.InterceptorRecovery: interceptor.ToggleUnaryInterceptor(m.recoverySwitch, interceptor.RecoveryUnaryInterceptor(m.logger)),
+		interceptor.InterceptorLogging:  interceptor.ToggleUnaryInterceptor(m.loggingSwitch, interceptor.LoggingUnaryInterceptor(m.logger, loggingOpts...)),
+		interceptor.InterceptorMetrics:  interceptor.ToggleUnaryInterceptor(m.metricsSwitch, interceptor.MetricsUnaryInterceptor()),
+	})...)
+	streamInterceptors = append(streamInterceptors, interceptor.OrderBuiltins(order, map[string]grpc.StreamServerInterceptor{
+		interceptor.InterceptorRecovery: interceptor.ToggleStreamInterceptor(m.recoverySwitch, interceptor.RecoveryStreamInterceptor(m.logger)),
+		interceptor.InterceptorLogging:  interceptor.ToggleStreamInterceptor(m.loggingSwitch, interceptor.LoggingStreamInterceptor(m.logger, loggingOpts...)),
+		interceptor.InterceptorMetrics:  interceptor.ToggleStreamInterceptor(m.metricsSwitch, interceptor.MetricsStreamInterceptor()),
+	})...)
+
+	// 消息大小校验，位于内置拦截器之后以便记录被拒绝的请求
+	if serverCfg := m.config.GRPC.Server; serverCfg.EnableMessageSizeCheck {
+		unaryInterceptors = append(unaryInterceptors, interceptor.MessageSizeUnaryInterceptor(serverCfg.MaxRecvMsgSize))
+		streamInterceptors = append(streamInterceptors, interceptor.MessageSizeStreamInterceptor(serverCfg.MaxRecvMsgSize))
+	}
+
+	// 反射服务访问控制，位于内置拦截器之后以便记录被拒绝的请求
+	if auth := m.config.GRPC.Server.ReflectionAuth; auth.Enabled {
+		unaryInterceptors = append(unaryInterceptors, interceptor.ReflectionAuthUnaryInterceptor(auth.MetadataKey, auth.Token))
+		streamInterceptors = append(streamInterceptors, interceptor.ReflectionAuthStreamInterceptor(auth.MetadataKey, auth.Token))
+	}
+
+	// 方法访问控制，位于内置拦截器之后以便记录被拒绝的请求
+	if acl := m.config.GRPC.Server.MethodACL; len(acl.Allow) > 0 || len(acl.Deny) > 0 {
+		code := interceptor.MethodACLCode(acl.Code)
+		unaryInterceptors = append(unaryInterceptors, interceptor.MethodACLUnaryInterceptor(acl.Allow, acl.Deny, code))
+		streamInterceptors = append(streamInterceptors, interceptor.MethodACLStreamInterceptor(acl.Allow, acl.Deny, code))
+	}
+
+	// 必需元数据校验，位于内置拦截器之后以便记录被拒绝的请求
+	if serverCfg := m.config.GRPC.Server; len(serverCfg.RequiredMetadata) > 0 {
+		unaryInterceptors = append(unaryInterceptors, interceptor.RequiredMetadataUnaryInterceptor(serverCfg.RequiredMetadata))
+		streamInterceptors = append(streamInterceptors, interceptor.RequiredMetadataStreamInterceptor(serverCfg.RequiredMetadata))
+	}
+
+	// 请求校验，位于内置拦截器之后以便记录被拒绝的请求
+	if m.config.GRPC.Server.EnableValidation {
+		unaryInterceptors = append(unaryInterceptors, interceptor.ValidationUnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, interceptor.ValidationStreamInterceptor())
+	}
+
+	// 废弃方法告警
+	if serverCfg := m.config.GRPC.Server; len(serverCfg.DeprecatedMethods) > 0 {
+		unaryInterceptors = append(unaryInterceptors, interceptor.DeprecationUnaryInterceptor(m.logger, serverCfg.DeprecatedMethods, serverCfg.DeprecationHeader))
+		streamInterceptors = append(streamInterceptors, interceptor.DeprecationStreamInterceptor(m.logger, serverCfg.DeprecatedMethods, serverCfg.DeprecationHeader))
+	}
+
+	// 服务器标识响应头
+	if serverCfg := m.config.GRPC.Server; serverCfg.EnableServerIdentity {
+		identity := interceptor.ResolveServerIdentity(serverCfg.ServerIdentity)
+		unaryInterceptors = append(unaryInterceptors, interceptor.ServerIdentityUnaryInterceptor(serverCfg.ServerIdentityHeader, identity))
+		streamInterceptors = append(streamInterceptors, interceptor.ServerIdentityStreamInterceptor(serverCfg.ServerIdentityHeader, identity))
+	}
+
+	// 响应压缩，客户端支持时使用配置的算法
+	if serverCfg := m.config.GRPC.Server; serverCfg.EnableCompression {
+		unaryInterceptors = append(unaryInterceptors, interceptor.CompressionUnaryInterceptor(serverCfg.CompressionLevel))
+		streamInterceptors = append(streamInterceptors, interceptor.CompressionStreamInterceptor(serverCfg.CompressionLevel))
+	}
 
 	return unaryInterceptors, streamInterceptors
 }
 
+// tracingOptions 构建追踪拦截器选项
+func (m *GrpcServerModule) tracingOptions() []interceptor.TracingOption {
+	if m.tracerProvider == nil {
+		return nil
+	}
+	return []interceptor.TracingOption{interceptor.WithTracerProvider(m.tracerProvider)}
+}
+
+// SetTracerProvider 设置追踪使用的 TracerProvider，需在 Initialize 之前调用
+func (m *GrpcServerModule) SetTracerProvider(tp trace.TracerProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.tracerProvider = tp
+}
+
+// SetGrpcServer 使用调用方提供的 gRPC 服务器，需在 Initialize 之前调用
+// 配置中的服务器选项和内置拦截器不生效；健康检查、反射（未注册时）和业务服务仍会注册到该服务器，因此不能提前调用其 Serve
+func (m *GrpcServerModule) SetGrpcServer(server *grpc.Server) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.existingServer = server
+}
+
+// ApplyInterceptorConfig 应用拦截器配置，用于配置重载后动态启用或禁用内置拦截器并调整慢请求阈值
+func (m *GrpcServerModule) ApplyInterceptorConfig(cfg *config.GRPCServerConfig) {
+	m.loggingSwitch.Set(cfg.EnableLogging)
+	m.recoverySwitch.Set(cfg.EnableRecovery)
+	m.metricsSwitch.Set(cfg.EnableMetrics)
+	m.slowThreshold.Set(time.Duration(cfg.SlowThreshold) * time.Millisecond)
+
+	m.logger.Info("gRPC server interceptor config applied",
+		zap.Bool("logging", cfg.EnableLogging),
+		zap.Bool("recovery", cfg.EnableRecovery),
+		zap.Bool("metrics", cfg.EnableMetrics),
+		zap.Int("slow_threshold_ms", cfg.SlowThreshold))
+}
+
+// GRPCServer 返回模块使用的 *grpc.Server，Initialize 之前返回 nil
+// 在 Initialize 与 Start 之间可以注册 channelz、xDS 等不是 ServiceRegistrar 的服务；
+// Start 之后服务器已在服务，再注册服务或修改服务器是不安全的
+func (m *GrpcServerModule) GRPCServer() *grpc.Server {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.grpcServer
+}
+
 // GetAddress 获取服务器地址
 func (m *GrpcServerModule) GetAddress() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	if m.listener == nil {
 		return ""
 	}
@@ -255,8 +519,9 @@ func (m *MetricsModule) Enabled() bool {
 func (m *MetricsModule) Initialize(app *GrpcApplication) error {
 	mux := http.NewServeMux()
 
-	// 指标端点
-	mux.Handle(m.config.Metrics.Path, promhttp.Handler())
+	// 指标端点，抓取方请求 OpenMetrics 格式时同时返回请求持续时间的追踪样本
+	mux.Handle(m.config.Metrics.Path, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
 
 	// 健康检查端点
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -270,12 +535,21 @@ func (m *MetricsModule) Initialize(app *GrpcApplication) error {
 		w.Write([]byte("Ready"))
 	})
 
+	// 版本信息端点
+	mux.Handle("/version", version.Handler())
+
+	// 设置连接超时，避免慢速连接占用资源
+	timeouts := m.config.Metrics.HTTPTimeouts
 	m.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", m.config.Metrics.Port),
-		Handler: mux,
+		Addr:              fmt.Sprintf("%s:%d", m.config.Metrics.Host, m.config.Metrics.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: time.Duration(timeouts.ReadHeaderTimeout) * time.Second,
+		ReadTimeout:       time.Duration(timeouts.ReadTimeout) * time.Second,
+		WriteTimeout:      time.Duration(timeouts.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(timeouts.IdleTimeout) * time.Second,
 	}
 
-	m.logger.Info("Metrics module initialized", zap.Int("port", m.config.Metrics.Port))
+	m.logger.Info("Metrics module initialized", zap.String("address", m.httpServer.Addr))
 	return nil
 }
 
@@ -323,10 +597,19 @@ type DiscoveryModule struct {
 	config         *config.Config
 	logger         *zap.Logger
 	serviceName    string
+	serviceNames   []string // 业务服务声明的服务名，分别注册到服务发现
 	serviceManager *discovery.ServiceManager
 	registry       discovery.Registry
 	started        bool
 	mu             sync.RWMutex
+
+	// 应用的 gRPC 服务器模块，注册时使用其实际监听的端口
+	grpcServer *GrpcServerModule
+
+	// 后台保持注册的重试策略和协程
+	backoff       discovery.RegistrationBackoff
+	registerStop  context.CancelFunc
+	registerGroup sync.WaitGroup
 }
 
 // NewDiscoveryModule 创建服务发现模块
@@ -347,15 +630,23 @@ func (m *DiscoveryModule) Enabled() bool {
 }
 
 func (m *DiscoveryModule) Initialize(app *GrpcApplication) error {
-	// 创建服务发现注册器
-	registry, err := discovery.NewRegistry(&m.config.Discovery, m.logger)
-	if err != nil {
-		return fmt.Errorf("failed to create registry: %w", err)
+	// 创建服务发现注册器，已预先设置时直接使用
+	if m.registry == nil {
+		registry, err := discovery.NewRegistry(&m.config.Discovery, m.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create registry: %w", err)
+		}
+		m.registry = registry
 	}
-	m.registry = registry
 
 	// 创建服务管理器
-	m.serviceManager = discovery.NewServiceManager(registry, m.logger)
+	m.serviceManager = discovery.NewServiceManager(m.registry, m.logger)
+	m.serviceNames = declaredServiceNames(app.services)
+	for _, module := range app.modules {
+		if serverModule, ok := module.(*GrpcServerModule); ok {
+			m.grpcServer = serverModule
+		}
+	}
 
 	m.logger.Info("Discovery module initialized",
 		zap.String("type", m.config.Discovery.Type),
@@ -372,28 +663,58 @@ func (m *DiscoveryModule) Start(ctx context.Context) error {
 		return nil
 	}
 
-	// 注册服务到服务发现
+	// 注册服务到服务发现，业务服务声明的服务名分别注册
+	// 注册失败时在后台按指数退避加抖动重试，租约丢失后重新注册，直到模块停止
+	keepCtx, cancel := context.WithCancel(context.Background())
+	m.registerStop = cancel
+	for _, name := range append([]string{m.serviceName}, m.serviceNames...) {
+		serviceInfo := m.registerService(ctx, name)
+		m.registerGroup.Add(1)
+		go func() {
+			defer m.registerGroup.Done()
+			m.serviceManager.KeepRegistered(keepCtx, serviceInfo, m.backoff)
+		}()
+	}
+
+	m.started = true
+	return nil
+}
+
+// registerService 以指定服务名注册到服务发现，失败时只记录日志，允许应用继续运行
+func (m *DiscoveryModule) registerService(ctx context.Context, name string) *discovery.ServiceInfo {
+	address, port, err := discovery.AdvertiseAddress(m.config)
+	if err != nil {
+		m.logger.Warn("Failed to resolve advertise address, registering listen host", zap.Error(err))
+		address, port = m.config.Server.Host, m.config.Server.GRPCPort
+	}
+
+	// 未配置 advertise_port 时注册实际监听的端口，grpc_port 为 0 或 auto_port 改用空闲端口时也能被正确发现
+	if m.config.Discovery.AdvertisePort == 0 && m.grpcServer != nil {
+		if _, boundPort, err := net.SplitHostPort(m.grpcServer.GetAddress()); err == nil {
+			if p, err := strconv.Atoi(boundPort); err == nil {
+				port = p
+			}
+		}
+	}
+
 	serviceInfo := &discovery.ServiceInfo{
-		Name:    m.serviceName,
-		Address: m.config.Server.Host,
-		Port:    m.config.Server.GRPCPort,
-		Metadata: map[string]string{
-			"version": "1.0.0",
-		},
+		Name:     name,
+		Address:  address,
+		Port:     port,
+		Metadata: discovery.InstanceMetadata(&m.config.Discovery),
 	}
 
 	if err := m.serviceManager.RegisterService(ctx, serviceInfo); err != nil {
-		m.logger.Warn("Failed to register service to discovery", zap.Error(err))
-		// 不返回错误，允许应用继续运行
-	} else {
-		m.logger.Info("Service registered to discovery",
-			zap.String("service", m.serviceName),
-			zap.String("address", serviceInfo.Address),
-			zap.Int("port", serviceInfo.Port))
+		m.logger.Warn("Failed to register service to discovery, retrying in background",
+			zap.String("service", name),
+			zap.Error(err))
+		return serviceInfo
 	}
-
-	m.started = true
-	return nil
+	m.logger.Info("Service registered to discovery",
+		zap.String("service", name),
+		zap.String("address", serviceInfo.Address),
+		zap.Int("port", serviceInfo.Port))
+	return serviceInfo
 }
 
 func (m *DiscoveryModule) Stop(ctx context.Context) error {
@@ -406,17 +727,25 @@ func (m *DiscoveryModule) Stop(ctx context.Context) error {
 
 	m.logger.Info("Stopping discovery module...")
 
-	// 注销所有服务
+	// 停止后台注册，之后不会再有新的注册
+	m.registerStop()
+	m.registerGroup.Wait()
+
+	// 注销所有服务，失败时继续关闭注册器，错误合并返回
+	var errs []error
 	if err := m.serviceManager.DeregisterAll(ctx); err != nil {
 		m.logger.Error("Failed to deregister services", zap.Error(err))
+		errs = append(errs, fmt.Errorf("failed to deregister services: %w", err))
 	}
 
 	// 关闭注册器
 	if err := m.registry.Close(); err != nil {
 		m.logger.Error("Failed to close registry", zap.Error(err))
+		errs = append(errs, fmt.Errorf("failed to close registry: %w", err))
 	}
+	m.registry = nil
 
 	m.started = false
 	m.logger.Info("Discovery module stopped")
-	return nil
+	return errors.Join(errs...)
 }
\ No newline at end of file
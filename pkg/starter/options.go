@@ -1,8 +1,12 @@
 package starter
 
 import (
+	"time"
+
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 // AppOption 配置选项
@@ -77,6 +81,32 @@ func WithAppMetrics(enabled bool) AppOption {
 	}
 }
 
+// WithTracerProvider 设置 gRPC 服务器追踪使用的 TracerProvider
+// 仅在 grpc.server.enable_tracing 启用时生效，未设置时使用 otel 全局 TracerProvider
+func WithTracerProvider(tp trace.TracerProvider) AppOption {
+	return func(app *GrpcApplication) {
+		app.tracerProvider = tp
+	}
+}
+
+// WithExistingGrpcServer 使用调用方已配置的 gRPC 服务器
+// 框架仍负责监听、启动、健康检查和服务发现，但不再根据配置构建服务器选项和内置拦截器
+func WithExistingGrpcServer(server *grpc.Server) AppOption {
+	return func(app *GrpcApplication) {
+		app.existingGrpcServer = server
+	}
+}
+
+// WithAppShutdownTimeout 设置关闭超时，所有模块需在超时内停止，小于等于 0 时使用默认的 30 秒
+// 容器平台的 terminationGracePeriodSeconds 较短时应设置为小于该值
+func WithAppShutdownTimeout(timeout time.Duration) AppOption {
+	return func(app *GrpcApplication) {
+		if timeout > 0 {
+			app.shutdownTimeout = timeout
+		}
+	}
+}
+
 // DefaultOptions 默认配置选项
 func DefaultOptions() []AppOption {
 	return []AppOption{
@@ -2,12 +2,24 @@ package starter
 
 import (
 	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/go-grpc-kit/go-grpc-kit/internal/grpcserver"
 	"github.com/go-grpc-kit/go-grpc-kit/pkg/config"
+	"github.com/go-grpc-kit/go-grpc-kit/pkg/discovery"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // MockService 模拟 gRPC 服务
@@ -364,10 +376,67 @@ func TestStartAndStopModules(t *testing.T) {
 	}
 }
 
+func TestGrpcServerModuleConcurrentLifecycle(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.GRPCPort = 0
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+	module := NewGrpcServerModule(&cfg, zap.NewNop())
+
+	// 未初始化时不能启动
+	if err := module.Start(context.Background()); err == nil {
+		t.Error("Expected error when starting before initialize")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := module.Initialize(app); err != nil {
+				t.Errorf("Initialize failed: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			// 可能早于初始化执行，此时返回未初始化错误
+			module.Start(context.Background())
+			module.GetAddress()
+		}()
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if err := module.Stop(ctx); err != nil {
+				t.Errorf("Stop failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 重复初始化是安全的，最终可以正常启动和停止
+	if err := module.Initialize(app); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := module.Initialize(app); err != nil {
+		t.Fatalf("Second Initialize failed: %v", err)
+	}
+	if err := module.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := module.Stop(ctx); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+}
+
 // MockModule 模拟模块
 type MockModule struct {
 	name    string
 	enabled bool
+	stopErr error
+	started *[]string
+	stopped *[]string
 }
 
 func (m *MockModule) Name() string {
@@ -386,13 +455,351 @@ func (m *MockModule) Initialize(app *GrpcApplication) error {
 }
 
 func (m *MockModule) Start(ctx context.Context) error {
+	if m.started != nil {
+		*m.started = append(*m.started, m.Name())
+	}
 	return nil
 }
 
 func (m *MockModule) Stop(ctx context.Context) error {
+	if m.stopped != nil {
+		*m.stopped = append(*m.stopped, m.Name())
+	}
+	return m.stopErr
+}
+
+// priorityModule 带停止优先级的模拟模块
+type priorityModule struct {
+	MockModule
+	priority int
+}
+
+func (m *priorityModule) ShutdownPriority() int {
+	return m.priority
+}
+
+// dependentModule 声明依赖的模拟模块
+type dependentModule struct {
+	MockModule
+	deps []string
+}
+
+func (m *dependentModule) Dependencies() []string {
+	return m.deps
+}
+
+// startBeforeModule 声明需要晚于自己启动的模块的模拟模块
+type startBeforeModule struct {
+	MockModule
+	before []string
+}
+
+func (m *startBeforeModule) StartBefore() []string {
+	return m.before
+}
+
+// signalModule 启动时向当前进程发送 SIGTERM，用于测试 Run 的关闭流程
+type signalModule struct {
+	MockModule
+}
+
+func (m *signalModule) Start(ctx context.Context) error {
+	return syscall.Kill(os.Getpid(), syscall.SIGTERM)
+}
+
+func TestShutdownAggregatesStopErrors(t *testing.T) {
+	app := New(
+		WithAppLogger(zap.NewNop()),
+		WithGrpcPort(0),
+		WithAppMetrics(false),
+		WithAppDiscovery(false),
+	)
+
+	var stopped []string
+	errFirst := errors.New("drain failed")
+	errSecond := errors.New("deregister failed")
+	app.RegisterModule(&MockModule{name: "first", enabled: true, stopErr: errFirst, stopped: &stopped})
+	app.RegisterModule(&MockModule{name: "ok", enabled: true, stopped: &stopped})
+	app.RegisterModule(&MockModule{name: "second", enabled: true, stopErr: errSecond, stopped: &stopped})
+
+	// 注销失败的服务发现模块
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Discovery.Type = "etcd"
+	errDeregister := errors.New("registry unavailable")
+	discoveryModule := &DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "failing-service",
+		registry:    &recordingRegistry{deregisterErr: errDeregister},
+	}
+	if err := discoveryModule.Initialize(app); err != nil {
+		t.Fatalf("Failed to initialize discovery module: %v", err)
+	}
+	if err := discoveryModule.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start discovery module: %v", err)
+	}
+	app.RegisterModule(discoveryModule)
+
+	err := app.shutdown()
+	if err == nil {
+		t.Fatal("Expected shutdown to return an error")
+	}
+	if !errors.Is(err, errFirst) || !errors.Is(err, errSecond) {
+		t.Errorf("Expected both stop errors to be joined, got %v", err)
+	}
+	if !errors.Is(err, errDeregister) {
+		t.Errorf("Expected deregister error to be joined, got %v", err)
+	}
+
+	// 失败的模块不影响其它模块停止
+	if len(stopped) != 3 {
+		t.Errorf("Expected all 3 modules to be stopped, got %v", stopped)
+	}
+}
+
+// deadlineModule 记录 Stop 收到的上下文截止时间的模拟模块
+type deadlineModule struct {
+	MockModule
+	deadline time.Time
+}
+
+func (m *deadlineModule) Stop(ctx context.Context) error {
+	m.deadline, _ = ctx.Deadline()
 	return nil
 }
 
+func TestWithAppShutdownTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []AppOption
+		timeout time.Duration
+	}{
+		{name: "default", timeout: 30 * time.Second},
+		{name: "configured", opts: []AppOption{WithAppShutdownTimeout(5 * time.Second)}, timeout: 5 * time.Second},
+		{name: "non-positive keeps default", opts: []AppOption{WithAppShutdownTimeout(0)}, timeout: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]AppOption{
+				WithAppLogger(zap.NewNop()),
+				WithGrpcPort(0),
+				WithAppMetrics(false),
+				WithAppDiscovery(false),
+			}, tt.opts...)
+			app := New(opts...)
+			module := &deadlineModule{MockModule: MockModule{name: "deadline", enabled: true}}
+			app.RegisterModule(module)
+
+			before := time.Now()
+			if err := app.shutdown(); err != nil {
+				t.Fatalf("Failed to shutdown: %v", err)
+			}
+			// 模块停止时的截止时间由关闭超时决定
+			remaining := module.deadline.Sub(before)
+			if remaining < tt.timeout-time.Second || remaining > tt.timeout+time.Second {
+				t.Errorf("Expected shutdown deadline about %v, got %v", tt.timeout, remaining)
+			}
+		})
+	}
+}
+
+func TestShutdownStopsGrpcServerLast(t *testing.T) {
+	app := New(
+		WithAppLogger(zap.NewNop()),
+		WithGrpcPort(0),
+		WithAppMetrics(false),
+		WithAppDiscovery(false),
+	)
+	app.RegisterModule(&MockModule{name: "metrics", enabled: true})
+	app.RegisterModule(&MockModule{name: "discovery", enabled: true})
+
+	// 模拟在 gRPC 服务器之前注册的模块
+	app.modules = append([]Module{&MockModule{name: "early", enabled: true}}, app.modules...)
+
+	var names []string
+	for _, module := range app.stopOrder() {
+		names = append(names, module.Name())
+	}
+
+	expected := []string{"discovery", "metrics", "early", "grpc-server"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected stop order %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected stop order %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestShutdownPriorityOrder(t *testing.T) {
+	app := New(
+		WithAppLogger(zap.NewNop()),
+		WithGrpcPort(0),
+		WithAppMetrics(false),
+		WithAppDiscovery(false),
+	)
+
+	var stopped []string
+	app.RegisterModule(&priorityModule{MockModule{name: "discovery", enabled: true, stopped: &stopped}, 10})
+	app.RegisterModule(&MockModule{name: "http", enabled: true, stopped: &stopped})
+	app.RegisterModule(&MockModule{name: "cache", enabled: true, stopped: &stopped})
+	app.RegisterModule(&priorityModule{MockModule{name: "flush", enabled: true, stopped: &stopped}, -5})
+	app.RegisterModule(&priorityModule{MockModule{name: "disabled", enabled: false, stopped: &stopped}, 100})
+
+	var names []string
+	for _, module := range app.stopOrder() {
+		names = append(names, module.Name())
+	}
+
+	// 高优先级先停止，相同优先级反向注册顺序，gRPC 服务器最后
+	expected := []string{"discovery", "cache", "http", "flush", "grpc-server"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected stop order %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected stop order %v, got %v", expected, names)
+		}
+	}
+
+	if err := app.shutdown(); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+	if len(stopped) != 4 || stopped[0] != "discovery" || stopped[3] != "flush" {
+		t.Errorf("Unexpected stop sequence %v", stopped)
+	}
+}
+
+func TestModuleDependenciesOrder(t *testing.T) {
+	app := New(
+		WithAppLogger(zap.NewNop()),
+		WithGrpcPort(0),
+		WithAppMetrics(false),
+		WithAppDiscovery(false),
+	)
+
+	var started, stopped []string
+	app.RegisterModule(&dependentModule{MockModule{name: "handler", enabled: true, started: &started, stopped: &stopped}, []string{"db", "cache"}})
+	app.RegisterModule(&MockModule{name: "audit", enabled: true, started: &started, stopped: &stopped})
+	app.RegisterModule(&dependentModule{MockModule{name: "cache", enabled: true, started: &started, stopped: &stopped}, []string{"db"}})
+	app.RegisterModule(&MockModule{name: "db", enabled: true, started: &started, stopped: &stopped})
+
+	if err := app.sortModules(); err != nil {
+		t.Fatalf("Failed to sort modules: %v", err)
+	}
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	if err := app.startModules(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	if err := app.shutdown(); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	expectedStart := []string{"audit", "db", "cache", "handler"}
+	expectedStop := []string{"handler", "cache", "db", "audit"}
+	for i := range expectedStart {
+		if len(started) != len(expectedStart) || started[i] != expectedStart[i] {
+			t.Fatalf("Expected start order %v, got %v", expectedStart, started)
+		}
+		if len(stopped) != len(expectedStop) || stopped[i] != expectedStop[i] {
+			t.Fatalf("Expected stop order %v, got %v", expectedStop, stopped)
+		}
+	}
+}
+
+func TestModuleStartBeforeGrpcServer(t *testing.T) {
+	app := New(
+		WithAppLogger(zap.NewNop()),
+		WithGrpcPort(0),
+		WithAppMetrics(false),
+		WithAppDiscovery(false),
+	)
+
+	// 在内置模块之后注册，仍然先于 grpc-server 启动
+	var started, stopped []string
+	app.RegisterModule(&MockModule{name: "audit", enabled: true, started: &started, stopped: &stopped})
+	app.RegisterModule(&startBeforeModule{MockModule{name: "db", enabled: true, started: &started, stopped: &stopped}, []string{"grpc-server"}})
+
+	if err := app.sortModules(); err != nil {
+		t.Fatalf("Failed to sort modules: %v", err)
+	}
+	var order []string
+	for _, module := range app.modules {
+		order = append(order, module.Name())
+	}
+	expectedStart := []string{"audit", "db", "grpc-server"}
+	for i := range expectedStart {
+		if len(order) != len(expectedStart) || order[i] != expectedStart[i] {
+			t.Fatalf("Expected start order %v, got %v", expectedStart, order)
+		}
+	}
+
+	// grpc-server 停止优先级最低，但 db 被它依赖，仍在其之后停止
+	var stopOrder []string
+	for _, module := range app.stopOrder() {
+		stopOrder = append(stopOrder, module.Name())
+	}
+	expectedStop := []string{"audit", "grpc-server", "db"}
+	for i := range expectedStop {
+		if len(stopOrder) != len(expectedStop) || stopOrder[i] != expectedStop[i] {
+			t.Fatalf("Expected stop order %v, got %v", expectedStop, stopOrder)
+		}
+	}
+
+	app = New(WithAppLogger(zap.NewNop()), WithAppMetrics(false), WithAppDiscovery(false))
+	app.RegisterModule(&startBeforeModule{MockModule{name: "db", enabled: true}, []string{"missing"}})
+	if err := app.sortModules(); err == nil {
+		t.Error("Expected error for unknown StartBefore module")
+	}
+}
+
+func TestModuleDependenciesErrors(t *testing.T) {
+	app := New(WithAppLogger(zap.NewNop()), WithAppMetrics(false), WithAppDiscovery(false))
+	app.RegisterModule(&dependentModule{MockModule{name: "a", enabled: true}, []string{"b"}})
+	app.RegisterModule(&dependentModule{MockModule{name: "b", enabled: true}, []string{"a"}})
+
+	if err := app.sortModules(); err == nil {
+		t.Error("Expected error for circular dependency")
+	}
+
+	app = New(WithAppLogger(zap.NewNop()), WithAppMetrics(false), WithAppDiscovery(false))
+	app.RegisterModule(&dependentModule{MockModule{name: "a", enabled: true}, []string{"missing"}})
+
+	if err := app.sortModules(); err == nil {
+		t.Error("Expected error for unknown dependency")
+	}
+}
+
+func TestRunReturnsShutdownError(t *testing.T) {
+	app := New(
+		WithAppLogger(zap.NewNop()),
+		WithGrpcPort(0),
+		WithAppMetrics(false),
+		WithAppDiscovery(false),
+	)
+	errStop := errors.New("drain failed")
+	app.RegisterModule(&signalModule{MockModule{name: "signal", enabled: true, stopErr: errStop}})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run()
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, errStop) {
+			t.Errorf("Expected Run to return stop error, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run did not return after SIGTERM")
+	}
+}
+
 // BenchmarkNew 性能测试
 func BenchmarkNew(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -426,4 +833,607 @@ func BenchmarkNewWithOptions(b *testing.B) {
 			b.Fatal("Expected app to be created")
 		}
 	}
-}
\ No newline at end of file
+}
+func TestMetricsModuleHTTPTimeouts(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Metrics = config.MetricsConfig{
+		Enabled: true,
+		Port:    8081,
+		Path:    "/metrics",
+		HTTPTimeouts: config.HTTPTimeoutsConfig{
+			ReadHeaderTimeout: 2,
+			ReadTimeout:       3,
+			WriteTimeout:      4,
+			IdleTimeout:       5,
+		},
+	}
+
+	module := NewMetricsModule(cfg, zap.NewNop())
+	if err := module.Initialize(&GrpcApplication{config: cfg}); err != nil {
+		t.Fatalf("Failed to initialize metrics module: %v", err)
+	}
+
+	if module.httpServer.ReadHeaderTimeout != 2*time.Second {
+		t.Errorf("Expected ReadHeaderTimeout 2s, got %s", module.httpServer.ReadHeaderTimeout)
+	}
+	if module.httpServer.ReadTimeout != 3*time.Second {
+		t.Errorf("Expected ReadTimeout 3s, got %s", module.httpServer.ReadTimeout)
+	}
+	if module.httpServer.WriteTimeout != 4*time.Second {
+		t.Errorf("Expected WriteTimeout 4s, got %s", module.httpServer.WriteTimeout)
+	}
+	if module.httpServer.IdleTimeout != 5*time.Second {
+		t.Errorf("Expected IdleTimeout 5s, got %s", module.httpServer.IdleTimeout)
+	}
+}
+
+func TestMetricsModuleHost(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Metrics = config.MetricsConfig{
+		Enabled: true,
+		Port:    8081,
+		Path:    "/metrics",
+	}
+
+	module := NewMetricsModule(cfg, zap.NewNop())
+	if err := module.Initialize(&GrpcApplication{config: cfg}); err != nil {
+		t.Fatalf("Failed to initialize metrics module: %v", err)
+	}
+	if module.httpServer.Addr != ":8081" {
+		t.Errorf("Expected default address ':8081', got '%s'", module.httpServer.Addr)
+	}
+
+	cfg.Metrics.Host = "127.0.0.1"
+	module = NewMetricsModule(cfg, zap.NewNop())
+	if err := module.Initialize(&GrpcApplication{config: cfg}); err != nil {
+		t.Fatalf("Failed to initialize metrics module: %v", err)
+	}
+	if module.httpServer.Addr != "127.0.0.1:8081" {
+		t.Errorf("Expected address '127.0.0.1:8081', got '%s'", module.httpServer.Addr)
+	}
+}
+
+// recordingRegistry 记录注册服务的模拟注册器
+type recordingRegistry struct {
+	mu         sync.Mutex
+	registered []*discovery.ServiceInfo
+	// failures 剩余的失败注册次数
+	failures int
+	attempts int
+	// deregisterErr 注销时返回的错误
+	deregisterErr error
+}
+
+func (r *recordingRegistry) Register(ctx context.Context, service *discovery.ServiceInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("registry unavailable")
+	}
+	r.registered = append(r.registered, service)
+	return nil
+}
+
+func (r *recordingRegistry) Deregister(ctx context.Context, service *discovery.ServiceInfo) error {
+	return r.deregisterErr
+}
+
+func (r *recordingRegistry) Discover(ctx context.Context, serviceName string) ([]*discovery.ServiceInfo, error) {
+	return nil, nil
+}
+
+func (r *recordingRegistry) Watch(ctx context.Context, serviceName string) (<-chan []*discovery.ServiceInfo, error) {
+	return make(chan []*discovery.ServiceInfo), nil
+}
+
+func (r *recordingRegistry) Close() error {
+	return nil
+}
+
+func TestWithExistingGrpcServer(t *testing.T) {
+	// 预先获取空闲端口，服务发现注册的是配置中的端口
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port: %v", err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = port
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+
+	// 调用方自带拦截器的服务器
+	var intercepted atomic.Int32
+	existing := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		intercepted.Add(1)
+		return handler(ctx, req)
+	}))
+
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()), WithExistingGrpcServer(existing))
+
+	// 使用模拟注册器的服务发现模块代替自动注册的 etcd 模块
+	cfg.Discovery.Type = "etcd"
+	registry := &recordingRegistry{}
+	app.RegisterModule(&DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "existing-service",
+		registry:    registry,
+	})
+
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	if err := app.startModules(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	defer app.shutdown()
+
+	serverModule := app.modules[0].(*GrpcServerModule)
+	if serverModule.grpcServer != existing {
+		t.Fatal("Expected module to wrap the existing gRPC server")
+	}
+
+	conn, err := grpc.NewClient(serverModule.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING status, got %v", resp.Status)
+	}
+	if intercepted.Load() != 1 {
+		t.Errorf("Expected caller's interceptor to handle the call, got %d calls", intercepted.Load())
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if len(registry.registered) != 1 {
+		t.Fatalf("Expected 1 registered service, got %d", len(registry.registered))
+	}
+	if info := registry.registered[0]; info.Name != "existing-service" || info.Port != port {
+		t.Errorf("Expected existing-service on port %d, got %s on port %d", port, info.Name, info.Port)
+	}
+}
+
+// slowReadyService 在 ready 关闭前阻塞 WaitReady 的服务
+type slowReadyService struct {
+	MockService
+	ready chan struct{}
+}
+
+func (s *slowReadyService) WaitReady(ctx context.Context) error {
+	select {
+	case <-s.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestServiceReadinessGating(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+
+	service := &slowReadyService{ready: make(chan struct{})}
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+	app.RegisterService(service)
+
+	// 使用模拟注册器的服务发现模块
+	cfg.Discovery.Type = "etcd"
+	registry := &recordingRegistry{}
+	app.RegisterModule(&DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "slow-service",
+		registry:    registry,
+	})
+
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	started := make(chan error, 1)
+	go func() {
+		started <- app.startModules(context.Background())
+	}()
+	defer app.shutdown()
+
+	address := app.modules[0].(*GrpcServerModule).GetAddress()
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	healthStatus := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		return resp.Status
+	}
+
+	// 服务就绪前不可用且未注册到服务发现
+	if got := healthStatus(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected NOT_SERVING before ready, got %v", got)
+	}
+	registry.mu.Lock()
+	registeredEarly := len(registry.registered)
+	registry.mu.Unlock()
+	if registeredEarly != 0 {
+		t.Errorf("Expected no registration before ready, got %d", registeredEarly)
+	}
+
+	close(service.ready)
+	if err := <-started; err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+
+	if got := healthStatus(); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING after ready, got %v", got)
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if len(registry.registered) != 1 {
+		t.Errorf("Expected 1 registration after ready, got %d", len(registry.registered))
+	}
+}
+
+// multiNameService 声明多个服务名的服务
+type multiNameService struct {
+	MockService
+	names []string
+}
+
+func (s *multiNameService) ServiceNames() []string {
+	return s.names
+}
+
+func TestNamedServiceRegistrar(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+	app.RegisterService(&multiNameService{names: []string{"test.OrderService", "test.InventoryService"}})
+	app.RegisterService(&MockService{})
+
+	cfg.Discovery.Type = "etcd"
+	registry := &recordingRegistry{}
+	app.RegisterModule(&DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "multi-service",
+		registry:    registry,
+	})
+
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	if err := app.startModules(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	defer app.shutdown()
+
+	// 每个声明的服务名都有健康状态
+	serverModule := app.modules[0].(*GrpcServerModule)
+	for _, name := range []string{"test.OrderService", "test.InventoryService"} {
+		resp, err := serverModule.healthSrv.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: name})
+		if err != nil {
+			t.Fatalf("Health check for %s failed: %v", name, err)
+		}
+		if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			t.Errorf("Expected %s to be SERVING, got %v", name, resp.Status)
+		}
+	}
+
+	// 模块的服务名和每个声明的服务名分别注册
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	var names []string
+	for _, info := range registry.registered {
+		names = append(names, info.Name)
+	}
+	expected := []string{"multi-service", "test.OrderService", "test.InventoryService"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected registrations %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected registration %d to be %s, got %s", i, expected[i], names[i])
+		}
+	}
+}
+
+func TestDiscoveryModuleRegistrationRetry(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 9090
+	cfg.Discovery.Type = "etcd"
+
+	registry := &recordingRegistry{failures: 3}
+	module := &DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "flaky-service",
+		registry:    registry,
+		backoff:     discovery.RegistrationBackoff{InitialInterval: 10 * time.Millisecond, MaxInterval: 40 * time.Millisecond},
+	}
+	if err := module.Initialize(New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))); err != nil {
+		t.Fatalf("Failed to initialize module: %v", err)
+	}
+
+	// 首次注册失败不阻止启动
+	if err := module.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start module: %v", err)
+	}
+
+	registered := func() int {
+		registry.mu.Lock()
+		defer registry.mu.Unlock()
+		return len(registry.registered)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for registered() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected service to eventually register")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := module.Stop(context.Background()); err != nil {
+		t.Fatalf("Failed to stop module: %v", err)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if registry.attempts != 4 {
+		t.Errorf("Expected 4 registration attempts, got %d", registry.attempts)
+	}
+	if len(registry.registered) != 1 || registry.registered[0].Name != "flaky-service" {
+		t.Errorf("Expected flaky-service to be registered once, got %v", registry.registered)
+	}
+}
+
+func TestDiscoveryModuleAdvertiseAddress(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "0.0.0.0"
+	cfg.Server.GRPCPort = 9090
+	cfg.Discovery.Type = "etcd"
+	cfg.Discovery.AdvertisePort = 19090
+	cfg.Discovery.Metadata.Weight = 2
+	cfg.Discovery.Metadata.Zone = "zone-a"
+
+	registry := &recordingRegistry{}
+	module := &DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "wildcard-service",
+		registry:    registry,
+	}
+	if err := module.Initialize(New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))); err != nil {
+		t.Fatalf("Failed to initialize module: %v", err)
+	}
+	if err := module.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start module: %v", err)
+	}
+	defer module.Stop(context.Background())
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if len(registry.registered) != 1 {
+		t.Fatalf("Expected service to be registered once, got %d", len(registry.registered))
+	}
+	// 通配地址替换为本机的具体 IP
+	service := registry.registered[0]
+	if ip := net.ParseIP(service.Address); ip == nil || ip.IsUnspecified() {
+		t.Errorf("Expected concrete IP address, got %q", service.Address)
+	}
+	if service.Port != 19090 {
+		t.Errorf("Expected advertise port 19090, got %d", service.Port)
+	}
+	// 实例元数据来自 discovery.metadata
+	if service.Weight() != 2 || service.Zone() != "zone-a" || !service.IsServing() {
+		t.Errorf("Expected metadata from config, got %v", service.Metadata)
+	}
+}
+
+func TestDiscoveryModuleRegistersBoundPort(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+
+	// 使用模拟注册器的服务发现模块
+	cfg.Discovery.Type = "etcd"
+	registry := &recordingRegistry{}
+	app.RegisterModule(&DiscoveryModule{
+		config:      &cfg,
+		logger:      zap.NewNop(),
+		serviceName: "bound-service",
+		registry:    registry,
+	})
+
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	if err := app.startModules(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	defer app.shutdown()
+
+	// grpc_port 为 0 时注册实际监听的端口
+	_, boundPort, err := net.SplitHostPort(app.modules[0].(*GrpcServerModule).GetAddress())
+	if err != nil {
+		t.Fatalf("Failed to parse server address: %v", err)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if len(registry.registered) != 1 {
+		t.Fatalf("Expected 1 registered service, got %d", len(registry.registered))
+	}
+	if info := registry.registered[0]; info.Address != "localhost" || strconv.Itoa(info.Port) != boundPort {
+		t.Errorf("Expected localhost:%s to be registered, got %s:%d", boundPort, info.Address, info.Port)
+	}
+}
+
+func TestGrpcServerModuleGRPCServer(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+	serverModule := app.modules[0].(*GrpcServerModule)
+	if serverModule.GRPCServer() != nil {
+		t.Error("Expected no gRPC server before Initialize")
+	}
+
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+
+	// 在 Initialize 与 Start 之间直接在服务器上注册额外的服务
+	var pings atomic.Int32
+	serverModule.GRPCServer().RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.ExtraService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Ping",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				in := new(emptypb.Empty)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				pings.Add(1)
+				return &emptypb.Empty{}, nil
+			},
+		}},
+	}, struct{}{})
+
+	if err := app.startModules(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	defer app.shutdown()
+
+	conn, err := grpc.NewClient(serverModule.GetAddress(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := conn.Invoke(ctx, "/test.ExtraService/Ping", &emptypb.Empty{}, &emptypb.Empty{}); err != nil {
+		t.Fatalf("Failed to call extra service: %v", err)
+	}
+	if pings.Load() != 1 {
+		t.Errorf("Expected extra service to handle 1 call, got %d", pings.Load())
+	}
+}
+
+// lifecycleService 记录生命周期回调的服务
+type lifecycleService struct {
+	MockService
+	mu     sync.Mutex
+	events []string
+}
+
+func (s *lifecycleService) OnServerReady() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, "ready")
+}
+
+func (s *lifecycleService) OnServerStopping() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, "stopping")
+}
+
+func (s *lifecycleService) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.events...)
+}
+
+func TestServiceLifecycle(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+
+	service := &lifecycleService{}
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+	app.RegisterService(service)
+
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	if events := service.recorded(); len(events) != 0 {
+		t.Errorf("Expected no callbacks before start, got %v", events)
+	}
+
+	if err := app.startModules(context.Background()); err != nil {
+		t.Fatalf("Failed to start modules: %v", err)
+	}
+	if events := service.recorded(); len(events) != 1 || events[0] != "ready" {
+		t.Errorf("Expected ready after start, got %v", events)
+	}
+
+	app.shutdown()
+	if events := service.recorded(); len(events) != 2 || events[1] != "stopping" {
+		t.Errorf("Expected stopping after shutdown, got %v", events)
+	}
+}
+
+func TestDisableDefaultHealth(t *testing.T) {
+	cfg := *config.Get()
+	cfg.Server.Host = "localhost"
+	cfg.Server.GRPCPort = 0
+	cfg.Metrics.Enabled = false
+	cfg.Discovery.Type = ""
+	cfg.AutoRegister.Enabled = false
+	cfg.GRPC.Server.DisableDefaultHealth = true
+
+	app := New(WithConfig(&cfg), WithAppLogger(zap.NewNop()))
+	if err := app.initializeModules(); err != nil {
+		t.Fatalf("Failed to initialize modules: %v", err)
+	}
+	defer app.shutdown()
+
+	module := app.modules[0].(*GrpcServerModule)
+	if grpcserver.HasService(module.grpcServer, grpc_health_v1.Health_ServiceDesc.ServiceName) {
+		t.Error("Expected standard health service not to be registered")
+	}
+}
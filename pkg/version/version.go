@@ -0,0 +1,68 @@
+// Package version 提供构建版本信息，通过 -ldflags -X 在构建时注入
+//
+//	go build -ldflags "-X github.com/go-grpc-kit/go-grpc-kit/pkg/version.Version=v1.2.0 \
+//		-X github.com/go-grpc-kit/go-grpc-kit/pkg/version.Commit=$(git rev-parse HEAD) \
+//		-X github.com/go-grpc-kit/go-grpc-kit/pkg/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 构建时注入的版本信息
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// 构建信息指标，值固定为 1，版本信息作为标签
+var buildInfo = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "build_info",
+		Help: "Build information of the running binary",
+	},
+	[]string{"version", "commit", "build_date", "go_version"},
+)
+
+func init() {
+	info := Get()
+	buildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion).Set(1)
+}
+
+// Info 版本信息
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get 获取当前版本信息
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String 返回可读的版本描述
+func (i Info) String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", i.Version, i.Commit, i.BuildDate, i.GoVersion)
+}
+
+// Handler 返回以 JSON 输出版本信息的 HTTP 处理器
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Get())
+	})
+}
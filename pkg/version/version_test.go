@@ -0,0 +1,60 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandlerReturnsInjectedValues(t *testing.T) {
+	oldVersion, oldCommit, oldDate := Version, Commit, BuildDate
+	defer func() { Version, Commit, BuildDate = oldVersion, oldCommit, oldDate }()
+	Version, Commit, BuildDate = "v1.2.3", "abc123", "2024-01-02T03:04:05Z"
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+
+	var info Info
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	want := Info{Version: "v1.2.3", Commit: "abc123", BuildDate: "2024-01-02T03:04:05Z", GoVersion: runtime.Version()}
+	if info != want {
+		t.Errorf("Expected %+v, got %+v", want, info)
+	}
+}
+
+func TestBuildInfoGaugeRegistered(t *testing.T) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	found := false
+	for _, family := range families {
+		if family.GetName() == "build_info" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("Expected build_info metric to be registered")
+	}
+
+	info := Get()
+	value := testutil.ToFloat64(buildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion))
+	if value != 1 {
+		t.Errorf("Expected build_info value 1, got %v", value)
+	}
+}